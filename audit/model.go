@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/util"
+	"time"
+)
+
+// Actions recorded by the audit log
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Sources of the changes
+const (
+	SourceREST    = "rest"
+	SourceGraphQL = "graphql"
+	// SourceImport marks changes applied from samples or files
+	SourceImport = "import"
+)
+
+// Entities recorded by the audit log
+const (
+	EntityPage        = "page"
+	EntityEvent       = "event"
+	EntityField       = "field"
+	EntityType        = "type"
+	EntityProject     = "project"
+	EntityEnvironment = "environment"
+	EntityMonitor     = "monitor"
+	EntityRequest     = "request"
+	EntityFolder      = "folder"
+	// EntityCollection is the collection of the saved requests of the event replaced by the import
+	EntityCollection = "collection"
+	EntityUser       = "user"
+	// EntityProjectRole is the role of the user in the project, the id of the entity is the id of the user
+	EntityProjectRole = "project_role"
+	EntityAPIToken    = "api_token"
+	EntityScenario    = "scenario"
+	// EntitySnapshot, EntityRecording and EntityFuzzRun are recorded without the catalog, the messages and the report
+	EntitySnapshot  = "snapshot"
+	EntityRecording = "recording"
+	EntityLoadTest  = "load_test"
+	EntityFuzzRun   = "fuzz_run"
+)
+
+// Entry records a single change of an entity with its state before and after the change.
+// Before is null for created entities, after is null for deleted ones.
+type Entry struct {
+	ID       uint64 `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	Entity   string `json:"entity" gorm:"size:32;index:idx_audit_entries_entity;column:entity"`
+	EntityId uint64 `json:"entityId" gorm:"index:idx_audit_entries_entity;column:entityId"`
+	// ProjectId is the project of pages and events, zero for entities common for all projects
+	ProjectId uint64 `json:"projectId" gorm:"index;column:projectId"`
+	Action    string `json:"action" gorm:"size:16;column:action"`
+	// ActorId is the user of the request, null for anonymous requests and service tokens
+	ActorId    util.NullInt64 `json:"actorId" gorm:"type:BIGINT;index;column:actorId"`
+	ApiTokenId util.NullInt64 `json:"apiTokenId" gorm:"type:BIGINT;column:apiTokenId"`
+	RequestId  string         `json:"requestId" gorm:"size:64;index;column:requestId"`
+	Source     string         `json:"source" gorm:"size:16;column:source"`
+	Before     util.RawJSON   `json:"before" gorm:"type:longtext;column:before"`
+	After      util.RawJSON   `json:"after" gorm:"type:longtext;column:after"`
+	CreatedAt  time.Time      `json:"createdAt" gorm:"index;column:createdAt"`
+}
+
+func (Entry) TableName() string {
+	return "audit_entries"
+}
+
+// Filter selects the entries of the list, zero values match any entry.
+type Filter struct {
+	Entity    string
+	EntityId  uint64
+	ProjectId uint64
+	ActorId   uint64
+	Action    string
+	Source    string
+	RequestId string
+	// Entries created in [From, To)
+	From time.Time
+	To   time.Time
+}
+
+// Match reports whether the entry passes the filter.
+func (f *Filter) Match(e *Entry) bool {
+	switch {
+	case len(f.Entity) > 0 && e.Entity != f.Entity,
+		f.EntityId > 0 && e.EntityId != f.EntityId,
+		f.ProjectId > 0 && e.ProjectId != f.ProjectId,
+		f.ActorId > 0 && (!e.ActorId.Valid || uint64(e.ActorId.Int64) != f.ActorId),
+		len(f.Action) > 0 && e.Action != f.Action,
+		len(f.Source) > 0 && e.Source != f.Source,
+		len(f.RequestId) > 0 && e.RequestId != f.RequestId,
+		!f.From.IsZero() && e.CreatedAt.Before(f.From),
+		!f.To.IsZero() && !e.CreatedAt.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// Snapshot returns the JSON of the entity state, nil for nil entities. The state is copied at once,
+// so later changes of the entity do not leak into the entry.
+func Snapshot(v interface{}) util.RawJSON {
+	if v == nil {
+		return nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil
+	}
+	return b
+}
+
+type sourceContextKey struct{}
+
+// WithSource returns the copy of the context marking the changes with the source.
+func WithSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, sourceContextKey{}, source)
+}
+
+// SourceFromContext returns the source of the changes, REST when the context does not set it.
+func SourceFromContext(ctx context.Context) string {
+	if ctx != nil {
+		if source, ok := ctx.Value(sourceContextKey{}).(string); ok && len(source) > 0 {
+			return source
+		}
+	}
+	return SourceREST
+}
@@ -0,0 +1,63 @@
+package audit
+
+import (
+	"github.com/nskondratev/api-page-go-back/logger"
+	"time"
+)
+
+// DefaultRetentionTick is the period of removing the expired entries
+const DefaultRetentionTick = time.Hour
+
+type RetentionConfig struct {
+	Store  Store
+	Logger logger.Logger
+	// Retention is the age of the removed entries, entries are kept forever when zero
+	Retention time.Duration
+	Tick      time.Duration
+}
+
+// Retention removes the entries older than the retention period.
+type Retention struct {
+	c    *RetentionConfig
+	tick time.Duration
+}
+
+func NewRetention(c *RetentionConfig) *Retention {
+	r := &Retention{c: c, tick: c.Tick}
+	if r.tick <= 0 {
+		r.tick = DefaultRetentionTick
+	}
+	return r
+}
+
+// Run prunes the entries every tick, it returns at once when the entries are kept forever.
+func (r *Retention) Run() {
+	if r.c.Retention <= 0 {
+		return
+	}
+	r.prune(time.Now())
+	ticker := time.NewTicker(r.tick)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		r.prune(now)
+	}
+}
+
+func (r *Retention) prune(now time.Time) {
+	deleted, err := r.Prune(now)
+	if err != nil {
+		r.c.Logger.Errorf("Error while removing expired audit entries: %s", err.Error())
+		return
+	}
+	if deleted > 0 {
+		r.c.Logger.Infof("Removed %d expired audit entries", deleted)
+	}
+}
+
+// Prune removes the entries older than the retention period at the moment.
+func (r *Retention) Prune(now time.Time) (int, error) {
+	if r.c.Retention <= 0 {
+		return 0, nil
+	}
+	return r.c.Store.DeleteBefore(now.Add(-r.c.Retention))
+}
@@ -0,0 +1,29 @@
+package audit_test
+
+import (
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/audit/store"
+	"testing"
+	"time"
+)
+
+func TestRetention_Prune(t *testing.T) {
+	s := store.NewMemory(&store.MemoryConfig{})
+	now := time.Now()
+	for _, age := range []time.Duration{48 * time.Hour, 12 * time.Hour, time.Minute} {
+		if err := s.Create(&audit.Entry{Entity: audit.EntityPage, Action: audit.ActionUpdate, CreatedAt: now.Add(-age)}); err != nil {
+			t.Fatalf("Can not create test entry: %s", err.Error())
+		}
+	}
+
+	if deleted, err := audit.NewRetention(&audit.RetentionConfig{Store: s}).Prune(now); err != nil || deleted != 0 {
+		t.Errorf("Entries are removed without retention: %d, %v", deleted, err)
+	}
+	deleted, err := audit.NewRetention(&audit.RetentionConfig{Store: s, Retention: 24 * time.Hour}).Prune(now)
+	if err != nil {
+		t.Fatalf("Can not prune entries: %s", err.Error())
+	}
+	if _, total, _ := s.List(&audit.Filter{}, 0, 100); deleted != 1 || total != 2 {
+		t.Errorf("Unexpected entries after pruning %d: total %d", deleted, total)
+	}
+}
@@ -0,0 +1,11 @@
+package audit
+
+import "time"
+
+type Store interface {
+	Create(*Entry) error
+	// List returns the entries passing the filter, newest first
+	List(f *Filter, offset, limit int) ([]*Entry, int, error)
+	// DeleteBefore removes the entries created before the time and returns their number
+	DeleteBefore(time.Time) (int, error)
+}
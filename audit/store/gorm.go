@@ -0,0 +1,71 @@
+package store
+
+import (
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"time"
+)
+
+type Gorm struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+}
+
+func NewGorm(c *GormConfig) audit.Store {
+	return &Gorm{
+		db:     c.DB,
+		logger: c.Logger,
+	}
+}
+
+func (s *Gorm) Create(e *audit.Entry) error {
+	return s.db.Create(e).Error
+}
+
+func (s *Gorm) List(f *audit.Filter, offset, limit int) ([]*audit.Entry, int, error) {
+	entries, total := make([]*audit.Entry, 0), 0
+	qb := s.db.Model(&entries)
+	if len(f.Entity) > 0 {
+		qb = qb.Where("`entity` = ?", f.Entity)
+	}
+	if f.EntityId > 0 {
+		qb = qb.Where("`entityId` = ?", f.EntityId)
+	}
+	if f.ProjectId > 0 {
+		qb = qb.Where("`projectId` = ?", f.ProjectId)
+	}
+	if f.ActorId > 0 {
+		qb = qb.Where("`actorId` = ?", f.ActorId)
+	}
+	if len(f.Action) > 0 {
+		qb = qb.Where("`action` = ?", f.Action)
+	}
+	if len(f.Source) > 0 {
+		qb = qb.Where("`source` = ?", f.Source)
+	}
+	if len(f.RequestId) > 0 {
+		qb = qb.Where("`requestId` = ?", f.RequestId)
+	}
+	if !f.From.IsZero() {
+		qb = qb.Where("`createdAt` >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		qb = qb.Where("`createdAt` < ?", f.To)
+	}
+	if err := qb.Count(&total).Error; err != nil {
+		return entries, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("`createdAt` desc, `id` desc").Find(&entries).Error
+	return entries, total, err
+}
+
+func (s *Gorm) DeleteBefore(t time.Time) (int, error) {
+	res := s.db.Where("`createdAt` < ?", t).Delete(&audit.Entry{})
+	return int(res.RowsAffected), res.Error
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger  logger.Logger
+	records []*audit.Entry
+	lastId  uint64
+	mu      *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	return &Memory{
+		logger:  c.Logger,
+		records: make([]*audit.Entry, 0),
+		mu:      &sync.Mutex{},
+	}
+}
+
+func (s *Memory) Create(e *audit.Entry) error {
+	s.mu.Lock()
+	s.lastId++
+	e.ID = s.lastId
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	s.records = append(s.records, e)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) List(f *audit.Filter, offset, limit int) ([]*audit.Entry, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := make([]*audit.Entry, 0)
+	for _, el := range s.records {
+		if f.Match(el) {
+			entries = append(entries, el)
+		}
+	}
+	// Newest first, like the gorm store
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	total := len(entries)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return entries[offset : offset+l], total, nil
+}
+
+func (s *Memory) DeleteBefore(t time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kept := s.records[:0]
+	for _, el := range s.records {
+		if !el.CreatedAt.Before(t) {
+			kept = append(kept, el)
+		}
+	}
+	deleted := len(s.records) - len(kept)
+	for i := len(kept); i < len(s.records); i++ {
+		s.records[i] = nil
+	}
+	s.records = kept
+	return deleted, nil
+}
@@ -0,0 +1,64 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/util"
+	"testing"
+	"time"
+)
+
+func TestMemory_List(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+	now := time.Now()
+	entries := []*audit.Entry{
+		{Entity: audit.EntityEvent, EntityId: 1, ProjectId: 1, Action: audit.ActionCreate, Source: audit.SourceREST, ActorId: util.NewNullInt64FromInt64(2), CreatedAt: now.Add(-3 * time.Hour)},
+		{Entity: audit.EntityEvent, EntityId: 1, ProjectId: 1, Action: audit.ActionUpdate, Source: audit.SourceImport, RequestId: "abc", CreatedAt: now.Add(-2 * time.Hour)},
+		{Entity: audit.EntityPage, EntityId: 1, ProjectId: 2, Action: audit.ActionDelete, Source: audit.SourceREST, ActorId: util.NewNullInt64FromInt64(2), CreatedAt: now.Add(-time.Hour)},
+	}
+	for _, e := range entries {
+		if err := s.Create(e); err != nil {
+			t.Fatalf("Can not create test entry: %s", err.Error())
+		}
+	}
+
+	cases := []struct {
+		filter *audit.Filter
+		offset int
+		limit  int
+		total  int
+		ids    []uint64
+	}{
+		{&audit.Filter{}, 0, 100, 3, []uint64{3, 2, 1}},
+		{&audit.Filter{}, 1, 1, 3, []uint64{2}},
+		{&audit.Filter{Entity: audit.EntityEvent, EntityId: 1}, 0, 100, 2, []uint64{2, 1}},
+		{&audit.Filter{ActorId: 2}, 0, 100, 2, []uint64{3, 1}},
+		{&audit.Filter{ProjectId: 2}, 0, 100, 1, []uint64{3}},
+		{&audit.Filter{Source: audit.SourceImport, RequestId: "abc"}, 0, 100, 1, []uint64{2}},
+		{&audit.Filter{Action: audit.ActionDelete, Entity: audit.EntityEvent}, 0, 100, 0, []uint64{}},
+		{&audit.Filter{From: now.Add(-150 * time.Minute), To: now.Add(-time.Hour)}, 0, 100, 1, []uint64{2}},
+	}
+
+	for caseNum, item := range cases {
+		list, total, err := s.List(item.filter, item.offset, item.limit)
+		if err != nil {
+			t.Fatalf("[%d] Can not list entries: %s", caseNum, err.Error())
+		}
+		if total != item.total || len(list) != len(item.ids) {
+			t.Errorf("[%d] Unexpected entries: total %d, %+v", caseNum, total, list)
+			continue
+		}
+		for i, id := range item.ids {
+			if list[i].ID != id {
+				t.Errorf("[%d] Unexpected entry at %d. Wanted: %d, received: %d", caseNum, i, id, list[i].ID)
+			}
+		}
+	}
+
+	deleted, err := s.DeleteBefore(now.Add(-90 * time.Minute))
+	if err != nil {
+		t.Fatalf("Can not delete entries: %s", err.Error())
+	}
+	if list, total, _ := s.List(&audit.Filter{}, 0, 100); deleted != 2 || total != 1 || list[0].ID != 3 {
+		t.Errorf("Unexpected entries after deleting %d: total %d, %+v", deleted, total, list)
+	}
+}
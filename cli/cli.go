@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/environments"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	"github.com/nskondratev/api-page-go-back/users"
+	"io"
+	"sort"
+)
+
+// Exit codes of the CLI commands
+const (
+	ExitOk      = 0
+	ExitFailure = 1
+	ExitError   = 2
+)
+
+// Config holds the stores of the default project, the -project flag of the commands selects another one.
+type Config struct {
+	Logger logger.Logger
+	// ProjectStore resolves the -project flag, only the default project is available without it
+	ProjectStore projects.Store
+	EventStore   events.Store
+	// TypeStore resolves the shared types of the catalog
+	TypeStore      registry.Store
+	SnapshotStore  snapshots.Store
+	ScenarioStore  scenarios.Store
+	RecordingStore recordings.Store
+	// LoadTestStore keeps the results of load tests when set
+	LoadTestStore loadtests.Store
+	// EnvironmentStore resolves the -env flag of runs
+	EnvironmentStore environments.Store
+	// UserStore keeps the accounts created with create-user
+	UserStore users.Store
+	Out       io.Writer
+}
+
+const projectUsage = "Slug of the project, the default project when empty"
+
+// forProject returns the copy of the config with the stores of the project named by the slug.
+func (c *Config) forProject(slug string) (*Config, error) {
+	if len(slug) < 1 {
+		return c, nil
+	}
+	if c.ProjectStore == nil {
+		return nil, fmt.Errorf("project %q can not be resolved", slug)
+	}
+	projectId, err := projects.IdBySlug(c.ProjectStore, slug)
+	if err != nil {
+		return nil, err
+	}
+	scoped := *c
+	if c.EventStore != nil {
+		scoped.EventStore = c.EventStore.ForProject(projectId)
+	}
+	if c.SnapshotStore != nil {
+		scoped.SnapshotStore = c.SnapshotStore.ForProject(projectId)
+	}
+	if c.ScenarioStore != nil {
+		scoped.ScenarioStore = c.ScenarioStore.ForProject(projectId)
+	}
+	if c.RecordingStore != nil {
+		scoped.RecordingStore = c.RecordingStore.ForProject(projectId)
+	}
+	if c.LoadTestStore != nil {
+		scoped.LoadTestStore = c.LoadTestStore.ForProject(projectId)
+	}
+	if c.EnvironmentStore != nil {
+		scoped.EnvironmentStore = c.EnvironmentStore.ForProject(projectId)
+	}
+	return &scoped, nil
+}
+
+type command struct {
+	description string
+	run         func(c *Config, args []string) (int, error)
+}
+
+var commands = map[string]*command{
+	"check-breaking": {
+		description: "Compare two catalog versions and fail on breaking changes",
+		run:         checkBreaking,
+	},
+	"coverage": {
+		description: "Report the catalog coverage of recorded or logged traffic",
+		run:         reportCoverage,
+	},
+	"create-user": {
+		description: "Create a user or reset the password of the existing one",
+		run:         createUser,
+	},
+	"fuzz": {
+		description: "Send malformed payloads of an event to a target and report the failures",
+		run:         fuzzEvent,
+	},
+	"load-test": {
+		description: "Emit catalog events over many connections and report latency and throughput",
+		run:         loadTest,
+	},
+	"run-scenario": {
+		description: "Run a contract test scenario against a target and fail when it does not pass",
+		run:         runScenario,
+	},
+	"scan-sources": {
+		description: "Find emits and subscriptions in sources which do not match the catalog",
+		run:         scanSources,
+	},
+}
+
+// Run executes the command named by the first argument and returns the process exit code.
+func Run(c *Config, args []string) int {
+	if len(args) < 1 {
+		usage(c.Out)
+		return ExitError
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(c.Out, "unknown command: %s\n", args[0])
+		usage(c.Out)
+		return ExitError
+	}
+	code, err := cmd.run(c, args[1:])
+	if err != nil {
+		fmt.Fprintf(c.Out, "%s: %s\n", args[0], err.Error())
+		return ExitError
+	}
+	return code
+}
+
+func usage(out io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(out, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(out, "  %-20s %s\n", name, commands[name].description)
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/coverage"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"os"
+)
+
+func reportCoverage(c *Config, args []string) (int, error) {
+	fs := flag.NewFlagSet("coverage", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	sessions := fs.String("sessions", "", "Comma separated ids of recorded sessions, all sessions by default")
+	file := fs.String("file", "", "Traffic log in NDJSON to use instead of recorded sessions")
+	format := fs.String("format", "text", "Output format: text or json")
+	min := fs.Float64("min", 0, "Fail when the percent of observed catalog events is lower")
+	project := fs.String("project", "", projectUsage)
+	if err := fs.Parse(args); err != nil {
+		return ExitError, err
+	}
+	c, err := c.forProject(*project)
+	if err != nil {
+		return ExitError, err
+	}
+	var messages []recordings.Message
+	if len(*file) > 0 {
+		f, err := os.Open(*file)
+		if err != nil {
+			return ExitError, err
+		}
+		defer f.Close()
+		if messages, err = recordings.ReadNDJSON(f); err != nil {
+			return ExitError, err
+		}
+	} else {
+		ids, err := coverage.ParseIds(*sessions)
+		if err != nil {
+			return ExitError, err
+		}
+		if messages, err = coverage.SessionMessages(c.RecordingStore, ids); err != nil {
+			return ExitError, err
+		}
+	}
+	catalog, err := c.EventStore.GetAll()
+	if err != nil {
+		return ExitError, err
+	}
+	report := coverage.Compute(catalog, messages)
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(c.Out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return ExitError, err
+		}
+	case "text":
+		writeCoverage(c, report)
+	default:
+		return ExitError, fmt.Errorf("unknown format: %s", *format)
+	}
+	if report.Coverage < *min {
+		return ExitFailure, nil
+	}
+	return ExitOk, nil
+}
+
+func writeCoverage(c *Config, r *coverage.Report) {
+	fmt.Fprintf(c.Out, "%d of %d catalog events observed (%.1f%%) in %d messages\n", r.ObservedEvents, r.CatalogEvents, r.Coverage, r.Messages)
+	for _, value := range r.NeverObserved {
+		fmt.Fprintf(c.Out, "never observed: %s\n", value)
+	}
+	for _, count := range r.Undocumented {
+		fmt.Fprintf(c.Out, "undocumented event: %s (%d)\n", count.Name, count.Count)
+	}
+	for _, e := range r.Events {
+		for _, count := range e.UndocumentedFields {
+			fmt.Fprintf(c.Out, "undocumented field: %s %s (%d of %d)\n", e.Event, count.Name, count.Count, e.Count)
+		}
+		for _, count := range e.MissingRequired {
+			fmt.Fprintf(c.Out, "missing required field: %s %s (%d of %d)\n", e.Event, count.Name, count.Count, e.Count)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/projects"
+	projectStore "github.com/nskondratev/api-page-go-back/projects/store"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	recordingStore "github.com/nskondratev/api-page-go-back/recordings/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/util"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	e := router.New()
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+	rs := recordingStore.NewMemory(&recordingStore.MemoryConfig{Logger: e.Logger})
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGOUT", Value: "logout", Type: events.TypeClient},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+	session := &recordings.Session{Name: "Login", Messages: []recordings.Message{
+		{Direction: recordings.DirectionOut, Kind: "event", Event: "login", Data: util.RawJSON(`{"name":"John","remember":true}`)},
+	}}
+	if err := rs.Create(session); err != nil {
+		t.Fatalf("Can not create test recording: %s", err.Error())
+	}
+	prs := projectStore.NewMemory(&projectStore.MemoryConfig{Logger: e.Logger})
+	if err := projects.EnsureDefault(prs); err != nil {
+		t.Fatalf("Can not create default project: %s", err.Error())
+	}
+	if err := prs.Create(&projects.Project{Name: "Chat", Slug: "chat"}); err != nil {
+		t.Fatalf("Can not create test project: %s", err.Error())
+	}
+	if err := es.ForProject(2).Create(&events.Event{Constant: "MESSAGE", Value: "message", Type: events.TypeClient}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+
+	f, err := ioutil.TempFile("", "traffic")
+	if err != nil {
+		t.Fatalf("Can not create temp file: %s", err.Error())
+	}
+	defer os.Remove(f.Name())
+	f.WriteString(`{"direction":"in","kind":"event","event":"login","data":{}}` + "\n" + `{"direction":"out","kind":"event","event":"logout"}` + "\n")
+	f.Close()
+
+	cases := []struct {
+		args                []string
+		exitCode            int
+		outputShouldContain string
+	}{
+		{[]string{"coverage"}, ExitOk, "undocumented field: login remember (1 of 1)"},
+		{[]string{"coverage", "-sessions", "1", "-min", "60"}, ExitFailure, "1 of 2 catalog events observed (50.0%) in 1 messages"},
+		{[]string{"coverage", "-file", f.Name(), "-min", "100"}, ExitOk, "missing required field: login name (1 of 1)"},
+		{[]string{"coverage", "-format", "json"}, ExitOk, `"neverObserved": [`},
+		{[]string{"coverage", "-sessions", "42"}, ExitError, "recorded session with id = 42 does not exist"},
+		{[]string{"coverage", "-format", "xml"}, ExitError, "unknown format: xml"},
+		{[]string{"coverage", "-project", "chat"}, ExitOk, "0 of 1 catalog events observed (0.0%) in 0 messages"},
+		{[]string{"coverage", "-project", "billing"}, ExitError, `project "billing" does not exist`},
+	}
+
+	for caseNum, item := range cases {
+		out := &bytes.Buffer{}
+		code := Run(&Config{ProjectStore: prs, EventStore: es, RecordingStore: rs, Out: out}, item.args)
+
+		if code != item.exitCode {
+			t.Errorf("[%d] exit code mismatch. Want: %d, received: %d, output: %s", caseNum, item.exitCode, code, out.String())
+		}
+
+		if !strings.Contains(out.String(), item.outputShouldContain) {
+			t.Errorf("[%d] output doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.outputShouldContain, out.String())
+		}
+	}
+}
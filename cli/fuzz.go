@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/fuzz"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func fuzzEvent(c *Config, args []string) (int, error) {
+	fs := flag.NewFlagSet("fuzz", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	ref := fs.String("event", "", "Event id, value or constant")
+	target := fs.String("target", "", "URL of the target real-time API")
+	protocol := fs.String("protocol", "socketio", "Protocol of the target: socketio or json")
+	timeout := fs.Duration("timeout", fuzz.DefaultTimeout, "Timeout of the ack and the response event")
+	quiet := fs.Duration("quiet", fuzz.DefaultQuiet, "Wait for disconnects and error replies of events which are not answered")
+	kinds := fs.String("kinds", "", "Comma separated kinds of mutations, all kinds when empty: "+strings.Join(fuzz.Kinds, ", "))
+	errorEvents := fs.String("error-events", strings.Join(fuzz.DefaultErrorEvents, ","), "Comma separated names of events the target replies with on invalid input")
+	minimizeRuns := fs.Int("minimize-runs", fuzz.DefaultMinimizeRuns, "Limit of payloads sent to minimize a single failure")
+	oversized := fs.Int("oversized-length", fuzz.DefaultOversizedLength, "Length of oversized strings")
+	depth := fs.Int("nesting-depth", fuzz.DefaultNestingDepth, "Depth of deeply nested values")
+	failOn := fs.String("fail-on", fuzz.OutcomeDisconnect+","+fuzz.OutcomeTimeout+","+fuzz.OutcomeConnectError, "Comma separated outcomes which fail the run: disconnect, error-reply, timeout, connect-error")
+	format := fs.String("format", "text", "Output format: text or json")
+	jsonPath := fs.String("json", "", "Write the JSON report to the file")
+	headers := &headerFlags{}
+	fs.Var(headers, "header", "Header of the handshake in the \"Name: value\" form, may be repeated")
+	project := fs.String("project", "", projectUsage)
+	if err := fs.Parse(args); err != nil {
+		return ExitError, err
+	}
+	c, err := c.forProject(*project)
+	if err != nil {
+		return ExitError, err
+	}
+	if len(*ref) < 1 || len(*target) < 1 {
+		return ExitError, fmt.Errorf("-event and -target are required")
+	}
+	if *format != "text" && *format != "json" {
+		return ExitError, fmt.Errorf("unknown format: %s", *format)
+	}
+	known := make(map[string]bool, len(fuzz.Kinds))
+	for _, kind := range fuzz.Kinds {
+		known[kind] = true
+	}
+	for _, kind := range splitList(*kinds) {
+		if !known[kind] {
+			return ExitError, fmt.Errorf("unknown mutation kind: %s", kind)
+		}
+	}
+	fails, err := parseOutcomes(*failOn)
+	if err != nil {
+		return ExitError, err
+	}
+	fc := &fuzz.Config{
+		Target:       *target,
+		Protocol:     *protocol,
+		Header:       http.Header{},
+		Timeout:      *timeout,
+		Quiet:        *quiet,
+		ErrorEvents:  splitList(*errorEvents),
+		MinimizeRuns: *minimizeRuns,
+		MutateConfig: fuzz.MutateConfig{
+			Kinds:           splitList(*kinds),
+			OversizedLength: *oversized,
+			NestingDepth:    *depth,
+		},
+	}
+	for _, h := range *headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 {
+			return ExitError, fmt.Errorf("invalid header: %s", h)
+		}
+		fc.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	if fc.Catalog, err = c.EventStore.GetAll(); err != nil {
+		return ExitError, err
+	}
+	e := findEvent(fc.Catalog, *ref)
+	if e == nil {
+		return ExitError, fmt.Errorf("event %q does not exist", *ref)
+	}
+	if fc.Types, err = events.ResolveEventTypes(c.TypeStore, e); err != nil {
+		return ExitError, err
+	}
+
+	report := fuzz.Execute(e, fc)
+	if len(report.Error) > 0 {
+		return ExitError, errors.New(report.Error)
+	}
+	if len(*jsonPath) > 0 {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return ExitError, err
+		}
+		if err := ioutil.WriteFile(*jsonPath, data, 0644); err != nil {
+			return ExitError, err
+		}
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(c.Out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return ExitError, err
+		}
+	} else {
+		writeFuzz(c, report)
+	}
+	for _, res := range report.Results {
+		if fails[res.Outcome] {
+			return ExitFailure, nil
+		}
+	}
+	return ExitOk, nil
+}
+
+func writeFuzz(c *Config, r *fuzz.Report) {
+	for _, res := range r.Results {
+		fmt.Fprintf(c.Out, "[%s] %s", res.Outcome, res.Name)
+		if len(res.Message) > 0 {
+			fmt.Fprintf(c.Out, ": %s", res.Message)
+		}
+		fmt.Fprintln(c.Out)
+		if len(res.Reproducer) > 0 {
+			fmt.Fprintf(c.Out, "  reproducer: %s\n", res.Reproducer)
+		}
+	}
+	fmt.Fprintf(c.Out, "%d mutation(s) of %s, %d failure(s)\n", len(r.Results), r.Event, r.Failures)
+}
+
+// findEvent looks the event up by id, value or constant.
+func findEvent(catalog []*events.Event, ref string) *events.Event {
+	id, _ := strconv.ParseUint(ref, 10, 64)
+	for _, e := range catalog {
+		if e.Value == ref || e.Constant == ref || id > 0 && e.ID == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func parseOutcomes(s string) (map[string]bool, error) {
+	res := make(map[string]bool)
+	for _, name := range splitList(s) {
+		switch name {
+		case fuzz.OutcomeDisconnect, fuzz.OutcomeErrorReply, fuzz.OutcomeTimeout, fuzz.OutcomeConnectError:
+			res[name] = true
+		default:
+			return nil, fmt.Errorf("unknown outcome: %s", name)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/util"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFuzzEvent(t *testing.T) {
+	e := router.New()
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	cases := []struct {
+		args                []string
+		exitCode            int
+		outputShouldContain string
+	}{
+		{[]string{"fuzz", "-event", "login", "-target", target.URL, "-kinds", "valid,missing,wrong-type", "-timeout", "500ms"}, ExitOk, "[error-reply] wrong-type name: mock_error"},
+		{[]string{"fuzz", "-event", "LOGIN", "-target", target.URL, "-kinds", "missing", "-timeout", "500ms", "-fail-on", "error-reply"}, ExitFailure, "[error-reply] missing name: mock_error {\"event\":\"login\",\"message\":\"payload does not match the event fields\""},
+		{[]string{"fuzz", "-event", "2", "-target", target.URL, "-kinds", "valid", "-timeout", "500ms"}, ExitOk, "1 mutation(s) of login, 0 failure(s)"},
+		{[]string{"fuzz", "-event", "user", "-target", target.URL}, ExitError, "event user is not emitted by clients"},
+		{[]string{"fuzz", "-event", "logout", "-target", target.URL}, ExitError, `event "logout" does not exist`},
+		{[]string{"fuzz", "-event", "login", "-target", target.URL, "-kinds", "huge"}, ExitError, "unknown mutation kind: huge"},
+		{[]string{"fuzz", "-event", "login", "-target", target.URL, "-fail-on", "crash"}, ExitError, "unknown outcome: crash"},
+		{[]string{"fuzz", "-event", "login"}, ExitError, "-event and -target are required"},
+	}
+
+	for caseNum, item := range cases {
+		out := &bytes.Buffer{}
+		code := Run(&Config{EventStore: es, Out: out}, item.args)
+
+		if code != item.exitCode {
+			t.Errorf("[%d] exit code mismatch. Want: %d, received: %d, output: %s", caseNum, item.exitCode, code, out.String())
+		}
+
+		if !strings.Contains(out.String(), item.outputShouldContain) {
+			t.Errorf("[%d] output doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.outputShouldContain, out.String())
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func loadTest(c *Config, args []string) (int, error) {
+	fs := flag.NewFlagSet("load-test", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	name := fs.String("name", "", "Name of the stored run")
+	target := fs.String("target", "", "URL of the target real-time API")
+	protocol := fs.String("protocol", "socketio", "Protocol of the target: socketio or json")
+	connections := fs.Int("connections", 10, "Number of concurrent connections")
+	rate := fs.Float64("rate", 10, "Emits per second over all connections")
+	duration := fs.Duration("duration", 10*time.Second, "Duration of the emits")
+	rampUp := fs.Duration("ramp-up", 0, "Period the connects are spread over")
+	timeout := fs.Duration("timeout", loadtests.DefaultTimeout, "Timeout of acks and response events")
+	eventsList := fs.String("events", "", "Comma separated values of the emitted events, all client events when empty")
+	format := fs.String("format", "text", "Output format: text or json")
+	jsonPath := fs.String("json", "", "Write the JSON result to the file")
+	maxP95 := fs.Float64("max-p95", 0, "Fail when the p95 latency of acks or responses exceeds the milliseconds")
+	compare := fs.Uint64("compare", 0, "Id of the stored run the result is compared with")
+	headers := &headerFlags{}
+	fs.Var(headers, "header", "Header of the handshake in the \"Name: value\" form, may be repeated")
+	project := fs.String("project", "", projectUsage)
+	if err := fs.Parse(args); err != nil {
+		return ExitError, err
+	}
+	c, err := c.forProject(*project)
+	if err != nil {
+		return ExitError, err
+	}
+	if len(*target) < 1 {
+		return ExitError, fmt.Errorf("-target is required")
+	}
+	if *format != "text" && *format != "json" {
+		return ExitError, fmt.Errorf("unknown format: %s", *format)
+	}
+	lc := &loadtests.Config{
+		Target:      *target,
+		Protocol:    *protocol,
+		Header:      http.Header{},
+		Connections: *connections,
+		Rate:        *rate,
+		Duration:    *duration,
+		RampUp:      *rampUp,
+		Timeout:     *timeout,
+		Events:      splitList(*eventsList),
+	}
+	for _, h := range *headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 {
+			return ExitError, fmt.Errorf("invalid header: %s", h)
+		}
+		lc.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	if lc.Catalog, err = c.EventStore.GetAll(); err != nil {
+		return ExitError, err
+	}
+	if lc.Types, err = events.ResolveEventTypes(c.TypeStore, lc.Catalog...); err != nil {
+		return ExitError, err
+	}
+	if err := lc.Validate(); err != nil {
+		return ExitError, err
+	}
+	var baseline *loadtests.Run
+	if *compare > 0 {
+		if c.LoadTestStore == nil {
+			return ExitError, fmt.Errorf("runs are not stored")
+		}
+		if baseline, err = c.LoadTestStore.GetById(*compare); err != nil {
+			return ExitError, err
+		}
+		if baseline == nil || baseline.Result == nil {
+			return ExitError, fmt.Errorf("load test %d has no result", *compare)
+		}
+	}
+
+	run := loadtests.NewRun(*name, lc)
+	if c.LoadTestStore != nil {
+		if err := c.LoadTestStore.Create(run); err != nil {
+			return ExitError, err
+		}
+		if err := loadtests.Finish(c.LoadTestStore, run, lc); err != nil {
+			return ExitError, err
+		}
+	} else if run.Result, err = loadtests.Execute(lc); err != nil {
+		return ExitError, err
+	}
+	if len(run.Error) > 0 {
+		return ExitError, errors.New(run.Error)
+	}
+
+	var deltas []*loadtests.Delta
+	if baseline != nil {
+		deltas = loadtests.Compare(baseline.Result, run.Result)
+	}
+	if len(*jsonPath) > 0 {
+		data, err := json.MarshalIndent(run.Result, "", "  ")
+		if err != nil {
+			return ExitError, err
+		}
+		if err := ioutil.WriteFile(*jsonPath, data, 0644); err != nil {
+			return ExitError, err
+		}
+	}
+	if *format == "json" {
+		enc := json.NewEncoder(c.Out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(run); err != nil {
+			return ExitError, err
+		}
+	} else {
+		writeLoadTest(c, run, deltas)
+	}
+
+	res := run.Result
+	if *maxP95 > 0 && (res.Acks.P95Ms > *maxP95 || res.Responses.P95Ms > *maxP95) {
+		fmt.Fprintf(c.Out, "p95 latency exceeds %gms\n", *maxP95)
+		return ExitFailure, nil
+	}
+	return ExitOk, nil
+}
+
+func writeLoadTest(c *Config, run *loadtests.Run, deltas []*loadtests.Delta) {
+	res := run.Result
+	if run.ID > 0 {
+		fmt.Fprintf(c.Out, "run %d\n", run.ID)
+	}
+	fmt.Fprintf(c.Out, "connections: %d connected, %d failed, %d disconnected\n", res.Connected, res.ConnectErrors, res.Disconnects)
+	fmt.Fprintf(c.Out, "emitted: %d (%.1f/s), received: %d (%.1f/s)\n", res.Emitted, res.EmitsPerSecond, res.Received, res.ReceivedPerSecond)
+	fmt.Fprintf(c.Out, "errors: %d, timeouts: %d\n", res.Errors, res.Timeouts)
+	for _, l := range []struct {
+		name string
+		*loadtests.Latency
+	}{{"acks", res.Acks}, {"responses", res.Responses}} {
+		if l.Count < 1 {
+			continue
+		}
+		fmt.Fprintf(c.Out, "%s: %d, min %.1fms, mean %.1fms, p50 %.1fms, p95 %.1fms, p99 %.1fms, max %.1fms\n",
+			l.name, l.Count, l.MinMs, l.MeanMs, l.P50Ms, l.P95Ms, l.P99Ms, l.MaxMs)
+	}
+	for _, s := range res.ErrorSamples {
+		fmt.Fprintf(c.Out, "error: %s\n", s)
+	}
+	for _, d := range deltas {
+		mark := ""
+		if d.Worse {
+			mark = " (worse)"
+		}
+		fmt.Fprintf(c.Out, "%s: %g -> %g, %+.1f%%%s\n", d.Metric, d.From, d.To, d.Change, mark)
+	}
+}
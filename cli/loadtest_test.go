@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	loadTestStore "github.com/nskondratev/api-page-go-back/loadtests/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/util"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLoadTest(t *testing.T) {
+	e := router.New()
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+	lts := loadTestStore.NewMemory(&loadTestStore.MemoryConfig{Logger: e.Logger})
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	args := []string{"load-test", "-target", target.URL, "-connections", "2", "-rate", "50", "-duration", "200ms", "-timeout", "1s"}
+	cases := []struct {
+		args                []string
+		exitCode            int
+		outputShouldContain string
+	}{
+		{append(args, "-name", "baseline"), ExitOk, "run 1\nconnections: 2 connected, 0 failed, 0 disconnected"},
+		{append(args, "-compare", "1"), ExitOk, "acks.p95Ms: "},
+		{append(args, "-max-p95", "0.000001"), ExitFailure, "p95 latency exceeds"},
+		{append(args, "-events", "logout"), ExitError, "event logout is not described in the catalog"},
+		{append(args, "-compare", "10"), ExitError, "load test 10 has no result"},
+		{[]string{"load-test", "-connections", "2"}, ExitError, "-target is required"},
+	}
+
+	for caseNum, item := range cases {
+		out := &bytes.Buffer{}
+		code := Run(&Config{EventStore: es, LoadTestStore: lts, Out: out}, item.args)
+
+		if code != item.exitCode {
+			t.Errorf("[%d] exit code mismatch. Want: %d, received: %d, output: %s", caseNum, item.exitCode, code, out.String())
+		}
+
+		if !strings.Contains(out.String(), item.outputShouldContain) {
+			t.Errorf("[%d] output doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.outputShouldContain, out.String())
+		}
+	}
+
+	run, err := lts.GetById(1)
+	if err != nil || run == nil || run.Name != "baseline" || run.Status != loadtests.StatusFinished || run.Result == nil {
+		t.Errorf("Run was not stored: %+v, error: %v", run, err)
+	}
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/environments"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// headerFlags collects repeated -header "Name: value" flags.
+type headerFlags []string
+
+func (h *headerFlags) String() string {
+	return strings.Join(*h, ", ")
+}
+
+func (h *headerFlags) Set(v string) error {
+	*h = append(*h, v)
+	return nil
+}
+
+func runScenario(c *Config, args []string) (int, error) {
+	fs := flag.NewFlagSet("run-scenario", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	ref := fs.String("scenario", "", "Scenario id or name")
+	target := fs.String("target", "", "URL of the target real-time API, the base URL of the environment by default")
+	protocol := fs.String("protocol", "", "Protocol of the target: socketio or json, socketio by default")
+	env := fs.String("env", "", "Name of the environment providing the target, the headers and the variables")
+	junitPath := fs.String("junit", "", "Write the JUnit XML report to the file")
+	jsonPath := fs.String("json", "", "Write the JSON report to the file")
+	headers := &headerFlags{}
+	fs.Var(headers, "header", "Header of the handshake in the \"Name: value\" form, may be repeated")
+	project := fs.String("project", "", projectUsage)
+	if err := fs.Parse(args); err != nil {
+		return ExitError, err
+	}
+	c, err := c.forProject(*project)
+	if err != nil {
+		return ExitError, err
+	}
+	if len(*ref) < 1 || len(*target) < 1 && len(*env) < 1 {
+		return ExitError, fmt.Errorf("-scenario and -target or -env are required")
+	}
+	s, err := findScenario(c.ScenarioStore, *ref)
+	if err != nil {
+		return ExitError, err
+	}
+	rc := &scenarios.RunConfig{Target: *target, Protocol: *protocol, Header: http.Header{}}
+	for _, h := range *headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 {
+			return ExitError, fmt.Errorf("invalid header: %s", h)
+		}
+		rc.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	if len(*env) > 0 {
+		e, err := findEnvironment(c.EnvironmentStore, *env)
+		if err != nil {
+			return ExitError, err
+		}
+		if err := rc.ApplyEnvironment(e); err != nil {
+			return ExitError, err
+		}
+	}
+	if rc.Catalog, err = c.EventStore.GetAll(); err != nil {
+		return ExitError, err
+	}
+	if rc.Types, err = events.ResolveEventTypes(c.TypeStore, rc.Catalog...); err != nil {
+		return ExitError, err
+	}
+
+	report := scenarios.Run(s, rc)
+
+	if len(*junitPath) > 0 {
+		data, err := report.JUnit()
+		if err != nil {
+			return ExitError, err
+		}
+		if err := ioutil.WriteFile(*junitPath, data, 0644); err != nil {
+			return ExitError, err
+		}
+	}
+	if len(*jsonPath) > 0 {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return ExitError, err
+		}
+		if err := ioutil.WriteFile(*jsonPath, data, 0644); err != nil {
+			return ExitError, err
+		}
+	}
+
+	if len(report.Error) > 0 {
+		fmt.Fprintf(c.Out, "%s: %s\n", s.Name, report.Error)
+	}
+	for _, step := range report.Steps {
+		fmt.Fprintf(c.Out, "[%s] %d. %s %s", step.Status, step.Index, step.Action, step.Event)
+		if len(step.Message) > 0 {
+			fmt.Fprintf(c.Out, ": %s", step.Message)
+		}
+		fmt.Fprintln(c.Out)
+		for _, e := range step.Errors {
+			fmt.Fprintf(c.Out, "    %s\n", e.Error())
+		}
+	}
+	if !report.Passed {
+		fmt.Fprintf(c.Out, "%s failed in %d ms\n", s.Name, report.DurationMs)
+		return ExitFailure, nil
+	}
+	fmt.Fprintf(c.Out, "%s passed in %d ms\n", s.Name, report.DurationMs)
+	return ExitOk, nil
+}
+
+// findScenario loads the scenario by id or by name.
+func findScenario(ss scenarios.Store, ref string) (*scenarios.Scenario, error) {
+	var s *scenarios.Scenario
+	var err error
+	if id, parseErr := strconv.ParseUint(ref, 10, 64); parseErr == nil {
+		s, err = ss.GetById(id)
+	} else {
+		s, err = ss.GetByName(ref)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("scenario %q does not exist", ref)
+	}
+	return s, nil
+}
+
+// findEnvironment loads the environment by name.
+func findEnvironment(es environments.Store, name string) (*environments.Environment, error) {
+	if es == nil {
+		return nil, fmt.Errorf("environments are not available")
+	}
+	e, err := es.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("environment %q does not exist", name)
+	}
+	return e, nil
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/nskondratev/api-page-go-back/environments"
+	environmentStore "github.com/nskondratev/api-page-go-back/environments/store"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	scenarioStore "github.com/nskondratev/api-page-go-back/scenarios/store"
+	"github.com/nskondratev/api-page-go-back/util"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunScenario(t *testing.T) {
+	e := router.New()
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+	scs := scenarioStore.NewMemory(&scenarioStore.MemoryConfig{Logger: e.Logger})
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+	for _, s := range []*scenarios.Scenario{
+		{Name: "login", Steps: []*scenarios.Step{
+			{Action: scenarios.ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"John"}`)},
+			{Action: scenarios.ActionExpect, Event: "user"},
+		}},
+		{Name: "greet", Steps: []*scenarios.Step{
+			{Action: scenarios.ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"${user}"}`)},
+			{Action: scenarios.ActionExpect, Event: "user"},
+		}},
+		{Name: "broken", Steps: []*scenarios.Step{
+			{Action: scenarios.ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"John"}`)},
+			{Action: scenarios.ActionExpect, Event: "user", Match: map[string]util.RawJSON{"name": util.RawJSON(`"John"`)}},
+		}},
+	} {
+		if err := scs.Create(s); err != nil {
+			t.Fatalf("Can not create test scenario: %s", err.Error())
+		}
+	}
+
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	ens := environmentStore.NewMemory(&environmentStore.MemoryConfig{Logger: e.Logger})
+	if err := ens.Create(&environments.Environment{Name: "mock", BaseUrl: target.URL, Variables: []*environments.Param{{Name: "user", Value: "John"}}}); err != nil {
+		t.Fatalf("Can not create test environment: %s", err.Error())
+	}
+
+	dir, err := ioutil.TempDir("", "run-scenario")
+	if err != nil {
+		t.Fatalf("Can not create temp dir: %s", err.Error())
+	}
+	defer os.RemoveAll(dir)
+	junitPath := filepath.Join(dir, "report.xml")
+
+	cases := []struct {
+		args                []string
+		exitCode            int
+		outputShouldContain string
+	}{
+		{[]string{"run-scenario", "-scenario", "login", "-target", target.URL, "-junit", junitPath}, ExitOk, "[passed] 2. expect user"},
+		{[]string{"run-scenario", "-scenario", "3", "-target", target.URL}, ExitFailure, `[failed] 2. expect user: name is "name", expected "John"`},
+		{[]string{"run-scenario", "-scenario", "logout", "-target", target.URL}, ExitError, `scenario "logout" does not exist`},
+		{[]string{"run-scenario", "-scenario", "greet", "-env", "mock"}, ExitOk, "greet passed"},
+		{[]string{"run-scenario", "-scenario", "greet", "-env", "prod"}, ExitError, `environment "prod" does not exist`},
+		{[]string{"run-scenario", "-scenario", "login"}, ExitError, "-scenario and -target or -env are required"},
+	}
+
+	for caseNum, item := range cases {
+		out := &bytes.Buffer{}
+		code := Run(&Config{EventStore: es, ScenarioStore: scs, EnvironmentStore: ens, Out: out}, item.args)
+
+		if code != item.exitCode {
+			t.Errorf("[%d] exit code mismatch. Want: %d, received: %d, output: %s", caseNum, item.exitCode, code, out.String())
+		}
+
+		if !strings.Contains(out.String(), item.outputShouldContain) {
+			t.Errorf("[%d] output doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.outputShouldContain, out.String())
+		}
+	}
+
+	if data, err := ioutil.ReadFile(junitPath); err != nil || !strings.Contains(string(data), `<testsuite name="login" tests="2" failures="0"`) {
+		t.Errorf("Unexpected JUnit report: %s, error: %v", data, err)
+	}
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+)
+
+func checkBreaking(c *Config, args []string) (int, error) {
+	fs := flag.NewFlagSet("check-breaking", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	from := fs.String("from", "", "Snapshot id or name to compare from")
+	to := fs.String("to", snapshots.LiveRef, "Snapshot id or name to compare to, \"live\" for the current catalog")
+	format := fs.String("format", "text", "Output format: text or json")
+	project := fs.String("project", "", projectUsage)
+	if err := fs.Parse(args); err != nil {
+		return ExitError, err
+	}
+	c, err := c.forProject(*project)
+	if err != nil {
+		return ExitError, err
+	}
+	if len(*from) < 1 {
+		return ExitError, fmt.Errorf("-from is required")
+	}
+	diff, err := snapshots.Compare(c.SnapshotStore, c.EventStore, c.TypeStore, *from, *to)
+	if err != nil {
+		return ExitError, err
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(c.Out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			return ExitError, err
+		}
+	case "text":
+		for _, change := range diff.Changes {
+			fmt.Fprintln(c.Out, change.String())
+		}
+		fmt.Fprintf(c.Out, "%d change(s) between %s and %s\n", len(diff.Changes), *from, *to)
+	default:
+		return ExitError, fmt.Errorf("unknown format: %s", *format)
+	}
+	if diff.Breaking {
+		return ExitFailure, nil
+	}
+	return ExitOk, nil
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	snapshotStore "github.com/nskondratev/api-page-go-back/snapshots/store"
+	"strings"
+	"testing"
+)
+
+func TestCheckBreaking(t *testing.T) {
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{})
+	ss := snapshotStore.NewMemory(&snapshotStore.MemoryConfig{})
+
+	for _, value := range []string{"ping", "pong"} {
+		if err := es.Create(&events.Event{Constant: strings.ToUpper(value), Value: value, Type: "client"}); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	catalog, err := es.GetAll()
+	if err != nil {
+		t.Fatalf("Can not get events catalog: %s", err.Error())
+	}
+
+	if err := ss.Create(&snapshots.Snapshot{Name: "release-1", Events: catalog}); err != nil {
+		t.Fatalf("Can not create test snapshot: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "HELLO", Value: "hello", Type: "client"}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+
+	if err := ss.Create(&snapshots.Snapshot{Name: "release-2"}); err != nil {
+		t.Fatalf("Can not create test snapshot: %s", err.Error())
+	}
+
+	cases := []struct {
+		args                []string
+		exitCode            int
+		outputShouldContain string
+	}{
+		{[]string{"check-breaking", "-from", "release-1"}, ExitOk, "[non-breaking] event_added: hello"},
+		{[]string{"check-breaking", "-from", "release-1", "-to", "release-2"}, ExitFailure, "[BREAKING] event_removed: ping"},
+		{[]string{"check-breaking", "-from", "release-1", "-to", "release-2", "-format", "json"}, ExitFailure, `"breaking": true`},
+		{[]string{"check-breaking", "-from", "release-3"}, ExitError, `snapshot "release-3" does not exist`},
+		{[]string{"check-breaking"}, ExitError, "-from is required"},
+		{[]string{"unknown"}, ExitError, "unknown command"},
+	}
+
+	for caseNum, item := range cases {
+		out := &bytes.Buffer{}
+		code := Run(&Config{EventStore: es, SnapshotStore: ss, Out: out}, item.args)
+
+		if code != item.exitCode {
+			t.Errorf("[%d] exit code mismatch. Want: %d, received: %d, output: %s", caseNum, item.exitCode, code, out.String())
+		}
+
+		if !strings.Contains(out.String(), item.outputShouldContain) {
+			t.Errorf("[%d] output doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.outputShouldContain, out.String())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/scanner"
+	"strings"
+)
+
+// Categories of findings which fail the scan
+const (
+	findingUndocumented = "undocumented"
+	findingUnused       = "unused"
+	findingMismatched   = "mismatched"
+)
+
+func scanSources(c *Config, args []string) (int, error) {
+	fs := flag.NewFlagSet("scan-sources", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	dir := fs.String("dir", ".", "Directory with JavaScript, TypeScript and Go sources")
+	side := fs.String("side", scanner.SideClient, "Side of the scanned code: client or server")
+	format := fs.String("format", "text", "Output format: text or json")
+	ignore := fs.String("ignore", "", "Comma separated event names which are not reported as undocumented")
+	failOn := fs.String("fail-on", findingUndocumented+","+findingMismatched, "Comma separated findings which fail the scan: undocumented, unused, mismatched")
+	project := fs.String("project", "", projectUsage)
+	if err := fs.Parse(args); err != nil {
+		return ExitError, err
+	}
+	c, err := c.forProject(*project)
+	if err != nil {
+		return ExitError, err
+	}
+	if *side != scanner.SideClient && *side != scanner.SideServer {
+		return ExitError, fmt.Errorf("unknown side: %s", *side)
+	}
+	fails, err := parseFindings(*failOn)
+	if err != nil {
+		return ExitError, err
+	}
+	res, err := scanner.Scan(*dir)
+	if err != nil {
+		return ExitError, err
+	}
+	catalog, err := c.EventStore.GetAll()
+	if err != nil {
+		return ExitError, err
+	}
+	report := scanner.Check(res, catalog, *side, splitList(*ignore))
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(c.Out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return ExitError, err
+		}
+	case "text":
+		writeScan(c, report)
+	default:
+		return ExitError, fmt.Errorf("unknown format: %s", *format)
+	}
+	if fails[findingUndocumented] && len(report.Undocumented) > 0 ||
+		fails[findingUnused] && len(report.Unused) > 0 ||
+		fails[findingMismatched] && len(report.Mismatched) > 0 {
+		return ExitFailure, nil
+	}
+	return ExitOk, nil
+}
+
+func writeScan(c *Config, r *scanner.Report) {
+	for _, call := range r.Undocumented {
+		fmt.Fprintf(c.Out, "%s:%d: undocumented event %q (%s)\n", call.File, call.Line, call.Event, call.Kind)
+	}
+	for _, m := range r.Mismatched {
+		fmt.Fprintf(c.Out, "%s:%d: %s event %q is called with %s\n", m.File, m.Line, m.Type, m.Event, m.Kind)
+	}
+	for _, value := range r.Unused {
+		fmt.Fprintf(c.Out, "unused event %q\n", value)
+	}
+	for _, e := range r.Errors {
+		fmt.Fprintf(c.Out, "can not parse %s\n", e)
+	}
+	fmt.Fprintf(c.Out, "%d call(s) in %d file(s): %d undocumented, %d mismatched, %d unused event(s)\n",
+		r.Calls, r.Files, len(r.Undocumented), len(r.Mismatched), len(r.Unused))
+}
+
+func parseFindings(s string) (map[string]bool, error) {
+	res := make(map[string]bool)
+	for _, name := range splitList(s) {
+		switch name {
+		case findingUndocumented, findingUnused, findingMismatched:
+			res[name] = true
+		default:
+			return nil, fmt.Errorf("unknown finding: %s", name)
+		}
+	}
+	return res, nil
+}
+
+func splitList(s string) []string {
+	res := make([]string, 0)
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			res = append(res, item)
+		}
+	}
+	return res
+}
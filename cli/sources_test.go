@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"strings"
+	"testing"
+)
+
+func TestScanSources(t *testing.T) {
+	e := router.New()
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+
+	for _, ev := range []*events.Event{
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient},
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend},
+		{Constant: "LOGOUT", Value: "logout", Type: events.TypeClient},
+		{Constant: "CHAT_MESSAGE", Value: "chat_message", Type: events.TypeFrontend},
+		{Constant: "NOTICE", Value: "notice", Type: events.TypeFrontend},
+		{Constant: "TYPING", Value: "typing", Type: events.TypeClient},
+		{Constant: "PRESENCE", Value: "presence", Type: events.TypeFrontend},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	dir := "../scanner/testdata/src"
+	cases := []struct {
+		args                []string
+		exitCode            int
+		outputShouldContain string
+	}{
+		{[]string{"scan-sources", "-dir", dir + "/web"}, ExitFailure, `app.ts:11: frontend event "user" is called with emit`},
+		{[]string{"scan-sources", "-dir", dir + "/web", "-fail-on", "undocumented"}, ExitOk, `unused event "presence"`},
+		{[]string{"scan-sources", "-dir", dir + "/server", "-side", "server", "-fail-on", "undocumented"}, ExitOk, "6 call(s) in 1 file(s): 0 undocumented, 1 mismatched, 2 unused event(s)"},
+		{[]string{"scan-sources", "-dir", dir + "/server", "-side", "server", "-fail-on", "unused"}, ExitFailure, `unused event "logout"`},
+		{[]string{"scan-sources", "-dir", dir, "-format", "json", "-fail-on", ""}, ExitOk, `"mismatched": [`},
+		{[]string{"scan-sources", "-dir", dir, "-side", "browser"}, ExitError, "unknown side: browser"},
+		{[]string{"scan-sources", "-dir", dir, "-fail-on", "typos"}, ExitError, "unknown finding: typos"},
+	}
+
+	for caseNum, item := range cases {
+		out := &bytes.Buffer{}
+		code := Run(&Config{EventStore: es, Out: out}, item.args)
+
+		if code != item.exitCode {
+			t.Errorf("[%d] exit code mismatch. Want: %d, received: %d, output: %s", caseNum, item.exitCode, code, out.String())
+		}
+
+		if !strings.Contains(out.String(), item.outputShouldContain) {
+			t.Errorf("[%d] output doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.outputShouldContain, out.String())
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/users"
+)
+
+// minPasswordLength matches the validation of the users API
+const minPasswordLength = 8
+
+// createUser creates the user or resets the password of the existing user with the email. New users are
+// admins unless -role is passed, the role of existing users is changed only with -role.
+func createUser(c *Config, args []string) (int, error) {
+	fs := flag.NewFlagSet("create-user", flag.ContinueOnError)
+	fs.SetOutput(c.Out)
+	email := fs.String("email", "", "Email of the user")
+	name := fs.String("name", "", "Name of the user, the email by default")
+	password := fs.String("password", "", "Password of the user")
+	role := fs.String("role", "", "Role of the user: reader, editor or admin. New users are admins by default")
+	if err := fs.Parse(args); err != nil {
+		return ExitError, err
+	}
+	if len(*role) > 0 && !users.ValidRole(*role) {
+		return ExitError, fmt.Errorf("unknown role %q", *role)
+	}
+	if len(*email) < 1 {
+		return ExitError, fmt.Errorf("-email is required")
+	}
+	if len(*password) < minPasswordLength {
+		return ExitError, fmt.Errorf("-password must be at least %d characters long", minPasswordLength)
+	}
+	u, err := c.UserStore.GetByEmail(*email)
+	if err != nil {
+		return ExitError, err
+	}
+	if u == nil {
+		u = &users.User{Email: *email, Name: *name, Role: *role}
+		if len(u.Name) < 1 {
+			u.Name = u.Email
+		}
+		if len(u.Role) < 1 {
+			u.Role = users.RoleAdmin
+		}
+		if err := u.SetPassword(*password); err != nil {
+			return ExitError, err
+		}
+		if err := c.UserStore.Create(u); err != nil {
+			return ExitError, err
+		}
+		fmt.Fprintf(c.Out, "%s %s created with id %d\n", u.Role, u.Email, u.ID)
+		return ExitOk, nil
+	}
+	if len(*name) > 0 {
+		u.Name = *name
+	}
+	if len(*role) > 0 {
+		u.Role = *role
+	}
+	if err := u.SetPassword(*password); err != nil {
+		return ExitError, err
+	}
+	if err := c.UserStore.Update(u); err != nil {
+		return ExitError, err
+	}
+	fmt.Fprintf(c.Out, "password of user %s is reset\n", u.Email)
+	return ExitOk, nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"github.com/nskondratev/api-page-go-back/users"
+	userStore "github.com/nskondratev/api-page-go-back/users/store"
+	"strings"
+	"testing"
+)
+
+func TestCreateUser(t *testing.T) {
+	us := userStore.NewMemory(&userStore.MemoryConfig{})
+
+	cases := []struct {
+		args                []string
+		exitCode            int
+		outputShouldContain string
+	}{
+		{[]string{"create-user", "-email", "john@example.com", "-password", "secret-password"}, ExitOk, "admin john@example.com created with id 1"},
+		{[]string{"create-user", "-email", "john@example.com", "-password", "new-password"}, ExitOk, "password of user john@example.com is reset"},
+		{[]string{"create-user", "-email", "jane@example.com", "-password", "jane-password", "-role", "reader"}, ExitOk, "reader jane@example.com created with id 2"},
+		{[]string{"create-user", "-email", "jane@example.com", "-password", "jane-password", "-role", "owner"}, ExitError, `unknown role "owner"`},
+		{[]string{"create-user", "-email", "jane@example.com", "-password", "short"}, ExitError, "-password must be at least 8 characters long"},
+		{[]string{"create-user", "-password", "secret-password"}, ExitError, "-email is required"},
+	}
+
+	for caseNum, item := range cases {
+		out := &bytes.Buffer{}
+		code := Run(&Config{UserStore: us, Out: out}, item.args)
+
+		if code != item.exitCode {
+			t.Errorf("[%d] exit code mismatch. Want: %d, received: %d, output: %s", caseNum, item.exitCode, code, out.String())
+		}
+
+		if !strings.Contains(out.String(), item.outputShouldContain) {
+			t.Errorf("[%d] output doesn't contain needed info. Want: %s, received: %s", caseNum, item.outputShouldContain, out.String())
+		}
+	}
+
+	u, err := us.GetByEmail("john@example.com")
+	if err != nil || u == nil {
+		t.Fatalf("Can not get created user: %+v, %v", u, err)
+	}
+	if u.Name != "john@example.com" || u.Role != users.RoleAdmin || !u.CheckPassword("new-password") {
+		t.Errorf("Unexpected created user: %+v", u)
+	}
+}
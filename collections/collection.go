@@ -0,0 +1,156 @@
+package collections
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/util"
+)
+
+// Version of the export file format
+const Version = 1
+
+// Collection holds the folders and the requests of the event. It is the format of the export file,
+// ids of the file only link requests and folders with their parents.
+type Collection struct {
+	Version  int        `json:"version"`
+	Event    string     `json:"event"`
+	Folders  []*Folder  `json:"folders"`
+	Requests []*Request `json:"requests"`
+}
+
+// Load reads the collection of the event from the store.
+func Load(s Store, eventId uint64, event string) (*Collection, error) {
+	folders, err := s.ListFolders(eventId)
+	if err != nil {
+		return nil, err
+	}
+	requests, err := s.ListRequests(eventId)
+	if err != nil {
+		return nil, err
+	}
+	return &Collection{Version: Version, Event: event, Folders: folders, Requests: requests}, nil
+}
+
+// Validate checks that the folders and the requests reference folders of the collection and
+// that folders are not nested in themselves.
+func (c *Collection) Validate() error {
+	if c.Version != Version {
+		return fmt.Errorf("unsupported collection version: %d", c.Version)
+	}
+	parents := make(map[uint64]util.NullInt64, len(c.Folders))
+	for _, f := range c.Folders {
+		if len(f.Name) < 1 {
+			return errors.New("folder name is empty")
+		}
+		if _, ok := parents[f.ID]; ok {
+			return fmt.Errorf("folder id %d is not unique", f.ID)
+		}
+		parents[f.ID] = f.ParentId
+	}
+	for _, f := range c.Folders {
+		if err := checkParent(parents, f.ID, f.ParentId); err != nil {
+			return err
+		}
+	}
+	for _, r := range c.Requests {
+		if len(r.Name) < 1 {
+			return errors.New("request name is empty")
+		}
+		if _, ok := parents[uint64(r.FolderId.Int64)]; r.FolderId.Valid && !ok {
+			return fmt.Errorf("request %s references missing folder %d", r.Name, r.FolderId.Int64)
+		}
+	}
+	return nil
+}
+
+// Import stores the folders and the requests of the collection for the event. Folders are created
+// before their children and get new ids, references of the collection are updated accordingly.
+func Import(s Store, eventId uint64, c *Collection) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	ids := make(map[uint64]uint64, len(c.Folders))
+	for created := 0; created < len(c.Folders); {
+		progress := false
+		for _, f := range c.Folders {
+			if _, ok := ids[f.ID]; ok {
+				continue
+			}
+			parentId, ok := uint64(0), true
+			if f.ParentId.Valid {
+				parentId, ok = ids[uint64(f.ParentId.Int64)]
+			}
+			if !ok {
+				continue
+			}
+			folder := &Folder{EventId: eventId, Name: f.Name}
+			if parentId > 0 {
+				folder.ParentId = util.NewNullInt64FromInt64(int64(parentId))
+			}
+			if err := s.CreateFolder(folder); err != nil {
+				return err
+			}
+			ids[f.ID] = folder.ID
+			created++
+			progress = true
+		}
+		if !progress {
+			return errors.New("folders are nested in themselves")
+		}
+	}
+	for _, r := range c.Requests {
+		request := *r
+		request.ID = 0
+		request.EventId = eventId
+		if r.FolderId.Valid {
+			request.FolderId = util.NewNullInt64FromInt64(int64(ids[uint64(r.FolderId.Int64)]))
+		}
+		if err := s.CreateRequest(&request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckParent returns an error when the folder would be nested in itself under the parent.
+func CheckParent(folders []*Folder, id uint64, parentId util.NullInt64) error {
+	parents := make(map[uint64]util.NullInt64, len(folders))
+	for _, f := range folders {
+		parents[f.ID] = f.ParentId
+	}
+	parents[id] = parentId
+	return checkParent(parents, id, parentId)
+}
+
+func checkParent(parents map[uint64]util.NullInt64, id uint64, parentId util.NullInt64) error {
+	seen := map[uint64]bool{id: true}
+	for parentId.Valid {
+		parent := uint64(parentId.Int64)
+		if seen[parent] {
+			return fmt.Errorf("folder %d is nested in itself", id)
+		}
+		seen[parent] = true
+		next, ok := parents[parent]
+		if !ok {
+			return fmt.Errorf("folder %d references missing folder %d", id, parent)
+		}
+		parentId = next
+	}
+	return nil
+}
+
+// Subtree returns ids of the folder and of the folders nested in it.
+func Subtree(folders []*Folder, id uint64) []uint64 {
+	children := make(map[uint64][]uint64)
+	for _, f := range folders {
+		if f.ParentId.Valid {
+			parent := uint64(f.ParentId.Int64)
+			children[parent] = append(children[parent], f.ID)
+		}
+	}
+	ids := []uint64{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
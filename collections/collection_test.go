@@ -0,0 +1,46 @@
+package collections
+
+import (
+	"github.com/nskondratev/api-page-go-back/util"
+	"reflect"
+	"testing"
+)
+
+func TestCollection_Validate(t *testing.T) {
+	parent := util.NewNullInt64FromInt64
+
+	cases := []struct {
+		collection *Collection
+		err        string
+	}{
+		{&Collection{Version: 1, Folders: []*Folder{{ID: 1, Name: "A"}, {ID: 2, Name: "B", ParentId: parent(1)}}, Requests: []*Request{{Name: "R", FolderId: parent(2)}}}, ""},
+		{&Collection{Version: 2}, "unsupported collection version: 2"},
+		{&Collection{Version: 1, Folders: []*Folder{{ID: 1, Name: "A"}, {ID: 1, Name: "B"}}}, "folder id 1 is not unique"},
+		{&Collection{Version: 1, Folders: []*Folder{{ID: 1, Name: "A", ParentId: parent(2)}, {ID: 2, Name: "B", ParentId: parent(1)}}}, "folder 1 is nested in itself"},
+		{&Collection{Version: 1, Folders: []*Folder{{ID: 1, Name: "A", ParentId: parent(3)}}}, "folder 1 references missing folder 3"},
+		{&Collection{Version: 1, Requests: []*Request{{Name: "R", FolderId: parent(5)}}}, "request R references missing folder 5"},
+		{&Collection{Version: 1, Requests: []*Request{{}}}, "request name is empty"},
+	}
+
+	for caseNum, item := range cases {
+		err := item.collection.Validate()
+		if len(item.err) < 1 && err != nil || len(item.err) > 0 && (err == nil || err.Error() != item.err) {
+			t.Errorf("[%d] Unexpected error. Expected: %q, actual: %v", caseNum, item.err, err)
+		}
+	}
+}
+
+func TestSubtree(t *testing.T) {
+	parent := util.NewNullInt64FromInt64
+	folders := []*Folder{{ID: 1}, {ID: 2, ParentId: parent(1)}, {ID: 3, ParentId: parent(2)}, {ID: 4}, {ID: 5, ParentId: parent(1)}}
+
+	if ids := Subtree(folders, 1); !reflect.DeepEqual(ids, []uint64{1, 2, 5, 3}) {
+		t.Errorf("Unexpected subtree: %v", ids)
+	}
+	if err := CheckParent(folders, 1, parent(3)); err == nil || err.Error() != "folder 1 is nested in itself" {
+		t.Errorf("Unexpected error of the cycle: %v", err)
+	}
+	if err := CheckParent(folders, 3, parent(4)); err != nil {
+		t.Errorf("Unexpected error: %s", err.Error())
+	}
+}
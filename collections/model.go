@@ -0,0 +1,43 @@
+package collections
+
+import (
+	"github.com/nskondratev/api-page-go-back/util"
+	"time"
+)
+
+// Folder groups saved requests of the event, folders may be nested.
+type Folder struct {
+	ID        uint64         `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	EventId   uint64         `json:"eventId" gorm:"index;column:eventId"`
+	ParentId  util.NullInt64 `json:"parentId" gorm:"type:BIGINT;index;column:parentId"`
+	Name      string         `json:"name" gorm:"size:255;column:name"`
+	CreatedAt time.Time      `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+func (Folder) TableName() string {
+	return "request_folders"
+}
+
+// Request is a payload of the event saved for testing. Strings of the payload may reference
+// variables as ${name}, they are substituted with values of the environment.
+type Request struct {
+	ID          uint64         `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	EventId     uint64         `json:"eventId" gorm:"index;column:eventId"`
+	FolderId    util.NullInt64 `json:"folderId" gorm:"type:BIGINT;index;column:folderId"`
+	Name        string         `json:"name" gorm:"size:255;column:name"`
+	Description string         `json:"description" gorm:"type:text;column:description"`
+	// Environment names the target environment the request is sent to
+	Environment string       `json:"environment" gorm:"size:255;column:environment"`
+	Payload     util.RawJSON `json:"payload" gorm:"type:longtext;column:payload"`
+	// Ack requests the acknowledgement of the emit
+	Ack bool `json:"ack" gorm:"type:TINYINT(1);default:0;column:ack"`
+	// ExpectedResponse is the payload of the ack or the response event the target should reply with
+	ExpectedResponse util.RawJSON `json:"expectedResponse" gorm:"type:longtext;column:expectedResponse"`
+	CreatedAt        time.Time    `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt        time.Time    `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+func (Request) TableName() string {
+	return "saved_requests"
+}
@@ -0,0 +1,16 @@
+package collections
+
+type Store interface {
+	GetFolderById(uint64) (*Folder, error)
+	// ListFolders returns folders of the event ordered by name
+	ListFolders(eventId uint64) ([]*Folder, error)
+	CreateFolder(*Folder) error
+	UpdateFolder(*Folder) error
+	DeleteFolder(*Folder) error
+	GetRequestById(uint64) (*Request, error)
+	// ListRequests returns requests of the event ordered by name
+	ListRequests(eventId uint64) ([]*Request, error)
+	CreateRequest(*Request) error
+	UpdateRequest(*Request) error
+	DeleteRequest(*Request) error
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/collections"
+	"github.com/nskondratev/api-page-go-back/logger"
+)
+
+type Gorm struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+}
+
+func NewGorm(c *GormConfig) collections.Store {
+	return &Gorm{
+		db:     c.DB,
+		logger: c.Logger,
+	}
+}
+
+func (s *Gorm) GetFolderById(id uint64) (*collections.Folder, error) {
+	var folder collections.Folder
+	if err := s.db.First(&folder, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &folder, nil
+}
+
+func (s *Gorm) ListFolders(eventId uint64) ([]*collections.Folder, error) {
+	foldersList := make([]*collections.Folder, 0)
+	err := s.db.Where("`eventId` = ?", eventId).Order("name asc").Find(&foldersList).Error
+	return foldersList, err
+}
+
+func (s *Gorm) CreateFolder(folder *collections.Folder) error {
+	return s.db.Create(folder).Error
+}
+
+func (s *Gorm) UpdateFolder(folder *collections.Folder) error {
+	existing := &collections.Folder{}
+	if err := s.db.First(existing, folder.ID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("[collections.store.gorm] folder with id = %d does not exist", folder.ID)
+		}
+		return err
+	}
+	folder.CreatedAt = existing.CreatedAt
+	res := s.db.Save(folder)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[collections.store.gorm] folder with id = %d was not updated", folder.ID)
+	}
+	return nil
+}
+
+func (s *Gorm) DeleteFolder(folder *collections.Folder) error {
+	res := s.db.Delete(folder)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[collections.store.gorm] folder with id = %d was not deleted", folder.ID)
+	}
+	return nil
+}
+
+func (s *Gorm) GetRequestById(id uint64) (*collections.Request, error) {
+	var request collections.Request
+	if err := s.db.First(&request, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (s *Gorm) ListRequests(eventId uint64) ([]*collections.Request, error) {
+	requestsList := make([]*collections.Request, 0)
+	err := s.db.Where("`eventId` = ?", eventId).Order("name asc").Find(&requestsList).Error
+	return requestsList, err
+}
+
+func (s *Gorm) CreateRequest(request *collections.Request) error {
+	return s.db.Create(request).Error
+}
+
+func (s *Gorm) UpdateRequest(request *collections.Request) error {
+	existing := &collections.Request{}
+	if err := s.db.First(existing, request.ID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("[collections.store.gorm] request with id = %d does not exist", request.ID)
+		}
+		return err
+	}
+	request.CreatedAt = existing.CreatedAt
+	res := s.db.Save(request)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[collections.store.gorm] request with id = %d was not updated", request.ID)
+	}
+	return nil
+}
+
+func (s *Gorm) DeleteRequest(request *collections.Request) error {
+	res := s.db.Delete(request)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[collections.store.gorm] request with id = %d was not deleted", request.ID)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/collections"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger        logger.Logger
+	folders       []*collections.Folder
+	requests      []*collections.Request
+	lastFolderId  uint64
+	lastRequestId uint64
+	mu            *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	return &Memory{
+		logger:   c.Logger,
+		folders:  make([]*collections.Folder, 0),
+		requests: make([]*collections.Request, 0),
+		mu:       &sync.Mutex{},
+	}
+}
+
+func (s *Memory) GetFolderById(id uint64) (*collections.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.folders {
+		if el.ID == id {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) ListFolders(eventId uint64) ([]*collections.Folder, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	foldersList := make([]*collections.Folder, 0)
+	for _, el := range s.folders {
+		if el.EventId == eventId {
+			foldersList = append(foldersList, el)
+		}
+	}
+	// Ordered by name, like the gorm store
+	sort.SliceStable(foldersList, func(i, j int) bool {
+		return foldersList[i].Name < foldersList[j].Name
+	})
+	return foldersList, nil
+}
+
+func (s *Memory) CreateFolder(folder *collections.Folder) error {
+	s.mu.Lock()
+	s.lastFolderId++
+	folder.ID = s.lastFolderId
+	folder.CreatedAt = time.Now()
+	folder.UpdatedAt = folder.CreatedAt
+	s.folders = append(s.folders, folder)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) UpdateFolder(folder *collections.Folder) error {
+	folder.UpdatedAt = time.Now()
+	s.mu.Lock()
+	for i, el := range s.folders {
+		if el.ID == folder.ID {
+			folder.CreatedAt = el.CreatedAt
+			s.folders[i] = folder
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) DeleteFolder(folder *collections.Folder) error {
+	s.mu.Lock()
+	for i, el := range s.folders {
+		if el.ID == folder.ID {
+			copy(s.folders[i:], s.folders[i+1:])
+			s.folders[len(s.folders)-1] = nil
+			s.folders = s.folders[:len(s.folders)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) GetRequestById(id uint64) (*collections.Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.requests {
+		if el.ID == id {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) ListRequests(eventId uint64) ([]*collections.Request, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requestsList := make([]*collections.Request, 0)
+	for _, el := range s.requests {
+		if el.EventId == eventId {
+			requestsList = append(requestsList, el)
+		}
+	}
+	// Ordered by name, like the gorm store
+	sort.SliceStable(requestsList, func(i, j int) bool {
+		return requestsList[i].Name < requestsList[j].Name
+	})
+	return requestsList, nil
+}
+
+func (s *Memory) CreateRequest(request *collections.Request) error {
+	s.mu.Lock()
+	s.lastRequestId++
+	request.ID = s.lastRequestId
+	request.CreatedAt = time.Now()
+	request.UpdatedAt = request.CreatedAt
+	s.requests = append(s.requests, request)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) UpdateRequest(request *collections.Request) error {
+	request.UpdatedAt = time.Now()
+	s.mu.Lock()
+	for i, el := range s.requests {
+		if el.ID == request.ID {
+			request.CreatedAt = el.CreatedAt
+			s.requests[i] = request
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) DeleteRequest(request *collections.Request) error {
+	s.mu.Lock()
+	for i, el := range s.requests {
+		if el.ID == request.ID {
+			copy(s.requests[i:], s.requests[i+1:])
+			s.requests[len(s.requests)-1] = nil
+			s.requests = s.requests[:len(s.requests)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,87 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/collections"
+	"github.com/nskondratev/api-page-go-back/testutils"
+	"testing"
+)
+
+func TestMemory_CreateRequest(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	cases := []testutils.MemoryCreateTestCase{
+		{ItemToCreate: &collections.Request{EventId: 1, Name: "Login"}, TotalRows: 1, LastItemID: 1},
+		{ItemToCreate: &collections.Request{EventId: 1, Name: "Login without name"}, TotalRows: 2, LastItemID: 2},
+	}
+
+	for caseNum, item := range cases {
+		requestToCreate, ok := item.ItemToCreate.(*collections.Request)
+
+		if !ok {
+			t.Errorf("[%d] Can not convert test case item to create to *collections.Request type", caseNum)
+		}
+
+		if err := s.CreateRequest(requestToCreate); err != nil {
+			t.Errorf("[%d] error while creating request %+v", caseNum, requestToCreate)
+		}
+
+		if len(s.requests) != item.TotalRows {
+			t.Errorf("[%d] total rows mismatch. Want %d, received %d", caseNum, item.TotalRows, len(s.requests))
+		}
+
+		if s.requests[len(s.requests)-1].ID != item.LastItemID {
+			t.Errorf("[%d] last request id mismatch. Want %d, received %d", caseNum, item.LastItemID, s.requests[len(s.requests)-1].ID)
+		}
+	}
+}
+
+func TestMemory_ListRequests(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	for _, r := range []*collections.Request{
+		{EventId: 1, Name: "Logout"},
+		{EventId: 2, Name: "Chat"},
+		{EventId: 1, Name: "Login"},
+	} {
+		if err := s.CreateRequest(r); err != nil {
+			t.Fatalf("Can not create test request: %s", err.Error())
+		}
+	}
+
+	requests, err := s.ListRequests(1)
+	if err != nil {
+		t.Fatalf("Can not list requests: %s", err.Error())
+	}
+	if len(requests) != 2 || requests[0].Name != "Login" || requests[1].Name != "Logout" {
+		t.Errorf("Unexpected requests of the event: %+v", requests)
+	}
+}
+
+func TestMemory_UpdateFolder(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	created := &collections.Folder{EventId: 1, Name: "Happy path"}
+	if err := s.CreateFolder(created); err != nil {
+		t.Fatalf("Can not create test folder: %s", err.Error())
+	}
+
+	updated := &collections.Folder{ID: created.ID, EventId: 1, Name: "Errors"}
+	if err := s.UpdateFolder(updated); err != nil {
+		t.Fatalf("Can not update folder: %s", err.Error())
+	}
+
+	stored, err := s.GetFolderById(created.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("Updated folder was not found: %v", err)
+	}
+	if !stored.CreatedAt.Equal(created.CreatedAt) || stored.Name != "Errors" {
+		t.Errorf("Unexpected updated folder: %+v", stored)
+	}
+
+	if err := s.DeleteFolder(stored); err != nil {
+		t.Fatalf("Can not delete folder: %s", err.Error())
+	}
+	if folders, _ := s.ListFolders(1); len(folders) != 0 {
+		t.Errorf("Folder was not deleted: %+v", folders)
+	}
+}
@@ -4,12 +4,37 @@ import (
 	"flag"
 	"github.com/joho/godotenv"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type AppConfig struct {
 	DBConnectionString string
 	Addr               string
 	BaseUrl            string
+	// Mock server of the events catalog
+	MockEnabled  bool
+	MockInterval time.Duration
+	MockSchedule string
+	// Slug of the project whose catalog the mock serves, the default project when empty
+	MockProject string
+	// Hosts of the targets the server connects to on behalf of the users, every host is denied when empty
+	AllowedHosts []string
+	// Limit of connections of load tests started with the API, no limit when zero
+	LoadTestMaxConnections int
+	// SecretKey encrypts secrets of environments, they can not be stored when it is empty
+	SecretKey string
+	// JwtSecret signs the access tokens of users, the server does not start when it is empty
+	JwtSecret string
+	// Lifetime of the access tokens
+	AccessTokenTTL time.Duration
+	// Age of the removed audit entries, entries are kept forever when zero
+	AuditRetention time.Duration
+	// Number of the ws broadcasts kept for the resuming clients, clients can not resume when it is negative
+	WsReplaySize int
+	// Args are the positional arguments left after the flags. When present the app runs a CLI command instead of the server.
+	Args []string
 }
 
 func GetAppConfig() (*AppConfig, error) {
@@ -17,6 +42,11 @@ func GetAppConfig() (*AppConfig, error) {
 		defaultConnectionString = ""
 		defaultAddr             = ""
 		defaultBaseUrl          = ""
+		defaultMockInterval     = 10 * time.Second
+		defaultLoadTestMaxConns = 1000
+		defaultAccessTokenTTL   = 15 * time.Minute
+		defaultAuditRetention   = 90 * 24 * time.Hour
+		defaultWsReplaySize     = 1000
 	)
 	conf := &AppConfig{}
 
@@ -36,6 +66,66 @@ func GetAppConfig() (*AppConfig, error) {
 	if len(conf.BaseUrl) < 1 && len(os.Getenv("BASE_URL")) > 0 {
 		conf.BaseUrl = os.Getenv("BASE_URL")
 	}
+	flag.BoolVar(&conf.MockEnabled, "mock", false, "Serve the mock of the events catalog on /api/mock")
+	if !conf.MockEnabled && os.Getenv("MOCK_ENABLED") == "true" {
+		conf.MockEnabled = true
+	}
+	flag.DurationVar(&conf.MockInterval, "mock-interval", defaultMockInterval, "Interval between emits of frontend events by the mock, 0 disables them")
+	if len(os.Getenv("MOCK_INTERVAL")) > 0 {
+		if interval, err := time.ParseDuration(os.Getenv("MOCK_INTERVAL")); err == nil {
+			conf.MockInterval = interval
+		}
+	}
+	flag.StringVar(&conf.MockSchedule, "mock-schedule", "", "Emit intervals of the mock by event value, e.g. \"status=5s,tick=1m\"")
+	if len(conf.MockSchedule) < 1 && len(os.Getenv("MOCK_SCHEDULE")) > 0 {
+		conf.MockSchedule = os.Getenv("MOCK_SCHEDULE")
+	}
+	flag.StringVar(&conf.MockProject, "mock-project", "", "Slug of the project whose catalog the mock serves, the default project when empty")
+	if len(conf.MockProject) < 1 && len(os.Getenv("MOCK_PROJECT")) > 0 {
+		conf.MockProject = os.Getenv("MOCK_PROJECT")
+	}
+	flag.IntVar(&conf.LoadTestMaxConnections, "load-test-max-connections", defaultLoadTestMaxConns, "Limit of connections of load tests started with the API, 0 disables the limit")
+	if len(os.Getenv("LOAD_TEST_MAX_CONNECTIONS")) > 0 {
+		if limit, err := strconv.Atoi(os.Getenv("LOAD_TEST_MAX_CONNECTIONS")); err == nil {
+			conf.LoadTestMaxConnections = limit
+		}
+	}
+	flag.StringVar(&conf.SecretKey, "secret-key", "", "Key encrypting secrets of environments")
+	if len(conf.SecretKey) < 1 && len(os.Getenv("SECRET_KEY")) > 0 {
+		conf.SecretKey = os.Getenv("SECRET_KEY")
+	}
+	flag.StringVar(&conf.JwtSecret, "jwt-secret", "", "Secret signing the access tokens of users")
+	if len(conf.JwtSecret) < 1 && len(os.Getenv("JWT_SECRET")) > 0 {
+		conf.JwtSecret = os.Getenv("JWT_SECRET")
+	}
+	flag.DurationVar(&conf.AccessTokenTTL, "access-token-ttl", defaultAccessTokenTTL, "Lifetime of the access tokens of users")
+	if len(os.Getenv("ACCESS_TOKEN_TTL")) > 0 {
+		if ttl, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL")); err == nil {
+			conf.AccessTokenTTL = ttl
+		}
+	}
+	flag.DurationVar(&conf.AuditRetention, "audit-retention", defaultAuditRetention, "Age of the removed audit entries, 0 keeps them forever")
+	if len(os.Getenv("AUDIT_RETENTION")) > 0 {
+		if retention, err := time.ParseDuration(os.Getenv("AUDIT_RETENTION")); err == nil {
+			conf.AuditRetention = retention
+		}
+	}
+	flag.IntVar(&conf.WsReplaySize, "ws-replay-size", defaultWsReplaySize, "Number of the ws broadcasts kept for the resuming clients, -1 disables resuming")
+	if len(os.Getenv("WS_REPLAY_SIZE")) > 0 {
+		if size, err := strconv.Atoi(os.Getenv("WS_REPLAY_SIZE")); err == nil {
+			conf.WsReplaySize = size
+		}
+	}
+	allowedHosts := flag.String("allowed-hosts", "", "Comma separated hosts allowed as targets of the relay, the tests and the monitors, * allows any host")
 	flag.Parse()
+	if len(*allowedHosts) < 1 {
+		*allowedHosts = os.Getenv("ALLOWED_HOSTS")
+	}
+	for _, host := range strings.Split(*allowedHosts, ",") {
+		if host = strings.TrimSpace(host); len(host) > 0 {
+			conf.AllowedHosts = append(conf.AllowedHosts, host)
+		}
+	}
+	conf.Args = flag.Args()
 	return conf, nil
 }
@@ -0,0 +1,189 @@
+package coverage
+
+import (
+	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"sort"
+	"strings"
+)
+
+// Report compares the observed traffic with the events catalog.
+// Only event frames are taken into account, acks are not matched with their emits.
+type Report struct {
+	Messages       int `json:"messages"`
+	CatalogEvents  int `json:"catalogEvents"`
+	ObservedEvents int `json:"observedEvents"`
+	// Coverage is the percent of catalog events observed in the traffic
+	Coverage float64 `json:"coverage"`
+	// NeverObserved lists values of catalog events absent in the traffic
+	NeverObserved []string `json:"neverObserved"`
+	// Undocumented lists observed events missing from the catalog
+	Undocumented []*Count `json:"undocumented"`
+	// Events lists observed catalog events
+	Events []*EventCoverage `json:"events"`
+}
+
+type EventCoverage struct {
+	Event string `json:"event"`
+	Count int    `json:"count"`
+	// UndocumentedFields lists payload keys without a field definition
+	UndocumentedFields []*Count `json:"undocumentedFields"`
+	// MissingRequired lists required fields absent in payloads
+	MissingRequired []*Count `json:"missingRequired"`
+}
+
+// Count is the number of messages a name was observed in.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type counter map[string]int
+
+func (c counter) list() []*Count {
+	res := make([]*Count, 0, len(c))
+	for name, count := range c {
+		res = append(res, &Count{Name: name, Count: count})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Count != res[j].Count {
+			return res[i].Count > res[j].Count
+		}
+		return res[i].Name < res[j].Name
+	})
+	return res
+}
+
+// Compute builds the coverage report of the messages.
+func Compute(catalog []*events.Event, messages []recordings.Message) *Report {
+	byValue := make(map[string]*events.Event, len(catalog))
+	for _, e := range catalog {
+		byValue[e.Value] = e
+	}
+	observed := make(map[string]int)
+	undocumented := counter{}
+	undocumentedFields := make(map[string]counter)
+	missingRequired := make(map[string]counter)
+	report := &Report{CatalogEvents: len(catalog)}
+
+	for i := range messages {
+		m := &messages[i]
+		if m.Kind != socket.FrameEvent {
+			continue
+		}
+		report.Messages++
+		e, ok := byValue[m.Event]
+		if !ok {
+			undocumented[m.Event]++
+			continue
+		}
+		if observed[e.Value] == 0 {
+			undocumentedFields[e.Value] = counter{}
+			missingRequired[e.Value] = counter{}
+		}
+		observed[e.Value]++
+		var payload interface{}
+		if len(m.Data) > 0 {
+			if err := json.Unmarshal(m.Data, &payload); err != nil {
+				continue
+			}
+		}
+		documented := documentedKeys(e.Fields)
+		for _, key := range PayloadKeys(payload) {
+			if !documented.covers(key) {
+				undocumentedFields[e.Value][key]++
+			}
+		}
+		for _, key := range events.MissingRequired(e.Fields, payload) {
+			missingRequired[e.Value][key]++
+		}
+	}
+
+	report.NeverObserved = make([]string, 0)
+	for _, e := range catalog {
+		if observed[e.Value] == 0 {
+			report.NeverObserved = append(report.NeverObserved, e.Value)
+		}
+	}
+	sort.Strings(report.NeverObserved)
+	report.Undocumented = undocumented.list()
+	report.Events = make([]*EventCoverage, 0, len(observed))
+	for value, count := range observed {
+		report.Events = append(report.Events, &EventCoverage{
+			Event:              value,
+			Count:              count,
+			UndocumentedFields: undocumentedFields[value].list(),
+			MissingRequired:    missingRequired[value].list(),
+		})
+	}
+	sort.Slice(report.Events, func(i, j int) bool {
+		return report.Events[i].Event < report.Events[j].Event
+	})
+	report.ObservedEvents = len(observed)
+	if report.CatalogEvents > 0 {
+		report.Coverage = float64(report.ObservedEvents) * 100 / float64(report.CatalogEvents)
+	}
+	return report
+}
+
+// keySet holds documented keys. Keys of fields referencing shared types cover their nested keys.
+type keySet struct {
+	keys   map[string]bool
+	shared []string
+}
+
+func documentedKeys(fields []events.Field) *keySet {
+	ks := &keySet{keys: make(map[string]bool, len(fields))}
+	for _, f := range fields {
+		ks.keys[f.Key.String] = true
+		if f.TypeId.Valid {
+			ks.shared = append(ks.shared, f.Key.String)
+		}
+	}
+	return ks
+}
+
+func (ks *keySet) covers(key string) bool {
+	if ks.keys[key] {
+		return true
+	}
+	for _, prefix := range ks.shared {
+		if strings.HasPrefix(key, prefix+".") || strings.HasPrefix(key, prefix+"[]") {
+			return true
+		}
+	}
+	return false
+}
+
+// PayloadKeys lists the keys of the decoded payload in the form of field keys:
+// "user.name" for nested keys and "items[].id" for keys of array elements.
+func PayloadKeys(payload interface{}) []string {
+	seen := make(map[string]bool)
+	collectKeys(payload, "", seen)
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func collectKeys(v interface{}, prefix string, seen map[string]bool) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for name, child := range x {
+			key := name
+			if len(prefix) > 0 {
+				key = prefix + "." + name
+			}
+			seen[key] = true
+			collectKeys(child, key, seen)
+		}
+	case []interface{}:
+		for _, item := range x {
+			collectKeys(item, prefix+"[]", seen)
+		}
+	}
+}
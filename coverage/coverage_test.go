@@ -0,0 +1,85 @@
+package coverage
+
+import (
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"github.com/nskondratev/api-page-go-back/util"
+	"reflect"
+	"testing"
+)
+
+func testCatalog() []*events.Event {
+	name, _ := util.NewNullStringFromString("name")
+	email, _ := util.NewNullStringFromString("email")
+	user, _ := util.NewNullStringFromString("user")
+	return []*events.Event{
+		{Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}, {Key: email, Type: "string"}}},
+		{Value: "profile", Type: events.TypeFrontend, Fields: []events.Field{{Key: user, Type: "object", TypeId: util.NewNullInt64FromInt64(1)}}},
+		{Value: "logout", Type: events.TypeClient},
+	}
+}
+
+func TestCompute(t *testing.T) {
+	messages := []recordings.Message{
+		{Direction: recordings.DirectionOut, Kind: "event", Event: "login", Data: util.RawJSON(`{"name":"John","remember":true}`)},
+		{Direction: recordings.DirectionOut, Kind: "event", Event: "login", Data: util.RawJSON(`{"remember":false}`)},
+		{Direction: recordings.DirectionIn, Kind: "ack", Data: util.RawJSON(`{"ok":true}`)},
+		{Direction: recordings.DirectionIn, Kind: "event", Event: "profile", Data: util.RawJSON(`{"user":{"id":1,"tags":["a"]}}`)},
+		{Direction: recordings.DirectionIn, Kind: "event", Event: "notice", Data: util.RawJSON(`{}`)},
+	}
+
+	r := Compute(testCatalog(), messages)
+
+	if r.Messages != 4 || r.CatalogEvents != 3 || r.ObservedEvents != 2 {
+		t.Errorf("Unexpected counts: %+v", r)
+	}
+	if int(r.Coverage) != 66 {
+		t.Errorf("Unexpected coverage: %f", r.Coverage)
+	}
+	if !reflect.DeepEqual(r.NeverObserved, []string{"logout"}) {
+		t.Errorf("Unexpected never observed events: %v", r.NeverObserved)
+	}
+	if len(r.Undocumented) != 1 || *r.Undocumented[0] != (Count{Name: "notice", Count: 1}) {
+		t.Errorf("Unexpected undocumented events: %v", r.Undocumented)
+	}
+	if len(r.Events) != 2 || r.Events[0].Event != "login" || r.Events[0].Count != 2 {
+		t.Fatalf("Unexpected events: %v", r.Events)
+	}
+	login := r.Events[0]
+	if len(login.UndocumentedFields) != 1 || *login.UndocumentedFields[0] != (Count{Name: "remember", Count: 2}) {
+		t.Errorf("Unexpected undocumented fields: %v", login.UndocumentedFields)
+	}
+	if len(login.MissingRequired) != 1 || *login.MissingRequired[0] != (Count{Name: "name", Count: 1}) {
+		t.Errorf("Unexpected missing required fields: %v", login.MissingRequired)
+	}
+	if profile := r.Events[1]; len(profile.UndocumentedFields) != 0 {
+		t.Errorf("Keys of shared types should be documented: %v", profile.UndocumentedFields)
+	}
+}
+
+func TestPayloadKeys(t *testing.T) {
+	cases := []struct {
+		payload interface{}
+		keys    []string
+	}{
+		{map[string]interface{}{"user": map[string]interface{}{"name": "John"}}, []string{"user", "user.name"}},
+		{map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 1}}}, []string{"items", "items[].id"}},
+		{"text", []string{}},
+		{nil, []string{}},
+	}
+
+	for caseNum, item := range cases {
+		if keys := PayloadKeys(item.payload); !reflect.DeepEqual(keys, item.keys) {
+			t.Errorf("[%d] Unexpected keys. Want: %v, received: %v", caseNum, item.keys, keys)
+		}
+	}
+}
+
+func TestParseIds(t *testing.T) {
+	if ids, err := ParseIds(" 1, 2,,"); err != nil || !reflect.DeepEqual(ids, []uint64{1, 2}) {
+		t.Errorf("Unexpected ids: %v, error: %v", ids, err)
+	}
+	if _, err := ParseIds("1,a"); err == nil {
+		t.Error("Invalid id should not be parsed")
+	}
+}
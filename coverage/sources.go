@@ -0,0 +1,50 @@
+package coverage
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"strconv"
+	"strings"
+)
+
+// SessionMessages loads the messages of the recorded sessions, of all sessions when ids are empty.
+func SessionMessages(rs recordings.Store, ids []uint64) ([]recordings.Message, error) {
+	if len(ids) < 1 {
+		list, _, err := rs.List(0, -1)
+		if err != nil {
+			return nil, err
+		}
+		for _, el := range list {
+			ids = append(ids, el.ID)
+		}
+	}
+	messages := make([]recordings.Message, 0)
+	for _, id := range ids {
+		session, err := rs.GetById(id)
+		if err != nil {
+			return nil, err
+		}
+		if session == nil {
+			return nil, fmt.Errorf("recorded session with id = %d does not exist", id)
+		}
+		messages = append(messages, session.Messages...)
+	}
+	return messages, nil
+}
+
+// ParseIds parses the comma separated list of session ids.
+func ParseIds(s string) ([]uint64, error) {
+	ids := make([]uint64, 0)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 1 {
+			continue
+		}
+		id, err := strconv.ParseUint(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid session id: %s", item)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,11 @@
+package db
+
+import "github.com/jinzhu/gorm"
+
+// DropIndex removes the index of the table when it exists, the migrations use it for replaced indexes.
+func DropIndex(d *gorm.DB, table, name string) error {
+	if !d.Dialect().HasIndex(table, name) {
+		return nil
+	}
+	return d.Table(table).RemoveIndex(name).Error
+}
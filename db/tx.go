@@ -0,0 +1,22 @@
+package db
+
+import "github.com/jinzhu/gorm"
+
+// Transaction runs fn inside a transaction, committing when fn returns nil and rolling back otherwise.
+func Transaction(d *gorm.DB, fn func(tx *gorm.DB) error) error {
+	tx := d.Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			panic(r)
+		}
+	}()
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
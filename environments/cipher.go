@@ -0,0 +1,96 @@
+package environments
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+)
+
+// ErrNoKey is returned when secrets are stored or read without the secret key configured.
+var ErrNoKey = errors.New("secret key is not configured")
+
+// Cipher encrypts values of secrets with AES-GCM. The key is derived from the configured secret key.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher returns the cipher of the key, nil for the empty key. A nil cipher fails on environments with secrets.
+func NewCipher(key string) (*Cipher, error) {
+	if len(key) < 1 {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Seal returns a copy of the environment with values of secrets encrypted.
+func (c *Cipher) Seal(e *Environment) (*Environment, error) {
+	var sealErr error
+	sealed := e.mapSecrets(func(p *Param) string {
+		value, err := c.encrypt(p.Value)
+		if err != nil && sealErr == nil {
+			sealErr = err
+		}
+		return value
+	})
+	return sealed, sealErr
+}
+
+// Open decrypts values of secrets of the stored environment in place.
+func (c *Cipher) Open(e *Environment) error {
+	for _, list := range [][]*Param{e.Query, e.Headers, e.Variables} {
+		for _, p := range list {
+			if !p.Secret {
+				continue
+			}
+			value, err := c.decrypt(p.Value)
+			if err != nil {
+				return err
+			}
+			p.Value = value
+		}
+	}
+	return nil
+}
+
+func (c *Cipher) encrypt(value string) (string, error) {
+	if c == nil {
+		return "", ErrNoKey
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+func (c *Cipher) decrypt(value string) (string, error) {
+	if c == nil {
+		return "", ErrNoKey
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < c.aead.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+	nonce, sealed := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	plain, err := c.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", errors.New("can not decrypt the secret, the secret key may have changed")
+	}
+	return string(plain), nil
+}
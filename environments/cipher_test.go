@@ -0,0 +1,54 @@
+package environments
+
+import (
+	"testing"
+)
+
+func TestCipher_SealOpen(t *testing.T) {
+	c, err := NewCipher("test key")
+	if err != nil {
+		t.Fatalf("Can not create cipher: %s", err.Error())
+	}
+	e := &Environment{
+		Name:      "stage",
+		Query:     []*Param{{Name: "token", Value: "s3cr3t", Secret: true}},
+		Variables: []*Param{{Name: "user", Value: "bob"}},
+	}
+
+	sealed, err := c.Seal(e)
+	if err != nil {
+		t.Fatalf("Can not seal environment: %s", err.Error())
+	}
+	if sealed.Query[0].Value == "s3cr3t" || sealed.Variables[0].Value != "bob" {
+		t.Errorf("Unexpected sealed params: %+v, %+v", sealed.Query[0], sealed.Variables[0])
+	}
+	if e.Query[0].Value != "s3cr3t" {
+		t.Errorf("Seal changed the source environment: %+v", e.Query[0])
+	}
+
+	if err := c.Open(sealed); err != nil {
+		t.Fatalf("Can not open environment: %s", err.Error())
+	}
+	if sealed.Query[0].Value != "s3cr3t" {
+		t.Errorf("Unexpected opened secret: %s", sealed.Query[0].Value)
+	}
+
+	sealed, _ = c.Seal(e)
+	other, _ := NewCipher("other key")
+	if err := other.Open(sealed); err == nil {
+		t.Errorf("Secret is opened with another key")
+	}
+}
+
+func TestCipher_NoKey(t *testing.T) {
+	c, err := NewCipher("")
+	if err != nil || c != nil {
+		t.Fatalf("Unexpected cipher of the empty key: %v, %v", c, err)
+	}
+	if _, err := c.Seal(&Environment{Variables: []*Param{{Name: "user", Value: "bob"}}}); err != nil {
+		t.Errorf("Environment without secrets is not sealed: %s", err.Error())
+	}
+	if _, err := c.Seal(&Environment{Headers: []*Param{{Name: "Authorization", Value: "token", Secret: true}}}); err != ErrNoKey {
+		t.Errorf("Unexpected error of the secret without the key: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package environments
+
+import (
+	"errors"
+	"github.com/graphql-go/graphql"
+	"github.com/nskondratev/api-page-go-back/gql"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/users"
+)
+
+var ParamGraphQLType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "EnvironmentParam",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+			"value": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Value of the param, masked for secrets",
+			},
+			"secret": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+		},
+	},
+)
+
+var GraphQLType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "Environment",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.ID,
+			},
+			"name": &graphql.Field{
+				Type: graphql.String,
+			},
+			"description": &graphql.Field{
+				Type: graphql.String,
+			},
+			"baseUrl": &graphql.Field{
+				Type: graphql.String,
+			},
+			"protocol": &graphql.Field{
+				Type: graphql.String,
+			},
+			"query": &graphql.Field{
+				Type: graphql.NewList(ParamGraphQLType),
+			},
+			"headers": &graphql.Field{
+				Type: graphql.NewList(ParamGraphQLType),
+			},
+			"variables": &graphql.Field{
+				Type: graphql.NewList(ParamGraphQLType),
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
+
+func RegisterGraphQLQueries(es Store, hub *gql.GraphQLHub) error {
+	environmentByIdQuery := &graphql.Field{
+		Type:        GraphQLType,
+		Description: "Get environment by id",
+		Args: graphql.FieldConfigArgument{
+			"id": &graphql.ArgumentConfig{
+				Type: graphql.Int,
+			},
+		},
+		Resolve: getByIdResolver(es),
+	}
+	if err := hub.AddQuery("environment", environmentByIdQuery); err != nil {
+		return err
+	}
+	return nil
+}
+
+// getByIdResolver resolves the environment with values of secrets masked.
+func getByIdResolver(es Store) func(graphql.ResolveParams) (interface{}, error) {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		id, ok := p.Args["id"].(int)
+		if !ok {
+			return nil, errors.New("graphql: cannot parse id argument")
+		}
+		projectId := projects.IdFromContext(p.Context)
+		if err := users.Check(p.Context, projectId, users.PermTestsRead); err != nil {
+			return nil, err
+		}
+		environment, err := es.ForProject(projectId).GetById(uint64(id))
+		if err != nil || environment == nil {
+			return nil, err
+		}
+		return environment.Masked(), nil
+	}
+}
@@ -0,0 +1,182 @@
+package environments
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Mask replaces secret values in responses. A secret sent back as the mask keeps its stored value.
+const Mask = "********"
+
+// Environment is a named set of the target connection settings and variables, like dev, stage or prod.
+// Query params are added to the handshake URL, headers are sent with the handshake and variables are
+// referenced from payloads of scenarios and saved requests as ${name}.
+type Environment struct {
+	ID          uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId   uint64    `json:"projectId" gorm:"unique_index:idx_environments_project_name;default:1;column:projectId"`
+	Name        string    `json:"name" gorm:"size:255;unique_index:idx_environments_project_name;column:name"`
+	Description string    `json:"description" gorm:"type:text;column:description"`
+	BaseUrl     string    `json:"baseUrl" gorm:"size:1024;column:baseUrl"`
+	Protocol    string    `json:"protocol" gorm:"size:32;column:protocol"`
+	Query       []*Param  `json:"query" gorm:"-"`
+	Headers     []*Param  `json:"headers" gorm:"-"`
+	Variables   []*Param  `json:"variables" gorm:"-"`
+	Data        string    `json:"-" gorm:"type:longtext;column:params"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+// Param is a named value of the environment. Values of secrets are encrypted at rest and masked in responses.
+type Param struct {
+	Name   string `json:"name" validate:"required"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret"`
+}
+
+type params struct {
+	Query     []*Param `json:"query"`
+	Headers   []*Param `json:"headers"`
+	Variables []*Param `json:"variables"`
+}
+
+func (Environment) TableName() string {
+	return "environments"
+}
+
+// BeforeSave serializes the params into the params column.
+func (e *Environment) BeforeSave() error {
+	data, err := json.Marshal(&params{Query: e.Query, Headers: e.Headers, Variables: e.Variables})
+	if err != nil {
+		return err
+	}
+	e.Data = string(data)
+	return nil
+}
+
+// AfterFind restores the params from the params column.
+func (e *Environment) AfterFind() error {
+	p := &params{}
+	if len(e.Data) > 0 {
+		if err := json.Unmarshal([]byte(e.Data), p); err != nil {
+			return err
+		}
+	}
+	e.Query, e.Headers, e.Variables = orEmpty(p.Query), orEmpty(p.Headers), orEmpty(p.Variables)
+	return nil
+}
+
+// Masked returns a copy of the environment with values of secrets replaced by the mask.
+func (e *Environment) Masked() *Environment {
+	return e.mapSecrets(func(p *Param) string {
+		return Mask
+	})
+}
+
+// KeepSecrets restores values of secrets sent back as the mask from the existing environment.
+func (e *Environment) KeepSecrets(existing *Environment) {
+	keep := func(updated, stored []*Param) {
+		for _, p := range updated {
+			if !p.Secret || p.Value != Mask {
+				continue
+			}
+			for _, s := range stored {
+				if s.Name == p.Name && s.Secret {
+					p.Value = s.Value
+				}
+			}
+		}
+	}
+	keep(e.Query, existing.Query)
+	keep(e.Headers, existing.Headers)
+	keep(e.Variables, existing.Variables)
+}
+
+// Target returns the base URL with the query params added.
+func (e *Environment) Target() (string, error) {
+	u, err := url.Parse(e.BaseUrl)
+	if err != nil {
+		return "", err
+	}
+	if len(e.Query) > 0 {
+		q := u.Query()
+		for _, p := range e.Query {
+			q.Set(p.Name, p.Value)
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
+}
+
+// Header returns the headers of the handshake.
+func (e *Environment) Header() http.Header {
+	h := http.Header{}
+	for _, p := range e.Headers {
+		h.Set(p.Name, p.Value)
+	}
+	return h
+}
+
+// Values returns the variables by name.
+func (e *Environment) Values() map[string]interface{} {
+	values := make(map[string]interface{}, len(e.Variables))
+	for _, p := range e.Variables {
+		values[p.Name] = p.Value
+	}
+	return values
+}
+
+// SecretValues returns the non-empty values of secrets, messages built from the params mask them.
+func (e *Environment) SecretValues() []string {
+	values := make([]string, 0)
+	for _, list := range [][]*Param{e.Query, e.Headers, e.Variables} {
+		for _, p := range list {
+			if p.Secret && len(p.Value) > 0 {
+				values = append(values, p.Value)
+			}
+		}
+	}
+	return values
+}
+
+// WithoutSecrets returns a copy of the environment without the secret params.
+func (e *Environment) WithoutSecrets() *Environment {
+	res := *e
+	public := func(list []*Param) []*Param {
+		kept := make([]*Param, 0, len(list))
+		for _, p := range list {
+			if !p.Secret {
+				kept = append(kept, p)
+			}
+		}
+		return kept
+	}
+	res.Query, res.Headers, res.Variables = public(e.Query), public(e.Headers), public(e.Variables)
+	return &res
+}
+
+// mapSecrets returns a copy of the environment with values of secrets replaced by the result of fn.
+func (e *Environment) mapSecrets(fn func(*Param) string) *Environment {
+	res := *e
+	copyParams := func(list []*Param) []*Param {
+		copied := make([]*Param, len(list))
+		for i, p := range list {
+			param := *p
+			if param.Secret {
+				param.Value = fn(p)
+			}
+			copied[i] = &param
+		}
+		return copied
+	}
+	res.Query, res.Headers, res.Variables = copyParams(e.Query), copyParams(e.Headers), copyParams(e.Variables)
+	return &res
+}
+
+func orEmpty(list []*Param) []*Param {
+	if list == nil {
+		return make([]*Param, 0)
+	}
+	return list
+}
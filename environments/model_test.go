@@ -0,0 +1,70 @@
+package environments
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvironment_Masked(t *testing.T) {
+	e := &Environment{
+		Name:      "stage",
+		Headers:   []*Param{{Name: "Authorization", Value: "Bearer s3cr3t", Secret: true}},
+		Variables: []*Param{{Name: "user", Value: "bob"}},
+	}
+	masked := e.Masked()
+	if masked.Headers[0].Value != Mask || masked.Variables[0].Value != "bob" {
+		t.Errorf("Unexpected masked params: %+v, %+v", masked.Headers[0], masked.Variables[0])
+	}
+	if e.Headers[0].Value != "Bearer s3cr3t" {
+		t.Errorf("Masked changed the source environment: %+v", e.Headers[0])
+	}
+
+	updated := &Environment{Headers: []*Param{{Name: "Authorization", Value: Mask, Secret: true}, {Name: "X-Token", Value: Mask, Secret: true}}}
+	updated.KeepSecrets(e)
+	if updated.Headers[0].Value != "Bearer s3cr3t" || updated.Headers[1].Value != Mask {
+		t.Errorf("Unexpected kept secrets: %+v, %+v", updated.Headers[0], updated.Headers[1])
+	}
+}
+
+func TestEnvironment_Target(t *testing.T) {
+	e := &Environment{
+		BaseUrl: "wss://stage.example.com/socket?v=2",
+		Query:   []*Param{{Name: "token", Value: "a b"}},
+		Headers: []*Param{{Name: "Cookie", Value: "sid=1"}},
+	}
+	target, err := e.Target()
+	if err != nil {
+		t.Fatalf("Can not build target: %s", err.Error())
+	}
+	if target != "wss://stage.example.com/socket?token=a+b&v=2" {
+		t.Errorf("Unexpected target: %s", target)
+	}
+	if e.Header().Get("Cookie") != "sid=1" {
+		t.Errorf("Unexpected header: %+v", e.Header())
+	}
+	if _, err := (&Environment{BaseUrl: "://"}).Target(); err == nil {
+		t.Errorf("Invalid base URL is accepted")
+	}
+	if values := (&Environment{Variables: []*Param{{Name: "user", Value: "bob"}}}).Values(); !reflect.DeepEqual(values, map[string]interface{}{"user": "bob"}) {
+		t.Errorf("Unexpected values: %+v", values)
+	}
+}
+
+func TestEnvironment_WithoutSecrets(t *testing.T) {
+	e := &Environment{
+		Name:      "stage",
+		Query:     []*Param{{Name: "token", Value: "s3cr3t", Secret: true}},
+		Headers:   []*Param{{Name: "Authorization", Value: "Bearer s3cr3t", Secret: true}, {Name: "Cookie", Value: "sid=1"}},
+		Variables: []*Param{{Name: "user", Value: "bob"}, {Name: "empty", Secret: true}},
+	}
+	if values := e.SecretValues(); len(values) != 2 || values[0] != "s3cr3t" || values[1] != "Bearer s3cr3t" {
+		t.Errorf("Unexpected values of secrets: %v", values)
+	}
+	public := e.WithoutSecrets()
+	if len(public.Query) != 0 || len(public.Headers) != 1 || public.Headers[0].Name != "Cookie" || len(public.Variables) != 1 {
+		t.Errorf("Unexpected params without secrets: %+v, %+v, %+v", public.Query, public.Headers, public.Variables)
+	}
+	if len(public.SecretValues()) != 0 || len(e.Headers) != 2 {
+		t.Errorf("WithoutSecrets changed the source environment: %+v", e.Headers)
+	}
+}
@@ -0,0 +1,13 @@
+package environments
+
+// Store keeps the environments of a single project. Environments of other projects are not visible to it.
+type Store interface {
+	GetById(uint64) (*Environment, error)
+	GetByName(string) (*Environment, error)
+	List(offset, limit int) ([]*Environment, int, error)
+	Create(*Environment) error
+	Update(*Environment) error
+	Delete(*Environment) error
+	// ForProject returns the store of the environments of the project sharing the storage with this one
+	ForProject(projectId uint64) Store
+}
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/environments"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+)
+
+type Gorm struct {
+	db        *gorm.DB
+	logger    logger.Logger
+	cipher    *environments.Cipher
+	projectId uint64
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+	// Cipher encrypts values of secrets, environments with secrets can not be stored without it
+	Cipher *environments.Cipher
+	// ProjectId is the project of the environments, the default project when zero
+	ProjectId uint64
+}
+
+func NewGorm(c *GormConfig) environments.Store {
+	s := &Gorm{
+		db:        c.DB,
+		logger:    c.Logger,
+		cipher:    c.Cipher,
+		projectId: c.ProjectId,
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Gorm) ForProject(projectId uint64) environments.Store {
+	return NewGorm(&GormConfig{DB: s.db, Logger: s.logger, Cipher: s.cipher, ProjectId: projectId})
+}
+
+// scoped limits the query to the environments of the project.
+func (s *Gorm) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("`projectId` = ?", s.projectId)
+}
+
+func (s *Gorm) GetById(id uint64) (*environments.Environment, error) {
+	var environment environments.Environment
+	if err := s.scoped(s.db).First(&environment, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := s.cipher.Open(&environment); err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+func (s *Gorm) GetByName(name string) (*environments.Environment, error) {
+	var environment environments.Environment
+	if err := s.scoped(s.db).Where("`name` = ?", name).First(&environment).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := s.cipher.Open(&environment); err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+func (s *Gorm) List(offset, limit int) ([]*environments.Environment, int, error) {
+	environmentsList, total := make([]*environments.Environment, 0), 0
+	qb := s.scoped(s.db.Model(&environmentsList))
+	if err := qb.Count(&total).Error; err != nil {
+		return environmentsList, total, err
+	}
+	if err := qb.Offset(offset).Limit(limit).Order("name asc").Find(&environmentsList).Error; err != nil {
+		return environmentsList, total, err
+	}
+	for _, environment := range environmentsList {
+		if err := s.cipher.Open(environment); err != nil {
+			return environmentsList, total, err
+		}
+	}
+	return environmentsList, total, nil
+}
+
+func (s *Gorm) Create(environment *environments.Environment) error {
+	environment.ProjectId = s.projectId
+	sealed, err := s.cipher.Seal(environment)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Create(sealed).Error; err != nil {
+		return err
+	}
+	environment.ID = sealed.ID
+	environment.CreatedAt = sealed.CreatedAt
+	environment.UpdatedAt = sealed.UpdatedAt
+	return nil
+}
+
+func (s *Gorm) Update(environment *environments.Environment) error {
+	existing := &environments.Environment{}
+	if err := s.scoped(s.db).First(existing, environment.ID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("[environments.store.gorm] environment with id = %d does not exist", environment.ID)
+		}
+		return err
+	}
+	environment.CreatedAt = existing.CreatedAt
+	environment.ProjectId = existing.ProjectId
+	sealed, err := s.cipher.Seal(environment)
+	if err != nil {
+		return err
+	}
+	res := s.db.Save(sealed)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[environments.store.gorm] environment with id = %d was not updated", environment.ID)
+	}
+	environment.UpdatedAt = sealed.UpdatedAt
+	return nil
+}
+
+func (s *Gorm) Delete(environment *environments.Environment) error {
+	res := s.scoped(s.db).Delete(environment)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[environments.store.gorm] environment with id = %d was not deleted", environment.ID)
+	}
+	return nil
+}
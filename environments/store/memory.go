@@ -0,0 +1,139 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/environments"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger    logger.Logger
+	projectId uint64
+	// Records of all projects are shared by the stores returned by ForProject
+	*memoryRecords
+}
+
+type memoryRecords struct {
+	records []*environments.Environment
+	lastId  uint64
+	mu      *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+	// ProjectId is the project of the environments, the default project when zero
+	ProjectId uint64
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	s := &Memory{
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+		memoryRecords: &memoryRecords{
+			records: make([]*environments.Environment, 0),
+			mu:      &sync.Mutex{},
+		},
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Memory) ForProject(projectId uint64) environments.Store {
+	return &Memory{
+		logger:        s.logger,
+		projectId:     projectId,
+		memoryRecords: s.memoryRecords,
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*environments.Environment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id && el.ProjectId == s.projectId {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) GetByName(name string) (*environments.Environment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.Name == name && el.ProjectId == s.projectId {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int) ([]*environments.Environment, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	environmentsList := make([]*environments.Environment, 0, len(s.records))
+	for _, el := range s.records {
+		if el.ProjectId == s.projectId {
+			environmentsList = append(environmentsList, el)
+		}
+	}
+	// Ordered by name, like the gorm store
+	sort.SliceStable(environmentsList, func(i, j int) bool {
+		return environmentsList[i].Name < environmentsList[j].Name
+	})
+	total := len(environmentsList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return environmentsList[offset : offset+l], total, nil
+}
+
+func (s *Memory) Create(environment *environments.Environment) error {
+	s.mu.Lock()
+	s.lastId++
+	environment.ID = s.lastId
+	environment.ProjectId = s.projectId
+	environment.CreatedAt = time.Now()
+	environment.UpdatedAt = environment.CreatedAt
+	s.records = append(s.records, environment)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Update(environment *environments.Environment) error {
+	environment.UpdatedAt = time.Now()
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == environment.ID && el.ProjectId == s.projectId {
+			environment.CreatedAt = el.CreatedAt
+			environment.ProjectId = el.ProjectId
+			s.records[i] = environment
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Delete(environment *environments.Environment) error {
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == environment.ID && el.ProjectId == s.projectId {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,29 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/environments"
+	"testing"
+)
+
+func TestMemory_List(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	for _, name := range []string{"stage", "dev", "prod"} {
+		if err := s.Create(&environments.Environment{Name: name}); err != nil {
+			t.Fatalf("Can not create test environment: %s", err.Error())
+		}
+	}
+
+	list, total, err := s.List(1, 5)
+	if err != nil {
+		t.Fatalf("Can not list environments: %s", err.Error())
+	}
+	if total != 3 || len(list) != 2 || list[0].Name != "prod" || list[1].Name != "stage" {
+		t.Errorf("Unexpected environments list: total %d, %+v", total, list)
+	}
+
+	found, err := s.GetByName("dev")
+	if err != nil || found == nil || found.ID != 2 {
+		t.Errorf("Unexpected environment by name: %+v, %v", found, err)
+	}
+}
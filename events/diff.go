@@ -0,0 +1,291 @@
+package events
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"sort"
+	"strings"
+)
+
+const (
+	ChangeEventAdded            = "event_added"
+	ChangeEventRemoved          = "event_removed"
+	ChangeEventTypeChanged      = "event_type_changed"
+	ChangeEventDescription      = "event_description_changed"
+	ChangeFieldAdded            = "field_added"
+	ChangeFieldRemoved          = "field_removed"
+	ChangeFieldBecameRequired   = "field_became_required"
+	ChangeFieldBecameOptional   = "field_became_optional"
+	ChangeFieldTypeChanged      = "field_type_changed"
+	ChangeFieldDescription      = "field_description_changed"
+	ChangeFieldConstraints      = "field_constraints_changed"
+	ChangeResponseEventChanged  = "response_event_changed"
+	ChangeResponseTimeoutChange = "response_timeout_changed"
+	ChangeTypeKindChanged       = "type_kind_changed"
+	ChangeEnumValueAdded        = "enum_value_added"
+	ChangeEnumValueRemoved      = "enum_value_removed"
+)
+
+// Change is a single difference between two versions of the events catalog.
+type Change struct {
+	Kind     string `json:"kind"`
+	Breaking bool   `json:"breaking"`
+	Event    string `json:"event"`
+	Field    string `json:"field,omitempty"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+}
+
+func (c *Change) String() string {
+	severity := "non-breaking"
+	if c.Breaking {
+		severity = "BREAKING"
+	}
+	subject := c.Event
+	if len(c.Field) > 0 {
+		subject += "." + c.Field
+	}
+	s := fmt.Sprintf("[%s] %s: %s", severity, c.Kind, subject)
+	if len(c.From) > 0 || len(c.To) > 0 {
+		s += fmt.Sprintf(" (%q -> %q)", c.From, c.To)
+	}
+	return s
+}
+
+type Diff struct {
+	Breaking bool      `json:"breaking"`
+	Changes  []*Change `json:"changes"`
+}
+
+// differ compares the definitions of the shared types referenced by the fields of both catalogs.
+type differ struct {
+	*Diff
+	fromTypes Types
+	toTypes   Types
+	// stack holds the shared types being compared, which stops the recursion of self-referencing types
+	stack map[string]bool
+}
+
+// Compare classifies every difference between two catalogs. Events are matched
+// by their value (the name sent over the wire), fields by their key. Fields keeping
+// the same shared type are compared with the definitions of the type in both catalogs,
+// so a change of the type is reported for every field using it.
+func Compare(from, to []*Event, fromTypes, toTypes Types) *Diff {
+	d := &differ{Diff: &Diff{Changes: make([]*Change, 0)}, fromTypes: fromTypes, toTypes: toTypes, stack: make(map[string]bool)}
+	fromByValue, toByValue := eventsByValue(from), eventsByValue(to)
+	fromIds, toIds := eventValuesById(from), eventValuesById(to)
+	for _, value := range eventValues(fromByValue, toByValue) {
+		oldEvent, inFrom := fromByValue[value]
+		newEvent, inTo := toByValue[value]
+		switch {
+		case !inTo:
+			d.add(&Change{Kind: ChangeEventRemoved, Breaking: true, Event: value})
+		case !inFrom:
+			d.add(&Change{Kind: ChangeEventAdded, Event: value})
+		default:
+			if oldEvent.Type != newEvent.Type {
+				d.add(&Change{Kind: ChangeEventTypeChanged, Breaking: true, Event: value, From: oldEvent.Type, To: newEvent.Type})
+			}
+			if oldEvent.Description != newEvent.Description {
+				d.add(&Change{Kind: ChangeEventDescription, Event: value})
+			}
+			d.compareFields(value, "", oldEvent.Fields, newEvent.Fields)
+			d.compareFields(value, "ack.", oldEvent.AckFields, newEvent.AckFields)
+			oldResponse, newResponse := fromIds[oldEvent.ResponseEventId.Int64], toIds[newEvent.ResponseEventId.Int64]
+			if oldResponse != newResponse {
+				d.add(&Change{Kind: ChangeResponseEventChanged, Breaking: len(oldResponse) > 0, Event: value, From: oldResponse, To: newResponse})
+			}
+			if oldEvent.ResponseTimeout != newEvent.ResponseTimeout {
+				d.add(&Change{
+					Kind:  ChangeResponseTimeoutChange,
+					Event: value,
+					From:  fmt.Sprintf("%d", oldEvent.ResponseTimeout),
+					To:    fmt.Sprintf("%d", newEvent.ResponseTimeout),
+				})
+			}
+		}
+	}
+	return d.Diff
+}
+
+func (d *Diff) add(c *Change) {
+	d.Changes = append(d.Changes, c)
+	d.Breaking = d.Breaking || c.Breaking
+}
+
+func (d *differ) compareFields(event, prefix string, from, to []Field) {
+	fromByKey, toByKey := fieldsByKey(from), fieldsByKey(to)
+	for _, key := range fieldKeys(fromByKey, toByKey) {
+		oldField, inFrom := fromByKey[key]
+		newField, inTo := toByKey[key]
+		switch {
+		case !inTo:
+			d.add(&Change{Kind: ChangeFieldRemoved, Breaking: true, Event: event, Field: prefix + key})
+		case !inFrom:
+			d.add(&Change{Kind: ChangeFieldAdded, Breaking: newField.Required, Event: event, Field: prefix + key, To: newField.Type})
+		default:
+			if oldField.Type != newField.Type {
+				d.add(&Change{Kind: ChangeFieldTypeChanged, Breaking: true, Event: event, Field: prefix + key, From: oldField.Type, To: newField.Type})
+			} else {
+				d.compareShared(event, prefix+key, oldField.Type)
+			}
+			if !oldField.Required && newField.Required {
+				d.add(&Change{Kind: ChangeFieldBecameRequired, Breaking: true, Event: event, Field: prefix + key})
+			}
+			if oldField.Required && !newField.Required {
+				d.add(&Change{Kind: ChangeFieldBecameOptional, Event: event, Field: prefix + key})
+			}
+			if oldField.Description != newField.Description {
+				d.add(&Change{Kind: ChangeFieldDescription, Event: event, Field: prefix + key})
+			}
+			oldConstraints, newConstraints := oldField.Constraints(), newField.Constraints()
+			if strings.Join(oldConstraints, "; ") != strings.Join(newConstraints, "; ") {
+				d.add(&Change{
+					Kind:     ChangeFieldConstraints,
+					Breaking: tightens(oldField, newField),
+					Event:    event,
+					Field:    prefix + key,
+					From:     strings.Join(oldConstraints, "; "),
+					To:       strings.Join(newConstraints, "; "),
+				})
+			}
+		}
+	}
+}
+
+// compareShared compares the definitions of the shared type in both catalogs: its kind,
+// the values of enums and the fields of objects.
+func (d *differ) compareShared(event, path, name string) {
+	oldType, newType := d.fromTypes[name], d.toTypes[name]
+	if oldType == nil || newType == nil || d.stack[name] {
+		return
+	}
+	d.stack[name] = true
+	defer delete(d.stack, name)
+	if oldType.Kind != newType.Kind {
+		d.add(&Change{Kind: ChangeTypeKindChanged, Breaking: true, Event: event, Field: path, From: oldType.Kind, To: newType.Kind})
+		return
+	}
+	if oldType.Kind == registry.KindEnum {
+		oldValues, newValues := enumValues(oldType), enumValues(newType)
+		for _, value := range sortedKeys(oldValues) {
+			if !newValues[value] {
+				d.add(&Change{Kind: ChangeEnumValueRemoved, Breaking: true, Event: event, Field: path, From: value})
+			}
+		}
+		for _, value := range sortedKeys(newValues) {
+			if !oldValues[value] {
+				d.add(&Change{Kind: ChangeEnumValueAdded, Event: event, Field: path, To: value})
+			}
+		}
+		return
+	}
+	d.compareFields(event, path+".", typeFields(oldType), typeFields(newType))
+}
+
+func enumValues(t *registry.Type) map[string]bool {
+	res := make(map[string]bool, len(t.Values))
+	for _, v := range t.Values {
+		res[v.Value] = true
+	}
+	return res
+}
+
+func typeFields(t *registry.Type) []Field {
+	res := make([]Field, len(t.Fields))
+	for i, tf := range t.Fields {
+		res[i] = Field{Key: tf.Key, Type: tf.Type, Required: tf.Required, Description: tf.Description}
+	}
+	return res
+}
+
+func eventsByValue(list []*Event) map[string]*Event {
+	res := make(map[string]*Event, len(list))
+	for _, e := range list {
+		res[e.Value] = e
+	}
+	return res
+}
+
+func eventValuesById(list []*Event) map[int64]string {
+	res := make(map[int64]string, len(list))
+	for _, e := range list {
+		res[int64(e.ID)] = e.Value
+	}
+	return res
+}
+
+func fieldsByKey(list []Field) map[string]*Field {
+	res := make(map[string]*Field, len(list))
+	for i := range list {
+		res[list[i].Key.String] = &list[i]
+	}
+	return res
+}
+
+func eventValues(a, b map[string]*Event) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	return sortedKeys(seen)
+}
+
+func fieldKeys(a, b map[string]*Field) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	return sortedKeys(seen)
+}
+
+func sortedKeys(seen map[string]bool) []string {
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// tightens reports whether the constraints of the new field may reject values valid for the old one:
+// a raised min or minLength, a lowered max or maxLength, a removed enum value or a new pattern or format.
+// Relaxed constraints and changed defaults are not breaking.
+func tightens(from, to *Field) bool {
+	switch {
+	case to.Minimum.Valid && (!from.Minimum.Valid || to.Minimum.Float64 > from.Minimum.Float64):
+		return true
+	case to.Maximum.Valid && (!from.Maximum.Valid || to.Maximum.Float64 < from.Maximum.Float64):
+		return true
+	case to.MinLength.Valid && (!from.MinLength.Valid || to.MinLength.Int64 > from.MinLength.Int64):
+		return true
+	case to.MaxLength.Valid && (!from.MaxLength.Valid || to.MaxLength.Int64 < from.MaxLength.Int64):
+		return true
+	case len(to.Pattern) > 0 && to.Pattern != from.Pattern:
+		return true
+	case len(to.Format) > 0 && to.Format != from.Format:
+		return true
+	}
+	if len(to.Enum) < 1 {
+		return false
+	}
+	if len(from.Enum) < 1 {
+		return true
+	}
+	allowed := make(map[string]bool, len(to.Enum))
+	for _, v := range to.Enum {
+		allowed[v] = true
+	}
+	for _, v := range from.Enum {
+		if !allowed[v] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package events
+
+import (
+	"github.com/nskondratev/api-page-go-back/util"
+	"testing"
+)
+
+func TestCompare_Constraints(t *testing.T) {
+	length := util.NewNullInt64FromInt64
+	number := util.NewNullFloat64FromFloat64
+	cases := []struct {
+		from, to Field
+		breaking bool
+	}{
+		{Field{MaxLength: length(10)}, Field{MaxLength: length(20)}, false},
+		{Field{MaxLength: length(20)}, Field{MaxLength: length(10)}, true},
+		{Field{MaxLength: length(20)}, Field{}, false},
+		{Field{}, Field{MaxLength: length(20)}, true},
+		{Field{MinLength: length(2)}, Field{MinLength: length(1)}, false},
+		{Field{MinLength: length(1)}, Field{MinLength: length(2)}, true},
+		{Field{Minimum: number(0)}, Field{Minimum: number(-1)}, false},
+		{Field{Minimum: number(0)}, Field{Minimum: number(1)}, true},
+		{Field{Maximum: number(10)}, Field{Maximum: number(100)}, false},
+		{Field{Maximum: number(10)}, Field{Maximum: number(9.5)}, true},
+		{Field{Enum: util.StringList{"a"}}, Field{Enum: util.StringList{"a", "b"}}, false},
+		{Field{Enum: util.StringList{"a", "b"}}, Field{Enum: util.StringList{"a"}}, true},
+		{Field{Enum: util.StringList{"a"}}, Field{}, false},
+		{Field{}, Field{Enum: util.StringList{"a"}}, true},
+		{Field{Pattern: "^[a-z]+$"}, Field{}, false},
+		{Field{Pattern: "^[a-z]+$"}, Field{Pattern: "^[a-z0-9]+$"}, true},
+		{Field{Format: "email"}, Field{}, false},
+		{Field{}, Field{Format: "email"}, true},
+		{Field{DefaultValue: util.RawJSON(`"a"`)}, Field{DefaultValue: util.RawJSON(`"b"`)}, false},
+		{Field{MaxLength: length(10), MinLength: length(2)}, Field{MaxLength: length(20), MinLength: length(3)}, true},
+	}
+
+	key, _ := util.NewNullStringFromString("name")
+	for caseNum, item := range cases {
+		item.from.Key, item.from.Type = key, "string"
+		item.to.Key, item.to.Type = key, "string"
+		diff := Compare(
+			[]*Event{{Value: "user", Fields: []Field{item.from}}},
+			[]*Event{{Value: "user", Fields: []Field{item.to}}},
+			nil, nil,
+		)
+		if len(diff.Changes) != 1 || diff.Changes[0].Kind != ChangeFieldConstraints {
+			t.Errorf("[%d] Unexpected changes: %+v", caseNum, diff.Changes)
+			continue
+		}
+		if diff.Breaking != item.breaking {
+			t.Errorf("[%d] Unexpected breaking flag of %s -> %s. Want %t", caseNum, diff.Changes[0].From, diff.Changes[0].To, item.breaking)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package events
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// RenderMarkdown generates the Markdown documentation of the events catalog.
+func RenderMarkdown(list []*Event) []byte {
+	byId := make(map[uint64]*Event, len(list))
+	for _, e := range list {
+		byId[e.ID] = e
+	}
+	b := &bytes.Buffer{}
+	b.WriteString("# Events\n")
+	for _, eType := range []string{"client", "frontend"} {
+		fmt.Fprintf(b, "\n## %s events\n", strings.Title(eType))
+		for _, e := range list {
+			if e.Type != eType {
+				continue
+			}
+			fmt.Fprintf(b, "\n### `%s`\n\n", e.Value)
+			fmt.Fprintf(b, "Constant: `%s`\n\n", e.Constant)
+			if e.Label.Valid && len(e.Label.String) > 0 {
+				fmt.Fprintf(b, "%s\n\n", e.Label.String)
+			}
+			if len(e.Description) > 0 {
+				fmt.Fprintf(b, "%s\n\n", e.Description)
+			}
+			writeFieldsTable(b, "Payload", e.Fields)
+			writeFieldsTable(b, "Acknowledgement", e.AckFields)
+			if e.HasResponse() {
+				responseName := fmt.Sprintf("#%d", e.ResponseEventId.Int64)
+				if r, ok := byId[uint64(e.ResponseEventId.Int64)]; ok {
+					responseName = "`" + r.Value + "`"
+				}
+				fmt.Fprintf(b, "Response: %s", responseName)
+				if e.ResponseTimeout > 0 {
+					fmt.Fprintf(b, " within %d ms", e.ResponseTimeout)
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.Bytes()
+}
+
+func writeFieldsTable(b *bytes.Buffer, title string, fields []Field) {
+	if len(fields) < 1 {
+		return
+	}
+	fmt.Fprintf(b, "%s:\n\n| Key | Type | Required | Constraints | Description |\n| --- | --- | --- | --- | --- |\n", title)
+	for _, f := range fields {
+		required := "no"
+		if f.Required {
+			required = "yes"
+		}
+		constraints := strings.Replace(strings.Join(f.Constraints(), "; "), "|", "\\|", -1)
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s | %s |\n", f.Key.String, f.Type, required, constraints, strings.Replace(f.Description, "\n", " ", -1))
+	}
+	b.WriteString("\n")
+}
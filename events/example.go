@@ -0,0 +1,170 @@
+package events
+
+import (
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// GenerateExample builds a payload satisfying the fields definition. Defaults and enum values are
+// preferred, other values are derived from the type, format and limits of the field.
+// Patterns are not taken into account. Values of the shared types are built from their definitions.
+func GenerateExample(fields []Field, types Types) interface{} {
+	sorted := make([]*Field, len(fields))
+	for i := range fields {
+		sorted[i] = &fields[i]
+	}
+	// Parents are generated before their nested keys
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.Count(sorted[i].Key.String, ".") < strings.Count(sorted[j].Key.String, ".")
+	})
+	var root interface{}
+	for _, f := range sorted {
+		if len(f.Key.String) < 1 {
+			root = ExampleValue(f, types)
+			continue
+		}
+		if _, ok := root.(map[string]interface{}); !ok {
+			root = make(map[string]interface{})
+		}
+		setPath(root.(map[string]interface{}), strings.Split(f.Key.String, "."), ExampleValue(f, types))
+	}
+	if root == nil {
+		return make(map[string]interface{})
+	}
+	return root
+}
+
+// ExampleValue returns a value satisfying the constraints of the single field.
+func ExampleValue(f *Field, types Types) interface{} {
+	if len(f.DefaultValue) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(f.DefaultValue, &v); err == nil {
+			return v
+		}
+	}
+	t := strings.ToLower(f.Type)
+	if len(f.Enum) > 0 {
+		if t == "number" || t == "integer" || t == "int" || t == "float" || t == "double" {
+			if n, err := strconv.ParseFloat(f.Enum[0], 64); err == nil {
+				return n
+			}
+		}
+		return f.Enum[0]
+	}
+	switch t {
+	case "string":
+		return exampleString(f)
+	case "number", "float", "double":
+		return exampleNumber(f, false)
+	case "integer", "int":
+		return exampleNumber(f, true)
+	case "boolean", "bool":
+		return true
+	case "object":
+		return make(map[string]interface{})
+	case "array":
+		return make([]interface{}, 0)
+	}
+	if shared := types[f.Type]; shared != nil {
+		return types.exampleShared(shared, make(map[string]bool))
+	}
+	return nil
+}
+
+func exampleString(f *Field) string {
+	var s string
+	switch f.Format {
+	case FormatUUID:
+		return "123e4567-e89b-12d3-a456-426614174000"
+	case FormatEmail:
+		return "user@example.com"
+	case FormatDateTime:
+		return "2019-01-01T00:00:00Z"
+	default:
+		s = f.Key.String
+		if idx := strings.LastIndex(s, "."); idx >= 0 {
+			s = s[idx+1:]
+		}
+		s = strings.TrimSuffix(s, "[]")
+		if len(s) < 1 {
+			s = "string"
+		}
+	}
+	// Lengths are counted in runes like in the validation. Fields stored before the length limits
+	// were capped may exceed MaxLengthLimit.
+	length := int64(utf8.RuneCountInString(s))
+	if f.MinLength.Valid && length < f.MinLength.Int64 {
+		s += strings.Repeat("x", int(minInt64(f.MinLength.Int64, MaxLengthLimit)-length))
+	}
+	if f.MaxLength.Valid && length > f.MaxLength.Int64 {
+		s = string([]rune(s)[:f.MaxLength.Int64])
+	}
+	return s
+}
+
+func exampleNumber(f *Field, integer bool) float64 {
+	n := 1.0
+	switch {
+	case f.Minimum.Valid && f.Maximum.Valid:
+		n = f.Minimum.Float64 + (f.Maximum.Float64-f.Minimum.Float64)/2
+	case f.Minimum.Valid:
+		n = f.Minimum.Float64
+	case f.Maximum.Valid && f.Maximum.Float64 < n:
+		n = f.Maximum.Float64
+	}
+	if integer {
+		n = math.Ceil(n)
+		if f.Maximum.Valid && n > f.Maximum.Float64 {
+			n = math.Floor(f.Maximum.Float64)
+		}
+	}
+	return n
+}
+
+func setPath(obj map[string]interface{}, segments []string, value interface{}) {
+	name := strings.TrimSuffix(segments[0], "[]")
+	isArray := name != segments[0]
+	if len(segments) == 1 {
+		if isArray {
+			if value == nil {
+				obj[name] = make([]interface{}, 0)
+			} else {
+				obj[name] = []interface{}{value}
+			}
+			return
+		}
+		obj[name] = value
+		return
+	}
+	if isArray {
+		items, ok := obj[name].([]interface{})
+		if !ok || len(items) < 1 {
+			items = []interface{}{make(map[string]interface{})}
+		}
+		item, ok := items[0].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			items[0] = item
+		}
+		obj[name] = items
+		setPath(item, segments[1:], value)
+		return
+	}
+	child, ok := obj[name].(map[string]interface{})
+	if !ok {
+		child = make(map[string]interface{})
+		obj[name] = child
+	}
+	setPath(child, segments[1:], value)
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
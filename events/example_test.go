@@ -0,0 +1,38 @@
+package events
+
+import (
+	"github.com/nskondratev/api-page-go-back/util"
+	"testing"
+)
+
+func TestExampleValue_Length(t *testing.T) {
+	length := util.NewNullInt64FromInt64
+	cases := []struct {
+		key      string
+		field    Field
+		expected string
+	}{
+		{"имя", Field{MaxLength: length(2)}, "им"},
+		{"é", Field{MinLength: length(3)}, "éxx"},
+		{"name", Field{MinLength: length(2), MaxLength: length(3)}, "nam"},
+		{"name", Field{MinLength: length(1 << 40)}, ""},
+	}
+	for caseNum, item := range cases {
+		f := item.field
+		f.Key, _ = util.NewNullStringFromString(item.key)
+		f.Type = "string"
+		v, ok := ExampleValue(&f, nil).(string)
+		if f.MinLength.Int64 > MaxLengthLimit {
+			if !ok || len(v) != MaxLengthLimit {
+				t.Errorf("[%d] Example of the legacy minLength is not capped: %d", caseNum, len(v))
+			}
+			continue
+		}
+		if !ok || v != item.expected {
+			t.Errorf("[%d] Unexpected example. Want %q, received %q", caseNum, item.expected, v)
+		}
+		if errs := ValidatePayload([]Field{f}, nil, map[string]interface{}{item.key: v}); len(errs) > 0 {
+			t.Errorf("[%d] Example does not match the field: %s", caseNum, errs[0].Error())
+		}
+	}
+}
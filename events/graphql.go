@@ -0,0 +1,235 @@
+package events
+
+import (
+	"errors"
+	"github.com/graphql-go/graphql"
+	"github.com/nskondratev/api-page-go-back/gql"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/users"
+)
+
+var FieldGraphQLType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "EventField",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.ID,
+			},
+			"key": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if f := fieldSource(p); f != nil && f.Key.Valid {
+						return f.Key.String, nil
+					}
+					return nil, nil
+				},
+			},
+			"type": &graphql.Field{
+				Type: graphql.String,
+			},
+			"typeId": &graphql.Field{
+				Type: graphql.ID,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if f := fieldSource(p); f != nil && f.TypeId.Valid {
+						return f.TypeId.Int64, nil
+					}
+					return nil, nil
+				},
+			},
+			"required": &graphql.Field{
+				Type: graphql.Boolean,
+			},
+			"description": &graphql.Field{
+				Type: graphql.String,
+			},
+			"minimum": &graphql.Field{
+				Type: graphql.Float,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if f := fieldSource(p); f != nil && f.Minimum.Valid {
+						return f.Minimum.Float64, nil
+					}
+					return nil, nil
+				},
+			},
+			"maximum": &graphql.Field{
+				Type: graphql.Float,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if f := fieldSource(p); f != nil && f.Maximum.Valid {
+						return f.Maximum.Float64, nil
+					}
+					return nil, nil
+				},
+			},
+			"minLength": &graphql.Field{
+				Type: graphql.Int,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if f := fieldSource(p); f != nil && f.MinLength.Valid {
+						return f.MinLength.Int64, nil
+					}
+					return nil, nil
+				},
+			},
+			"maxLength": &graphql.Field{
+				Type: graphql.Int,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if f := fieldSource(p); f != nil && f.MaxLength.Valid {
+						return f.MaxLength.Int64, nil
+					}
+					return nil, nil
+				},
+			},
+			"pattern": &graphql.Field{
+				Type: graphql.String,
+			},
+			"format": &graphql.Field{
+				Type: graphql.String,
+			},
+			"enum": &graphql.Field{
+				Type: graphql.NewList(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if f := fieldSource(p); f != nil && f.Enum != nil {
+						return []string(f.Enum), nil
+					}
+					return nil, nil
+				},
+			},
+			"default": &graphql.Field{
+				Type:        graphql.String,
+				Description: "Default value encoded as JSON",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if f := fieldSource(p); f != nil && len(f.DefaultValue) > 0 {
+						return string(f.DefaultValue), nil
+					}
+					return nil, nil
+				},
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
+
+var GraphQLType = graphql.NewObject(
+	graphql.ObjectConfig{
+		Name: "Event",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.ID,
+			},
+			"constant": &graphql.Field{
+				Type: graphql.String,
+			},
+			"label": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if e, ok := p.Source.(*Event); ok && e.Label.Valid {
+						return e.Label.String, nil
+					}
+					return nil, nil
+				},
+			},
+			"value": &graphql.Field{
+				Type: graphql.String,
+			},
+			"description": &graphql.Field{
+				Type: graphql.String,
+			},
+			"type": &graphql.Field{
+				Type: graphql.String,
+			},
+			"fields": &graphql.Field{
+				Type: graphql.NewList(FieldGraphQLType),
+			},
+			"ackFields": &graphql.Field{
+				Type: graphql.NewList(FieldGraphQLType),
+			},
+			"responseEventId": &graphql.Field{
+				Type: graphql.ID,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if e, ok := p.Source.(*Event); ok && e.HasResponse() {
+						return e.ResponseEventId.Int64, nil
+					}
+					return nil, nil
+				},
+			},
+			"responseTimeout": &graphql.Field{
+				Type: graphql.Int,
+			},
+			"createdAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+			"updatedAt": &graphql.Field{
+				Type: graphql.DateTime,
+			},
+		},
+	},
+)
+
+func RegisterGraphQLQueries(es Store, hub *gql.GraphQLHub) error {
+	// The response event has the same type as its source, so the field is added after the type is declared.
+	GraphQLType.AddFieldConfig("responseEvent", &graphql.Field{
+		Type:        GraphQLType,
+		Description: "Event expected as a reply",
+		Resolve:     responseEventResolver(es),
+	})
+	eventByIdQuery := &graphql.Field{
+		Type:        GraphQLType,
+		Description: "Get event by id",
+		Args: graphql.FieldConfigArgument{
+			"id": &graphql.ArgumentConfig{
+				Type: graphql.Int,
+			},
+		},
+		Resolve: getByIdResolver(es),
+	}
+	if err := hub.AddQuery("event", eventByIdQuery); err != nil {
+		return err
+	}
+	return nil
+}
+
+func getByIdResolver(es Store) func(graphql.ResolveParams) (interface{}, error) {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		id, ok := p.Args["id"].(int)
+		if !ok {
+			return nil, errors.New("graphql: cannot parse id argument")
+		}
+		projectId := projects.IdFromContext(p.Context)
+		if err := users.Check(p.Context, projectId, users.PermEventsRead); err != nil {
+			return nil, err
+		}
+		event, err := es.ForProject(projectId).GetById(uint64(id))
+		if err != nil || event == nil {
+			return nil, err
+		}
+		return event, nil
+	}
+}
+
+func responseEventResolver(es Store) func(graphql.ResolveParams) (interface{}, error) {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		e, ok := p.Source.(*Event)
+		if !ok || !e.HasResponse() {
+			return nil, nil
+		}
+		responseEvent, err := es.ForProject(projects.IdFromContext(p.Context)).GetById(uint64(e.ResponseEventId.Int64))
+		if err != nil || responseEvent == nil {
+			return nil, err
+		}
+		return responseEvent, nil
+	}
+}
+
+func fieldSource(p graphql.ResolveParams) *Field {
+	switch f := p.Source.(type) {
+	case Field:
+		return &f
+	case *Field:
+		return f
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package events
+
+import (
+	"github.com/nskondratev/api-page-go-back/util"
+	"sort"
+	"strings"
+)
+
+// Types of inferred fields which are not JSON types
+const (
+	TypeMixed = "mixed"
+	TypeNull  = "null"
+)
+
+type keyStats struct {
+	types   map[string]bool
+	nonNull int
+}
+
+type inference struct {
+	keys map[string]*keyStats
+	// Number of objects observed at the key
+	objects map[string]int
+}
+
+// InferFields proposes the fields describing all the sample payloads. A key is required when it is
+// present and not null in every object containing it, keys with values of several types get the mixed type.
+// Objects in arrays are described with "items[].key" fields, scalar items with an "items[]" field.
+func InferFields(samples []interface{}) []Field {
+	inf := &inference{keys: make(map[string]*keyStats), objects: make(map[string]int)}
+	for _, s := range samples {
+		inf.visit("", s)
+	}
+	keys := make([]string, 0, len(inf.keys))
+	for key := range inf.keys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fields := make([]Field, 0, len(keys))
+	for _, key := range keys {
+		stats := inf.keys[key]
+		t := stats.typeName()
+		// The object root and objects in arrays are described by the fields of their keys
+		if t == "object" && (len(key) < 1 || strings.HasSuffix(key, "[]")) {
+			continue
+		}
+		required := false
+		if len(key) < 1 {
+			required = stats.nonNull == len(samples)
+		} else if !strings.HasSuffix(key, "[]") {
+			required = stats.nonNull == inf.objects[parentKey(key)]
+		}
+		k, _ := util.NewNullStringFromString(key)
+		fields = append(fields, Field{Key: k, Type: t, Required: required})
+	}
+	return fields
+}
+
+func (inf *inference) visit(key string, v interface{}) {
+	stats, ok := inf.keys[key]
+	if !ok {
+		stats = &keyStats{types: make(map[string]bool)}
+		inf.keys[key] = stats
+	}
+	t := jsonTypeOf(v)
+	if t != TypeNull {
+		stats.types[t] = true
+		stats.nonNull++
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		inf.objects[key]++
+		for name, child := range x {
+			childKey := name
+			if len(key) > 0 {
+				childKey = key + "." + name
+			}
+			inf.visit(childKey, child)
+		}
+	case []interface{}:
+		for _, item := range x {
+			inf.visit(key+"[]", item)
+		}
+	}
+}
+
+func (s *keyStats) typeName() string {
+	switch len(s.types) {
+	case 0:
+		return TypeNull
+	case 1:
+		for t := range s.types {
+			return t
+		}
+	}
+	return TypeMixed
+}
+
+func parentKey(key string) string {
+	if idx := strings.LastIndex(key, "."); idx >= 0 {
+		return key[:idx]
+	}
+	return ""
+}
+
+// MergeFields keeps the stored definition of the inferred keys which are already described: the ID,
+// the description and the constraints survive, the type and required-ness are taken from the inference.
+// Stored types of the same JSON type, e.g. integer for number, are kept. Stored keys absent in the samples
+// are kept as well and become optional when their parent was observed. Fields referencing shared types
+// are kept as is and cover the inferred keys nested in them.
+func MergeFields(stored, inferred []Field) []Field {
+	byKey := fieldsByKey(stored)
+	shared := make([]string, 0)
+	for _, f := range stored {
+		if f.TypeId.Valid {
+			shared = append(shared, f.Key.String)
+		}
+	}
+	observed := map[string]bool{"": true}
+	merged := make([]Field, 0, len(inferred))
+	for _, f := range inferred {
+		observed[f.Key.String] = true
+		observed[strings.TrimSuffix(f.Key.String, "[]")] = true
+		if isNestedIn(f.Key.String, shared) {
+			continue
+		}
+		old, ok := byKey[f.Key.String]
+		if !ok {
+			merged = append(merged, f)
+			continue
+		}
+		m := *old
+		if !old.TypeId.Valid && jsonTypeName(old.Type) != f.Type {
+			m.Type = f.Type
+		}
+		m.Required = f.Required
+		merged = append(merged, m)
+	}
+	for _, f := range stored {
+		if observed[f.Key.String] {
+			continue
+		}
+		if observed[strings.TrimSuffix(parentKey(f.Key.String), "[]")] {
+			f.Required = false
+		}
+		merged = append(merged, f)
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Key.String < merged[j].Key.String
+	})
+	return merged
+}
+
+// jsonTypeName returns the JSON type of the field type, so that integer fields are not replaced by number ones.
+func jsonTypeName(t string) string {
+	switch strings.ToLower(t) {
+	case "number", "integer", "int", "float", "double":
+		return "number"
+	case "boolean", "bool":
+		return "boolean"
+	}
+	return strings.ToLower(t)
+}
+
+func isNestedIn(key string, parents []string) bool {
+	for _, p := range parents {
+		if strings.HasPrefix(key, p+".") || strings.HasPrefix(key, p+"[]") {
+			return true
+		}
+	}
+	return false
+}
@@ -5,15 +5,36 @@ import (
 	"time"
 )
 
+// Types of events
+const (
+	// TypeFrontend events are emitted by the server to the frontend
+	TypeFrontend = "frontend"
+	// TypeClient events are emitted by the client to the server
+	TypeClient = "client"
+)
+
+// Field describes a key of the event payload. When TypeId references a shared type
+// from the registry, Type holds the name of that type.
 type Field struct {
 	ID          uint64          `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
 	EventId     uint64          `json:"eventId" gorm:"column:eventId"`
 	Type        string          `json:"type" gorm:"size:255;column:type"`
+	TypeId      util.NullInt64  `json:"typeId" gorm:"type:BIGINT;index;column:typeId"`
 	Key         util.NullString `json:"key" gorm:"size:255;column:key"`
 	Required    bool            `json:"required" gorm:"type:TINYINT(1);default:0;column:required"`
 	Description string          `json:"description" gorm:"type:text;column:description"`
-	CreatedAt   time.Time       `json:"createdAt" gorm:"column:createdAt"`
-	UpdatedAt   time.Time       `json:"updatedAt" gorm:"column:updatedAt"`
+	// Constraints of the value
+	Minimum      util.NullFloat64 `json:"minimum" gorm:"type:DOUBLE;column:minimum"`
+	Maximum      util.NullFloat64 `json:"maximum" gorm:"type:DOUBLE;column:maximum"`
+	MinLength    util.NullInt64   `json:"minLength" gorm:"type:INT;column:minLength"`
+	MaxLength    util.NullInt64   `json:"maxLength" gorm:"type:INT;column:maxLength"`
+	Pattern      string           `json:"pattern" gorm:"size:1024;column:pattern"`
+	Format       string           `json:"format" gorm:"size:64;column:format"`
+	Enum         util.StringList  `json:"enum" gorm:"type:text;column:enumValues"`
+	DefaultValue util.RawJSON     `json:"default" gorm:"type:text;column:defaultValue"`
+	Ack          bool             `json:"-" gorm:"type:TINYINT(1);default:0;column:ack"`
+	CreatedAt    time.Time        `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt    time.Time        `json:"updatedAt" gorm:"column:updatedAt"`
 }
 
 func (Field) TableName() string {
@@ -22,24 +43,35 @@ func (Field) TableName() string {
 
 type Event struct {
 	ID          uint64          `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId   uint64          `json:"projectId" gorm:"index;default:1;column:projectId"`
 	Constant    string          `json:"constant" gorm:"size:255;column:constant"`
 	Label       util.NullString `json:"label" gorm:"size:255;column:label"`
 	Value       string          `json:"value" gorm:"size:255;column:value"`
 	Description string          `json:"description" gorm:"type:text;column:description"`
 	Type        string          `json:"type" gorm:"type:ENUM('frontend','client');default:'frontend'"`
 	Fields      []Field         `json:"fields" gorm:"foreignKey:eventId;"`
-	CreatedAt   time.Time       `json:"createdAt" gorm:"column:createdAt"`
-	UpdatedAt   time.Time       `json:"updatedAt" gorm:"column:updatedAt"`
+	// AckFields describes the payload passed to the acknowledgement callback of the emit.
+	AckFields []Field `json:"ackFields" gorm:"foreignKey:eventId;"`
+	// ResponseEventId links the event which is expected as a reply within ResponseTimeout milliseconds.
+	ResponseEventId util.NullInt64 `json:"responseEventId" gorm:"type:BIGINT;column:responseEventId"`
+	ResponseTimeout int            `json:"responseTimeout" gorm:"column:responseTimeout;default:0"`
+	// Users who created and last updated the event, null for changes made before users were introduced
+	CreatedBy util.NullInt64 `json:"createdBy" gorm:"type:BIGINT;column:createdBy"`
+	UpdatedBy util.NullInt64 `json:"updatedBy" gorm:"type:BIGINT;column:updatedBy"`
+	CreatedAt time.Time      `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt" gorm:"column:updatedAt"`
 }
 
 type EventList struct {
-	ID        uint64          `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
-	Constant  string          `json:"constant" gorm:"size:255;column:constant"`
-	Label     util.NullString `json:"label" gorm:"size:255;column:label"`
-	Value     string          `json:"value" gorm:"size:255;column:value"`
-	Type      string          `json:"type" gorm:"type:ENUM('frontend','client');default:'frontend'"`
-	CreatedAt time.Time       `json:"createdAt" gorm:"column:createdAt"`
-	UpdatedAt time.Time       `json:"updatedAt" gorm:"column:updatedAt"`
+	ID              uint64          `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId       uint64          `json:"projectId" gorm:"index;default:1;column:projectId"`
+	Constant        string          `json:"constant" gorm:"size:255;column:constant"`
+	Label           util.NullString `json:"label" gorm:"size:255;column:label"`
+	Value           string          `json:"value" gorm:"size:255;column:value"`
+	Type            string          `json:"type" gorm:"type:ENUM('frontend','client');default:'frontend'"`
+	ResponseEventId util.NullInt64  `json:"responseEventId" gorm:"type:BIGINT;column:responseEventId"`
+	CreatedAt       time.Time       `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt       time.Time       `json:"updatedAt" gorm:"column:updatedAt"`
 }
 
 func (Event) TableName() string {
@@ -49,3 +81,31 @@ func (Event) TableName() string {
 func (EventList) TableName() string {
 	return "events"
 }
+
+// MarkAckFields sets the Ack flag on fields according to the list they belong to,
+// so both lists can share the event_fields table.
+func (e *Event) MarkAckFields() {
+	for i := range e.Fields {
+		e.Fields[i].Ack = false
+	}
+	for i := range e.AckFields {
+		e.AckFields[i].Ack = true
+	}
+}
+
+// HasResponse reports whether the event is linked to a response event.
+func (e *Event) HasResponse() bool {
+	return e.ResponseEventId.Valid && e.ResponseEventId.Int64 > 0
+}
+
+// UsesType reports whether any payload or ack field of the event references the shared type.
+func (e *Event) UsesType(typeId uint64) bool {
+	for _, list := range [][]Field{e.Fields, e.AckFields} {
+		for _, f := range list {
+			if f.TypeId.Valid && uint64(f.TypeId.Int64) == typeId {
+				return true
+			}
+		}
+	}
+	return false
+}
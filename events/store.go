@@ -1,9 +1,17 @@
 package events
 
+// Store keeps the events of a single project. Events of other projects are not visible to it.
 type Store interface {
 	GetById(uint64) (*Event, error)
+	GetAll() ([]*Event, error)
+	GetByTypeId(uint64) ([]*Event, error)
+	// GetByResponseEventId returns the events expecting the event as their response
+	GetByResponseEventId(uint64) ([]*Event, error)
 	List(offset, limit int, sort string, descending bool, eType string, query string) ([]*EventList, int, error)
 	Create(*Event) error
 	Update(*Event) error
+	UpdateField(*Field) error
 	Delete(*Event) error
+	// ForProject returns the store of the events of the project sharing the storage with this one
+	ForProject(projectId uint64) Store
 }
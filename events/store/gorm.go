@@ -3,31 +3,50 @@ package store
 import (
 	"fmt"
 	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/db"
 	"github.com/nskondratev/api-page-go-back/events"
 	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
 	"strings"
 )
 
 type Gorm struct {
-	db     *gorm.DB
-	logger logger.Logger
+	db        *gorm.DB
+	logger    logger.Logger
+	projectId uint64
 }
 
 type GormConfig struct {
 	DB     *gorm.DB
 	Logger logger.Logger
+	// ProjectId is the project of the events, the default project when zero
+	ProjectId uint64
 }
 
 func NewGorm(c *GormConfig) events.Store {
-	return &Gorm{
-		db:     c.DB,
-		logger: c.Logger,
+	s := &Gorm{
+		db:        c.DB,
+		logger:    c.Logger,
+		projectId: c.ProjectId,
 	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Gorm) ForProject(projectId uint64) events.Store {
+	return NewGorm(&GormConfig{DB: s.db, Logger: s.logger, ProjectId: projectId})
+}
+
+// scoped limits the query to the events of the project.
+func (s *Gorm) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("`projectId` = ?", s.projectId)
 }
 
 func (s *Gorm) GetById(id uint64) (*events.Event, error) {
 	var event events.Event
-	if err := s.db.Preload("Fields").First(&event, id).Error; err != nil {
+	if err := s.preloadFields(s.scoped(s.db)).First(&event, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			return nil, nil
 		}
@@ -36,6 +55,31 @@ func (s *Gorm) GetById(id uint64) (*events.Event, error) {
 	return &event, nil
 }
 
+func (s *Gorm) GetAll() ([]*events.Event, error) {
+	eventsList := make([]*events.Event, 0)
+	err := s.preloadFields(s.scoped(s.db)).Order("id asc").Find(&eventsList).Error
+	return eventsList, err
+}
+
+func (s *Gorm) GetByTypeId(typeId uint64) ([]*events.Event, error) {
+	eventsList := make([]*events.Event, 0)
+	var ids []uint64
+	if err := s.db.Model(&events.Field{}).Where("typeId = ?", typeId).Pluck("DISTINCT eventId", &ids).Error; err != nil {
+		return eventsList, err
+	}
+	if len(ids) < 1 {
+		return eventsList, nil
+	}
+	err := s.preloadFields(s.scoped(s.db)).Where("id IN (?)", ids).Order("id asc").Find(&eventsList).Error
+	return eventsList, err
+}
+
+func (s *Gorm) GetByResponseEventId(id uint64) ([]*events.Event, error) {
+	eventsList := make([]*events.Event, 0)
+	err := s.preloadFields(s.scoped(s.db)).Where("responseEventId = ?", id).Order("id asc").Find(&eventsList).Error
+	return eventsList, err
+}
+
 func (s *Gorm) List(offset, limit int, sort string, descending bool, eType string, query string) ([]*events.EventList, int, error) {
 	eventsList, total := []*events.EventList{nil}, 0
 	bSort := strings.Builder{}
@@ -49,7 +93,7 @@ func (s *Gorm) List(offset, limit int, sort string, descending bool, eType strin
 		orderDirection = " desc"
 	}
 	bSort.WriteString(orderDirection)
-	qb := s.db.Model(&eventsList)
+	qb := s.scoped(s.db.Model(&eventsList))
 	if len(query) > 0 {
 		qArg := "%" + query + "%"
 		qb = qb.Where("`constant` LIKE ? OR `label` LIKE ? OR `value` LIKE ?", qArg, qArg, qArg)
@@ -65,38 +109,116 @@ func (s *Gorm) List(offset, limit int, sort string, descending bool, eType strin
 }
 
 func (s *Gorm) Create(e *events.Event) error {
+	e.ProjectId = s.projectId
+	e.MarkAckFields()
 	return s.db.Create(e).Error
 }
 
 func (s *Gorm) Update(e *events.Event) error {
-	res := s.db.First(&events.Event{}, e.ID)
+	existing := &events.Event{}
+	res := s.scoped(s.db).First(existing, e.ID)
 
 	if res.Error != nil {
 		if gorm.IsRecordNotFoundError(res.Error) {
-			return fmt.Errorf("[events.store.gorm] page with id = %d does not exist", e.ID)
+			return fmt.Errorf("[events.store.gorm] event with id = %d does not exist", e.ID)
 		}
 		return res.Error
 	}
 
-	if err := s.db.Delete(&events.Field{}, "eventId = ?", e.ID).Error; err != nil {
+	e.ProjectId = existing.ProjectId
+	e.CreatedBy = existing.CreatedBy
+	e.CreatedAt = existing.CreatedAt
+	e.MarkAckFields()
+
+	return db.Transaction(s.db, func(tx *gorm.DB) error {
+		if err := s.syncFields(tx, e); err != nil {
+			return err
+		}
+
+		// Fields are already synchronized, save only the event row
+		res := tx.Set("gorm:save_associations", false).Save(e)
+
+		if res.Error != nil {
+			return res.Error
+		}
+
+		if res.RowsAffected < 1 {
+			return fmt.Errorf("[events.store.gorm] event with id = %d was not updated", e.ID)
+		}
+
+		return nil
+	})
+}
+
+func (s *Gorm) UpdateField(f *events.Field) error {
+	if err := s.scoped(s.db).First(&events.Event{}, f.EventId).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("[events.store.gorm] event with id = %d does not exist", f.EventId)
+		}
 		return err
 	}
-
-	res = s.db.Save(&e)
+	existing := &events.Field{}
+	res := s.db.Where("eventId = ?", f.EventId).First(existing, f.ID)
 
 	if res.Error != nil {
+		if gorm.IsRecordNotFoundError(res.Error) {
+			return fmt.Errorf("[events.store.gorm] field with id = %d does not exist in event with id = %d", f.ID, f.EventId)
+		}
 		return res.Error
 	}
 
-	if res.RowsAffected < 1 {
-		return fmt.Errorf("[events.store.gorm] page with id = %d was not updated", e.ID)
-	}
+	f.Ack = existing.Ack
+	f.CreatedAt = existing.CreatedAt
+
+	return s.db.Save(f).Error
+}
 
+// syncFields diffs the fields of e against the stored ones by ID: known fields are updated,
+// fields without ID are inserted and stored fields absent from e are deleted.
+func (s *Gorm) syncFields(tx *gorm.DB, e *events.Event) error {
+	var stored []events.Field
+	if err := tx.Where("eventId = ?", e.ID).Find(&stored).Error; err != nil {
+		return err
+	}
+	storedById := make(map[uint64]*events.Field, len(stored))
+	for i := range stored {
+		storedById[stored[i].ID] = &stored[i]
+	}
+	kept := make(map[uint64]bool, len(stored))
+	for _, list := range [][]events.Field{e.Fields, e.AckFields} {
+		for i := range list {
+			f := &list[i]
+			f.EventId = e.ID
+			if f.ID == 0 {
+				if err := tx.Create(f).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			old, ok := storedById[f.ID]
+			if !ok {
+				return fmt.Errorf("[events.store.gorm] field with id = %d does not belong to event with id = %d", f.ID, e.ID)
+			}
+			f.CreatedAt = old.CreatedAt
+			if err := tx.Save(f).Error; err != nil {
+				return err
+			}
+			kept[f.ID] = true
+		}
+	}
+	for id := range storedById {
+		if kept[id] {
+			continue
+		}
+		if err := tx.Delete(&events.Field{}, "id = ?", id).Error; err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (s *Gorm) Delete(e *events.Event) error {
-	res := s.db.Delete(e)
+	res := s.scoped(s.db).Delete(e)
 	if res.Error != nil {
 		return res.Error
 	}
@@ -105,3 +227,7 @@ func (s *Gorm) Delete(e *events.Event) error {
 	}
 	return nil
 }
+
+func (s *Gorm) preloadFields(db *gorm.DB) *gorm.DB {
+	return db.Preload("Fields", "ack = ?", false).Preload("AckFields", "ack = ?", true)
+}
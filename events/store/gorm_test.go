@@ -207,6 +207,152 @@ func TestGorm_Update(t *testing.T) {
 	}
 }
 
+func TestGorm_UpdateSyncsFields(t *testing.T) {
+	d, es := setup(t)
+	testutils.CreateEventsTable(d)
+	defer testutils.DropEventsTable(d)
+
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"id", "name", "ok", "avatar"})
+	if err != nil {
+		t.Fatalf("Can not create keys: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "Constant 1", Value: "Value 1", Type: "client", Fields: []events.Field{
+		{Key: keys[0], Type: "number"},
+		{Key: keys[1], Type: "string"},
+	}, AckFields: []events.Field{
+		{Key: keys[2], Type: "boolean"},
+	}}); err != nil {
+		t.Fatalf("Can not create event for testing: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "Constant 2", Value: "Value 2", Type: "client", Fields: []events.Field{
+		{Key: keys[0], Type: "number"},
+	}}); err != nil {
+		t.Fatalf("Can not create event for testing: %s", err.Error())
+	}
+
+	// Field 4 belongs to the second event, the whole update must be rolled back
+	err = es.Update(&events.Event{ID: 1, Constant: "Constant 1 updated", Value: "Value 1", Type: "client", Fields: []events.Field{
+		{ID: 1, Key: keys[0], Type: "string"},
+		{ID: 4, Key: keys[3], Type: "string"},
+	}})
+
+	if err == nil {
+		t.Errorf("should return error for the field of another event")
+	}
+
+	e, err := es.GetById(1)
+	if err != nil || e == nil {
+		t.Fatalf("Can not fetch event: %v", err)
+	}
+
+	if e.Constant != "Constant 1" || len(e.Fields) != 2 || e.Fields[0].Type != "number" || len(e.AckFields) != 1 {
+		t.Errorf("Failed update was not rolled back: %+v", e)
+	}
+
+	// Field 1 is updated, field 2 is deleted, avatar is inserted and the ack field is kept
+	if err := es.Update(&events.Event{ID: 1, Constant: "Constant 1 updated", Value: "Value 1", Type: "client", Fields: []events.Field{
+		{ID: 1, Key: keys[0], Type: "string"},
+		{Key: keys[3], Type: "string"},
+	}, AckFields: []events.Field{
+		{ID: 3, Key: keys[2], Type: "boolean"},
+	}}); err != nil {
+		t.Fatalf("should update without error, but failed: %s", err.Error())
+	}
+
+	var stored []events.Field
+	if err := d.Where("eventId = ?", 1).Order("id asc").Find(&stored).Error; err != nil {
+		t.Fatalf("Can not fetch fields: %s", err.Error())
+	}
+
+	expected := []events.Field{
+		{ID: 1, Key: keys[0], Type: "string"},
+		{ID: 3, Key: keys[2], Type: "boolean", Ack: true},
+		{ID: 5, Key: keys[3], Type: "string"},
+	}
+
+	if len(stored) != len(expected) {
+		t.Fatalf("Fields count mismatch. Wanted: %d, received: %+v", len(expected), stored)
+	}
+
+	for i, f := range stored {
+		if f.ID != expected[i].ID || f.Key.String != expected[i].Key.String || f.Type != expected[i].Type || f.Ack != expected[i].Ack {
+			t.Errorf("[%d] Field mismatch. Wanted: %+v, received: %+v", i, expected[i], f)
+		}
+	}
+}
+
+type gormUpdateFieldTestCase struct {
+	fieldToUpdate *events.Field
+	ok            bool
+}
+
+func TestGorm_UpdateField(t *testing.T) {
+	d, es := setup(t)
+	testutils.CreateEventsTable(d)
+	defer testutils.DropEventsTable(d)
+
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"id", "ok"})
+	if err != nil {
+		t.Fatalf("Can not create keys: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "Constant 1", Value: "Value 1", Type: "client", Fields: []events.Field{
+		{Key: keys[0], Type: "number"},
+	}, AckFields: []events.Field{
+		{Key: keys[1], Type: "boolean"},
+	}}); err != nil {
+		t.Fatalf("Can not create event for testing: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "Constant 2", Value: "Value 2", Type: "client", Fields: []events.Field{
+		{Key: keys[0], Type: "number"},
+	}}); err != nil {
+		t.Fatalf("Can not create event for testing: %s", err.Error())
+	}
+
+	cases := []gormUpdateFieldTestCase{
+		{&events.Field{ID: 1, EventId: 1, Key: keys[0], Type: "string", Required: true}, true},
+		{&events.Field{ID: 2, EventId: 1, Key: keys[1], Type: "string"}, true},
+		{&events.Field{ID: 3, EventId: 1, Key: keys[0], Type: "string"}, false},
+		{&events.Field{ID: 1, EventId: 3, Key: keys[0], Type: "string"}, false},
+	}
+
+	for caseNum, item := range cases {
+		err := es.UpdateField(item.fieldToUpdate)
+
+		if item.ok && err != nil {
+			t.Errorf("[%d] should update without error, but failed: %s", caseNum, err.Error())
+		} else if !item.ok && err == nil {
+			t.Errorf("[%d] should return error", caseNum)
+		}
+	}
+
+	e, err := es.GetById(1)
+	if err != nil || e == nil {
+		t.Fatalf("Can not fetch event: %v", err)
+	}
+
+	if len(e.Fields) != 1 || e.Fields[0].Type != "string" || !e.Fields[0].Required {
+		t.Errorf("Field was not updated: %+v", e.Fields)
+	}
+
+	// The ack flag is kept from the stored field
+	if len(e.AckFields) != 1 || e.AckFields[0].Type != "string" {
+		t.Errorf("Ack field was not updated: %+v", e.AckFields)
+	}
+
+	other, err := es.GetById(2)
+	if err != nil || other == nil {
+		t.Fatalf("Can not fetch event: %v", err)
+	}
+
+	if other.Fields[0].Type != "number" {
+		t.Errorf("Field of another event was updated: %+v", other.Fields[0])
+	}
+}
+
 type gormListTestCase struct {
 	offset         int
 	limit          int
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/nskondratev/api-page-go-back/events"
 	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
 	"sort"
 	"strings"
 	"sync"
@@ -11,36 +12,99 @@ import (
 )
 
 type Memory struct {
-	logger  logger.Logger
-	records []*events.Event
-	mu      *sync.Mutex
+	logger    logger.Logger
+	projectId uint64
+	// Records of all projects are shared by the stores returned by ForProject
+	*memoryRecords
+}
+
+type memoryRecords struct {
+	records     []*events.Event
+	lastFieldId uint64
+	mu          *sync.Mutex
 }
 
 type MemoryConfig struct {
 	Logger logger.Logger
+	// ProjectId is the project of the events, the default project when zero
+	ProjectId uint64
 }
 
 func NewMemory(c *MemoryConfig) *Memory {
+	s := &Memory{
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+		memoryRecords: &memoryRecords{
+			records: make([]*events.Event, 0),
+			mu:      &sync.Mutex{},
+		},
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Memory) ForProject(projectId uint64) events.Store {
 	return &Memory{
-		logger:  c.Logger,
-		records: make([]*events.Event, 0),
-		mu:      &sync.Mutex{},
+		logger:        s.logger,
+		projectId:     projectId,
+		memoryRecords: s.memoryRecords,
 	}
 }
 
 func (s *Memory) GetById(id uint64) (*events.Event, error) {
 	for _, p := range s.records {
-		if p.ID == id {
+		if p.ID == id && p.ProjectId == s.projectId {
 			return p, nil
 		}
 	}
 	return nil, nil
 }
 
+func (s *Memory) GetAll() ([]*events.Event, error) {
+	s.mu.Lock()
+	eventsList := make([]*events.Event, 0, len(s.records))
+	for _, el := range s.records {
+		if el.ProjectId == s.projectId {
+			eventsList = append(eventsList, el)
+		}
+	}
+	s.mu.Unlock()
+	return eventsList, nil
+}
+
+func (s *Memory) GetByTypeId(typeId uint64) ([]*events.Event, error) {
+	eventsList := make([]*events.Event, 0)
+	s.mu.Lock()
+	for _, el := range s.records {
+		if el.ProjectId == s.projectId && el.UsesType(typeId) {
+			eventsList = append(eventsList, el)
+		}
+	}
+	s.mu.Unlock()
+	return eventsList, nil
+}
+
+func (s *Memory) GetByResponseEventId(id uint64) ([]*events.Event, error) {
+	eventsList := make([]*events.Event, 0)
+	s.mu.Lock()
+	for _, el := range s.records {
+		if el.ProjectId == s.projectId && el.HasResponse() && uint64(el.ResponseEventId.Int64) == id {
+			eventsList = append(eventsList, el)
+		}
+	}
+	s.mu.Unlock()
+	return eventsList, nil
+}
+
 func (s *Memory) List(offset, limit int, sort string, descending bool, eType, query string) ([]*events.EventList, int, error) {
 	eventsList, total := make([]*events.EventList, 0), 0
 	q := strings.ToLower(query)
 	for _, el := range s.records {
+		if el.ProjectId != s.projectId {
+			continue
+		}
 		if (len(q) < 1 || (strings.Contains(strings.ToLower(el.Constant), q) || strings.Contains(strings.ToLower(el.Label.String), q) || strings.Contains(strings.ToLower(el.Value), q))) && (len(eType) < 1 || eType == el.Type) {
 			eventsList = append(eventsList, EventToEventList(el))
 		}
@@ -67,20 +131,84 @@ func (s *Memory) List(offset, limit int, sort string, descending bool, eType, qu
 func (s *Memory) Update(e *events.Event) error {
 	e.UpdatedAt = time.Now()
 	s.mu.Lock()
+	defer s.mu.Unlock()
 	for i, el := range s.records {
-		if el.ID == e.ID {
+		if el.ID == e.ID && el.ProjectId == s.projectId {
+			if err := s.syncFields(el, e); err != nil {
+				return err
+			}
+			e.ProjectId = el.ProjectId
+			e.CreatedBy = el.CreatedBy
+			e.CreatedAt = el.CreatedAt
 			s.records[i] = e
 			break
 		}
 	}
-	s.mu.Unlock()
 	return nil
 }
 
+func (s *Memory) UpdateField(f *events.Field) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID != f.EventId || el.ProjectId != s.projectId {
+			continue
+		}
+		for _, list := range [][]events.Field{el.Fields, el.AckFields} {
+			for i := range list {
+				if list[i].ID == f.ID {
+					f.Ack = list[i].Ack
+					f.CreatedAt = list[i].CreatedAt
+					f.UpdatedAt = time.Now()
+					list[i] = *f
+					return nil
+				}
+			}
+		}
+	}
+	return fmt.Errorf("[events.store.memory] field with id = %d does not exist in event with id = %d", f.ID, f.EventId)
+}
+
+// syncFields keeps the IDs of the fields known to the stored event and assigns IDs to the new ones.
+func (s *Memory) syncFields(stored, e *events.Event) error {
+	storedById := make(map[uint64]events.Field)
+	for _, list := range [][]events.Field{stored.Fields, stored.AckFields} {
+		for _, f := range list {
+			storedById[f.ID] = f
+		}
+	}
+	e.MarkAckFields()
+	for _, list := range [][]events.Field{e.Fields, e.AckFields} {
+		for i := range list {
+			f := &list[i]
+			if f.ID == 0 {
+				s.initField(e, f)
+				continue
+			}
+			old, ok := storedById[f.ID]
+			if !ok {
+				return fmt.Errorf("[events.store.memory] field with id = %d does not belong to event with id = %d", f.ID, e.ID)
+			}
+			f.EventId = e.ID
+			f.CreatedAt = old.CreatedAt
+			f.UpdatedAt = time.Now()
+		}
+	}
+	return nil
+}
+
+func (s *Memory) initField(e *events.Event, f *events.Field) {
+	s.lastFieldId++
+	f.ID = s.lastFieldId
+	f.EventId = e.ID
+	f.CreatedAt = time.Now()
+	f.UpdatedAt = time.Now()
+}
+
 func (s *Memory) Delete(e *events.Event) error {
 	s.mu.Lock()
 	for i, el := range s.records {
-		if el.ID == e.ID {
+		if el.ID == e.ID && el.ProjectId == s.projectId {
 			copy(s.records[i:], s.records[i+1:])
 			s.records[len(s.records)-1] = nil
 			s.records = s.records[:len(s.records)-1]
@@ -94,8 +222,15 @@ func (s *Memory) Delete(e *events.Event) error {
 func (s *Memory) Create(e *events.Event) error {
 	s.mu.Lock()
 	e.ID = uint64(len(s.records) + 1)
+	e.ProjectId = s.projectId
 	e.CreatedAt = time.Now()
 	e.UpdatedAt = time.Now()
+	e.MarkAckFields()
+	for _, list := range [][]events.Field{e.Fields, e.AckFields} {
+		for i := range list {
+			s.initField(e, &list[i])
+		}
+	}
 	s.records = append(s.records, e)
 	s.mu.Unlock()
 	return nil
@@ -178,12 +313,14 @@ func getSorterByKey(key string) (by, error) {
 
 func EventToEventList(e *events.Event) *events.EventList {
 	return &events.EventList{
-		ID:        e.ID,
-		Constant:  e.Constant,
-		Label:     e.Label,
-		Value:     e.Value,
-		Type:      e.Type,
-		CreatedAt: e.CreatedAt,
-		UpdatedAt: e.UpdatedAt,
+		ID:              e.ID,
+		ProjectId:       e.ProjectId,
+		Constant:        e.Constant,
+		Label:           e.Label,
+		Value:           e.Value,
+		Type:            e.Type,
+		ResponseEventId: e.ResponseEventId,
+		CreatedAt:       e.CreatedAt,
+		UpdatedAt:       e.UpdatedAt,
 	}
 }
@@ -3,6 +3,7 @@ package store
 import (
 	"github.com/nskondratev/api-page-go-back/events"
 	"github.com/nskondratev/api-page-go-back/testutils"
+	"github.com/nskondratev/api-page-go-back/util"
 	"reflect"
 	"testing"
 	"time"
@@ -230,6 +231,7 @@ func TestEventToEventList(t *testing.T) {
 	for i, e := range eventsToCreate {
 		eventLists[i] = &events.EventList{
 			ID:        e.ID,
+			ProjectId: e.ProjectId,
 			Constant:  e.Constant,
 			Label:     e.Label,
 			Value:     e.Value,
@@ -402,3 +404,167 @@ func TestMemory_List(t *testing.T) {
 		}
 	}
 }
+
+func TestMemory_UpdateKeepsFieldIds(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"id", "name", "email", "ok"})
+
+	if err != nil {
+		t.Fatalf("Can not create NullString keys from strings: %s", err.Error())
+	}
+
+	if err := s.Create(&events.Event{Constant: "USER", Value: "user", Fields: []events.Field{
+		{Key: keys[0], Type: "number"},
+		{Key: keys[1], Type: "string"},
+	}, AckFields: []events.Field{
+		{Key: keys[3], Type: "boolean"},
+	}}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+
+	created, _ := s.GetById(1)
+
+	if created.Fields[0].ID != 1 || created.Fields[1].ID != 2 || created.AckFields[0].ID != 3 {
+		t.Fatalf("Field ids were not assigned: %+v", created)
+	}
+
+	// Keep id, drop name, add email
+	if err := s.Update(&events.Event{ID: 1, Constant: "USER", Value: "user", Fields: []events.Field{
+		{ID: 1, Key: keys[0], Type: "string"},
+		{Key: keys[2], Type: "string"},
+	}, AckFields: []events.Field{
+		{ID: 3, Key: keys[3], Type: "boolean", Required: true},
+	}}); err != nil {
+		t.Fatalf("Can not update test event: %s", err.Error())
+	}
+
+	updated, _ := s.GetById(1)
+
+	expectedIds := []uint64{1, 4}
+
+	if len(updated.Fields) != len(expectedIds) {
+		t.Fatalf("Fields count mismatch. Want %d, received %d", len(expectedIds), len(updated.Fields))
+	}
+
+	for i, id := range expectedIds {
+		if updated.Fields[i].ID != id || updated.Fields[i].EventId != 1 {
+			t.Errorf("[%d] field id mismatch. Want %d, received %+v", i, id, updated.Fields[i])
+		}
+	}
+
+	if updated.Fields[0].Type != "string" || !updated.AckFields[0].Required || updated.AckFields[0].ID != 3 {
+		t.Errorf("Fields were not updated: %+v", updated)
+	}
+
+	if err := s.Update(&events.Event{ID: 1, Fields: []events.Field{{ID: 2, Key: keys[1]}}}); err == nil {
+		t.Errorf("Update with a field id which does not belong to the event should fail")
+	}
+}
+
+func TestMemory_UpdateField(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"id", "ok"})
+
+	if err != nil {
+		t.Fatalf("Can not create NullString keys from strings: %s", err.Error())
+	}
+
+	if err := s.Create(&events.Event{Constant: "USER", Value: "user", Fields: []events.Field{
+		{Key: keys[0], Type: "number"},
+	}, AckFields: []events.Field{
+		{Key: keys[1], Type: "boolean"},
+	}}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+
+	cases := []struct {
+		field      *events.Field
+		isErrorNil bool
+	}{
+		{&events.Field{ID: 1, EventId: 1, Key: keys[0], Type: "string"}, true},
+		{&events.Field{ID: 2, EventId: 1, Key: keys[1], Type: "string"}, true},
+		{&events.Field{ID: 2, EventId: 5, Key: keys[1], Type: "string"}, false},
+		{&events.Field{ID: 7, EventId: 1, Key: keys[1], Type: "string"}, false},
+	}
+
+	for caseNum, item := range cases {
+		err := s.UpdateField(item.field)
+
+		if item.isErrorNil != (err == nil) {
+			t.Errorf("[%d] unexpected error: %v", caseNum, err)
+		}
+	}
+
+	event, _ := s.GetById(1)
+
+	if event.Fields[0].Type != "string" || event.AckFields[0].Type != "string" || !event.AckFields[0].Ack {
+		t.Errorf("Fields were not updated in place: %+v", event)
+	}
+}
+
+func TestMemory_ForProject(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+	other := s.ForProject(2)
+
+	for _, item := range []struct {
+		store events.Store
+		event *events.Event
+	}{
+		{s, &events.Event{Constant: "LOGIN", Value: "login", Type: events.TypeClient}},
+		{other, &events.Event{Constant: "LOGIN", Value: "login", Type: events.TypeClient}},
+		{other, &events.Event{Constant: "USER", Value: "user", Type: events.TypeFrontend}},
+	} {
+		if err := item.store.Create(item.event); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	if list, _ := s.GetAll(); len(list) != 1 || list[0].ProjectId != 1 {
+		t.Errorf("Unexpected events of the default project: %+v", list)
+	}
+	if _, total, _ := other.List(0, 10, "", false, "", "login"); total != 1 {
+		t.Errorf("Unexpected number of found events of the other project: %d", total)
+	}
+	if e, _ := s.GetById(3); e != nil {
+		t.Errorf("Event of the other project is visible: %+v", e)
+	}
+	if err := s.Delete(&events.Event{ID: 2}); err != nil {
+		t.Fatalf("Can not delete event: %s", err.Error())
+	}
+	if e, _ := other.GetById(2); e == nil {
+		t.Error("Event of the other project was deleted")
+	}
+}
+
+func TestMemory_GetByResponseEventId(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+	for _, e := range []*events.Event{
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient},
+		{Constant: "LOGIN_OK", Value: "login_ok", Type: events.TypeFrontend},
+		{Constant: "RELOGIN", Value: "relogin", Type: events.TypeClient},
+	} {
+		if err := s.Create(e); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+	for _, id := range []uint64{1, 3} {
+		e, _ := s.GetById(id)
+		e.ResponseEventId = util.NewNullInt64FromInt64(2)
+	}
+	if err := s.ForProject(2).Create(&events.Event{Value: "other", ResponseEventId: util.NewNullInt64FromInt64(2)}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+
+	linkedBy, err := s.GetByResponseEventId(2)
+	if err != nil {
+		t.Fatalf("Can not get events by response event: %s", err.Error())
+	}
+	if len(linkedBy) != 2 || linkedBy[0].ID != 1 || linkedBy[1].ID != 3 {
+		t.Errorf("Unexpected events linked to the response event: %+v", linkedBy)
+	}
+	if linkedBy, _ := s.GetByResponseEventId(1); len(linkedBy) != 0 {
+		t.Errorf("Unexpected events linked to the event without links: %+v", linkedBy)
+	}
+}
@@ -0,0 +1,172 @@
+package events
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"strings"
+)
+
+// Types are the shared types of the registry referenced by fields, by name. Fields of unknown types
+// are not checked, so nil Types validate only the built-in types.
+type Types map[string]*registry.Type
+
+// IsBuiltinType reports whether the type is checked without the registry.
+func IsBuiltinType(t string) bool {
+	switch strings.ToLower(t) {
+	case "string", "number", "float", "double", "integer", "int", "boolean", "bool", "object", "array":
+		return true
+	}
+	return isNullType(t)
+}
+
+// ResolveTypes loads the shared types referenced by the fields, along with the types referenced
+// by the fields of those types.
+func ResolveTypes(s registry.Store, fieldLists ...[]Field) (Types, error) {
+	types := make(Types)
+	if s == nil {
+		return types, nil
+	}
+	pending := make([]string, 0)
+	for _, fields := range fieldLists {
+		for i := range fields {
+			f := &fields[i]
+			if f.TypeId.Valid {
+				t, err := s.GetById(uint64(f.TypeId.Int64))
+				if err != nil {
+					return nil, err
+				}
+				if t != nil && types[t.Name] == nil {
+					types[t.Name] = t
+					pending = append(pending, typeNamesOf(t)...)
+				}
+				continue
+			}
+			if !IsBuiltinType(f.Type) {
+				pending = append(pending, f.Type)
+			}
+		}
+	}
+	// Types already loaded are skipped, which stops the recursion of self-referencing types
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if _, ok := types[name]; ok {
+			continue
+		}
+		t, err := s.GetByName(name)
+		if err != nil {
+			return nil, err
+		}
+		types[name] = t
+		if t != nil {
+			pending = append(pending, typeNamesOf(t)...)
+		}
+	}
+	for name, t := range types {
+		if t == nil {
+			delete(types, name)
+		}
+	}
+	return types, nil
+}
+
+// ResolveEventTypes loads the shared types referenced by the fields and the ack fields of the events.
+func ResolveEventTypes(s registry.Store, list ...*Event) (Types, error) {
+	fieldLists := make([][]Field, 0, 2*len(list))
+	for _, e := range list {
+		fieldLists = append(fieldLists, e.Fields, e.AckFields)
+	}
+	return ResolveTypes(s, fieldLists...)
+}
+
+func typeNamesOf(t *registry.Type) []string {
+	names := make([]string, 0)
+	for _, tf := range t.Fields {
+		if !IsBuiltinType(tf.Type) {
+			names = append(names, tf.Type)
+		}
+	}
+	return names
+}
+
+// validateTyped checks the value against the built-in or the shared type.
+func (types Types) validateTyped(path, typeName string, required bool, v interface{}) []*ValidationError {
+	if v == nil {
+		if required && !isNullType(typeName) {
+			return []*ValidationError{{Path: path, Message: "value must not be null"}}
+		}
+		return nil
+	}
+	if t := types[typeName]; t != nil {
+		return types.validateShared(path, t, v)
+	}
+	if !matchesType(typeName, v) {
+		return []*ValidationError{{Path: path, Message: fmt.Sprintf("expected %s, got %s", typeName, jsonTypeOf(v))}}
+	}
+	return nil
+}
+
+// validateShared checks the enum membership or the fields of the object type. Nested values are
+// checked as deep as the value goes, so self-referencing types stop with the value.
+func (types Types) validateShared(path string, t *registry.Type, v interface{}) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+	if t.Kind == registry.KindEnum {
+		s := scalarString(v)
+		values := make([]string, len(t.Values))
+		for i, ev := range t.Values {
+			if ev.Value == s {
+				return errs
+			}
+			values[i] = ev.Value
+		}
+		return append(errs, &ValidationError{Path: path, Message: fmt.Sprintf("value %s is not one of %s [%s]", s, t.Name, strings.Join(values, ", "))})
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return append(errs, &ValidationError{Path: path, Message: fmt.Sprintf("expected %s, got %s", t.Name, jsonTypeOf(v))})
+	}
+	for _, tf := range t.Fields {
+		childPath := tf.Key.String
+		if len(path) > 0 {
+			childPath = path + "." + tf.Key.String
+		}
+		child, ok := obj[tf.Key.String]
+		if !ok {
+			if tf.Required {
+				errs = append(errs, &ValidationError{Path: childPath, Message: "required key is missing"})
+			}
+			continue
+		}
+		errs = append(errs, types.validateTyped(childPath, tf.Type, tf.Required, child)...)
+	}
+	return errs
+}
+
+// exampleShared builds the value of the shared type. Types being built are on the stack,
+// their optional fields are skipped and required ones are null.
+func (types Types) exampleShared(t *registry.Type, stack map[string]bool) interface{} {
+	if t.Kind == registry.KindEnum {
+		if len(t.Values) < 1 {
+			return nil
+		}
+		return t.Values[0].Value
+	}
+	stack[t.Name] = true
+	defer delete(stack, t.Name)
+	obj := make(map[string]interface{}, len(t.Fields))
+	for _, tf := range t.Fields {
+		nested := types[tf.Type]
+		if nested == nil {
+			obj[tf.Key.String] = ExampleValue(&Field{Key: tf.Key, Type: tf.Type}, types)
+			continue
+		}
+		if stack[nested.Name] {
+			if tf.Required {
+				obj[tf.Key.String] = nil
+			}
+			continue
+		}
+		obj[tf.Key.String] = types.exampleShared(nested, stack)
+	}
+	return obj
+}
@@ -0,0 +1,338 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Supported string formats
+const (
+	FormatUUID     = "uuid"
+	FormatEmail    = "email"
+	FormatDateTime = "date-time"
+)
+
+// MaxLengthLimit caps minLength and maxLength of the fields, example values are built up to minLength
+const MaxLengthLimit = 1 << 16
+
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidationError describes a payload value which does not match its field definition.
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Path) < 1 {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// CheckConstraints reports an error when the constraints of the field contradict each other
+// or can not be applied.
+func (f *Field) CheckConstraints() error {
+	if len(f.Format) > 0 && f.Format != FormatUUID && f.Format != FormatEmail && f.Format != FormatDateTime {
+		return fmt.Errorf("field %s: unknown format %q", f.Key.String, f.Format)
+	}
+	if len(f.Pattern) > 0 {
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return fmt.Errorf("field %s: invalid pattern: %s", f.Key.String, err.Error())
+		}
+	}
+	if f.Minimum.Valid && f.Maximum.Valid && f.Minimum.Float64 > f.Maximum.Float64 {
+		return fmt.Errorf("field %s: minimum is greater than maximum", f.Key.String)
+	}
+	if (f.MinLength.Valid && f.MinLength.Int64 < 0) || (f.MaxLength.Valid && f.MaxLength.Int64 < 0) {
+		return fmt.Errorf("field %s: length limits must not be negative", f.Key.String)
+	}
+	if (f.MinLength.Valid && f.MinLength.Int64 > MaxLengthLimit) || (f.MaxLength.Valid && f.MaxLength.Int64 > MaxLengthLimit) {
+		return fmt.Errorf("field %s: length limits must not exceed %d", f.Key.String, MaxLengthLimit)
+	}
+	if f.MinLength.Valid && f.MaxLength.Valid && f.MinLength.Int64 > f.MaxLength.Int64 {
+		return fmt.Errorf("field %s: minLength is greater than maxLength", f.Key.String)
+	}
+	if len(f.DefaultValue) > 0 {
+		var v interface{}
+		if err := json.Unmarshal(f.DefaultValue, &v); err != nil {
+			return fmt.Errorf("field %s: invalid default value: %s", f.Key.String, err.Error())
+		}
+		if errs := f.validateValue(f.Key.String, v, nil); len(errs) > 0 {
+			return fmt.Errorf("field %s: default value does not match the field: %s", f.Key.String, errs[0].Message)
+		}
+	}
+	return nil
+}
+
+// Constraints lists the constraints of the field in a human readable form, e.g. "min 1" or "format uuid".
+func (f *Field) Constraints() []string {
+	parts := make([]string, 0)
+	if f.Minimum.Valid {
+		parts = append(parts, "min "+strconv.FormatFloat(f.Minimum.Float64, 'f', -1, 64))
+	}
+	if f.Maximum.Valid {
+		parts = append(parts, "max "+strconv.FormatFloat(f.Maximum.Float64, 'f', -1, 64))
+	}
+	if f.MinLength.Valid {
+		parts = append(parts, "minLength "+strconv.FormatInt(f.MinLength.Int64, 10))
+	}
+	if f.MaxLength.Valid {
+		parts = append(parts, "maxLength "+strconv.FormatInt(f.MaxLength.Int64, 10))
+	}
+	if len(f.Pattern) > 0 {
+		parts = append(parts, "pattern "+f.Pattern)
+	}
+	if len(f.Format) > 0 {
+		parts = append(parts, "format "+f.Format)
+	}
+	if len(f.Enum) > 0 {
+		parts = append(parts, "one of "+strings.Join(f.Enum, ", "))
+	}
+	if len(f.DefaultValue) > 0 {
+		parts = append(parts, "default "+string(f.DefaultValue))
+	}
+	return parts
+}
+
+// ValidatePayload checks the decoded JSON payload against the fields definition.
+//
+// Field keys are paths: "user.name" addresses a nested key and "items[].id" addresses
+// the key in every element of the items array. An empty key describes the payload itself.
+// A missing key is reported only when its parent object is present. Values of the shared types
+// are checked against their enum values or their fields.
+func ValidatePayload(fields []Field, types Types, payload interface{}) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+	for i := range fields {
+		f := &fields[i]
+		found, missing := lookup(payload, f.Key.String)
+		if f.Required {
+			for _, path := range missing {
+				errs = append(errs, &ValidationError{Path: path, Message: "required key is missing"})
+			}
+		}
+		for _, l := range found {
+			errs = append(errs, f.validateValue(l.path, l.value, types)...)
+		}
+	}
+	return errs
+}
+
+// MissingRequired returns the keys of the required fields which are absent in the decoded payload.
+// Like ValidatePayload, a key is missing only when its parent object is present.
+func MissingRequired(fields []Field, payload interface{}) []string {
+	keys := make([]string, 0)
+	for i := range fields {
+		if !fields[i].Required {
+			continue
+		}
+		if _, missing := lookup(payload, fields[i].Key.String); len(missing) > 0 {
+			keys = append(keys, fields[i].Key.String)
+		}
+	}
+	return keys
+}
+
+// ValidateRawPayload decodes the JSON payload and validates it against the fields definition.
+func ValidateRawPayload(fields []Field, types Types, raw []byte) []*ValidationError {
+	var payload interface{}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return []*ValidationError{{Message: "payload is not a valid JSON: " + err.Error()}}
+		}
+	}
+	return ValidatePayload(fields, types, payload)
+}
+
+func (f *Field) validateValue(path string, v interface{}, types Types) []*ValidationError {
+	errs := make([]*ValidationError, 0)
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	if v == nil {
+		if f.Required && !isNullType(f.Type) {
+			fail("value must not be null")
+		}
+		return errs
+	}
+	if t := types[f.Type]; t != nil {
+		if shared := types.validateShared(path, t, v); len(shared) > 0 {
+			return append(errs, shared...)
+		}
+	} else if !matchesType(f.Type, v) {
+		fail("expected %s, got %s", f.Type, jsonTypeOf(v))
+		return errs
+	}
+	switch value := v.(type) {
+	case float64:
+		if f.Minimum.Valid && value < f.Minimum.Float64 {
+			fail("value %v is less than minimum %v", value, f.Minimum.Float64)
+		}
+		if f.Maximum.Valid && value > f.Maximum.Float64 {
+			fail("value %v is greater than maximum %v", value, f.Maximum.Float64)
+		}
+	case string:
+		length := int64(utf8.RuneCountInString(value))
+		if f.MinLength.Valid && length < f.MinLength.Int64 {
+			fail("length %d is less than minLength %d", length, f.MinLength.Int64)
+		}
+		if f.MaxLength.Valid && length > f.MaxLength.Int64 {
+			fail("length %d is greater than maxLength %d", length, f.MaxLength.Int64)
+		}
+		if len(f.Pattern) > 0 {
+			if re, err := regexp.Compile(f.Pattern); err == nil && !re.MatchString(value) {
+				fail("value does not match pattern %s", f.Pattern)
+			}
+		}
+		if len(f.Format) > 0 && !matchesFormat(f.Format, value) {
+			fail("value is not a valid %s", f.Format)
+		}
+	}
+	if len(f.Enum) > 0 {
+		s := scalarString(v)
+		allowed := false
+		for _, e := range f.Enum {
+			if e == s {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			fail("value %s is not one of [%s]", s, strings.Join(f.Enum, ", "))
+		}
+	}
+	return errs
+}
+
+type located struct {
+	path  string
+	value interface{}
+}
+
+func lookup(root interface{}, key string) ([]located, []string) {
+	if len(key) < 1 {
+		return []located{{value: root}}, nil
+	}
+	found, missing := make([]located, 0), make([]string, 0)
+	walk(root, strings.Split(key, "."), "", &found, &missing)
+	return found, missing
+}
+
+func walk(v interface{}, segments []string, path string, found *[]located, missing *[]string) {
+	if len(segments) < 1 {
+		*found = append(*found, located{path: path, value: v})
+		return
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		// The parent is absent or has another type, which is reported by its own field
+		return
+	}
+	name := strings.TrimSuffix(segments[0], "[]")
+	isArray := name != segments[0]
+	childPath := name
+	if len(path) > 0 {
+		childPath = path + "." + name
+	}
+	child, ok := obj[name]
+	if !ok {
+		*missing = append(*missing, childPath)
+		return
+	}
+	if !isArray {
+		walk(child, segments[1:], childPath, found, missing)
+		return
+	}
+	items, ok := child.([]interface{})
+	if !ok {
+		return
+	}
+	for i, item := range items {
+		walk(item, segments[1:], fmt.Sprintf("%s[%d]", childPath, i), found, missing)
+	}
+}
+
+func isNullType(t string) bool {
+	switch strings.ToLower(t) {
+	case "null", "any", "mixed", "":
+		return true
+	}
+	return false
+}
+
+func matchesType(t string, v interface{}) bool {
+	switch strings.ToLower(t) {
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "number", "float", "double":
+		_, ok := v.(float64)
+		return ok
+	case "integer", "int":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean", "bool":
+		_, ok := v.(bool)
+		return ok
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	}
+	// Unknown types, e.g. shared types missing in the registry, are not checked
+	return true
+}
+
+func matchesFormat(format, s string) bool {
+	switch format {
+	case FormatUUID:
+		return uuidRegexp.MatchString(s)
+	case FormatEmail:
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case FormatDateTime:
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	}
+	return true
+}
+
+func jsonTypeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func scalarString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,115 @@
+package fuzz
+
+import "sort"
+
+// shrink passes smaller variants of the decoded JSON value to try, the largest reductions first.
+// It stops at the first variant try accepts and reports whether there was one.
+func shrink(v interface{}, try func(interface{}) bool) bool {
+	if d := depth(v); d > 2 && try(cut(v, d/2)) {
+		return true
+	}
+	switch x := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if try(without(x, k)) {
+				return true
+			}
+		}
+		for _, k := range keys {
+			if shrink(x[k], func(child interface{}) bool { return try(with(x, k, child)) }) {
+				return true
+			}
+		}
+	case []interface{}:
+		if len(x) > 1 && (try(x[:len(x)/2]) || try(x[len(x)/2:])) {
+			return true
+		}
+		if len(x) == 1 && try(make([]interface{}, 0)) {
+			return true
+		}
+		for i := range x {
+			i := i
+			if shrink(x[i], func(item interface{}) bool {
+				items := make([]interface{}, len(x))
+				copy(items, x)
+				items[i] = item
+				return try(items)
+			}) {
+				return true
+			}
+		}
+	case string:
+		if len(x) > 1 && try(x[:len(x)/2]) {
+			return true
+		}
+		if len(x) == 1 && try("") {
+			return true
+		}
+	}
+	return false
+}
+
+func depth(v interface{}) int {
+	max := 0
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for _, child := range x {
+			if d := depth(child); d > max {
+				max = d
+			}
+		}
+	case []interface{}:
+		for _, item := range x {
+			if d := depth(item); d > max {
+				max = d
+			}
+		}
+	default:
+		return 0
+	}
+	return max + 1
+}
+
+// cut replaces objects and arrays below the depth with empty ones.
+func cut(v interface{}, d int) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(x))
+		if d > 0 {
+			for k, child := range x {
+				res[k] = cut(child, d-1)
+			}
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, 0, len(x))
+		if d > 0 {
+			for _, item := range x {
+				res = append(res, cut(item, d-1))
+			}
+		}
+		return res
+	}
+	return v
+}
+
+func without(obj map[string]interface{}, key string) map[string]interface{} {
+	res := make(map[string]interface{}, len(obj))
+	for k, v := range obj {
+		if k != key {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func with(obj map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	res := without(obj, key)
+	res[key] = value
+	return res
+}
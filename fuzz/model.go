@@ -0,0 +1,134 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/util"
+	"time"
+)
+
+// Statuses of runs
+const (
+	StatusRunning  = "running"
+	StatusFinished = "finished"
+	StatusFailed   = "failed"
+)
+
+// Run is the fuzzing of the event against the target. The report is stored when the run is over.
+type Run struct {
+	ID        uint64          `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId uint64          `json:"projectId" gorm:"index;default:1;column:projectId"`
+	EventId   uint64          `json:"eventId" gorm:"index;column:eventId"`
+	Event     string          `json:"event" gorm:"size:255;column:event"`
+	Target    string          `json:"target" gorm:"size:1024;column:target"`
+	Protocol  string          `json:"protocol" gorm:"size:32;column:protocol"`
+	Kinds     util.StringList `json:"kinds" gorm:"type:text;column:kinds"`
+	Status    string          `json:"status" gorm:"type:ENUM('running','finished','failed');default:'running';column:status"`
+	Error     string          `json:"error" gorm:"type:text;column:error"`
+	// Failures counts results of the report with outcomes other than ok
+	Failures   int        `json:"failures" gorm:"column:failures;default:0"`
+	Report     *Report    `json:"report" gorm:"-"`
+	Data       string     `json:"-" gorm:"type:longtext;column:report"`
+	FinishedAt *time.Time `json:"finishedAt" gorm:"column:finishedAt"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+// RunList is the run without the report.
+type RunList struct {
+	ID         uint64          `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId  uint64          `json:"projectId" gorm:"column:projectId"`
+	EventId    uint64          `json:"eventId" gorm:"column:eventId"`
+	Event      string          `json:"event" gorm:"column:event"`
+	Target     string          `json:"target" gorm:"column:target"`
+	Protocol   string          `json:"protocol" gorm:"column:protocol"`
+	Kinds      util.StringList `json:"kinds" gorm:"column:kinds"`
+	Status     string          `json:"status" gorm:"column:status"`
+	Error      string          `json:"error" gorm:"column:error"`
+	Failures   int             `json:"failures" gorm:"column:failures"`
+	FinishedAt *time.Time      `json:"finishedAt" gorm:"column:finishedAt"`
+	CreatedAt  time.Time       `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt  time.Time       `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+func (Run) TableName() string {
+	return "fuzz_runs"
+}
+
+func (RunList) TableName() string {
+	return "fuzz_runs"
+}
+
+// Summary returns the run without the report.
+func (r *Run) Summary() *RunList {
+	return &RunList{
+		ID:         r.ID,
+		ProjectId:  r.ProjectId,
+		EventId:    r.EventId,
+		Event:      r.Event,
+		Target:     r.Target,
+		Protocol:   r.Protocol,
+		Kinds:      r.Kinds,
+		Status:     r.Status,
+		Error:      r.Error,
+		Failures:   r.Failures,
+		FinishedAt: r.FinishedAt,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}
+}
+
+// BeforeSave serializes the report into the report column.
+func (r *Run) BeforeSave() error {
+	if r.Report == nil {
+		r.Data = ""
+		return nil
+	}
+	data, err := json.Marshal(r.Report)
+	if err != nil {
+		return err
+	}
+	r.Data = string(data)
+	return nil
+}
+
+// AfterFind restores the report from the report column.
+func (r *Run) AfterFind() error {
+	r.Report = nil
+	if len(r.Data) < 1 {
+		return nil
+	}
+	r.Report = &Report{}
+	return json.Unmarshal([]byte(r.Data), r.Report)
+}
+
+// NewRun returns the running fuzzing of the event to be stored before Finish.
+func NewRun(e *events.Event, c *Config) *Run {
+	run := &Run{
+		EventId: e.ID,
+		Event:   e.Value,
+		Target:  c.Target,
+		Kinds:   c.Kinds,
+		Status:  StatusRunning,
+	}
+	if codec, err := socket.NewCodec(c.Protocol); err == nil {
+		run.Protocol = codec.Name()
+	}
+	return run
+}
+
+// Finish executes the created run and stores its report.
+func Finish(s Store, run *Run, e *events.Event, c *Config) error {
+	report := Execute(e, c)
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Report = report
+	run.Failures = report.Failures
+	run.Status = StatusFinished
+	if len(report.Error) > 0 {
+		run.Status = StatusFailed
+		run.Error = report.Error
+	}
+	return s.Update(run)
+}
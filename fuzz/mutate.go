@@ -0,0 +1,283 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/events"
+	"sort"
+	"strings"
+)
+
+// Kinds of mutations
+const (
+	// KindValid is the generated example payload, it checks the event is answered at all
+	KindValid       = "valid"
+	KindNoPayload   = "no-payload"
+	KindMissing     = "missing"
+	KindNull        = "null"
+	KindWrongType   = "wrong-type"
+	KindOversized   = "oversized"
+	KindExtraKey    = "extra-key"
+	KindDeepNesting = "deep-nesting"
+)
+
+// Kinds lists the mutation kinds in the order they are generated.
+var Kinds = []string{KindValid, KindNoPayload, KindMissing, KindNull, KindWrongType, KindOversized, KindExtraKey, KindDeepNesting}
+
+// Defaults of the mutation sizes
+const (
+	DefaultOversizedLength = 64 * 1024
+	DefaultNestingDepth    = 512
+)
+
+// ExtraKey is the name of the unexpected key added to objects of the payload.
+const ExtraKey = "__fuzz"
+
+// Mutation is a payload derived from the event definition. Data is empty for the no-payload mutation.
+type Mutation struct {
+	Name string          `json:"name"`
+	Kind string          `json:"kind"`
+	Key  string          `json:"key"`
+	Data json.RawMessage `json:"data"`
+}
+
+type MutateConfig struct {
+	// Kinds limits the generated mutations, all kinds when empty
+	Kinds []string
+	// OversizedLength of strings, DefaultOversizedLength when zero. It is raised above the max length of the field.
+	OversizedLength int
+	// NestingDepth of the deep nesting mutations, DefaultNestingDepth when zero
+	NestingDepth int
+	// Types are the shared types referenced by the fields
+	Types events.Types
+}
+
+// Mutate derives mutations of the example payload of the event fields: the valid example, no payload,
+// missing and null required fields, values of wrong types, oversized strings, unexpected keys and
+// deeply nested values.
+func Mutate(fields []events.Field, c *MutateConfig) []*Mutation {
+	m := &mutator{fields: sortedFields(fields), kinds: make(map[string]bool), oversized: c.OversizedLength, depth: c.NestingDepth, types: c.Types}
+	for _, kind := range c.Kinds {
+		m.kinds[kind] = true
+	}
+	if m.oversized <= 0 {
+		m.oversized = DefaultOversizedLength
+	}
+	if m.depth <= 0 {
+		m.depth = DefaultNestingDepth
+	}
+
+	m.add(KindValid, "", m.example())
+	if m.enabled(KindNoPayload) {
+		m.list = append(m.list, &Mutation{Name: KindNoPayload, Kind: KindNoPayload})
+	}
+	for _, f := range m.fields {
+		key := f.Key.String
+		if f.Required && len(key) > 0 && !strings.HasSuffix(key, "[]") {
+			m.addChange(KindMissing, key, func(obj map[string]interface{}, name string) {
+				delete(obj, name)
+			})
+		}
+		if f.Required {
+			m.addValue(KindNull, key, nil)
+		}
+		if v, ok := wrongTypeValue(f.Type); ok {
+			m.addValue(KindWrongType, key, v)
+		}
+		if isString(f.Type) {
+			length := m.oversized
+			if f.MaxLength.Valid && f.MaxLength.Int64 >= int64(length) {
+				// maxLength of fields stored before the length limits were capped may exceed MaxLengthLimit
+				maxLength := f.MaxLength.Int64
+				if maxLength > events.MaxLengthLimit {
+					maxLength = events.MaxLengthLimit
+				}
+				length = int(maxLength) + 1
+			}
+			m.addValue(KindOversized, key, strings.Repeat("x", length))
+		}
+	}
+	for _, f := range m.objects() {
+		m.addChange(KindExtraKey, f, func(obj map[string]interface{}, name string) {
+			if child, ok := obj[name].(map[string]interface{}); ok {
+				child[ExtraKey] = "unexpected"
+			}
+		})
+		m.addChange(KindDeepNesting, f, func(obj map[string]interface{}, name string) {
+			if child, ok := obj[name].(map[string]interface{}); ok {
+				child[ExtraKey] = nested(m.depth)
+			}
+		})
+	}
+	return m.list
+}
+
+type mutator struct {
+	fields    []*events.Field
+	kinds     map[string]bool
+	oversized int
+	depth     int
+	types     events.Types
+	list      []*Mutation
+}
+
+func (m *mutator) enabled(kind string) bool {
+	return len(m.kinds) < 1 || m.kinds[kind]
+}
+
+func (m *mutator) example() interface{} {
+	fields := make([]events.Field, len(m.fields))
+	for i, f := range m.fields {
+		fields[i] = *f
+	}
+	return events.GenerateExample(fields, m.types)
+}
+
+func (m *mutator) add(kind, key string, payload interface{}) {
+	if !m.enabled(kind) {
+		return
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	name := kind
+	if len(key) > 0 {
+		name += " " + key
+	}
+	m.list = append(m.list, &Mutation{Name: name, Kind: kind, Key: key, Data: data})
+}
+
+// addValue replaces the value of the key in a fresh example, the root key replaces the whole payload.
+func (m *mutator) addValue(kind, key string, v interface{}) {
+	if len(key) < 1 {
+		m.add(kind, key, v)
+		return
+	}
+	m.addChange(kind, key, func(obj map[string]interface{}, name string) {
+		obj[name] = v
+	})
+}
+
+// addChange applies the change to the object holding the key in a fresh example. The object is the
+// first item for keys of array items.
+func (m *mutator) addChange(kind, key string, change func(obj map[string]interface{}, name string)) {
+	if !m.enabled(kind) {
+		return
+	}
+	payload := m.example()
+	if len(key) < 1 {
+		root, ok := payload.(map[string]interface{})
+		if !ok {
+			return
+		}
+		// The root is wrapped, so the change applies to the payload itself
+		wrapper := map[string]interface{}{"": root}
+		change(wrapper, "")
+		m.add(kind, key, wrapper[""])
+		return
+	}
+	obj, name, ok := locate(payload, key)
+	if !ok {
+		return
+	}
+	if items, isItems := obj[name].([]interface{}); isItems && strings.HasSuffix(key, "[]") {
+		// Scalar items are changed in a single item object and put back
+		if len(items) < 1 {
+			return
+		}
+		item := map[string]interface{}{name: items[0]}
+		change(item, name)
+		if v, ok := item[name]; ok {
+			items[0] = v
+		} else {
+			obj[name] = make([]interface{}, 0)
+		}
+	} else {
+		change(obj, name)
+	}
+	m.add(kind, key, payload)
+}
+
+// objects returns keys of the object values of the example, the root object first.
+func (m *mutator) objects() []string {
+	keys := make([]string, 0)
+	if _, ok := m.example().(map[string]interface{}); ok {
+		keys = append(keys, "")
+	}
+	for _, f := range m.fields {
+		if len(f.Key.String) > 0 && strings.ToLower(f.Type) == "object" && !strings.HasSuffix(f.Key.String, "[]") {
+			keys = append(keys, f.Key.String)
+		}
+	}
+	return keys
+}
+
+// locate returns the object holding the last segment of the key, the key name is stripped of
+// the array suffix. Keys nested in arrays are looked up in the first item.
+func locate(root interface{}, key string) (map[string]interface{}, string, bool) {
+	obj, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, "", false
+	}
+	segments := strings.Split(key, ".")
+	for _, segment := range segments[:len(segments)-1] {
+		name := strings.TrimSuffix(segment, "[]")
+		child := obj[name]
+		if name != segment {
+			items, ok := child.([]interface{})
+			if !ok || len(items) < 1 {
+				return nil, "", false
+			}
+			child = items[0]
+		}
+		if obj, ok = child.(map[string]interface{}); !ok {
+			return nil, "", false
+		}
+	}
+	name := strings.TrimSuffix(segments[len(segments)-1], "[]")
+	if _, ok := obj[name]; !ok {
+		return nil, "", false
+	}
+	return obj, name, true
+}
+
+// wrongTypeValue returns a value of another JSON type than the field type. Shared and unknown types are skipped.
+func wrongTypeValue(t string) (interface{}, bool) {
+	switch strings.ToLower(t) {
+	case "string":
+		return 12345.0, true
+	case "number", "float", "double", "integer", "int":
+		return "12345", true
+	case "boolean", "bool":
+		return "true", true
+	case "object":
+		return []interface{}{}, true
+	case "array":
+		return map[string]interface{}{}, true
+	}
+	return nil, false
+}
+
+func isString(t string) bool {
+	return strings.ToLower(t) == "string"
+}
+
+// nested builds objects nested to the depth.
+func nested(depth int) interface{} {
+	var v interface{} = "deep"
+	for i := 0; i < depth; i++ {
+		v = map[string]interface{}{"a": v}
+	}
+	return v
+}
+
+func sortedFields(fields []events.Field) []*events.Field {
+	sorted := make([]*events.Field, len(fields))
+	for i := range fields {
+		sorted[i] = &fields[i]
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key.String < sorted[j].Key.String
+	})
+	return sorted
+}
@@ -0,0 +1,102 @@
+package fuzz
+
+import (
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/util"
+	"testing"
+)
+
+func TestMutate(t *testing.T) {
+	fields := []events.Field{
+		{Key: nullKey("name"), Type: "string", Required: true, MaxLength: util.NewNullInt64FromInt64(20)},
+		{Key: nullKey("age"), Type: "integer"},
+		{Key: nullKey("profile"), Type: "object", Required: true},
+		{Key: nullKey("profile.tags[]"), Type: "string"},
+		{Key: nullKey("items[].id"), Type: "integer", Required: true},
+		{Key: nullKey("user"), Type: "User"},
+	}
+
+	expected := []struct {
+		name string
+		data string
+	}{
+		{"valid", `{"age":1,"items":[{"id":1}],"name":"name","profile":{"tags":["tags"]},"user":null}`},
+		{"no-payload", ``},
+		{"wrong-type age", `{"age":"12345","items":[{"id":1}],"name":"name","profile":{"tags":["tags"]},"user":null}`},
+		{"missing items[].id", `{"age":1,"items":[{}],"name":"name","profile":{"tags":["tags"]},"user":null}`},
+		{"null items[].id", `{"age":1,"items":[{"id":null}],"name":"name","profile":{"tags":["tags"]},"user":null}`},
+		{"wrong-type items[].id", `{"age":1,"items":[{"id":"12345"}],"name":"name","profile":{"tags":["tags"]},"user":null}`},
+		{"missing name", `{"age":1,"items":[{"id":1}],"profile":{"tags":["tags"]},"user":null}`},
+		{"null name", `{"age":1,"items":[{"id":1}],"name":null,"profile":{"tags":["tags"]},"user":null}`},
+		{"wrong-type name", `{"age":1,"items":[{"id":1}],"name":12345,"profile":{"tags":["tags"]},"user":null}`},
+		{"oversized name", `{"age":1,"items":[{"id":1}],"name":"xxxxxxxxxxxxxxxxxxxxx","profile":{"tags":["tags"]},"user":null}`},
+		{"missing profile", `{"age":1,"items":[{"id":1}],"name":"name","user":null}`},
+		{"null profile", `{"age":1,"items":[{"id":1}],"name":"name","profile":null,"user":null}`},
+		{"wrong-type profile", `{"age":1,"items":[{"id":1}],"name":"name","profile":[],"user":null}`},
+		{"wrong-type profile.tags[]", `{"age":1,"items":[{"id":1}],"name":"name","profile":{"tags":[12345]},"user":null}`},
+		{"oversized profile.tags[]", `{"age":1,"items":[{"id":1}],"name":"name","profile":{"tags":["xxxxxxxx"]},"user":null}`},
+		{"extra-key", `{"__fuzz":"unexpected","age":1,"items":[{"id":1}],"name":"name","profile":{"tags":["tags"]},"user":null}`},
+		{"deep-nesting", `{"__fuzz":{"a":{"a":"deep"}},"age":1,"items":[{"id":1}],"name":"name","profile":{"tags":["tags"]},"user":null}`},
+		{"extra-key profile", `{"age":1,"items":[{"id":1}],"name":"name","profile":{"__fuzz":"unexpected","tags":["tags"]},"user":null}`},
+		{"deep-nesting profile", `{"age":1,"items":[{"id":1}],"name":"name","profile":{"__fuzz":{"a":{"a":"deep"}},"tags":["tags"]},"user":null}`},
+	}
+
+	mutations := Mutate(fields, &MutateConfig{OversizedLength: 8, NestingDepth: 2})
+	if len(mutations) != len(expected) {
+		for _, m := range mutations {
+			t.Logf("%s: %s", m.Name, m.Data)
+		}
+		t.Fatalf("Unexpected number of mutations. Expected: %d, actual: %d", len(expected), len(mutations))
+	}
+	for i, m := range mutations {
+		if m.Name != expected[i].name || string(m.Data) != expected[i].data {
+			t.Errorf("[%d] Unexpected mutation. Expected: %s %s, actual: %s %s", i, expected[i].name, expected[i].data, m.Name, m.Data)
+		}
+	}
+
+	mutations = Mutate(fields, &MutateConfig{Kinds: []string{KindMissing}})
+	if len(mutations) != 3 || mutations[0].Key != "items[].id" || mutations[1].Key != "name" || mutations[2].Key != "profile" {
+		t.Errorf("Unexpected mutations of the missing kind: %+v", mutations)
+	}
+
+	legacy := []events.Field{{Key: nullKey("name"), Type: "string", MaxLength: util.NewNullInt64FromInt64(1 << 40)}}
+	mutations = Mutate(legacy, &MutateConfig{Kinds: []string{KindOversized}})
+	if len(mutations) != 1 || len(mutations[0].Data) != events.MaxLengthLimit+1+len(`{"name":""}`) {
+		t.Errorf("Oversized string of the legacy maxLength is not capped: %d mutations", len(mutations))
+	}
+}
+
+func TestShrink(t *testing.T) {
+	var payload interface{} = map[string]interface{}{
+		"name":    "xxxxxxxxxxxxxxxx",
+		"profile": map[string]interface{}{"tags": []interface{}{"a", "b", "c"}},
+		"nested":  nested(8),
+	}
+	// The failure is reproduced while the name is longer than 3 characters
+	fails := func(v interface{}) bool {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		name, ok := obj["name"].(string)
+		return ok && len(name) > 3
+	}
+	for shrink(payload, func(candidate interface{}) bool {
+		if !fails(candidate) {
+			return false
+		}
+		payload = candidate
+		return true
+	}) {
+	}
+
+	obj := payload.(map[string]interface{})
+	if len(obj) != 1 || obj["name"] != "xxxx" {
+		t.Errorf("Payload was not minimized: %v", payload)
+	}
+}
+
+func nullKey(s string) util.NullString {
+	key, _ := util.NewNullStringFromString(s)
+	return key
+}
@@ -0,0 +1,258 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"net/http"
+	"time"
+)
+
+// Outcomes of sent mutations
+const (
+	OutcomeOk           = "ok"
+	OutcomeDisconnect   = "disconnect"
+	OutcomeErrorReply   = "error-reply"
+	OutcomeTimeout      = "timeout"
+	OutcomeConnectError = "connect-error"
+)
+
+// Defaults of the run
+const (
+	// DefaultTimeout limits the wait for the ack and the response event
+	DefaultTimeout = 2 * time.Second
+	// DefaultQuiet is the wait for disconnects and error replies of events which are not answered
+	DefaultQuiet = 500 * time.Millisecond
+	// DefaultMinimizeRuns limits the payloads sent to minimize a single failure
+	DefaultMinimizeRuns = 50
+)
+
+// DefaultErrorEvents are the names of events the targets reply with on invalid input.
+var DefaultErrorEvents = []string{"error", "exception", mock.ErrorEvent}
+
+// Length of the reply kept in the message of the result
+const maxMessageLength = 512
+
+type Config struct {
+	Target string
+	// Protocol of the target, socketio by default
+	Protocol string
+	Header   http.Header
+	Timeout  time.Duration
+	Quiet    time.Duration
+	// ErrorEvents replace DefaultErrorEvents when set
+	ErrorEvents  []string
+	MinimizeRuns int
+	// Catalog resolves the response event of the fuzzed event
+	Catalog []*events.Event
+	MutateConfig
+}
+
+// Report lists the outcome of every mutation sent to the target.
+type Report struct {
+	Event      string    `json:"event"`
+	Target     string    `json:"target"`
+	StartedAt  time.Time `json:"startedAt"`
+	DurationMs int64     `json:"durationMs"`
+	Error      string    `json:"error,omitempty"`
+	// Failures counts results with outcomes other than ok
+	Failures int       `json:"failures"`
+	Results  []*Result `json:"results"`
+}
+
+type Result struct {
+	*Mutation
+	Outcome    string `json:"outcome"`
+	Message    string `json:"message,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+	// Reproducer is the smallest payload found which fails with the same outcome
+	Reproducer   json.RawMessage `json:"reproducer,omitempty"`
+	MinimizeRuns int             `json:"minimizeRuns,omitempty"`
+}
+
+type runner struct {
+	c           *Config
+	codec       socket.Codec
+	event       *events.Event
+	response    string
+	ack         bool
+	errorEvents map[string]bool
+	timeout     time.Duration
+	quiet       time.Duration
+}
+
+// Execute sends every mutation of the event payload over a new connection and waits for the ack and the
+// response event, disconnects and error replies. Failed payloads are minimized while the outcome persists.
+func Execute(e *events.Event, c *Config) *Report {
+	report := &Report{Event: e.Value, Target: c.Target, StartedAt: time.Now(), Results: make([]*Result, 0)}
+	defer func() {
+		report.DurationMs = int64(time.Since(report.StartedAt) / time.Millisecond)
+	}()
+	if e.Type != events.TypeClient {
+		report.Error = fmt.Sprintf("event %s is not emitted by clients", e.Value)
+		return report
+	}
+	codec, err := socket.NewCodec(c.Protocol)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	r := &runner{
+		c:           c,
+		codec:       codec,
+		event:       e,
+		ack:         len(e.AckFields) > 0,
+		errorEvents: make(map[string]bool),
+		timeout:     c.Timeout,
+		quiet:       c.Quiet,
+	}
+	if r.timeout <= 0 {
+		r.timeout = DefaultTimeout
+	}
+	if r.quiet <= 0 {
+		r.quiet = DefaultQuiet
+	}
+	errorEvents := c.ErrorEvents
+	if len(errorEvents) < 1 {
+		errorEvents = DefaultErrorEvents
+	}
+	for _, name := range errorEvents {
+		r.errorEvents[name] = true
+	}
+	for _, ce := range c.Catalog {
+		if e.HasResponse() && ce.ID == uint64(e.ResponseEventId.Int64) {
+			r.response = ce.Value
+		}
+	}
+
+	for _, m := range Mutate(e.Fields, &c.MutateConfig) {
+		started := time.Now()
+		res := &Result{Mutation: m}
+		res.Outcome, res.Message = r.send(m.Data)
+		res.DurationMs = int64(time.Since(started) / time.Millisecond)
+		if res.Outcome != OutcomeOk {
+			report.Failures++
+			r.minimize(res)
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
+
+// send emits the payload over a new connection and classifies the reaction of the target.
+func (r *runner) send(data json.RawMessage) (string, string) {
+	conn, err := socket.Dial(r.c.Target, r.codec, r.c.Header)
+	if err != nil {
+		return OutcomeConnectError, err.Error()
+	}
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		conn.Close()
+	}()
+	frames := make(chan *socket.Frame, 16)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(frames)
+		for {
+			f, raw, err := conn.Read()
+			if err != nil {
+				if raw != nil {
+					continue
+				}
+				readErr <- err
+				return
+			}
+			select {
+			case frames <- f:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	f := socket.NewEventFrame(r.event.Value, data)
+	if r.ack {
+		f.AckId = 1
+	}
+	if err := conn.Send(f); err != nil {
+		return OutcomeDisconnect, err.Error()
+	}
+	acked, responded := !r.ack, len(r.response) < 1
+	answered := !acked || !responded
+	wait := r.quiet
+	if answered {
+		wait = r.timeout
+	}
+	deadline := time.After(wait)
+	for {
+		select {
+		case f, ok := <-frames:
+			if !ok {
+				return OutcomeDisconnect, (<-readErr).Error()
+			}
+			switch {
+			case f.Kind == socket.FrameEvent && r.errorEvents[f.Event]:
+				return OutcomeErrorReply, truncate(fmt.Sprintf("%s %s", f.Event, f.Data))
+			case f.Kind == socket.FrameAck && f.AckId == 1:
+				acked = true
+			case f.Kind == socket.FrameEvent && f.Event == r.response:
+				responded = true
+			}
+			if answered && acked && responded {
+				return OutcomeOk, ""
+			}
+		case <-deadline:
+			if !acked {
+				return OutcomeTimeout, fmt.Sprintf("ack was not received within %s", wait)
+			}
+			if !responded {
+				return OutcomeTimeout, fmt.Sprintf("event %s was not received within %s", r.response, wait)
+			}
+			return OutcomeOk, ""
+		}
+	}
+}
+
+// minimize shrinks the failed payload while the target fails with the same outcome. Failures of
+// connects and missing payloads can not be minimized.
+func (r *runner) minimize(res *Result) {
+	if res.Outcome == OutcomeConnectError || len(res.Data) < 1 {
+		return
+	}
+	var current interface{}
+	if err := json.Unmarshal(res.Data, &current); err != nil {
+		return
+	}
+	limit := r.c.MinimizeRuns
+	if limit <= 0 {
+		limit = DefaultMinimizeRuns
+	}
+	for shrunk := true; shrunk && res.MinimizeRuns < limit; {
+		shrunk = shrink(current, func(candidate interface{}) bool {
+			if res.MinimizeRuns >= limit {
+				return true
+			}
+			data, err := json.Marshal(candidate)
+			if err != nil {
+				return false
+			}
+			res.MinimizeRuns++
+			if outcome, _ := r.send(data); outcome != res.Outcome {
+				return false
+			}
+			current = candidate
+			return true
+		}) && res.MinimizeRuns < limit
+	}
+	res.Reproducer, _ = json.Marshal(current)
+}
+
+func truncate(s string) string {
+	if len(s) > maxMessageLength {
+		return s[:maxMessageLength] + "..."
+	}
+	return s
+}
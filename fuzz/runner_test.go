@@ -0,0 +1,116 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecute(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(fragileTarget))
+	defer target.Close()
+
+	e := &events.Event{
+		Value: "login",
+		Type:  events.TypeClient,
+		Fields: []events.Field{
+			{Key: nullKey("name"), Type: "string", Required: true},
+			{Key: nullKey("remember"), Type: "boolean"},
+		},
+		AckFields: []events.Field{{Key: nullKey("ok"), Type: "boolean", Required: true}},
+	}
+	report := Execute(e, &Config{
+		Target:       target.URL,
+		Protocol:     socket.CodecJSON,
+		Timeout:      200 * time.Millisecond,
+		MutateConfig: MutateConfig{OversizedLength: 256, NestingDepth: 4},
+	})
+	if len(report.Error) > 0 {
+		t.Fatalf("Unexpected error of the run: %s", report.Error)
+	}
+
+	expected := map[string]struct {
+		outcome    string
+		reproducer string
+	}{
+		"valid":               {OutcomeOk, ``},
+		"no-payload":          {OutcomeDisconnect, ``},
+		"missing name":        {OutcomeErrorReply, `{}`},
+		"null name":           {OutcomeDisconnect, `{"name":null}`},
+		"wrong-type name":     {OutcomeDisconnect, `{"name":12345}`},
+		"oversized name":      {OutcomeTimeout, `{"name":"` + strings.Repeat("x", 128) + `"}`},
+		"wrong-type remember": {OutcomeOk, ``},
+		"extra-key":           {OutcomeOk, ``},
+		"deep-nesting":        {OutcomeOk, ``},
+	}
+	if len(report.Results) != len(expected) || report.Failures != 5 {
+		t.Fatalf("Unexpected results: %d, failures: %d", len(report.Results), report.Failures)
+	}
+	for _, res := range report.Results {
+		exp, ok := expected[res.Name]
+		if !ok {
+			t.Errorf("Unexpected mutation: %s", res.Name)
+			continue
+		}
+		if res.Outcome != exp.outcome || string(res.Reproducer) != exp.reproducer {
+			t.Errorf("[%s] Unexpected result. Expected: %s %s, actual: %s %s, message: %s", res.Name, exp.outcome, exp.reproducer, res.Outcome, res.Reproducer, res.Message)
+		}
+	}
+}
+
+func TestExecuteNotClientEvent(t *testing.T) {
+	report := Execute(&events.Event{Value: "user", Type: events.TypeFrontend}, &Config{Target: "ws://localhost"})
+	if report.Error != "event user is not emitted by clients" || len(report.Results) != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+var upgrader = websocket.Upgrader{}
+
+// fragileTarget acks login events with valid names. It replies with an error to payloads without the
+// name, ignores names longer than 100 characters and drops the connection on names of other types.
+func fragileTarget(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+	codec := &socket.JSONCodec{}
+	for {
+		_, msg, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		f, err := codec.Decode(msg)
+		if err != nil || f.Kind != socket.FrameEvent {
+			continue
+		}
+		var payload map[string]interface{}
+		if err := json.Unmarshal(f.Data, &payload); err != nil || payload == nil {
+			return
+		}
+		var reply *socket.Frame
+		name, exists := payload["name"]
+		s, isString := name.(string)
+		switch {
+		case !exists:
+			reply = socket.NewEventFrame("error", json.RawMessage(`{"message":"name is required"}`))
+		case !isString:
+			return
+		case len(s) > 100:
+			continue
+		default:
+			reply = socket.NewAckFrame(f.AckId, json.RawMessage(`{"ok":true}`))
+		}
+		data, _ := codec.Encode(reply)
+		if err := ws.WriteMessage(websocket.TextMessage, data); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package fuzz
+
+// Store keeps the fuzz runs of a single project. Runs of other projects are not visible to it.
+type Store interface {
+	GetById(uint64) (*Run, error)
+	// List returns runs of the event newest first
+	List(offset, limit int, eventId uint64) ([]*RunList, int, error)
+	Create(*Run) error
+	Update(*Run) error
+	Delete(*Run) error
+	// ForProject returns the store of the runs of the project sharing the storage with this one
+	ForProject(projectId uint64) Store
+}
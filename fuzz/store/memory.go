@@ -0,0 +1,144 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/fuzz"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"sync"
+	"time"
+)
+
+// Memory keeps copies of the runs, they are updated by the goroutines executing them.
+type Memory struct {
+	logger    logger.Logger
+	projectId uint64
+	// Records of all projects are shared by the stores returned by ForProject
+	*memoryRecords
+}
+
+type memoryRecords struct {
+	records []*fuzz.Run
+	lastId  uint64
+	mu      *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+	// ProjectId is the project of the fuzz runs, the default project when zero
+	ProjectId uint64
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	s := &Memory{
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+		memoryRecords: &memoryRecords{
+			records: make([]*fuzz.Run, 0),
+			mu:      &sync.Mutex{},
+		},
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Memory) ForProject(projectId uint64) fuzz.Store {
+	return &Memory{
+		logger:        s.logger,
+		projectId:     projectId,
+		memoryRecords: s.memoryRecords,
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*fuzz.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id && el.ProjectId == s.projectId {
+			run := *el
+			return &run, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int, eventId uint64) ([]*fuzz.RunList, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runsList := make([]*fuzz.RunList, 0, len(s.records))
+	// Newest first, like the gorm store
+	for i := len(s.records) - 1; i >= 0; i-- {
+		el := s.records[i]
+		if el.ProjectId != s.projectId || el.EventId != eventId {
+			continue
+		}
+		runsList = append(runsList, &fuzz.RunList{
+			ID:         el.ID,
+			ProjectId:  el.ProjectId,
+			EventId:    el.EventId,
+			Event:      el.Event,
+			Target:     el.Target,
+			Protocol:   el.Protocol,
+			Kinds:      el.Kinds,
+			Status:     el.Status,
+			Error:      el.Error,
+			Failures:   el.Failures,
+			FinishedAt: el.FinishedAt,
+			CreatedAt:  el.CreatedAt,
+			UpdatedAt:  el.UpdatedAt,
+		})
+	}
+	total := len(runsList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return runsList[offset : offset+l], total, nil
+}
+
+func (s *Memory) Create(run *fuzz.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastId++
+	run.ID = s.lastId
+	run.ProjectId = s.projectId
+	run.CreatedAt = time.Now()
+	run.UpdatedAt = run.CreatedAt
+	stored := *run
+	s.records = append(s.records, &stored)
+	return nil
+}
+
+func (s *Memory) Update(run *fuzz.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, el := range s.records {
+		if el.ID == run.ID && el.ProjectId == s.projectId {
+			run.CreatedAt = el.CreatedAt
+			run.ProjectId = el.ProjectId
+			run.UpdatedAt = time.Now()
+			stored := *run
+			s.records[i] = &stored
+			break
+		}
+	}
+	return nil
+}
+
+func (s *Memory) Delete(run *fuzz.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, el := range s.records {
+		if el.ID == run.ID && el.ProjectId == s.projectId {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/fuzz"
+	"testing"
+)
+
+func TestMemory_Update(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	run := &fuzz.Run{EventId: 1, Event: "login", Status: fuzz.StatusRunning}
+	if err := s.Create(run); err != nil {
+		t.Fatalf("Can not create test run: %s", err.Error())
+	}
+
+	run.Status = fuzz.StatusFinished
+	run.Report = &fuzz.Report{Event: "login", Failures: 2}
+	run.Failures = 2
+	if err := s.Update(run); err != nil {
+		t.Fatalf("Can not update run: %s", err.Error())
+	}
+	run.Status = fuzz.StatusFailed
+
+	stored, err := s.GetById(run.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("Updated run was not found: %v", err)
+	}
+	if stored.Status != fuzz.StatusFinished || stored.Report.Failures != 2 || stored.ProjectId != 1 {
+		t.Errorf("Stored run should not change with the updated one: %+v", stored)
+	}
+}
+
+func TestMemory_List(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+	other := s.ForProject(2)
+
+	for _, eventId := range []uint64{1, 2, 1, 1} {
+		if err := s.Create(&fuzz.Run{EventId: eventId, Report: &fuzz.Report{}}); err != nil {
+			t.Fatalf("Can not create test run: %s", err.Error())
+		}
+	}
+	if err := other.Create(&fuzz.Run{EventId: 1}); err != nil {
+		t.Fatalf("Can not create test run: %s", err.Error())
+	}
+
+	list, total, err := s.List(1, 5, 1)
+	if err != nil {
+		t.Fatalf("Can not list runs: %s", err.Error())
+	}
+	if total != 3 || len(list) != 2 || list[0].ID != 3 || list[1].ID != 1 {
+		t.Errorf("Unexpected runs list: %+v, total: %d", list, total)
+	}
+
+	if list, total, _ = other.List(0, -1, 1); total != 1 || list[0].ID != 5 {
+		t.Errorf("Unexpected runs of the other project: %+v, total: %d", list, total)
+	}
+	if stored, _ := other.GetById(1); stored != nil {
+		t.Errorf("Run of another project was found: %+v", stored)
+	}
+}
+
+func TestMemory_Delete(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	run := &fuzz.Run{EventId: 1}
+	if err := s.Create(run); err != nil {
+		t.Fatalf("Can not create test run: %s", err.Error())
+	}
+	if err := s.ForProject(2).Delete(run); err != nil {
+		t.Fatalf("Can not delete run: %s", err.Error())
+	}
+	if stored, _ := s.GetById(run.ID); stored == nil {
+		t.Errorf("Run was deleted by the store of another project")
+	}
+	if err := s.Delete(run); err != nil {
+		t.Fatalf("Can not delete run: %s", err.Error())
+	}
+	if stored, _ := s.GetById(run.ID); stored != nil {
+		t.Errorf("Run was not deleted: %+v", stored)
+	}
+}
@@ -1,6 +1,7 @@
 package gql
 
 import (
+	"context"
 	"fmt"
 	"github.com/graphql-go/graphql"
 )
@@ -31,10 +32,12 @@ func (h *GraphQLHub) AddQuery(key string, q *graphql.Field) error {
 	return nil
 }
 
-func (h *GraphQLHub) Execute(query string) (*graphql.Result, error) {
+// Execute runs the query, the context is passed to the resolvers.
+func (h *GraphQLHub) Execute(ctx context.Context, query string) (*graphql.Result, error) {
 	result := graphql.Do(graphql.Params{
 		Schema:        h.schema,
 		RequestString: query,
+		Context:       ctx,
 	})
 	if len(result.Errors) > 0 {
 		return result, fmt.Errorf("graphql: fail to execute query: %v", result.Errors)
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/users"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type createdAPIToken struct {
+	*users.APIToken
+	// Token is shown once, only the hash of it is stored
+	Token string `json:"token"`
+}
+
+// ListAPITokens responds with the personal tokens of the user, admins list service tokens with ?kind=service.
+func (h *Handler) ListAPITokens(c echo.Context) error {
+	u := users.FromContext(c.Request().Context())
+	ownerId := u.ID
+	if c.QueryParam("kind") == users.APITokenService {
+		if code, err := h.checkManageServiceTokens(c); err != nil {
+			return c.JSON(code, &permissionErrorResponseEnvelope{
+				Error:      err.Error(),
+				Permission: users.PermUsersManage,
+			})
+		}
+		ownerId = 0
+	}
+	tokens, err := h.userStore.ListAPITokens(ownerId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: tokens,
+	})
+}
+
+// CreateAPIToken responds with the new token, the token itself is returned only here.
+// Service tokens are created by admins.
+func (h *Handler) CreateAPIToken(c echo.Context) error {
+	req := &apiTokenCreateRequest{}
+	if err := req.bind(c); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	u := users.FromContext(c.Request().Context())
+	if req.Kind == users.APITokenService {
+		if code, err := h.checkManageServiceTokens(c); err != nil {
+			return c.JSON(code, &permissionErrorResponseEnvelope{
+				Error:      err.Error(),
+				Permission: users.PermUsersManage,
+			})
+		}
+	}
+	plain, token, err := users.NewAPIToken(req.Name, req.Kind, req.Scopes)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	token.CreatedBy = u.ID
+	token.ExpiresAt = req.ExpiresAt
+	if token.Kind == users.APITokenPersonal {
+		token.UserId = u.ID
+	}
+	if err := h.userStore.CreateAPIToken(token); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	// The entry holds the prefix of the token, never the token itself
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityAPIToken,
+		EntityId: token.ID,
+		Action:   audit.ActionCreate,
+		After:    audit.Snapshot(token),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: &createdAPIToken{APIToken: token, Token: plain},
+	})
+}
+
+// RevokeAPIToken revokes the token, the token stays in the lists with revokedAt set.
+// Users revoke their own tokens, admins revoke any token.
+func (h *Handler) RevokeAPIToken(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	token, err := h.userStore.GetAPIToken(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	u := users.FromContext(c.Request().Context())
+	owned := token != nil && token.Kind == users.APITokenPersonal && token.UserId == u.ID
+	if token == nil || (!owned && users.Check(c.Request().Context(), 0, users.PermUsersManage) != nil) {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	before := audit.Snapshot(token)
+	if err := h.userStore.RevokeAPIToken(token.ID, time.Now()); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	token, err = h.userStore.GetAPIToken(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityAPIToken,
+		EntityId: id,
+		Action:   audit.ActionUpdate,
+		Before:   before,
+		After:    audit.Snapshot(token),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: token,
+	})
+}
+
+// requireSession rejects anonymous requests and requests authenticated with API tokens,
+// API tokens can not manage API tokens.
+func (h *Handler) requireSession(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		if users.APITokenFromContext(ctx) != nil {
+			return c.JSON(http.StatusForbidden, &errorResponseEnvelope{
+				Error: "API tokens can not manage API tokens",
+			})
+		}
+		if users.FromContext(ctx) == nil {
+			return c.JSON(http.StatusUnauthorized, &errorResponseEnvelope{
+				Error: "authentication required",
+			})
+		}
+		return next(c)
+	}
+}
+
+func (h *Handler) checkManageServiceTokens(c echo.Context) (int, error) {
+	if err := users.Check(c.Request().Context(), 0, users.PermUsersManage); err != nil {
+		return http.StatusForbidden, err
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/users"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHandler_APITokens(t *testing.T) {
+	e, tokens := setupRoutesHandlerTest(t)
+
+	create := func(token, body string) string {
+		rec := serveAuthTestRequest(e, http.MethodPost, "/api/tokens", body, token)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Can not create API token. Response code: %d, response body: %s", rec.Code, rec.Body.String())
+		}
+		created := &struct {
+			Data struct {
+				Token string `json:"token"`
+			} `json:"data"`
+		}{}
+		if err := json.Unmarshal(rec.Body.Bytes(), created); err != nil {
+			t.Fatalf("Can not decode created API token: %s", err.Error())
+		}
+		if !strings.HasPrefix(created.Data.Token, users.APITokenPrefix) {
+			t.Fatalf("Unexpected API token: %s", rec.Body.String())
+		}
+		return created.Data.Token
+	}
+	personal := create(tokens[users.RoleEditor], `{"name":"ci","scopes":["catalog.read","events.write"]}`)
+	service := create(tokens[users.RoleAdmin], `{"name":"runner","kind":"service","scopes":["tests.run"]}`)
+
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		handlerCreateTestCase
+	}{
+		{http.MethodPost, "/api/tokens", tokens[users.RoleReader], handlerCreateTestCase{`{"name":"ci","scopes":["pages.delete"]}`, http.StatusUnprocessableEntity, `unknown scope \"pages.delete\"`}},
+		{http.MethodPost, "/api/tokens", tokens[users.RoleReader], handlerCreateTestCase{`{"name":"ci","scopes":["tests.run"],"expiresAt":"2001-01-01T00:00:00Z"}`, http.StatusUnprocessableEntity, `expiresAt must be in the future`}},
+		{http.MethodPost, "/api/tokens", tokens[users.RoleReader], handlerCreateTestCase{`{"name":"runner","kind":"service","scopes":["tests.run"]}`, http.StatusForbidden, `"permission":"users.manage"`}},
+		{http.MethodPost, "/api/tokens", personal, handlerCreateTestCase{`{"name":"ci","scopes":["tests.run"]}`, http.StatusForbidden, `API tokens can not manage API tokens`}},
+		{http.MethodGet, "/api/tokens", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, `"name":"ci","kind":"personal","userId":3,"createdBy":3,"scopes":["catalog.read","events.write"],"prefix":"apt_`}},
+		{http.MethodGet, "/api/tokens?kind=service", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusForbidden, `"permission":"users.manage"`}},
+		{http.MethodGet, "/api/tokens?kind=service", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"name":"runner","kind":"service","userId":0,"createdBy":1`}},
+		{http.MethodPost, "/api/types", personal, handlerCreateTestCase{`{"name":"User","kind":"object"}`, http.StatusOK, `"name":"User"`}},
+		{http.MethodPost, "/api/pages", personal, handlerCreateTestCase{`{"title":"CI","text":"CI"}`, http.StatusForbidden, `missing permission \"pages.write\"`}},
+		{http.MethodGet, "/api/events", personal, handlerCreateTestCase{``, http.StatusOK, `"total":0`}},
+		{http.MethodGet, "/api/auth/me", personal, handlerCreateTestCase{``, http.StatusOK, `"email":"editor@example.com"`}},
+		{http.MethodGet, "/api/events", service, handlerCreateTestCase{``, http.StatusForbidden, `missing permission \"events.read\"`}},
+		{http.MethodGet, "/api/ws", service, handlerCreateTestCase{``, http.StatusForbidden, `missing permission \"pages.read\"`}},
+		{http.MethodPost, "/api/scenarios/99/run", service, handlerCreateTestCase{`{}`, http.StatusNotFound, ``}},
+		{http.MethodGet, "/api/auth/me", service, handlerCreateTestCase{``, http.StatusUnauthorized, `authentication required`}},
+		{http.MethodDelete, "/api/tokens/1", tokens[users.RoleReader], handlerCreateTestCase{``, http.StatusNotFound, `Not found`}},
+		{http.MethodDelete, "/api/tokens/1", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, `"revokedAt":"`}},
+		{http.MethodGet, "/api/events", personal, handlerCreateTestCase{``, http.StatusUnauthorized, `invalid or expired token`}},
+		{http.MethodDelete, "/api/tokens/2", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"revokedAt":"`}},
+		{http.MethodPost, "/api/scenarios/99/run", service, handlerCreateTestCase{`{}`, http.StatusUnauthorized, `invalid or expired token`}},
+	}
+
+	for caseNum, item := range cases {
+		rec := serveAuthTestRequest(e, item.method, item.path, item.inputData, item.token)
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code of %s %s. Wanted: %d, received: %d, response body: %s", caseNum, item.method, item.path, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
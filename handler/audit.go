@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/users"
+	"github.com/nskondratev/api-page-go-back/util"
+	"net/http"
+	"strconv"
+)
+
+// ListAuditEntries responds with the audit entries passing the filter of the query params, newest first.
+func (h *Handler) ListAuditEntries(c echo.Context) error {
+	req := &auditFilterRequest{}
+	f := &audit.Filter{}
+	if err := req.bind(c, f); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	entries, total, err := h.auditStore.List(f, offset, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  entries,
+		Total: total,
+	})
+}
+
+// record stores the audit entry of the change made by the request. Failures are logged, the change is
+// already stored at the moment.
+func (h *Handler) record(c echo.Context, e *audit.Entry) {
+	if h.auditStore == nil {
+		return
+	}
+	ctx := c.Request().Context()
+	e.ActorId = actorOf(c)
+	if t := users.APITokenFromContext(ctx); t != nil {
+		e.ApiTokenId = util.NewNullInt64FromInt64(int64(t.ID))
+	}
+	e.RequestId = c.Response().Header().Get(echo.HeaderXRequestID)
+	e.Source = audit.SourceFromContext(ctx)
+	if err := h.auditStore.Create(e); err != nil {
+		h.logger.Errorf("Error while storing audit entry of %s %s %d: %s", e.Action, e.Entity, e.EntityId, err.Error())
+	}
+}
+
+// withSource marks the changes of the request with the source.
+func withSource(c echo.Context, source string) {
+	r := c.Request()
+	c.SetRequest(r.WithContext(audit.WithSource(r.Context(), source)))
+}
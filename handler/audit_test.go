@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"github.com/nskondratev/api-page-go-back/users"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHandler_ListAuditEntries(t *testing.T) {
+	e, tokens := setupRoutesHandlerTest(t)
+
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		handlerCreateTestCase
+	}{
+		{http.MethodPost, "/api/events", tokens[users.RoleEditor], handlerCreateTestCase{`{"constant":"LOGIN","value":"login","type":"client","description":"Test"}`, http.StatusOK, `"id":1`}},
+		{http.MethodPost, "/api/events/1", tokens[users.RoleEditor], handlerCreateTestCase{`{"id":1,"constant":"LOGIN","value":"login","type":"client","description":"Changed"}`, http.StatusOK, `"description":"Changed"`}},
+		{http.MethodPost, "/api/events/infer", tokens[users.RoleEditor], handlerCreateTestCase{`{"value":"logout","samples":[{"name":"John"}],"apply":true}`, http.StatusOK, `"applied":true`}},
+		{http.MethodDelete, "/api/events/1", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, ``}},
+		{http.MethodDelete, "/api/events/1", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, ``}},
+		{http.MethodGet, "/api/audit", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusForbidden, `"permission":"audit.read"`}},
+		{http.MethodGet, "/api/audit?entity=event&entityId=1", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":3`}},
+		{http.MethodGet, "/api/audit?entity=event&entityId=1&limit=1", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"entity":"event","entityId":1,"projectId":1,"action":"delete","actorId":1,"apiTokenId":null,"requestId":"`}},
+		{http.MethodGet, "/api/audit?entity=event&action=delete", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"description":"Changed"`}},
+		{http.MethodGet, "/api/audit?action=update&actorId=3", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"before":{"id":1,"projectId":1,"constant":"LOGIN","label":null,"value":"login","description":"Test"`}},
+		{http.MethodGet, "/api/audit?source=import", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"entity":"event","entityId":2,"projectId":1,"action":"create","actorId":3,"apiTokenId":null`}},
+		{http.MethodGet, "/api/audit?source=import", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"source":"import","before":null`}},
+		{http.MethodGet, "/api/audit?from=2100-01-01T00:00:00Z", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":0`}},
+		{http.MethodGet, "/api/audit?actorId=john", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusUnprocessableEntity, `actorId must be a number`}},
+		{http.MethodGet, "/api/audit?to=yesterday", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusUnprocessableEntity, `to must be a time in RFC 3339`}},
+		{http.MethodGet, "/api/audit?action=rename", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusUnprocessableEntity, `Action`}},
+	}
+
+	for caseNum, item := range cases {
+		rec := serveAuthTestRequest(e, item.method, item.path, item.inputData, item.token)
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code of %s %s. Wanted: %d, received: %d, response body: %s", caseNum, item.method, item.path, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_AuditOfCollectionsUsersAndTokens(t *testing.T) {
+	e, tokens := setupRoutesHandlerTest(t)
+
+	imported := `{"version":1,"event":"login","folders":[{"id":5,"parentId":null,"name":"Imported"}],"requests":[{"id":7,"folderId":5,"name":"Bob"}]}`
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		handlerCreateTestCase
+	}{
+		{http.MethodPost, "/api/events", tokens[users.RoleEditor], handlerCreateTestCase{`{"constant":"LOGIN","value":"login","type":"client","description":"Test"}`, http.StatusOK, `"id":1`}},
+		{http.MethodPost, "/api/events/1/folders", tokens[users.RoleEditor], handlerCreateTestCase{`{"name":"Auth"}`, http.StatusOK, `"id":1`}},
+		{http.MethodPost, "/api/events/1/requests", tokens[users.RoleEditor], handlerCreateTestCase{`{"name":"John","folderId":1,"payload":{"name":"John"}}`, http.StatusOK, `"id":1`}},
+		{http.MethodPost, "/api/events/1/requests/1", tokens[users.RoleEditor], handlerCreateTestCase{`{"id":1,"name":"Jane","folderId":1,"payload":{"name":"Jane"}}`, http.StatusOK, `"name":"Jane"`}},
+		{http.MethodPost, "/api/events/1/folders/1", tokens[users.RoleEditor], handlerCreateTestCase{`{"id":1,"name":"Login"}`, http.StatusOK, `"name":"Login"`}},
+		{http.MethodPost, "/api/events/1/requests/import?replace=true", tokens[users.RoleEditor], handlerCreateTestCase{imported, http.StatusOK, `"name":"Imported"`}},
+		{http.MethodDelete, "/api/events/1/requests/2", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, ``}},
+		{http.MethodDelete, "/api/events/1/folders/2", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, ``}},
+		{http.MethodPost, "/api/users", tokens[users.RoleAdmin], handlerCreateTestCase{`{"email":"jane@example.com","name":"Jane","password":"s3cr3tpass"}`, http.StatusOK, `"id":4`}},
+		{http.MethodPost, "/api/users/4", tokens[users.RoleAdmin], handlerCreateTestCase{`{"id":4,"email":"jane@example.com","name":"Jane Doe","password":"n3ws3cr3t"}`, http.StatusOK, `"name":"Jane Doe"`}},
+		{http.MethodPost, "/api/users/4/roles", tokens[users.RoleAdmin], handlerCreateTestCase{`{"role":"editor"}`, http.StatusOK, `"role":"editor"`}},
+		{http.MethodPost, "/api/users/4/roles/2", tokens[users.RoleAdmin], handlerCreateTestCase{`{"role":"admin"}`, http.StatusOK, `"role":"admin"`}},
+		{http.MethodPost, "/api/users/4/roles/2", tokens[users.RoleAdmin], handlerCreateTestCase{`{"role":"reader"}`, http.StatusOK, `"role":"reader"`}},
+		{http.MethodDelete, "/api/users/4/roles/2", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"projects":[]`}},
+		{http.MethodDelete, "/api/users/4", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, ``}},
+		{http.MethodPost, "/api/tokens", tokens[users.RoleEditor], handlerCreateTestCase{`{"name":"CI","scopes":["catalog.read"]}`, http.StatusOK, `"token":"apt_`}},
+		{http.MethodDelete, "/api/tokens/1", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, `"revokedAt":"`}},
+		{http.MethodGet, "/api/audit?entity=request", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":3`}},
+		{http.MethodGet, "/api/audit?entity=request&action=update", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"entity":"request","entityId":1,"projectId":1,"action":"update","actorId":3`}},
+		{http.MethodGet, "/api/audit?entity=request&action=update", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"payload":{"name":"John"}`}},
+		{http.MethodGet, "/api/audit?entity=folder", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":3`}},
+		{http.MethodGet, "/api/audit?entity=folder&action=update", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"before":{"id":1,"eventId":1,"parentId":null,"name":"Auth"`}},
+		{http.MethodGet, "/api/audit?entity=collection", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"entity":"collection","entityId":1,"projectId":1,"action":"update","actorId":3`}},
+		{http.MethodGet, "/api/audit?entity=collection&source=import", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"name":"Imported"`}},
+		{http.MethodGet, "/api/audit?entity=user&entityId=4", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":4`}},
+		{http.MethodGet, "/api/audit?entity=user&action=update&limit=1", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"role":"reader"`}},
+		{http.MethodGet, "/api/audit?entity=user&action=update&limit=1", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"after":{"id":4,"email":"jane@example.com","name":"Jane Doe","role":"editor"`}},
+		{http.MethodGet, "/api/audit?entity=project_role", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":3`}},
+		{http.MethodGet, "/api/audit?entity=project_role&limit=1", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"entity":"project_role","entityId":4,"projectId":2,"action":"delete"`}},
+		{http.MethodGet, "/api/audit?entity=project_role&action=update", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"role":"admin"`}},
+		{http.MethodGet, "/api/audit?entity=api_token", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":2`}},
+		{http.MethodGet, "/api/audit?entity=api_token&action=update", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"before":{"id":1,"name":"CI","kind":"personal","userId":3`}},
+	}
+
+	for caseNum, item := range cases {
+		rec := serveAuthTestRequest(e, item.method, item.path, item.inputData, item.token)
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code of %s %s. Wanted: %d, received: %d, response body: %s", caseNum, item.method, item.path, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	// Passwords and tokens never reach the entries
+	rec := serveAuthTestRequest(e, http.MethodGet, "/api/audit", ``, tokens[users.RoleAdmin])
+	for _, secret := range []string{"s3cr3tpass", "n3ws3cr3t", "passwordHash", `"token":`, "tokenHash"} {
+		if strings.Contains(rec.Body.String(), secret) {
+			t.Errorf("Audit entries contain %s: %s", secret, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_AuditOfTests(t *testing.T) {
+	e, tokens := setupRoutesHandlerTest(t)
+
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		handlerCreateTestCase
+	}{
+		{http.MethodPost, "/api/events", tokens[users.RoleEditor], handlerCreateTestCase{`{"constant":"LOGIN","value":"login","type":"client","description":"Test"}`, http.StatusOK, `"id":1`}},
+		{http.MethodPost, "/api/scenarios", tokens[users.RoleEditor], handlerCreateTestCase{`{"name":"Login","steps":[{"action":"emit","event":"login"}]}`, http.StatusOK, `"id":1`}},
+		{http.MethodPost, "/api/scenarios/1", tokens[users.RoleEditor], handlerCreateTestCase{`{"id":1,"name":"Sign in","steps":[{"action":"emit","event":"login"}]}`, http.StatusOK, `"name":"Sign in"`}},
+		{http.MethodDelete, "/api/scenarios/1", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, ``}},
+		{http.MethodDelete, "/api/scenarios/1", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, ``}},
+		{http.MethodPost, "/api/snapshots", tokens[users.RoleEditor], handlerCreateTestCase{`{"name":"release-1"}`, http.StatusOK, `"id":1`}},
+		{http.MethodDelete, "/api/snapshots/1", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, ``}},
+		{http.MethodDelete, "/api/recordings/1", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, ``}},
+		{http.MethodGet, "/api/audit?entity=scenario", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":3`}},
+		{http.MethodGet, "/api/audit?entity=scenario&action=update", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"entity":"scenario","entityId":1,"projectId":1,"action":"update","actorId":3`}},
+		{http.MethodGet, "/api/audit?entity=scenario&action=delete", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"before":{"id":1,"projectId":1,"name":"Sign in"`}},
+		{http.MethodGet, "/api/audit?entity=snapshot", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":2`}},
+		{http.MethodGet, "/api/audit?entity=snapshot&action=create", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"after":{"id":1,"projectId":1,"name":"release-1","description":"","createdAt":"`}},
+		{http.MethodGet, "/api/audit?entity=recording", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `"total":0`}},
+	}
+
+	for caseNum, item := range cases {
+		rec := serveAuthTestRequest(e, item.method, item.path, item.inputData, item.token)
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code of %s %s. Wanted: %d, received: %d, response body: %s", caseNum, item.method, item.path, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/users"
+	"github.com/nskondratev/api-page-go-back/util"
+	"net/http"
+	"strings"
+)
+
+// Login responds with the access and the refresh tokens of the user.
+func (h *Handler) Login(c echo.Context) error {
+	req := &loginRequest{}
+	if err := req.bind(c); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	u, err := h.userStore.GetByEmail(req.Email)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if u == nil || !u.CheckPassword(req.Password) {
+		return c.JSON(http.StatusUnauthorized, &errorResponseEnvelope{
+			Error: "invalid email or password",
+		})
+	}
+	tokens, err := h.tokenIssuer.Issue(u)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: tokens,
+	})
+}
+
+// RefreshTokens exchanges the refresh token for new tokens, the refresh token can be used once.
+func (h *Handler) RefreshTokens(c echo.Context) error {
+	req := &refreshTokenRequest{}
+	if err := req.bind(c); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	tokens, err := h.tokenIssuer.Refresh(req.RefreshToken)
+	if err == users.ErrInvalidToken {
+		return c.JSON(http.StatusUnauthorized, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: tokens,
+	})
+}
+
+// Logout revokes the refresh token.
+func (h *Handler) Logout(c echo.Context) error {
+	req := &refreshTokenRequest{}
+	if err := req.bind(c); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.tokenIssuer.Revoke(req.RefreshToken); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// GetCurrentUser responds with the user of the access token.
+func (h *Handler) GetCurrentUser(c echo.Context) error {
+	u := users.FromContext(c.Request().Context())
+	if u == nil {
+		return c.JSON(http.StatusUnauthorized, &errorResponseEnvelope{
+			Error: "authentication required",
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: u,
+	})
+}
+
+// authenticate passes the user of the access token to the handlers in the request context. API tokens
+// are accepted as well, their grants are limited by the scopes. Requests changing anything are rejected
+// without a token, read requests are served anonymously.
+func (h *Handler) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		token := accessTokenOf(c)
+		if len(token) < 1 {
+			if !readOnly(c.Request().Method) {
+				return c.JSON(http.StatusUnauthorized, &errorResponseEnvelope{
+					Error: "authentication required",
+				})
+			}
+			return next(c)
+		}
+		var (
+			u        *users.User
+			apiToken *users.APIToken
+			err      error
+		)
+		if strings.HasPrefix(token, users.APITokenPrefix) {
+			apiToken, u, err = h.tokenIssuer.AuthenticateAPIToken(token)
+		} else {
+			u, err = h.tokenIssuer.Authenticate(token)
+		}
+		if err == users.ErrInvalidToken {
+			return c.JSON(http.StatusUnauthorized, &errorResponseEnvelope{
+				Error: err.Error(),
+			})
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+				Error: err.Error(),
+			})
+		}
+		var overrides []*users.ProjectRole
+		if u != nil {
+			if overrides, err = h.userStore.ListProjectRoles(u.ID); err != nil {
+				return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+					Error: err.Error(),
+				})
+			}
+		}
+		r := c.Request()
+		ctx := r.Context()
+		if u != nil {
+			ctx = users.NewContext(ctx, u)
+		}
+		if apiToken != nil {
+			ctx = users.WithGrants(users.WithAPIToken(ctx, apiToken), apiToken.Grants(u, overrides))
+		} else {
+			ctx = users.WithGrants(ctx, users.NewGrants(u, overrides))
+		}
+		c.SetRequest(r.WithContext(ctx))
+		return next(c)
+	}
+}
+
+// allow rejects the requests when the global role of the user lacks the permission.
+func (h *Handler) allow(perm users.Permission) echo.MiddlewareFunc {
+	return h.checkPermission(perm, false)
+}
+
+// allowInProject rejects the requests when the role of the user in the project of the request lacks the permission.
+func (h *Handler) allowInProject(perm users.Permission) echo.MiddlewareFunc {
+	return h.checkPermission(perm, true)
+}
+
+func (h *Handler) checkPermission(perm users.Permission, inProject bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if h.tokenIssuer == nil {
+				return next(c)
+			}
+			var projectId uint64
+			if inProject {
+				projectId = h.projectIdOf(c)
+			}
+			if err := users.Check(c.Request().Context(), projectId, perm); err != nil {
+				return forbidden(c, perm, err)
+			}
+			return next(c)
+		}
+	}
+}
+
+// checkInProject checks the permission which depends on the request body or params against the role
+// of the user in the project of the request.
+func (h *Handler) checkInProject(c echo.Context, perm users.Permission) error {
+	if h.tokenIssuer == nil {
+		return nil
+	}
+	return users.Check(c.Request().Context(), h.projectIdOf(c), perm)
+}
+
+func forbidden(c echo.Context, perm users.Permission, err error) error {
+	return c.JSON(http.StatusForbidden, &permissionErrorResponseEnvelope{
+		Error:      err.Error(),
+		Permission: perm,
+	})
+}
+
+// requireUser rejects anonymous reads, like the upgrade of the ws connection. Service tokens pass.
+func (h *Handler) requireUser(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := c.Request().Context()
+		if h.tokenIssuer != nil && users.FromContext(ctx) == nil && users.APITokenFromContext(ctx) == nil {
+			return c.JSON(http.StatusUnauthorized, &errorResponseEnvelope{
+				Error: "authentication required",
+			})
+		}
+		return next(c)
+	}
+}
+
+// accessTokenOf returns the bearer token of the Authorization header or the token query param, an access
+// token of a session or an API token.
+// Browsers can not set headers of the ws upgrade, so they pass the token in the query.
+func accessTokenOf(c echo.Context) string {
+	const prefix = "Bearer "
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if strings.HasPrefix(header, prefix) {
+		return strings.TrimSpace(header[len(prefix):])
+	}
+	return c.QueryParam("token")
+}
+
+func readOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// actorOf returns the ID of the user of the request, null for anonymous requests and service tokens.
+func actorOf(c echo.Context) util.NullInt64 {
+	if u := users.FromContext(c.Request().Context()); u != nil {
+		return util.NewNullInt64FromInt64(int64(u.ID))
+	}
+	return util.NullInt64{}
+}
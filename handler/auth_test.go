@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	pageStore "github.com/nskondratev/api-page-go-back/pages/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/users"
+	userStore "github.com/nskondratev/api-page-go-back/users/store"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Login(t *testing.T) {
+	e := setupAuthHandlerTest(t)
+
+	cases := []handlerCreateTestCase{
+		{`{"email":"john@example.com","password":"secret-password"}`, http.StatusOK, `"accessToken":"`},
+		{`{"email":"john@example.com","password":"wrong-password"}`, http.StatusUnauthorized, `invalid email or password`},
+		{`{"email":"jane@example.com","password":"secret-password"}`, http.StatusUnauthorized, `invalid email or password`},
+		{`{"email":"john@example.com"}`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		rec := serveAuthTestRequest(e, http.MethodPost, "/api/auth/login", item.inputData, "")
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+
+		if strings.Contains(rec.Body.String(), "passwordHash") || strings.Contains(rec.Body.String(), "$2a$") {
+			t.Errorf("[%d] Response body contains password hash: %s", caseNum, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_RefreshTokens(t *testing.T) {
+	e := setupAuthHandlerTest(t)
+	tokens := loginAuthTestUser(t, e)
+
+	refresh := `{"refreshToken":"` + tokens.RefreshToken + `"}`
+	if rec := serveAuthTestRequest(e, http.MethodPost, "/api/auth/refresh", refresh, ""); rec.Code != http.StatusOK {
+		t.Errorf("Unexpected response code of refresh. Wanted: %d, received: %d, response body: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if rec := serveAuthTestRequest(e, http.MethodPost, "/api/auth/refresh", refresh, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Used refresh token is accepted. Response code: %d, response body: %s", rec.Code, rec.Body.String())
+	}
+
+	tokens = loginAuthTestUser(t, e)
+	refresh = `{"refreshToken":"` + tokens.RefreshToken + `"}`
+	if rec := serveAuthTestRequest(e, http.MethodPost, "/api/auth/logout", refresh, ""); rec.Code != http.StatusOK {
+		t.Errorf("Unexpected response code of logout. Wanted: %d, received: %d, response body: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if rec := serveAuthTestRequest(e, http.MethodPost, "/api/auth/refresh", refresh, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Refresh token is accepted after logout. Response code: %d, response body: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHandler_Authenticate(t *testing.T) {
+	e := setupAuthHandlerTest(t)
+	token := loginAuthTestUser(t, e).AccessToken
+
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		handlerCreateTestCase
+	}{
+		{http.MethodPost, "/api/pages", "", handlerCreateTestCase{`{"title":"Page 1","text":"Page 1 text"}`, http.StatusUnauthorized, `authentication required`}},
+		{http.MethodPost, "/api/pages", "bad", handlerCreateTestCase{`{"title":"Page 1","text":"Page 1 text"}`, http.StatusUnauthorized, `invalid or expired token`}},
+		{http.MethodPost, "/api/pages", token, handlerCreateTestCase{`{"title":"Page 1","text":"Page 1 text"}`, http.StatusOK, `"createdBy":1,"updatedBy":1`}},
+		{http.MethodGet, "/api/pages/1", "", handlerCreateTestCase{``, http.StatusOK, `"title":"Page 1"`}},
+		{http.MethodGet, "/api/pages/1", "bad", handlerCreateTestCase{``, http.StatusUnauthorized, `invalid or expired token`}},
+		{http.MethodDelete, "/api/pages/1", "", handlerCreateTestCase{``, http.StatusUnauthorized, `authentication required`}},
+		{http.MethodPost, "/api/events", token, handlerCreateTestCase{`{"constant":"LOGIN","value":"login","type":"client","description":"Test"}`, http.StatusOK, `"createdBy":1,"updatedBy":1`}},
+		{http.MethodPost, "/api/events/1", "", handlerCreateTestCase{`{"constant":"LOGIN","value":"login","type":"client","description":"Test"}`, http.StatusUnauthorized, `authentication required`}},
+		{http.MethodPost, "/api/graphql", "", handlerCreateTestCase{`{"query":"{ event(id: 1) { value } }"}`, http.StatusUnauthorized, `authentication required`}},
+		{http.MethodGet, "/api/ws", "", handlerCreateTestCase{``, http.StatusUnauthorized, `authentication required`}},
+		{http.MethodGet, "/api/auth/me", token, handlerCreateTestCase{``, http.StatusOK, `"email":"john@example.com"`}},
+		{http.MethodGet, "/api/auth/me", "", handlerCreateTestCase{``, http.StatusUnauthorized, `authentication required`}},
+		{http.MethodPost, "/api/users", "", handlerCreateTestCase{`{"email":"jane@example.com","name":"Jane","password":"jane-password"}`, http.StatusUnauthorized, `authentication required`}},
+		{http.MethodPost, "/api/users", token, handlerCreateTestCase{`{"email":"jane@example.com","name":"Jane","password":"jane-password"}`, http.StatusOK, `"id":2,"email":"jane@example.com"`}},
+		{http.MethodPost, "/api/users", token, handlerCreateTestCase{`{"email":"jane@example.com","name":"Jane","password":"jane-password"}`, http.StatusConflict, `user with email \"jane@example.com\" already exists`}},
+		{http.MethodPost, "/api/users", token, handlerCreateTestCase{`{"email":"bob@example.com","name":"Bob","password":"short"}`, http.StatusUnprocessableEntity, emptyStr}},
+		{http.MethodDelete, "/api/users/1", token, handlerCreateTestCase{``, http.StatusUnprocessableEntity, `you can not delete yourself`}},
+		{http.MethodDelete, "/api/users/2", token, handlerCreateTestCase{``, http.StatusOK, emptyStr}},
+	}
+
+	for caseNum, item := range cases {
+		rec := serveAuthTestRequest(e, item.method, item.path, item.inputData, item.token)
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code of %s %s. Wanted: %d, received: %d, response body: %s", caseNum, item.method, item.path, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func setupAuthHandlerTest(t *testing.T) *echo.Echo {
+	e := router.New()
+
+	us := userStore.NewMemory(&userStore.MemoryConfig{Logger: e.Logger})
+	u := &users.User{Email: "john@example.com", Name: "John", Role: users.RoleAdmin}
+	if err := u.SetPassword("secret-password"); err != nil {
+		t.Fatalf("Can not set password of test user: %s", err.Error())
+	}
+	if err := us.Create(u); err != nil {
+		t.Fatalf("Can not create test user: %s", err.Error())
+	}
+	issuer, err := users.NewIssuer(&users.IssuerConfig{Store: us, Secret: "test"})
+	if err != nil {
+		t.Fatalf("Can not create token issuer: %s", err.Error())
+	}
+
+	h := New(&Config{
+		Logger:      e.Logger,
+		EventStore:  eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger}),
+		PageStore:   pageStore.NewMemory(&pageStore.MemoryConfig{Logger: e.Logger}),
+		UserStore:   us,
+		TokenIssuer: issuer,
+		WsHub:       ws.NewHubMock(),
+	})
+	h.Register(e.Group("/api"), e.Group(""))
+
+	return e
+}
+
+func loginAuthTestUser(t *testing.T, e *echo.Echo) *users.Tokens {
+	rec := serveAuthTestRequest(e, http.MethodPost, "/api/auth/login", `{"email":"john@example.com","password":"secret-password"}`, "")
+	res := &struct {
+		Data *users.Tokens `json:"data"`
+	}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), res); err != nil || res.Data == nil {
+		t.Fatalf("Can not login test user. Response code: %d, response body: %s", rec.Code, rec.Body.String())
+	}
+	return res.Data
+}
+
+func serveAuthTestRequest(e *echo.Echo, method, path, body, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if len(token) > 0 {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
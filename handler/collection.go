@@ -0,0 +1,581 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/collections"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"strconv"
+)
+
+// ListSavedRequests responds with the folders and the saved requests of the event.
+func (h *Handler) ListSavedRequests(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	collection, err := collections.Load(h.collectionStore, event.ID, event.Value)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: collection,
+	})
+}
+
+func (h *Handler) GetSavedRequest(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	sr, code, err := h.savedRequestFromParam(c, event)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: sr,
+	})
+}
+
+func (h *Handler) CreateSavedRequest(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	req := &savedRequestCreateRequest{}
+	sr := &collections.Request{EventId: event.ID}
+	if err := req.bind(c, sr); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkRequestFolder(event, sr.FolderId); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.collectionStore.CreateRequest(sr); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityRequest,
+		EntityId:  sr.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(sr),
+	})
+	wsMessage := &ws.ApRequestMessage{
+		EventConst: ws.RequestCreated,
+		Data: &ws.ApMessageRequestEnvelope{
+			Request: sr,
+		},
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting REQUEST_CREATED to ws: %s", err.Error())
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: sr,
+	})
+}
+
+func (h *Handler) UpdateSavedRequest(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, code, err := h.savedRequestFromParam(c, event)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	req := &savedRequestUpdateRequest{}
+	sr := &collections.Request{EventId: event.ID}
+	if err := req.bind(c, sr); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkRequestFolder(event, sr.FolderId); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	before := audit.Snapshot(existing)
+	if err := h.collectionStore.UpdateRequest(sr); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityRequest,
+		EntityId:  sr.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionUpdate,
+		Before:    before,
+		After:     audit.Snapshot(sr),
+	})
+	wsMessage := &ws.ApRequestMessage{
+		EventConst: ws.RequestUpdated,
+		Data: &ws.ApMessageRequestEnvelope{
+			Request: sr,
+		},
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting REQUEST_UPDATED to ws: %s", err.Error())
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: sr,
+	})
+}
+
+func (h *Handler) DeleteSavedRequest(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	sr, code, err := h.savedRequestFromParam(c, event)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.collectionStore.DeleteRequest(sr); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityRequest,
+		EntityId:  sr.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionDelete,
+		Before:    audit.Snapshot(sr),
+	})
+	wsMessage := &ws.ApIdMessage{
+		EventConst: ws.RequestDeleted,
+		Data: &ws.ApMessageOnlyIdEnvelope{
+			ID: sr.ID,
+		},
+		EventId: event.ID,
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting REQUEST_DELETED to ws: %s", err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) CreateRequestFolder(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	req := &requestFolderCreateRequest{}
+	f := &collections.Folder{EventId: event.ID}
+	if err := req.bind(c, f); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkRequestFolder(event, f.ParentId); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.collectionStore.CreateFolder(f); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityFolder,
+		EntityId:  f.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(f),
+	})
+	wsMessage := &ws.ApFolderMessage{
+		EventConst: ws.FolderCreated,
+		Data: &ws.ApMessageFolderEnvelope{
+			Folder: f,
+		},
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting FOLDER_CREATED to ws: %s", err.Error())
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: f,
+	})
+}
+
+// UpdateRequestFolder renames the folder or moves it to another parent folder of the event.
+func (h *Handler) UpdateRequestFolder(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, code, err := h.requestFolderFromParam(c, event)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	req := &requestFolderUpdateRequest{}
+	f := &collections.Folder{EventId: event.ID}
+	if err := req.bind(c, f); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkRequestFolder(event, f.ParentId); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	folders, err := h.collectionStore.ListFolders(event.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := collections.CheckParent(folders, f.ID, f.ParentId); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	before := audit.Snapshot(existing)
+	if err := h.collectionStore.UpdateFolder(f); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityFolder,
+		EntityId:  f.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionUpdate,
+		Before:    before,
+		After:     audit.Snapshot(f),
+	})
+	wsMessage := &ws.ApFolderMessage{
+		EventConst: ws.FolderUpdated,
+		Data: &ws.ApMessageFolderEnvelope{
+			Folder: f,
+		},
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting FOLDER_UPDATED to ws: %s", err.Error())
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: f,
+	})
+}
+
+// DeleteRequestFolder deletes the folder along with the nested folders and their requests.
+func (h *Handler) DeleteRequestFolder(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	f, code, err := h.requestFolderFromParam(c, event)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	collection, err := collections.Load(h.collectionStore, event.ID, event.Value)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.deleteRequestFolders(collection, collections.Subtree(collection.Folders, f.ID)); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityFolder,
+		EntityId:  f.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionDelete,
+		Before:    audit.Snapshot(f),
+	})
+	wsMessage := &ws.ApIdMessage{
+		EventConst: ws.FolderDeleted,
+		Data: &ws.ApMessageOnlyIdEnvelope{
+			ID: f.ID,
+		},
+		EventId: event.ID,
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting FOLDER_DELETED to ws: %s", err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// ExportSavedRequests responds with the collection of the event as a JSON file.
+func (h *Handler) ExportSavedRequests(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	collection, err := collections.Load(h.collectionStore, event.ID, event.Value)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=requests-"+event.Value+".json")
+	return c.JSONPretty(http.StatusOK, collection, "  ")
+}
+
+// ImportSavedRequests adds the folders and the requests of the exported collection to the event.
+// The existing ones are deleted first when the replace query param is "true".
+func (h *Handler) ImportSavedRequests(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	imported := &collections.Collection{}
+	if err := c.Bind(imported); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := imported.Validate(); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := collections.Load(h.collectionStore, event.ID, event.Value)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if c.QueryParam("replace") == "true" {
+		ids := make([]uint64, 0, len(existing.Folders))
+		for _, f := range existing.Folders {
+			ids = append(ids, f.ID)
+		}
+		for _, sr := range existing.Requests {
+			if !sr.FolderId.Valid {
+				if err := h.collectionStore.DeleteRequest(sr); err != nil {
+					return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+						Error: err.Error(),
+					})
+				}
+			}
+		}
+		if err := h.deleteRequestFolders(existing, ids); err != nil {
+			return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+				Error: err.Error(),
+			})
+		}
+	}
+	if err := collections.Import(h.collectionStore, event.ID, imported); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	collection, err := collections.Load(h.collectionStore, event.ID, event.Value)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	// The whole collection is recorded once, the imported requests and folders are not recorded one by one
+	withSource(c, audit.SourceImport)
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityCollection,
+		EntityId:  event.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionUpdate,
+		Before:    audit.Snapshot(existing),
+		After:     audit.Snapshot(collection),
+	})
+	wsMessage := &ws.ApCollectionMessage{
+		EventConst: ws.RequestsImported,
+		Data: &ws.ApMessageCollectionEnvelope{
+			EventId:    event.ID,
+			Collection: collection,
+		},
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting REQUESTS_IMPORTED to ws: %s", err.Error())
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: collection,
+	})
+}
+
+// RunSavedRequest sends the saved request to the target and responds with the report of the run. The
+// environment of the saved request provides the target and the variables unless the body names another one.
+func (h *Handler) RunSavedRequest(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	sr, code, err := h.savedRequestFromParam(c, event)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	req := &scenarioRunRequest{}
+	rc := &scenarios.RunConfig{}
+	if err := req.bind(c, rc); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if len(req.Environment) < 1 {
+		req.Environment = sr.Environment
+	}
+	env, code, err := h.applyEnvironment(c, req.Environment, rc)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if rc.Catalog, err = h.eventsOf(c).GetAll(); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if rc.Types, err = events.ResolveEventTypes(h.typeStore, rc.Catalog...); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	response := ""
+	for _, e := range rc.Catalog {
+		if event.HasResponse() && e.ID == uint64(event.ResponseEventId.Int64) {
+			response = e.Value
+		}
+	}
+	report := scenarios.Run(savedRequestScenario(sr, event.Value, response), rc)
+	maskReportTarget(report, env, req.Target)
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: report,
+	})
+}
+
+// savedRequestScenario builds the scenario sending the saved request as the event. The expected response
+// is matched against the ack payload when the request waits for the ack, otherwise against the payload of
+// the response event. Without both the expected response is not checked.
+func savedRequestScenario(sr *collections.Request, event, response string) *scenarios.Scenario {
+	emit := &scenarios.Step{Action: scenarios.ActionEmit, Event: event, Payload: sr.Payload, Ack: sr.Ack}
+	s := &scenarios.Scenario{Name: sr.Name, Description: sr.Description, Steps: []*scenarios.Step{emit}}
+	var match map[string]util.RawJSON
+	if len(sr.ExpectedResponse) > 0 {
+		match = map[string]util.RawJSON{"": sr.ExpectedResponse}
+	}
+	if sr.Ack {
+		emit.Match = match
+	} else if len(response) > 0 {
+		s.Steps = append(s.Steps, &scenarios.Step{Action: scenarios.ActionExpect, Event: response, Match: match})
+	}
+	return s
+}
+
+// deleteRequestFolders deletes the folders of the collection with the given ids and their requests.
+func (h *Handler) deleteRequestFolders(collection *collections.Collection, ids []uint64) error {
+	deleted := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	for _, sr := range collection.Requests {
+		if sr.FolderId.Valid && deleted[uint64(sr.FolderId.Int64)] {
+			if err := h.collectionStore.DeleteRequest(sr); err != nil {
+				return err
+			}
+		}
+	}
+	for _, id := range ids {
+		if err := h.collectionStore.DeleteFolder(&collections.Folder{ID: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRequestFolder checks that the referenced folder belongs to the event.
+func (h *Handler) checkRequestFolder(event *events.Event, folderId util.NullInt64) (int, error) {
+	if !folderId.Valid {
+		return http.StatusOK, nil
+	}
+	f, err := h.collectionStore.GetFolderById(uint64(folderId.Int64))
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if f == nil || f.EventId != event.ID {
+		return http.StatusUnprocessableEntity, fmt.Errorf("folder %d does not exist", folderId.Int64)
+	}
+	return http.StatusOK, nil
+}
+
+func (h *Handler) savedRequestFromParam(c echo.Context, event *events.Event) (*collections.Request, int, error) {
+	id, err := strconv.ParseUint(c.Param("requestId"), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	sr, err := h.collectionStore.GetRequestById(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if sr == nil || sr.EventId != event.ID {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	return sr, http.StatusOK, nil
+}
+
+func (h *Handler) requestFolderFromParam(c echo.Context, event *events.Event) (*collections.Folder, int, error) {
+	id, err := strconv.ParseUint(c.Param("folderId"), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	f, err := h.collectionStore.GetFolderById(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if f == nil || f.EventId != event.ID {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	return f, http.StatusOK, nil
+}
@@ -0,0 +1,305 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/collections"
+	collectionStore "github.com/nskondratev/api-page-go-back/collections/store"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_CreateSavedRequest(t *testing.T) {
+	e, h, cs := setupCollectionHandlerTest(t)
+
+	if err := cs.CreateFolder(&collections.Folder{EventId: 1, Name: "Smoke"}); err != nil {
+		t.Fatalf("Can not create test folder: %s", err.Error())
+	}
+	if err := cs.CreateFolder(&collections.Folder{EventId: 2, Name: "Other"}); err != nil {
+		t.Fatalf("Can not create test folder: %s", err.Error())
+	}
+
+	cases := []handlerCreateTestCase{
+		{`{"name":"Valid login","folderId":1,"environment":"staging","payload":{"name":"{{user}}"},"ack":true,"expectedResponse":{"ok":true}}`, http.StatusOK, `"id":1,"eventId":1,"folderId":1,"name":"Valid login","description":"","environment":"staging","payload":{"name":"{{user}}"},"ack":true,"expectedResponse":{"ok":true}`},
+		{`{"name":"Root request","payload":{}}`, http.StatusOK, `"id":2,"eventId":1,"folderId":null,"name":"Root request"`},
+		{`{"name":"Foreign folder","folderId":2}`, http.StatusUnprocessableEntity, `folder 2 does not exist`},
+		{`{"name":"Missing folder","folderId":5}`, http.StatusUnprocessableEntity, `folder 5 does not exist`},
+		{`{"payload":{}}`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		if err := h.CreateSavedRequest(c); err != nil {
+			t.Errorf("[%d] Fail to create saved request. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_UpdateSavedRequest(t *testing.T) {
+	e, h, cs := setupCollectionHandlerTest(t)
+
+	for _, sr := range []*collections.Request{
+		{EventId: 1, Name: "Login"},
+		{EventId: 2, Name: "Logout"},
+	} {
+		if err := cs.CreateRequest(sr); err != nil {
+			t.Fatalf("Can not create test request: %s", err.Error())
+		}
+	}
+
+	type updateTestCase struct {
+		requestId string
+		handlerCreateTestCase
+	}
+	cases := []updateTestCase{
+		{"1", handlerCreateTestCase{`{"name":"Login renamed","payload":{"name":"bob"}}`, http.StatusOK, `"id":1,"eventId":1,"folderId":null,"name":"Login renamed"`}},
+		{"2", handlerCreateTestCase{`{"name":"Logout renamed"}`, http.StatusNotFound, `Not found`}},
+		{"3", handlerCreateTestCase{`{"name":"Missing"}`, http.StatusNotFound, `Not found`}},
+		{"1", handlerCreateTestCase{`{"name":"Login","folderId":3}`, http.StatusUnprocessableEntity, `folder 3 does not exist`}},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "requestId")
+		c.SetParamValues("1", item.requestId)
+
+		if err := h.UpdateSavedRequest(c); err != nil {
+			t.Errorf("[%d] Fail to update saved request. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_UpdateRequestFolder(t *testing.T) {
+	e, h, cs := setupCollectionHandlerTest(t)
+
+	for _, f := range []*collections.Folder{
+		{EventId: 1, Name: "Auth"},
+		{EventId: 1, Name: "Login", ParentId: util.NewNullInt64FromInt64(1)},
+		{EventId: 1, Name: "Chat"},
+	} {
+		if err := cs.CreateFolder(f); err != nil {
+			t.Fatalf("Can not create test folder: %s", err.Error())
+		}
+	}
+
+	type updateTestCase struct {
+		folderId string
+		handlerCreateTestCase
+	}
+	cases := []updateTestCase{
+		{"3", handlerCreateTestCase{`{"name":"Chat","parentId":2}`, http.StatusOK, `"id":3,"eventId":1,"parentId":2,"name":"Chat"`}},
+		{"1", handlerCreateTestCase{`{"name":"Auth","parentId":3}`, http.StatusUnprocessableEntity, `folder 1 is nested in itself`}},
+		{"1", handlerCreateTestCase{`{"name":"Auth","parentId":1}`, http.StatusUnprocessableEntity, `folder 1 is nested in itself`}},
+		{"1", handlerCreateTestCase{`{"name":"Auth","parentId":7}`, http.StatusUnprocessableEntity, `folder 7 does not exist`}},
+		{"7", handlerCreateTestCase{`{"name":"Missing"}`, http.StatusNotFound, `Not found`}},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "folderId")
+		c.SetParamValues("1", item.folderId)
+
+		if err := h.UpdateRequestFolder(c); err != nil {
+			t.Errorf("[%d] Fail to update folder. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_DeleteRequestFolder(t *testing.T) {
+	e, h, cs := setupCollectionHandlerTest(t)
+
+	for _, f := range []*collections.Folder{
+		{EventId: 1, Name: "Auth"},
+		{EventId: 1, Name: "Login", ParentId: util.NewNullInt64FromInt64(1)},
+		{EventId: 1, Name: "Chat"},
+	} {
+		if err := cs.CreateFolder(f); err != nil {
+			t.Fatalf("Can not create test folder: %s", err.Error())
+		}
+	}
+	for _, sr := range []*collections.Request{
+		{EventId: 1, Name: "Valid login", FolderId: util.NewNullInt64FromInt64(2)},
+		{EventId: 1, Name: "Send message", FolderId: util.NewNullInt64FromInt64(3)},
+		{EventId: 1, Name: "Ping"},
+	} {
+		if err := cs.CreateRequest(sr); err != nil {
+			t.Fatalf("Can not create test request: %s", err.Error())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id", "folderId")
+	c.SetParamValues("1", "1")
+
+	if err := h.DeleteRequestFolder(c); err != nil {
+		t.Fatalf("Fail to delete folder. Error: %s", err.Error())
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected response code. Wanted: %d, received: %d, response body: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	folders, _ := cs.ListFolders(1)
+	if len(folders) != 1 || folders[0].Name != "Chat" {
+		t.Errorf("Unexpected folders left: %+v", folders)
+	}
+	requests, _ := cs.ListRequests(1)
+	if len(requests) != 2 || requests[0].Name != "Ping" || requests[1].Name != "Send message" {
+		t.Errorf("Unexpected requests left: %+v", requests)
+	}
+}
+
+func TestHandler_ImportSavedRequests(t *testing.T) {
+	e, h, cs := setupCollectionHandlerTest(t)
+
+	if err := cs.CreateRequest(&collections.Request{EventId: 2, Name: "Logout"}); err != nil {
+		t.Fatalf("Can not create test request: %s", err.Error())
+	}
+
+	exported := `{"version":1,"event":"login","folders":[` +
+		`{"id":10,"parentId":11,"name":"Valid"},` +
+		`{"id":11,"parentId":null,"name":"Auth"}],` +
+		`"requests":[{"id":4,"eventId":1,"folderId":10,"name":"Valid login","payload":{"name":"bob"}},` +
+		`{"id":5,"eventId":1,"folderId":null,"name":"Empty login"}]}`
+
+	cases := []struct {
+		query string
+		handlerCreateTestCase
+	}{
+		{"", handlerCreateTestCase{exported, http.StatusOK, `"folders":[{"id":1,"eventId":2,"parentId":null,"name":"Auth"`}},
+		{"", handlerCreateTestCase{`{"version":2}`, http.StatusUnprocessableEntity, `unsupported collection version: 2`}},
+		{"", handlerCreateTestCase{`{"version":1,"requests":[{"name":"Lost","folderId":3}]}`, http.StatusUnprocessableEntity, `request Lost references missing folder 3`}},
+		{"?replace=true", handlerCreateTestCase{exported, http.StatusOK, `"folders":[{"id":3,"eventId":2,"parentId":null,"name":"Auth"`}},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/"+item.query, strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("2")
+
+		if err := h.ImportSavedRequests(c); err != nil {
+			t.Errorf("[%d] Fail to import saved requests. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	folders, _ := cs.ListFolders(2)
+	if len(folders) != 2 || folders[0].Name != "Auth" || folders[1].ParentId.Int64 != int64(folders[0].ID) {
+		t.Errorf("Unexpected imported folders: %+v", folders)
+	}
+	requests, _ := cs.ListRequests(2)
+	if len(requests) != 2 || requests[1].Name != "Valid login" || requests[1].FolderId.Int64 != int64(folders[1].ID) || string(requests[1].Payload) != `{"name":"bob"}` {
+		t.Errorf("Unexpected imported requests: %+v", requests)
+	}
+}
+
+func TestHandler_ExportSavedRequests(t *testing.T) {
+	e, h, cs := setupCollectionHandlerTest(t)
+
+	if err := cs.CreateRequest(&collections.Request{EventId: 1, Name: "Valid login", Payload: util.RawJSON(`{"name":"bob"}`)}); err != nil {
+		t.Fatalf("Can not create test request: %s", err.Error())
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.ExportSavedRequests(c); err != nil {
+		t.Fatalf("Fail to export saved requests. Error: %s", err.Error())
+	}
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected response code. Wanted: %d, received: %d, response body: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	if disposition := rec.Header().Get(echo.HeaderContentDisposition); disposition != "attachment; filename=requests-login.json" {
+		t.Errorf("Unexpected content disposition: %s", disposition)
+	}
+	for _, s := range []string{`"version": 1`, `"event": "login"`, `"name": "Valid login"`} {
+		if !strings.Contains(rec.Body.String(), s) {
+			t.Errorf("Exported collection doesn't contain %s: %s", s, rec.Body.String())
+		}
+	}
+}
+
+func setupCollectionHandlerTest(t *testing.T) (*echo.Echo, *Handler, *collectionStore.Memory) {
+	e := router.New()
+
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+	for _, ev := range []*events.Event{
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient},
+		{Constant: "LOGOUT", Value: "logout", Type: events.TypeClient},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	cs := collectionStore.NewMemory(&collectionStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	h := New(&Config{
+		Logger:          e.Logger,
+		EventStore:      es,
+		CollectionStore: cs,
+		WsHub:           ws.NewHubMock(),
+	})
+
+	return e, h, cs
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/coverage"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"net/http"
+)
+
+// GetCoverage reports the catalog coverage of the recorded sessions given by the sessions query param,
+// of all recorded sessions when it is empty.
+func (h *Handler) GetCoverage(c echo.Context) error {
+	ids, err := coverage.ParseIds(c.QueryParam("sessions"))
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	messages, err := coverage.SessionMessages(h.recordingsOf(c), ids)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return h.coverageReport(c, messages)
+}
+
+// UploadCoverage reports the catalog coverage of the traffic log uploaded as NDJSON in the format of recording exports.
+func (h *Handler) UploadCoverage(c echo.Context) error {
+	messages, err := recordings.ReadNDJSON(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return h.coverageReport(c, messages)
+}
+
+func (h *Handler) coverageReport(c echo.Context, messages []recordings.Message) error {
+	catalog, err := h.eventsOf(c).GetAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: coverage.Compute(catalog, messages),
+	})
+}
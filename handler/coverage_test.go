@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	recordingStore "github.com/nskondratev/api-page-go-back/recordings/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_GetCoverage(t *testing.T) {
+	e, h := setupCoverageHandlerTest(t)
+
+	cases := []struct {
+		sessions                  string
+		responseCode              int
+		responseBodyShouldContain string
+	}{
+		{"", http.StatusOK, `"messages":1,"catalogEvents":2,"observedEvents":1,"coverage":50,"neverObserved":["logout"]`},
+		{"1", http.StatusOK, `"events":[{"event":"login","count":1,"undocumentedFields":[],"missingRequired":[]}]`},
+		{"42", http.StatusUnprocessableEntity, `recorded session with id = 42 does not exist`},
+		{"abc", http.StatusUnprocessableEntity, `invalid session id: abc`},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/?sessions="+item.sessions, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.GetCoverage(c); err != nil {
+			t.Errorf("[%d] Fail to get coverage. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_UploadCoverage(t *testing.T) {
+	e, h := setupCoverageHandlerTest(t)
+
+	cases := []handlerCreateTestCase{
+		{`{"direction":"out","kind":"event","event":"login","data":{"extra":1}}` + "\n" + `{"direction":"in","kind":"event","event":"welcome"}`, http.StatusOK, `"undocumented":[{"name":"welcome","count":1}],"events":[{"event":"login","count":1,"undocumentedFields":[{"name":"extra","count":1}],"missingRequired":[{"name":"name","count":1}]}]`},
+		{`{"direction":"sideways"}`, http.StatusUnprocessableEntity, emptyStr},
+		{`not json`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, ndjsonContentType)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.UploadCoverage(c); err != nil {
+			t.Errorf("[%d] Fail to upload coverage. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func setupCoverageHandlerTest(t *testing.T) (*echo.Echo, *Handler) {
+	e := router.New()
+
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+	rs := recordingStore.NewMemory(&recordingStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGOUT", Value: "logout", Type: events.TypeClient},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+	newTestRecording(t, rs)
+
+	h := New(&Config{
+		Logger:         e.Logger,
+		EventStore:     es,
+		RecordingStore: rs,
+		WsHub:          ws.NewHubMock(),
+	})
+
+	return e, h
+}
@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/events"
+	"net/http"
+)
+
+func (h *Handler) GetEventsDocs(c echo.Context) error {
+	eventsList, err := h.eventsOf(c).GetAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.Blob(http.StatusOK, "text/markdown; charset=UTF-8", events.RenderMarkdown(eventsList))
+}
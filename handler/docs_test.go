@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/util"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_GetEventsDocs(t *testing.T) {
+	e, h, es := setupEventHandlerTest()
+
+	eventsToCreate := []*events.Event{
+		{Constant: "PONG", Value: "pong", Type: "frontend"},
+		{
+			Constant:        "PING",
+			Value:           "ping",
+			Type:            "client",
+			ResponseEventId: util.NewNullInt64FromInt64(1),
+			ResponseTimeout: 300,
+			AckFields:       []events.Field{{Key: util.NullString{}, Type: "boolean", Required: true}},
+		},
+	}
+
+	for _, ev := range eventsToCreate {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(emptyStr))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.GetEventsDocs(c); err != nil {
+		t.Fatalf("Fail to get events docs. Error: %s", err.Error())
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Unexpected response code. Wanted: %d, received: %d", http.StatusOK, rec.Code)
+	}
+
+	for _, shouldContain := range []string{"### `ping`", "Acknowledgement:", "Response: `pong` within 300 ms"} {
+		if !strings.Contains(rec.Body.String(), shouldContain) {
+			t.Errorf("Response body doesn't contain needed info. Wanted: %s, received: %s", shouldContain, rec.Body.String())
+		}
+	}
+}
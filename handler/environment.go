@@ -0,0 +1,253 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/environments"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"strconv"
+)
+
+// GetEnvironment responds with the environment, values of secrets are masked.
+func (h *Handler) GetEnvironment(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	e, err := h.environmentsOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if e == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: e.Masked(),
+	})
+}
+
+func (h *Handler) ListEnvironments(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	environmentsList, total, err := h.environmentsOf(c).List(offset, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	masked := make([]*environments.Environment, len(environmentsList))
+	for i, e := range environmentsList {
+		masked[i] = e.Masked()
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  masked,
+		Total: total,
+	})
+}
+
+func (h *Handler) CreateEnvironment(c echo.Context) error {
+	req := &environmentCreateRequest{}
+	e := &environments.Environment{}
+	if err := req.bind(c, e); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkEnvironmentNameIsFree(c, e); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.environmentsOf(c).Create(e); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityEnvironment,
+		EntityId:  e.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(e.Masked()),
+	})
+	wsMessage := &ws.ApEnvironmentMessage{
+		EventConst: ws.EnvironmentCreated,
+		Data: &ws.ApMessageEnvironmentEnvelope{
+			Environment: e,
+		},
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting ENVIRONMENT_CREATED to ws: %s", err.Error())
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: e.Masked(),
+	})
+}
+
+// UpdateEnvironment replaces the environment. Secrets sent back with the masked value keep the stored one.
+func (h *Handler) UpdateEnvironment(c echo.Context) error {
+	req := &environmentUpdateRequest{}
+	e := &environments.Environment{}
+	if err := req.bind(c, e); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.environmentsOf(c).GetById(e.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	if code, err := h.checkEnvironmentNameIsFree(c, e); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	e.KeepSecrets(existing)
+	before := audit.Snapshot(existing.Masked())
+	if err := h.environmentsOf(c).Update(e); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityEnvironment,
+		EntityId:  e.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionUpdate,
+		Before:    before,
+		After:     audit.Snapshot(e.Masked()),
+	})
+	wsMessage := &ws.ApEnvironmentMessage{
+		EventConst: ws.EnvironmentUpdated,
+		Data: &ws.ApMessageEnvironmentEnvelope{
+			Environment: e,
+		},
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting ENVIRONMENT_UPDATED to ws: %s", err.Error())
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: e.Masked(),
+	})
+}
+
+func (h *Handler) DeleteEnvironment(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.environmentsOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.environmentsOf(c).Delete(&environments.Environment{ID: id}); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing != nil {
+		h.record(c, &audit.Entry{
+			Entity:    audit.EntityEnvironment,
+			EntityId:  id,
+			ProjectId: h.projectIdOf(c),
+			Action:    audit.ActionDelete,
+			Before:    audit.Snapshot(existing.Masked()),
+		})
+	}
+	wsMessage := &ws.ApIdMessage{
+		EventConst: ws.EnvironmentDeleted,
+		Data: &ws.ApMessageOnlyIdEnvelope{
+			ID: id,
+		},
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting ENVIRONMENT_DELETED to ws: %s", err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) checkEnvironmentNameIsFree(c echo.Context, e *environments.Environment) (int, error) {
+	existing, err := h.environmentsOf(c).GetByName(e.Name)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if existing != nil && existing.ID != e.ID {
+		return http.StatusConflict, fmt.Errorf("environment with name %q already exists", e.Name)
+	}
+	return http.StatusOK, nil
+}
+
+// applyEnvironment fills the target, the protocol, the headers and the vars of the run from the named
+// environment and returns it. Values set in the run config take precedence, secrets of the environment
+// are not applied to an overridden target. The target is required without the environment and its host
+// must be allowed.
+func (h *Handler) applyEnvironment(c echo.Context, name string, rc *scenarios.RunConfig) (*environments.Environment, int, error) {
+	if len(name) < 1 {
+		if len(rc.Target) < 1 {
+			return nil, http.StatusUnprocessableEntity, errors.New("target or environment is required")
+		}
+		if code, err := h.checkTarget(rc.Target); err != nil {
+			return nil, code, err
+		}
+		return nil, http.StatusOK, nil
+	}
+	e, err := h.environmentsOf(c).GetByName(name)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if e == nil {
+		return nil, http.StatusUnprocessableEntity, fmt.Errorf("environment %q does not exist", name)
+	}
+	if err := rc.ApplyEnvironment(e); err != nil {
+		return nil, http.StatusUnprocessableEntity, errors.New(scenarios.MaskSecrets(err.Error(), rc.Secrets))
+	}
+	if code, err := h.checkTarget(rc.Target); err != nil {
+		return nil, code, errors.New(scenarios.MaskSecrets(err.Error(), rc.Secrets))
+	}
+	return e, http.StatusOK, nil
+}
+
+// checkTarget rejects the targets whose hosts the server is not allowed to connect to.
+func (h *Handler) checkTarget(target string) (int, error) {
+	if err := h.allowedHosts.Check(target); err != nil {
+		return http.StatusForbidden, err
+	}
+	return http.StatusOK, nil
+}
+
+// maskReportTarget replaces the target of the report taken from the environment, its query may hold secrets.
+func maskReportTarget(report *scenarios.Report, e *environments.Environment, target string) {
+	if e == nil || len(target) > 0 {
+		return
+	}
+	if masked, err := e.Masked().Target(); err == nil {
+		report.Target = masked
+	}
+}
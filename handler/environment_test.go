@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/collections"
+	collectionStore "github.com/nskondratev/api-page-go-back/collections/store"
+	"github.com/nskondratev/api-page-go-back/environments"
+	environmentStore "github.com/nskondratev/api-page-go-back/environments/store"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_CreateEnvironment(t *testing.T) {
+	e, h, ens := setupEnvironmentHandlerTest()
+
+	cases := []handlerCreateTestCase{
+		{`{"name":"stage","baseUrl":"wss://stage.example.com","headers":[{"name":"Authorization","value":"Bearer s3cr3t","secret":true}],"variables":[{"name":"user","value":"bob"}]}`, http.StatusOK, `"headers":[{"name":"Authorization","value":"********","secret":true}],"variables":[{"name":"user","value":"bob","secret":false}]`},
+		{`{"name":"stage","baseUrl":"wss://other.example.com"}`, http.StatusConflict, `environment with name \"stage\" already exists`},
+		{`{"name":"dev","baseUrl":"ws://localhost","variables":[{"value":"bob"}]}`, http.StatusUnprocessableEntity, emptyStr},
+		{`{"name":"dev","baseUrl":"ws://localhost","protocol":"mqtt"}`, http.StatusUnprocessableEntity, `oneof`},
+		{`{"name":"dev"}`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.CreateEnvironment(c); err != nil {
+			t.Errorf("[%d] Fail to create environment. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	stored, _ := ens.GetByName("stage")
+	if stored == nil || stored.Headers[0].Value != "Bearer s3cr3t" {
+		t.Fatalf("Unexpected stored environment: %+v", stored)
+	}
+	data, err := (&ws.ApEnvironmentMessage{EventConst: ws.EnvironmentCreated, Data: &ws.ApMessageEnvironmentEnvelope{Environment: stored}}).BuildWsMessage()
+	if err != nil || strings.Contains(string(data), "s3cr3t") {
+		t.Errorf("Secret is sent to ws: %s, error: %v", data, err)
+	}
+}
+
+func TestHandler_UpdateEnvironment(t *testing.T) {
+	e, h, ens := setupEnvironmentHandlerTest()
+
+	if err := ens.Create(&environments.Environment{Name: "stage", BaseUrl: "wss://stage.example.com", Headers: []*environments.Param{{Name: "Authorization", Value: "Bearer s3cr3t", Secret: true}}}); err != nil {
+		t.Fatalf("Can not create test environment: %s", err.Error())
+	}
+
+	type updateTestCase struct {
+		id string
+		handlerCreateTestCase
+	}
+	cases := []updateTestCase{
+		{"1", handlerCreateTestCase{`{"name":"staging","baseUrl":"wss://stage.example.com","headers":[{"name":"Authorization","value":"********","secret":true}]}`, http.StatusOK, `"name":"staging"`}},
+		{"2", handlerCreateTestCase{`{"name":"prod","baseUrl":"wss://example.com"}`, http.StatusNotFound, `Not found`}},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(item.id)
+
+		if err := h.UpdateEnvironment(c); err != nil {
+			t.Errorf("[%d] Fail to update environment. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	if stored, _ := ens.GetById(1); stored == nil || stored.Headers[0].Value != "Bearer s3cr3t" {
+		t.Errorf("Masked secret replaced the stored one: %+v", stored.Headers[0])
+	}
+}
+
+func TestHandler_RunSavedRequest(t *testing.T) {
+	e := router.New()
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+	cs := collectionStore.NewMemory(&collectionStore.MemoryConfig{Logger: e.Logger})
+	ens := environmentStore.NewMemory(&environmentStore.MemoryConfig{Logger: e.Logger})
+	h := New(&Config{
+		Logger:           e.Logger,
+		EventStore:       es,
+		CollectionStore:  cs,
+		EnvironmentStore: ens,
+		AllowedHosts:     socket.AllowedHosts{"127.0.0.1"},
+		WsHub:            ws.NewHubMock(),
+	})
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	if err := ens.Create(&environments.Environment{
+		Name:      "mock",
+		BaseUrl:   target.URL,
+		Query:     []*environments.Param{{Name: "token", Value: "s3cr3t", Secret: true}},
+		Variables: []*environments.Param{{Name: "user", Value: "John"}, {Name: "password", Value: "s3cr3t-password", Secret: true}},
+	}); err != nil {
+		t.Fatalf("Can not create test environment: %s", err.Error())
+	}
+	for _, sr := range []*collections.Request{
+		{EventId: 2, Name: "Valid login", Environment: "mock", Payload: util.RawJSON(`{"name":"${user}"}`), ExpectedResponse: util.RawJSON(`{"name":"name"}`)},
+		{EventId: 2, Name: "Wrong response", Environment: "mock", Payload: util.RawJSON(`{"name":"${user}"}`), ExpectedResponse: util.RawJSON(`{"name":"${user}"}`)},
+		{EventId: 2, Name: "No environment", Payload: util.RawJSON(`{"name":"John"}`)},
+		{EventId: 2, Name: "Secret login", Environment: "mock", Payload: util.RawJSON(`{"name":"${password}"}`)},
+	} {
+		if err := cs.CreateRequest(sr); err != nil {
+			t.Fatalf("Can not create test request: %s", err.Error())
+		}
+	}
+
+	cases := []struct {
+		requestId string
+		handlerCreateTestCase
+	}{
+		{"1", handlerCreateTestCase{`{}`, http.StatusOK, `"scenario":"Valid login","target":"` + target.URL + `?token=%2A%2A%2A%2A%2A%2A%2A%2A","passed":true`}},
+		{"2", handlerCreateTestCase{`{}`, http.StatusOK, `"message":"payload is {\"name\":\"name\"}, expected {\"name\":\"John\"}"`}},
+		{"3", handlerCreateTestCase{`{"target":"` + target.URL + `"}`, http.StatusOK, `"passed":true`}},
+		{"3", handlerCreateTestCase{`{"target":"ws://internal.example.com"}`, http.StatusForbidden, `target host is not allowed: internal.example.com`}},
+		{"3", handlerCreateTestCase{`{}`, http.StatusUnprocessableEntity, `target or environment is required`}},
+		{"3", handlerCreateTestCase{`{"environment":"prod"}`, http.StatusUnprocessableEntity, `environment \"prod\" does not exist`}},
+		{"4", handlerCreateTestCase{`{}`, http.StatusOK, `"passed":true`}},
+		{"4", handlerCreateTestCase{`{"target":"` + target.URL + `"}`, http.StatusOK, `"message":"invalid payload: variable password is not captured"`}},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "requestId")
+		c.SetParamValues("2", item.requestId)
+
+		if err := h.RunSavedRequest(c); err != nil {
+			t.Errorf("[%d] Fail to run saved request. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+
+		if strings.Contains(rec.Body.String(), "s3cr3t") {
+			t.Errorf("[%d] Secret is in the response: %s", caseNum, rec.Body.String())
+		}
+	}
+}
+
+func setupEnvironmentHandlerTest() (*echo.Echo, *Handler, *environmentStore.Memory) {
+	e := router.New()
+
+	ens := environmentStore.NewMemory(&environmentStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	h := New(&Config{
+		Logger:           e.Logger,
+		EnvironmentStore: ens,
+		WsHub:            ws.NewHubMock(),
+	})
+
+	return e, h, ens
+}
@@ -1,11 +1,18 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
 	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/users"
 	"github.com/nskondratev/api-page-go-back/ws"
+	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
+	"unicode"
 )
 
 func (h *Handler) GetEvent(c echo.Context) error {
@@ -15,7 +22,7 @@ func (h *Handler) GetEvent(c echo.Context) error {
 			Error: err.Error(),
 		})
 	}
-	event, err := h.eventStore.GetById(id)
+	event, err := h.eventsOf(c).GetById(id)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
 			Error: err.Error(),
@@ -48,7 +55,7 @@ func (h *Handler) ListEvents(c echo.Context) error {
 		sort = "createdAt"
 		descending = true
 	}
-	eventsList, total, err := h.eventStore.List(offset, limit, sort, descending, eType, query)
+	eventsList, total, err := h.eventsOf(c).List(offset, limit, sort, descending, eType, query)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
 			Error: err.Error(),
@@ -68,20 +75,11 @@ func (h *Handler) CreateEvent(c echo.Context) error {
 			Error: err.Error(),
 		})
 	}
-	if err := h.eventStore.Create(event); err != nil {
-		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+	if code, err := h.createEvent(c, event); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
 			Error: err.Error(),
 		})
 	}
-	wsMessage := &ws.ApEventMessage{
-		EventConst: ws.EventCreated,
-		Data: &ws.ApMessageEventEnvelope{
-			Event: event,
-		},
-	}
-	if err := h.wsHub.Broadcast(wsMessage); err != nil {
-		h.logger.Warnf("Error while broadcasting EVENT_CREATED to ws: %s", err.Error())
-	}
 	return c.JSON(http.StatusOK, &responseEnvelope{
 		Data: event,
 	})
@@ -95,23 +93,77 @@ func (h *Handler) UpdateEvent(c echo.Context) error {
 			Error: err.Error(),
 		})
 	}
-	if err := h.eventStore.Update(event); err != nil {
-		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+	if code, err := h.updateEvent(c, event); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
 			Error: err.Error(),
 		})
 	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: event,
+	})
+}
+
+// createEvent stores the new event and notifies ws clients.
+func (h *Handler) createEvent(c echo.Context, event *events.Event) (int, error) {
+	if err := h.resolveEventLinks(c, event); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	event.CreatedBy = actorOf(c)
+	event.UpdatedBy = event.CreatedBy
+	if err := h.eventsOf(c).Create(event); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityEvent,
+		EntityId:  event.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(event),
+	})
+	wsMessage := &ws.ApEventMessage{
+		EventConst: ws.EventCreated,
+		Data: &ws.ApMessageEventEnvelope{
+			Event: event,
+		},
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting EVENT_CREATED to ws: %s", err.Error())
+	}
+	return http.StatusOK, nil
+}
+
+// updateEvent stores the changed event and notifies ws clients.
+func (h *Handler) updateEvent(c echo.Context, event *events.Event) (int, error) {
+	if err := h.resolveEventLinks(c, event); err != nil {
+		return http.StatusUnprocessableEntity, err
+	}
+	stored, code, err := h.checkFieldIds(c, event)
+	if err != nil {
+		return code, err
+	}
+	before := audit.Snapshot(stored)
+	event.UpdatedBy = actorOf(c)
+	if err := h.eventsOf(c).Update(event); err != nil {
+		return http.StatusInternalServerError, err
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityEvent,
+		EntityId:  event.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionUpdate,
+		Before:    before,
+		After:     audit.Snapshot(event),
+	})
 	wsMessage := &ws.ApEventMessage{
 		EventConst: ws.EventUpdated,
 		Data: &ws.ApMessageEventEnvelope{
 			Event: event,
 		},
 	}
-	if err := h.wsHub.Broadcast(wsMessage); err != nil {
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
 		h.logger.Warnf("Error while broadcasting EVENT_UPDATED to ws: %s", err.Error())
 	}
-	return c.JSON(http.StatusOK, &responseEnvelope{
-		Data: event,
-	})
+	return http.StatusOK, nil
 }
 
 func (h *Handler) DeleteEvent(c echo.Context) error {
@@ -121,22 +173,373 @@ func (h *Handler) DeleteEvent(c echo.Context) error {
 			Error: err.Error(),
 		})
 	}
+	existing, err := h.eventsOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkEventIsNotResponse(c, id); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
 	e := &events.Event{
 		ID: id,
 	}
-	if err := h.eventStore.Delete(e); err != nil {
+	if err := h.eventsOf(c).Delete(e); err != nil {
 		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
 			Error: err.Error(),
 		})
 	}
+	if existing != nil {
+		h.record(c, &audit.Entry{
+			Entity:    audit.EntityEvent,
+			EntityId:  id,
+			ProjectId: h.projectIdOf(c),
+			Action:    audit.ActionDelete,
+			Before:    audit.Snapshot(existing),
+		})
+	}
 	wsMessage := &ws.ApIdMessage{
 		EventConst: ws.EventDeleted,
 		Data: &ws.ApMessageOnlyIdEnvelope{
 			ID: e.ID,
 		},
 	}
-	if err := h.wsHub.Broadcast(wsMessage); err != nil {
+	if existing != nil {
+		wsMessage.EventType = existing.Type
+	}
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
 		h.logger.Warnf("Error while broadcasting EVENT_DELETED to ws: %s", err.Error())
 	}
 	return c.NoContent(http.StatusOK)
 }
+
+// checkEventIsNotResponse rejects the deletion of the event linked as the response of other events.
+func (h *Handler) checkEventIsNotResponse(c echo.Context, id uint64) (int, error) {
+	linkedBy, err := h.eventsOf(c).GetByResponseEventId(id)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	values := make([]string, 0, len(linkedBy))
+	for _, e := range linkedBy {
+		if e.ID != id {
+			values = append(values, e.Value)
+		}
+	}
+	if len(values) > 0 {
+		return http.StatusConflict, fmt.Errorf("event is the response of events: %s", strings.Join(values, ", "))
+	}
+	return http.StatusOK, nil
+}
+
+// resolveEventLinks checks that the response event and the shared types referenced by e exist
+// and fills in the names of the referenced types.
+func (h *Handler) resolveEventLinks(c echo.Context, e *events.Event) error {
+	for _, list := range [][]events.Field{e.Fields, e.AckFields} {
+		for i := range list {
+			if err := h.resolveFieldType(&list[i]); err != nil {
+				return err
+			}
+		}
+	}
+	if !e.ResponseEventId.Valid {
+		return nil
+	}
+	if e.ResponseEventId.Int64 < 1 {
+		return fmt.Errorf("response event id must be positive, got %d", e.ResponseEventId.Int64)
+	}
+	responseEvent, err := h.eventsOf(c).GetById(uint64(e.ResponseEventId.Int64))
+	if err != nil {
+		return err
+	}
+	if responseEvent == nil {
+		return fmt.Errorf("response event with id = %d does not exist", e.ResponseEventId.Int64)
+	}
+	return nil
+}
+
+func (h *Handler) resolveFieldType(f *events.Field) error {
+	if !f.TypeId.Valid {
+		return nil
+	}
+	t, err := h.typeStore.GetById(uint64(f.TypeId.Int64))
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return fmt.Errorf("type with id = %d does not exist", f.TypeId.Int64)
+	}
+	f.Type = t.Name
+	return nil
+}
+
+type payloadValidationResponse struct {
+	Valid  bool                      `json:"valid"`
+	Errors []*events.ValidationError `json:"errors"`
+}
+
+// ValidateEventPayload checks the request body against the fields of the event, or its ack fields when ack=true.
+func (h *Handler) ValidateEventPayload(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	body, err := ioutil.ReadAll(c.Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	fields := event.Fields
+	if c.QueryParam("ack") == "true" {
+		fields = event.AckFields
+	}
+	types, err := events.ResolveTypes(h.typeStore, fields)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	errs := events.ValidateRawPayload(fields, types, body)
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: &payloadValidationResponse{
+			Valid:  len(errs) < 1,
+			Errors: errs,
+		},
+	})
+}
+
+// GetEventExample generates a payload of the event, or of its ack when ack=true.
+func (h *Handler) GetEventExample(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	fields := event.Fields
+	if c.QueryParam("ack") == "true" {
+		fields = event.AckFields
+	}
+	types, err := events.ResolveTypes(h.typeStore, fields)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: events.GenerateExample(fields, types),
+	})
+}
+
+func (h *Handler) eventFromParam(c echo.Context) (*events.Event, int, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	event, err := h.eventsOf(c).GetById(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if event == nil {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	return event, http.StatusOK, nil
+}
+
+// PatchEventField updates a single field of the event keeping its ID.
+func (h *Handler) PatchEventField(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	fieldId, err := strconv.ParseUint(c.Param("fieldId"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	field := findField(event, fieldId)
+	if field == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	before := audit.Snapshot(field)
+	req := &fieldPatchRequest{}
+	if err := req.bind(c, field); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.resolveFieldType(field); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.eventsOf(c).UpdateField(field); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityField,
+		EntityId:  field.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionUpdate,
+		Before:    before,
+		After:     audit.Snapshot(field),
+	})
+	if event, err = h.eventsOf(c).GetById(event.ID); err == nil && event != nil {
+		wsMessage := &ws.ApEventMessage{
+			EventConst: ws.EventUpdated,
+			Data: &ws.ApMessageEventEnvelope{
+				Event: event,
+			},
+		}
+		if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
+			h.logger.Warnf("Error while broadcasting EVENT_UPDATED to ws: %s", err.Error())
+		}
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: field,
+	})
+}
+
+// checkFieldIds verifies that the fields of the updated event with IDs already belong to the stored event
+// and returns the stored event.
+func (h *Handler) checkFieldIds(c echo.Context, e *events.Event) (*events.Event, int, error) {
+	stored, err := h.eventsOf(c).GetById(e.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if stored == nil {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	for _, list := range [][]events.Field{e.Fields, e.AckFields} {
+		for _, f := range list {
+			if f.ID != 0 && findField(stored, f.ID) == nil {
+				return nil, http.StatusUnprocessableEntity, fmt.Errorf("field with id = %d does not belong to the event", f.ID)
+			}
+		}
+	}
+	return stored, http.StatusOK, nil
+}
+
+// findField returns the pointer to a copy of the payload or ack field with the id.
+func findField(e *events.Event, id uint64) *events.Field {
+	for _, list := range [][]events.Field{e.Fields, e.AckFields} {
+		for _, f := range list {
+			if f.ID == id {
+				return &f
+			}
+		}
+	}
+	return nil
+}
+
+type eventInferResponse struct {
+	Event    *events.Event `json:"event"`
+	Existing *events.Event `json:"existing"`
+	Diff     *events.Diff  `json:"diff"`
+	Applied  bool          `json:"applied"`
+}
+
+// InferEvent proposes the event with the fields inferred from the sample payloads, or ack payloads when ack is set.
+// The stored event with the same value is the base of the proposal and is compared with it. The proposal is
+// created or updated like a regular event when apply is set.
+func (h *Handler) InferEvent(c echo.Context) error {
+	req := &eventInferRequest{}
+	samples, err := req.bind(c)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	list, err := h.eventsOf(c).GetAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	var existing *events.Event
+	for _, e := range list {
+		if e.Value == req.Value {
+			existing = e
+			break
+		}
+	}
+	proposal := proposeEvent(req, existing, events.InferFields(samples))
+	from := make([]*events.Event, 0, 1)
+	if existing != nil {
+		from = append(from, existing)
+	}
+	res := &eventInferResponse{
+		Event:    proposal,
+		Existing: existing,
+		Diff:     events.Compare(from, []*events.Event{proposal}, nil, nil),
+	}
+	if req.Apply {
+		// The catalog is changed only by the writers, the proposal is open to the readers
+		if err := h.checkInProject(c, users.PermEventsWrite); err != nil {
+			return forbidden(c, users.PermEventsWrite, err)
+		}
+		withSource(c, audit.SourceImport)
+		code := http.StatusOK
+		if existing == nil {
+			code, err = h.createEvent(c, proposal)
+		} else {
+			code, err = h.updateEvent(c, proposal)
+		}
+		if err != nil {
+			return c.JSON(code, &errorResponseEnvelope{
+				Error: err.Error(),
+			})
+		}
+		res.Applied = true
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: res,
+	})
+}
+
+func proposeEvent(req *eventInferRequest, existing *events.Event, inferred []events.Field) *events.Event {
+	p := &events.Event{
+		Constant:    constantOf(req.Value),
+		Value:       req.Value,
+		Description: fmt.Sprintf("Inferred from %d samples", len(req.Samples)),
+		Type:        events.TypeFrontend,
+		Fields:      make([]events.Field, 0),
+		AckFields:   make([]events.Field, 0),
+	}
+	if existing != nil {
+		*p = *existing
+		p.Fields = append([]events.Field{}, existing.Fields...)
+		p.AckFields = append([]events.Field{}, existing.AckFields...)
+	}
+	if len(req.Type) > 0 {
+		p.Type = req.Type
+	}
+	if req.Ack {
+		p.AckFields = events.MergeFields(p.AckFields, inferred)
+	} else {
+		p.Fields = events.MergeFields(p.Fields, inferred)
+	}
+	return p
+}
+
+// constantOf converts the event value to the upper snake case constant.
+func constantOf(value string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, value), "_")
+}
@@ -2,10 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/labstack/echo"
 	"github.com/nskondratev/api-page-go-back/events"
 	"github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/registry"
+	registryStore "github.com/nskondratev/api-page-go-back/registry/store"
 	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/socket"
 	"github.com/nskondratev/api-page-go-back/testutils"
 	"github.com/nskondratev/api-page-go-back/util"
 	"github.com/nskondratev/api-page-go-back/ws"
@@ -20,7 +24,14 @@ func TestHandler_CreateEvent(t *testing.T) {
 	e, h, _ := setupEventHandlerTest()
 
 	cases := []handlerCreateTestCase{
-		{`{"constant":"Constant 1","value":"Value 1","label":"Label 1","description":"Description 1","type":"frontend"}`, http.StatusOK, `{"id":1,"constant":"Constant 1","label":"Label 1","value":"Value 1","description":"Description 1","type":"frontend","fields":[],"createdAt":`},
+		{`{"constant":"Constant 1","value":"Value 1","label":"Label 1","description":"Description 1","type":"frontend"}`, http.StatusOK, `{"id":1,"projectId":1,"constant":"Constant 1","label":"Label 1","value":"Value 1","description":"Description 1","type":"frontend","fields":[],"ackFields":[],"responseEventId":null,"responseTimeout":0,"createdBy":null,"updatedBy":null,"createdAt":`},
+		{`{"constant":"Constant 2","value":"Value 2","description":"Description 2","type":"client","ackFields":[{"key":"ok","type":"boolean","required":true,"description":"Ok"}],"responseEventId":1,"responseTimeout":500}`, http.StatusOK, `"ackFields":[{"id":1,"eventId":2,"type":"boolean","typeId":null,"key":"ok","required":true,"description":"Ok","minimum":null,"maximum":null,"minLength":null,"maxLength":null,"pattern":"","format":"","enum":null,"default":null,"createdAt"`},
+		{`{"constant":"Constant 3","value":"Value 3","description":"Description 3","type":"client","responseEventId":42}`, http.StatusUnprocessableEntity, `response event with id = 42 does not exist`},
+		{`{"constant":"Constant 4","value":"Value 4","description":"Description 4","type":"client","fields":[{"key":"id","type":"string","required":true,"description":"Id","format":"uuid","minLength":36,"maxLength":36,"default":"123e4567-e89b-12d3-a456-426614174000"}]}`, http.StatusOK, `"minLength":36,"maxLength":36,"pattern":"","format":"uuid","enum":null,"default":"123e4567-e89b-12d3-a456-426614174000"`},
+		{`{"constant":"Constant 5","value":"Value 5","description":"Description 5","type":"client","fields":[{"key":"age","type":"integer","minimum":10,"maximum":1}]}`, http.StatusUnprocessableEntity, `field age: minimum is greater than maximum`},
+		{`{"constant":"Constant 6","value":"Value 6","description":"Description 6","type":"client","fields":[{"key":"id","type":"string","format":"ipv4"}]}`, http.StatusUnprocessableEntity, `field id: unknown format \"ipv4\"`},
+		{`{"constant":"Constant 7","value":"Value 7","description":"Description 7","type":"client","fields":[{"key":"status","type":"string","enum":["on","off"],"default":"idle"}]}`, http.StatusUnprocessableEntity, `field status: default value does not match the field`},
+		{`{"constant":"Constant 8","value":"Value 8","description":"Description 8","type":"client","fields":[{"key":"name","type":"string","minLength":1099511627776}]}`, http.StatusUnprocessableEntity, `field name: length limits must not exceed 65536`},
 		{`{"constant":"Constant 1","value":"Value 1","label":"Label 1,"description":"Description 1}`, http.StatusUnprocessableEntity, emptyStr},
 		{`{"value":"Value 1"}`, http.StatusUnprocessableEntity, emptyStr},
 	}
@@ -67,7 +78,7 @@ func TestHandler_GetEvent(t *testing.T) {
 	}
 
 	cases := []handlerGetTestCase{
-		{"1", http.StatusOK, `"id":1,"constant":"Constant 1","label":"Label 1","value":"Value 1","description":"Description 1","type":"frontend","fields":null,"createdAt"`},
+		{"1", http.StatusOK, `"id":1,"projectId":1,"constant":"Constant 1","label":"Label 1","value":"Value 1","description":"Description 1","type":"frontend","fields":null,"ackFields":null,"responseEventId":null,"responseTimeout":0,"createdBy":null,"updatedBy":null,"createdAt"`},
 		{"badparam", http.StatusUnprocessableEntity, emptyStr},
 		{"45", http.StatusNotFound, `"error":"Not found"`},
 	}
@@ -114,8 +125,18 @@ func TestHandler_DeleteEvent(t *testing.T) {
 	}); err != nil {
 		t.Fatalf("Can not create test event: %s", err.Error())
 	}
+	if err := es.Create(&events.Event{
+		Constant:        "Constant 2",
+		Value:           "Value 2",
+		Type:            "client",
+		ResponseEventId: util.NewNullInt64FromInt64(1),
+	}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
 
 	cases := []handlerDeleteTestCase{
+		{"1", http.StatusConflict, "event is the response of events: Value 2"},
+		{"2", http.StatusOK, emptyStr},
 		{"1", http.StatusOK, emptyStr},
 		{"badparam", http.StatusUnprocessableEntity, emptyStr},
 	}
@@ -164,7 +185,11 @@ func TestHandler_UpdateEvent(t *testing.T) {
 	}
 
 	cases := []handlerUpdateTestCase{
-		{"1", `{"constant":"Constant 1 updated","value":"Value 1 updated","label":"Label 1","description":"Description 1","type":"frontend"}`, http.StatusOK, `"id":1,"constant":"Constant 1 updated","label":"Label 1","value":"Value 1 updated","description":"Description 1","type":"frontend","fields":[],"createdAt"`},
+		{"1", `{"constant":"Constant 1 updated","value":"Value 1 updated","label":"Label 1","description":"Description 1","type":"frontend"}`, http.StatusOK, `"id":1,"projectId":1,"constant":"Constant 1 updated","label":"Label 1","value":"Value 1 updated","description":"Description 1","type":"frontend","fields":[],"ackFields":[],"responseEventId":null,"responseTimeout":0,"createdBy":null,"updatedBy":null,"createdAt"`},
+		{"1", `{"constant":"Constant 1","value":"Value 1","description":"Description 1","type":"frontend","responseEventId":1,"responseTimeout":1000}`, http.StatusOK, `"responseEventId":1,"responseTimeout":1000`},
+		{"1", `{"constant":"Constant 1","value":"Value 1","description":"Description 1","type":"frontend","responseEventId":2}`, http.StatusUnprocessableEntity, `response event with id = 2 does not exist`},
+		{"1", `{"constant":"Constant 1","value":"Value 1","description":"Description 1","type":"frontend","fields":[{"id":7,"key":"id","type":"string"}]}`, http.StatusUnprocessableEntity, `field with id = 7 does not belong to the event`},
+		{"5", `{"constant":"Constant 1","value":"Value 1","description":"Description 1","type":"frontend"}`, http.StatusNotFound, emptyStr},
 		{"badparam", `{"constant":"Constant 1 updated","value":"Value 1 updated","label":"Label 1","description":"Description 1","type":"frontend"}`, http.StatusUnprocessableEntity, emptyStr},
 		{"1", `{"constant":"Constant 1 updated","value":"Value 1 updated","label":"Label 1"}`, http.StatusUnprocessableEntity, emptyStr},
 		{"1", `{"constant":"Constant 1 updated","value":"Value 1 updated","label":"Label 1","description":"Description 1","type":"frontend}`, http.StatusUnprocessableEntity, emptyStr},
@@ -317,8 +342,327 @@ func TestHandler_ListEvents(t *testing.T) {
 	}
 }
 
+func TestHandler_ValidateEventPayload(t *testing.T) {
+	e, h, es := setupEventHandlerTest()
+
+	createConstrainedTestEvent(t, es)
+
+	cases := []handlerUpdateTestCase{
+		{"1", `{"id":"123e4567-e89b-12d3-a456-426614174000","age":30,"status":"online","user":{"email":"user@example.com"},"tags":["a"]}`, http.StatusOK, `{"valid":true,"errors":[]}`},
+		{"1", `{"age":30}`, http.StatusOK, `{"path":"id","message":"required key is missing"}`},
+		{"1", `{"id":"not-uuid","age":30}`, http.StatusOK, `{"path":"id","message":"value is not a valid uuid"}`},
+		{"1", `{"id":"123e4567-e89b-12d3-a456-426614174000","age":130}`, http.StatusOK, `{"path":"age","message":"value 130 is greater than maximum 120"}`},
+		{"1", `{"id":"123e4567-e89b-12d3-a456-426614174000","age":"30"}`, http.StatusOK, `{"path":"age","message":"expected integer, got string"}`},
+		{"1", `{"id":"123e4567-e89b-12d3-a456-426614174000","status":"away"}`, http.StatusOK, `{"path":"status","message":"value away is not one of [online, offline]"}`},
+		{"1", `{"id":"123e4567-e89b-12d3-a456-426614174000","user":{}}`, http.StatusOK, `{"path":"user.email","message":"required key is missing"}`},
+		{"1", `{"id":"123e4567-e89b-12d3-a456-426614174000","tags":["a","toolongtag"]}`, http.StatusOK, `{"path":"tags[1]","message":"length 10 is greater than maxLength 5"}`},
+		{"1", `{"id":`, http.StatusOK, `payload is not a valid JSON`},
+		{"2", `{}`, http.StatusNotFound, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/events/:id/validate")
+		c.SetParamNames("id")
+		c.SetParamValues(item.id)
+
+		if err := h.ValidateEventPayload(c); err != nil {
+			t.Errorf("[%d] Fail to validate payload. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_GetEventExample(t *testing.T) {
+	e, h, es := setupEventHandlerTest()
+
+	createConstrainedTestEvent(t, es)
+
+	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(emptyStr))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/events/:id/example")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.GetEventExample(c); err != nil {
+		t.Fatalf("Fail to get example. Error: %s", err.Error())
+	}
+
+	expected := `{"data":{"age":69,"id":"123e4567-e89b-12d3-a456-426614174000","status":"online","tags":["tags"],"user":{"email":"user@example.com"}}}`
+
+	if strings.TrimSpace(rec.Body.String()) != expected {
+		t.Errorf("Example mismatch. Wanted: %s, received: %s", expected, rec.Body.String())
+	}
+
+	event, _ := es.GetById(1)
+	example, _ := json.Marshal(events.GenerateExample(event.Fields, nil))
+
+	if errs := events.ValidateRawPayload(event.Fields, nil, example); len(errs) > 0 {
+		t.Errorf("Generated example does not pass validation: %v", errs[0])
+	}
+}
+
+func TestHandler_ValidateEventPayloadSharedTypes(t *testing.T) {
+	e, h, es := setupEventHandlerTest()
+
+	createSharedTypesTestEvent(t, h, es)
+
+	cases := []handlerUpdateTestCase{
+		{"1", `{"status":"online","node":{"name":"root","child":{"name":"leaf"}}}`, http.StatusOK, `{"valid":true,"errors":[]}`},
+		{"1", `{"status":"away","node":{"name":"root"}}`, http.StatusOK, `{"path":"status","message":"value away is not one of Status [online, offline]"}`},
+		{"1", `{"status":"online","node":"root"}`, http.StatusOK, `{"path":"node","message":"expected Node, got string"}`},
+		{"1", `{"status":"online","node":{"child":{"name":"leaf"}}}`, http.StatusOK, `{"path":"node.name","message":"required key is missing"}`},
+		{"1", `{"status":"online","node":{"name":"root","child":{"name":1}}}`, http.StatusOK, `{"path":"node.child.name","message":"expected string, got number"}`},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/events/:id/validate")
+		c.SetParamNames("id")
+		c.SetParamValues(item.id)
+
+		if err := h.ValidateEventPayload(c); err != nil {
+			t.Errorf("[%d] Fail to validate payload. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_GetEventExampleSharedTypes(t *testing.T) {
+	e, h, es := setupEventHandlerTest()
+
+	createSharedTypesTestEvent(t, h, es)
+
+	req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(emptyStr))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/events/:id/example")
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.GetEventExample(c); err != nil {
+		t.Fatalf("Fail to get example. Error: %s", err.Error())
+	}
+
+	expected := `{"data":{"node":{"name":"name"},"status":"online"}}`
+
+	if strings.TrimSpace(rec.Body.String()) != expected {
+		t.Errorf("Example mismatch. Wanted: %s, received: %s", expected, rec.Body.String())
+	}
+}
+
+func TestHandler_PatchEventField(t *testing.T) {
+	e, h, es := setupEventHandlerTest()
+
+	createConstrainedTestEvent(t, es)
+
+	cases := []struct {
+		id, fieldId               string
+		inputData                 string
+		responseCode              int
+		responseBodyShouldContain string
+	}{
+		{"1", "2", `{"description":"Age in years","maximum":null}`, http.StatusOK, `"id":2,"eventId":1,"type":"integer","typeId":null,"key":"age","required":false,"description":"Age in years","minimum":18,"maximum":null`},
+		{"1", "2", `{"minimum":200,"maximum":100}`, http.StatusUnprocessableEntity, `minimum is greater than maximum`},
+		{"1", "2", `{"typeId":5}`, http.StatusUnprocessableEntity, emptyStr},
+		{"1", "2", `{"key":""}`, http.StatusUnprocessableEntity, `key is required`},
+		{"1", "2", `{"key":" "}`, http.StatusUnprocessableEntity, `key is required`},
+		{"1", "2", `{"type":""}`, http.StatusUnprocessableEntity, `type is required`},
+		{"1", "42", `{"description":"Unknown"}`, http.StatusNotFound, emptyStr},
+		{"3", "1", `{"description":"Unknown"}`, http.StatusNotFound, emptyStr},
+		{"1", "bad", `{"description":"Unknown"}`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/events/:id/fields/:fieldId")
+		c.SetParamNames("id", "fieldId")
+		c.SetParamValues(item.id, item.fieldId)
+
+		if err := h.PatchEventField(c); err != nil {
+			t.Errorf("[%d] Fail to patch field. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	event, _ := es.GetById(1)
+
+	if event.Fields[1].ID != 2 || event.Fields[1].Description != "Age in years" || event.Fields[0].ID != 1 {
+		t.Errorf("Field was not patched in place: %+v", event.Fields)
+	}
+}
+
+func TestHandler_InferEvent(t *testing.T) {
+	e, h, es := setupEventHandlerTest()
+	createConstrainedTestEvent(t, es)
+
+	cases := []handlerCreateTestCase{
+		{`{"value":"chat message","samples":[{"text":"hi","user":{"id":1},"tags":["a"]},{"text":"yo","meta":null,"items":[{"id":1},{"id":2,"sku":"x"}]}]}`, http.StatusOK, `"constant":"CHAT_MESSAGE","label":null,"value":"chat message","description":"Inferred from 2 samples","type":"frontend"`},
+		{`{"value":"chat message","samples":[{"text":"hi","user":{"id":1},"tags":["a"]},{"text":"yo","meta":null,"items":[{"id":1},{"id":2,"sku":"x"}]}]}`, http.StatusOK, `"existing":null,"diff":{"breaking":false,"changes":[{"kind":"event_added","breaking":false,"event":"chat message"}]},"applied":false`},
+		{`{"value":"user","samples":[{"id":"123e4567-e89b-12d3-a456-426614174000","age":30,"status":"online","extra":true}]}`, http.StatusOK, `"diff":{"breaking":true,"changes":[{"kind":"field_became_required","breaking":true,"event":"user","field":"age"},{"kind":"field_added","breaking":true,"event":"user","field":"extra","to":"boolean"},{"kind":"field_became_required","breaking":true,"event":"user","field":"status"}]}`},
+		{`{"value":"user","type":"client","ack":true,"samples":[true]}`, http.StatusOK, `"ackFields":[{"id":0,"eventId":0,"type":"boolean","typeId":null,"key":"","required":true`},
+		{`{"value":"user","samples":[]}`, http.StatusUnprocessableEntity, emptyStr},
+		{`{"samples":[{}]}`, http.StatusUnprocessableEntity, emptyStr},
+		{`{"value":"user","type":"server","samples":[{}]}`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.InferEvent(c); err != nil {
+			t.Errorf("[%d] Fail to infer event. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_InferEventFields(t *testing.T) {
+	e, h, _ := setupEventHandlerTest()
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"value":"chat","apply":true,"samples":[{"text":"hi","user":{"id":1},"tags":["a"]},{"text":"yo","meta":null,"items":[{"id":1},{"id":"2","sku":"x"}]}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.InferEvent(c); err != nil {
+		t.Fatalf("Fail to infer event. Error: %s", err.Error())
+	}
+
+	res := &struct {
+		Data eventInferResponse `json:"data"`
+	}{}
+	if err := json.Unmarshal(rec.Body.Bytes(), res); err != nil {
+		t.Fatalf("Can not decode response: %s, body: %s", err.Error(), rec.Body.String())
+	}
+
+	want := []string{"items array false", "items[].id mixed true", "items[].sku string false", "meta null false", "tags array false", "tags[] string false", "text string true", "user object false", "user.id number true"}
+	got := make([]string, 0)
+	for _, f := range res.Data.Event.Fields {
+		got = append(got, fmt.Sprintf("%s %s %t", f.Key.String, f.Type, f.Required))
+	}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Unexpected inferred fields. Want: %v, received: %v", want, got)
+	}
+
+	if !res.Data.Applied || res.Data.Event.ID != 1 {
+		t.Fatalf("Proposal was not applied: %s", rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"value":"chat","apply":true,"samples":[{"text":"hi"}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+
+	if err := h.InferEvent(c); err != nil {
+		t.Fatalf("Fail to infer event. Error: %s", err.Error())
+	}
+
+	stored, _ := h.eventStore.GetById(1)
+	got = make([]string, 0)
+	for _, f := range stored.Fields {
+		got = append(got, fmt.Sprintf("%d %s %t", f.ID, f.Key.String, f.Required))
+	}
+	want = []string{"1 items false", "2 items[].id true", "3 items[].sku false", "4 meta false", "5 tags false", "6 tags[] false", "7 text true", "8 user false", "9 user.id true"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Proposal was not applied to the stored event keeping the field ids. Want: %v, received: %v", want, got)
+	}
+}
+
 // Utility functions
 
+func createConstrainedTestEvent(t *testing.T, es events.Store) {
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"id", "age", "status", "user", "user.email", "tags[]"})
+
+	if err != nil {
+		t.Fatalf("Can not create keys from strings: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "USER", Value: "user", Type: "client", Fields: []events.Field{
+		{Key: keys[0], Type: "string", Required: true, Format: events.FormatUUID},
+		{Key: keys[1], Type: "integer", Minimum: util.NewNullFloat64FromFloat64(18), Maximum: util.NewNullFloat64FromFloat64(120)},
+		{Key: keys[2], Type: "string", Enum: util.StringList{"online", "offline"}},
+		{Key: keys[3], Type: "object"},
+		{Key: keys[4], Type: "string", Required: true, Format: events.FormatEmail},
+		{Key: keys[5], Type: "string", MaxLength: util.NewNullInt64FromInt64(5)},
+	}}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+}
+
+func createSharedTypesTestEvent(t *testing.T, h *Handler, es events.Store) {
+	typeKeys, err := testutils.NewArrayNullStringFromStrings([]string{"name", "child"})
+
+	if err != nil {
+		t.Fatalf("Can not create keys from strings: %s", err.Error())
+	}
+
+	if err := h.typeStore.Create(&registry.Type{Name: "Status", Kind: registry.KindEnum, Values: []registry.EnumValue{
+		{Value: "online"},
+		{Value: "offline"},
+	}}); err != nil {
+		t.Fatalf("Can not create test type: %s", err.Error())
+	}
+
+	if err := h.typeStore.Create(&registry.Type{Name: "Node", Kind: registry.KindObject, Fields: []registry.TypeField{
+		{Key: typeKeys[0], Type: "string", Required: true},
+		{Key: typeKeys[1], Type: "Node"},
+	}}); err != nil {
+		t.Fatalf("Can not create test type: %s", err.Error())
+	}
+
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"status", "node"})
+
+	if err != nil {
+		t.Fatalf("Can not create keys from strings: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "NODE", Value: "node", Type: "client", Fields: []events.Field{
+		{Key: keys[0], Type: "Status", Required: true},
+		{Key: keys[1], Type: "Node", Required: true},
+	}}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+}
+
 func setupEventHandlerTest() (*echo.Echo, *Handler, *store.Memory) {
 	e := router.New()
 
@@ -327,9 +671,11 @@ func setupEventHandlerTest() (*echo.Echo, *Handler, *store.Memory) {
 	})
 
 	h := New(&Config{
-		Logger:     e.Logger,
-		EventStore: es,
-		WsHub:      ws.NewHubMock(),
+		Logger:       e.Logger,
+		EventStore:   es,
+		TypeStore:    registryStore.NewMemory(&registryStore.MemoryConfig{Logger: e.Logger}),
+		AllowedHosts: socket.AllowedHosts{"127.0.0.1"},
+		WsHub:        ws.NewHubMock(),
 	})
 
 	return e, h, es
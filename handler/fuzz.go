@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/fuzz"
+	"net/http"
+	"strconv"
+)
+
+// ListFuzzRuns lists the fuzz runs of the event newest first, without their reports.
+func (h *Handler) ListFuzzRuns(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	runsList, total, err := h.fuzzRunsOf(c).List(offset, limit, event.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  runsList,
+		Total: total,
+	})
+}
+
+func (h *Handler) GetFuzzRun(c echo.Context) error {
+	run, code, err := h.fuzzRunFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: run,
+	})
+}
+
+// FuzzEvent starts sending mutations of the event payload to the target in the background. The run is
+// stored with the running status and gets the report with the outcome of each mutation and minimized
+// reproducers of the failures when it is over.
+func (h *Handler) FuzzEvent(c echo.Context) error {
+	event, code, err := h.eventFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if event.Type != events.TypeClient {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: fmt.Sprintf("event %s is not emitted by clients", event.Value),
+		})
+	}
+	req := &eventFuzzRequest{}
+	fc := &fuzz.Config{}
+	if err := req.bind(c, fc); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkTarget(fc.Target); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if fc.Catalog, err = h.eventsOf(c).GetAll(); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if fc.Types, err = events.ResolveEventTypes(h.typeStore, event); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	run, store := fuzz.NewRun(event, fc), h.fuzzRunsOf(c)
+	if err := store.Create(run); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityFuzzRun,
+		EntityId:  run.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(run.Summary()),
+	})
+	// The run is finished on a copy, the response encodes the created one
+	finished := *run
+	go func() {
+		if err := fuzz.Finish(store, &finished, event, fc); err != nil {
+			h.logger.Warnf("Error while storing report of fuzz run %d: %s", finished.ID, err.Error())
+		}
+	}()
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: run,
+	})
+}
+
+func (h *Handler) DeleteFuzzRun(c echo.Context) error {
+	run, code, err := h.fuzzRunFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if run.Status == fuzz.StatusRunning {
+		return c.JSON(http.StatusConflict, &errorResponseEnvelope{
+			Error: "fuzz run is running",
+		})
+	}
+	if err := h.fuzzRunsOf(c).Delete(run); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityFuzzRun,
+		EntityId:  run.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionDelete,
+		Before:    audit.Snapshot(run.Summary()),
+	})
+	return c.NoContent(http.StatusOK)
+}
+
+// fuzzRunFromParam returns the fuzz run of the runId param belonging to the event of the id param.
+func (h *Handler) fuzzRunFromParam(c echo.Context) (*fuzz.Run, int, error) {
+	eventId, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	id, err := strconv.ParseUint(c.Param("runId"), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	run, err := h.fuzzRunsOf(c).GetById(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if run == nil || run.EventId != eventId {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	return run, http.StatusOK, nil
+}
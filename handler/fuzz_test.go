@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	auditStore "github.com/nskondratev/api-page-go-back/audit/store"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/fuzz"
+	fuzzStore "github.com/nskondratev/api-page-go-back/fuzz/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	registryStore "github.com/nskondratev/api-page-go-back/registry/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_FuzzEvent(t *testing.T) {
+	e, h, es, fzs := setupFuzzHandlerTest()
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	cases := []struct {
+		id                        string
+		inputData                 string
+		responseCode              int
+		responseBodyShouldContain string
+		reportShouldContain       string
+	}{
+		{"2", `{"target":"` + target.URL + `","kinds":["valid","missing"],"timeoutMs":500}`, http.StatusOK, `"eventId":2,"event":"login","target":"` + target.URL + `","protocol":"socketio","kinds":["valid","missing"],"status":"running"`, `"failures":1,"results":[{"name":"valid","kind":"valid","key":"","data":{"name":"name"},"outcome":"ok"`},
+		{"2", `{"target":"` + target.URL + `","kinds":["missing"],"timeoutMs":500}`, http.StatusOK, `"status":"running"`, `"name":"missing name","kind":"missing","key":"name","data":{},"outcome":"error-reply","message":"mock_error {\"event\":\"login\",\"message\":\"payload does not match the event fields\"`},
+		{"2", `{"target":"` + target.URL + `","kinds":["broken"]}`, http.StatusUnprocessableEntity, `oneof`, emptyStr},
+		{"2", `{"target":"` + target.URL + `","timeoutMs":3600000}`, http.StatusUnprocessableEntity, `TimeoutMs`, emptyStr},
+		{"2", `{"target":"` + target.URL + `","quietMs":3600000}`, http.StatusUnprocessableEntity, `QuietMs`, emptyStr},
+		{"2", `{}`, http.StatusUnprocessableEntity, emptyStr, emptyStr},
+		{"2", `{"target":"ws://internal.example.com"}`, http.StatusForbidden, `target host is not allowed: internal.example.com`, emptyStr},
+		{"1", `{"target":"` + target.URL + `"}`, http.StatusUnprocessableEntity, `event user is not emitted by clients`, emptyStr},
+		{"3", `{"target":"` + target.URL + `"}`, http.StatusNotFound, `Not found`, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(item.id)
+
+		if err := h.FuzzEvent(c); err != nil {
+			t.Errorf("[%d] Fail to fuzz event. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+
+		if len(item.reportShouldContain) < 1 {
+			continue
+		}
+		created := &struct {
+			Data *fuzz.Run `json:"data"`
+		}{}
+		if err := json.Unmarshal(rec.Body.Bytes(), created); err != nil || created.Data == nil {
+			t.Fatalf("[%d] Can not parse the created run: %v", caseNum, err)
+		}
+		run := waitFuzzRun(t, fzs, created.Data.ID)
+		report, _ := json.Marshal(run.Report)
+		if run.Status != fuzz.StatusFinished || !strings.Contains(string(report), item.reportShouldContain) {
+			t.Errorf("[%d] Unexpected finished run: %+v, report: %s", caseNum, run, report)
+		}
+	}
+}
+
+func TestHandler_FuzzRuns(t *testing.T) {
+	e, h, es, fzs := setupFuzzHandlerTest()
+
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+	for _, run := range []*fuzz.Run{
+		{EventId: 2, Event: "login", Status: fuzz.StatusFinished, Failures: 1, Report: &fuzz.Report{Event: "login", Failures: 1}},
+		{EventId: 2, Event: "login", Status: fuzz.StatusRunning},
+		{EventId: 4, Event: "logout", Status: fuzz.StatusFinished},
+	} {
+		if err := fzs.Create(run); err != nil {
+			t.Fatalf("Can not create test run: %s", err.Error())
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues("2")
+	if err := h.ListFuzzRuns(c); err != nil {
+		t.Fatalf("Fail to list fuzz runs. Error: %s", err.Error())
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `"total":2`) || !strings.Contains(body, `{"id":2,"projectId":1,"eventId":2`) || strings.Contains(body, `"report"`) {
+		t.Errorf("Unexpected list of fuzz runs: %s", body)
+	}
+
+	cases := []struct {
+		method                    string
+		eventId                   string
+		runId                     string
+		responseCode              int
+		responseBodyShouldContain string
+	}{
+		{http.MethodGet, "2", "1", http.StatusOK, `"failures":1,"report":{"event":"login"`},
+		{http.MethodGet, "2", "3", http.StatusNotFound, `Not found`},
+		{http.MethodGet, "2", "x", http.StatusUnprocessableEntity, emptyStr},
+		{http.MethodDelete, "2", "2", http.StatusConflict, `fuzz run is running`},
+		{http.MethodDelete, "2", "1", http.StatusOK, emptyStr},
+		{http.MethodGet, "2", "1", http.StatusNotFound, `Not found`},
+	}
+
+	for caseNum, item := range cases {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(item.method, "/", nil), rec)
+		c.SetParamNames("id", "runId")
+		c.SetParamValues(item.eventId, item.runId)
+
+		var err error
+		if item.method == http.MethodDelete {
+			err = h.DeleteFuzzRun(c)
+		} else {
+			err = h.GetFuzzRun(c)
+		}
+		if err != nil {
+			t.Errorf("[%d] Fail to handle fuzz run. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	entries, total, err := h.auditStore.List(&audit.Filter{Entity: audit.EntityFuzzRun}, 0, 10)
+	if err != nil || total != 1 || entries[0].Action != audit.ActionDelete || entries[0].EntityId != 1 {
+		t.Fatalf("Unexpected audit entries of fuzz runs: %v, %+v", err, entries)
+	}
+	// The report is not copied to the entry
+	if before := string(entries[0].Before); !strings.Contains(before, `"failures":1`) || strings.Contains(before, `"report"`) {
+		t.Errorf("Unexpected audit entry of the deleted run: %s", before)
+	}
+}
+
+func waitFuzzRun(t *testing.T, fzs fuzz.Store, id uint64) *fuzz.Run {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		run, err := fzs.GetById(id)
+		if err != nil || run == nil {
+			t.Fatalf("Can not get fuzz run %d: %v", id, err)
+		}
+		if run.Status != fuzz.StatusRunning {
+			return run
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Fuzz run %d is not finished", id)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func setupFuzzHandlerTest() (*echo.Echo, *Handler, *eventStore.Memory, *fuzzStore.Memory) {
+	e := router.New()
+
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	fzs := fuzzStore.NewMemory(&fuzzStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	h := New(&Config{
+		Logger:       e.Logger,
+		EventStore:   es,
+		TypeStore:    registryStore.NewMemory(&registryStore.MemoryConfig{Logger: e.Logger}),
+		FuzzStore:    fzs,
+		AuditStore:   auditStore.NewMemory(&auditStore.MemoryConfig{Logger: e.Logger}),
+		AllowedHosts: socket.AllowedHosts{"127.0.0.1"},
+		WsHub:        ws.NewHubMock(),
+	})
+
+	return e, h, es, fzs
+}
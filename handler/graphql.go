@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
 	"net/http"
 )
 
@@ -23,7 +24,8 @@ func (h *Handler) handleGraphQLQuery(c echo.Context) error {
 			Error: err.Error(),
 		})
 	}
-	result, err := h.gqlHub.Execute(gq.Query)
+	withSource(c, audit.SourceGraphQL)
+	result, err := h.gqlHub.Execute(c.Request().Context(), gq.Query)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
 			Error: err.Error(),
@@ -1,35 +1,108 @@
 package handler
 
 import (
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/collections"
+	"github.com/nskondratev/api-page-go-back/environments"
 	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/fuzz"
 	"github.com/nskondratev/api-page-go-back/gql"
+	"github.com/nskondratev/api-page-go-back/loadtests"
 	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/monitors"
 	"github.com/nskondratev/api-page-go-back/pages"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/users"
 	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
 )
 
 type Handler struct {
-	logger     logger.Logger
-	pageStore  pages.Store
-	eventStore events.Store
-	wsHub      ws.IHub
-	gqlHub     *gql.GraphQLHub
+	logger           logger.Logger
+	projectStore     projects.Store
+	pageStore        pages.Store
+	eventStore       events.Store
+	snapshotStore    snapshots.Store
+	typeStore        registry.Store
+	recordingStore   recordings.Store
+	scenarioStore    scenarios.Store
+	loadTestStore    loadtests.Store
+	fuzzStore        fuzz.Store
+	collectionStore  collections.Store
+	environmentStore environments.Store
+	monitorStore     monitors.Store
+	userStore        users.Store
+	auditStore       audit.Store
+	tokenIssuer      *users.Issuer
+	wsHub            ws.IHub
+	gqlHub           *gql.GraphQLHub
+	mockServer       http.Handler
+	relay            http.Handler
+	allowedHosts     socket.AllowedHosts
+	// Limit of connections of load tests, no limit when zero
+	loadTestMaxConnections int
 }
 
 type Config struct {
-	Logger     logger.Logger
-	PageStore  pages.Store
-	EventStore events.Store
-	WsHub      ws.IHub
-	GraphQLHub *gql.GraphQLHub
+	Logger           logger.Logger
+	ProjectStore     projects.Store
+	PageStore        pages.Store
+	EventStore       events.Store
+	SnapshotStore    snapshots.Store
+	TypeStore        registry.Store
+	RecordingStore   recordings.Store
+	ScenarioStore    scenarios.Store
+	LoadTestStore    loadtests.Store
+	FuzzStore        fuzz.Store
+	CollectionStore  collections.Store
+	EnvironmentStore environments.Store
+	MonitorStore     monitors.Store
+	UserStore        users.Store
+	// AuditStore records the changes, they are not recorded when it is nil
+	AuditStore audit.Store
+	// TokenIssuer authenticates the requests, all routes are open when it is nil
+	TokenIssuer *users.Issuer
+	WsHub       ws.IHub
+	GraphQLHub  *gql.GraphQLHub
+	// MockServer is mounted on /mock when set
+	MockServer http.Handler
+	// Relay serves /ws sessions naming a target
+	Relay http.Handler
+	// AllowedHosts restricts the targets of the tests run by the server, every target is denied when empty
+	AllowedHosts socket.AllowedHosts
+	// LoadTestMaxConnections limits the connections of load tests, no limit when zero
+	LoadTestMaxConnections int
 }
 
 func New(hc *Config) *Handler {
 	return &Handler{
-		logger:     hc.Logger,
-		pageStore:  hc.PageStore,
-		eventStore: hc.EventStore,
-		wsHub:      hc.WsHub,
-		gqlHub:     hc.GraphQLHub,
+		logger:           hc.Logger,
+		projectStore:     hc.ProjectStore,
+		pageStore:        hc.PageStore,
+		eventStore:       hc.EventStore,
+		snapshotStore:    hc.SnapshotStore,
+		typeStore:        hc.TypeStore,
+		recordingStore:   hc.RecordingStore,
+		scenarioStore:    hc.ScenarioStore,
+		fuzzStore:        hc.FuzzStore,
+		collectionStore:  hc.CollectionStore,
+		environmentStore: hc.EnvironmentStore,
+		monitorStore:     hc.MonitorStore,
+		userStore:        hc.UserStore,
+		auditStore:       hc.AuditStore,
+		tokenIssuer:      hc.TokenIssuer,
+		wsHub:            hc.WsHub,
+		gqlHub:           hc.GraphQLHub,
+		mockServer:       hc.MockServer,
+		relay:            hc.Relay,
+		allowedHosts:     hc.AllowedHosts,
+
+		loadTestStore:          hc.LoadTestStore,
+		loadTestMaxConnections: hc.LoadTestMaxConnections,
 	}
 }
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) GetLoadTest(c echo.Context) error {
+	run, code, err := h.loadTestFromParam(c, "id")
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: run,
+	})
+}
+
+// ListLoadTests lists runs newest first, of the target given by the target query param when it is set.
+func (h *Handler) ListLoadTests(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	runsList, total, err := h.loadTestsOf(c).List(offset, limit, c.QueryParam("target"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  runsList,
+		Total: total,
+	})
+}
+
+// CreateLoadTest starts the run in the background. The run is stored with the running status
+// and gets the result when it is over.
+func (h *Handler) CreateLoadTest(c echo.Context) error {
+	req := &loadTestCreateRequest{}
+	lc := &loadtests.Config{}
+	if err := req.bind(c, lc); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkTarget(lc.Target); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if h.loadTestMaxConnections > 0 && lc.Connections > h.loadTestMaxConnections {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: fmt.Sprintf("number of connections exceeds the limit of %d", h.loadTestMaxConnections),
+		})
+	}
+	catalog, err := h.eventsOf(c).GetAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	lc.Catalog = catalog
+	if lc.Types, err = events.ResolveEventTypes(h.typeStore, catalog...); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := lc.Validate(); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	run, store := loadtests.NewRun(req.Name, lc), h.loadTestsOf(c)
+	if err := store.Create(run); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityLoadTest,
+		EntityId:  run.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(run),
+	})
+	// The run is finished on a copy, the response encodes the created one
+	finished := *run
+	go func() {
+		if err := loadtests.Finish(store, &finished, lc); err != nil {
+			h.logger.Warnf("Error while storing result of load test %d: %s", finished.ID, err.Error())
+		}
+	}()
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: run,
+	})
+}
+
+func (h *Handler) DeleteLoadTest(c echo.Context) error {
+	run, code, err := h.loadTestFromParam(c, "id")
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if run.Status == loadtests.StatusRunning {
+		return c.JSON(http.StatusConflict, &errorResponseEnvelope{
+			Error: "load test is running",
+		})
+	}
+	if err := h.loadTestsOf(c).Delete(run); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityLoadTest,
+		EntityId:  run.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionDelete,
+		Before:    audit.Snapshot(run),
+	})
+	return c.NoContent(http.StatusOK)
+}
+
+type loadTestComparison struct {
+	From   *loadtests.Run     `json:"from"`
+	To     *loadtests.Run     `json:"to"`
+	Deltas []*loadtests.Delta `json:"deltas"`
+}
+
+// CompareLoadTests lists the changes of the key metrics from the run to the other one.
+func (h *Handler) CompareLoadTests(c echo.Context) error {
+	from, code, err := h.loadTestFromParam(c, "id")
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	to, code, err := h.loadTestFromParam(c, "otherId")
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	for _, run := range []*loadtests.Run{from, to} {
+		if run.Result == nil {
+			return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+				Error: fmt.Sprintf("load test %d has no result", run.ID),
+			})
+		}
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: &loadTestComparison{
+			From:   from,
+			To:     to,
+			Deltas: loadtests.Compare(from.Result, to.Result),
+		},
+	})
+}
+
+func (h *Handler) loadTestFromParam(c echo.Context, name string) (*loadtests.Run, int, error) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	run, err := h.loadTestsOf(c).GetById(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if run == nil {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	return run, http.StatusOK, nil
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	loadTestStore "github.com/nskondratev/api-page-go-back/loadtests/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_CreateLoadTest(t *testing.T) {
+	e, h, es, lts := setupLoadTestHandlerTest()
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	cases := []handlerCreateTestCase{
+		{`{"name":"Login","target":"` + target.URL + `","connections":2,"rate":50,"durationMs":200}`, http.StatusOK, `"id":1,"projectId":1,"name":"Login","target":"` + target.URL + `","protocol":"socketio","connections":2,"rate":50,"durationMs":200,"events":["login"],"status":"running"`},
+		{`{"target":"` + target.URL + `","connections":2,"rate":50,"durationMs":200,"events":["logout"]}`, http.StatusUnprocessableEntity, `event logout is not described in the catalog`},
+		{`{"target":"` + target.URL + `","connections":20,"rate":50,"durationMs":200}`, http.StatusUnprocessableEntity, `number of connections exceeds the limit of 10`},
+		{`{"target":"` + target.URL + `","connections":2,"rate":50,"durationMs":200,"protocol":"mqtt"}`, http.StatusUnprocessableEntity, `oneof`},
+		{`{"target":"` + target.URL + `","connections":0,"rate":50,"durationMs":200}`, http.StatusUnprocessableEntity, emptyStr},
+		{`{"target":"ws://internal.example.com","connections":2,"rate":50,"durationMs":200}`, http.StatusForbidden, `target host is not allowed: internal.example.com`},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.CreateLoadTest(c); err != nil {
+			t.Errorf("[%d] Fail to create load test. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	run := waitLoadTest(t, lts, 1)
+	if run.Status != loadtests.StatusFinished || run.Result == nil || run.Result.Emitted < 1 || run.Result.Responses.Count != run.Result.Emitted {
+		t.Errorf("Unexpected finished run: %+v, result: %+v", run, run.Result)
+	}
+}
+
+func TestHandler_CompareLoadTests(t *testing.T) {
+	e, h, _, lts := setupLoadTestHandlerTest()
+
+	for _, run := range []*loadtests.Run{
+		{Name: "before", Target: "ws://app", Status: loadtests.StatusFinished, Result: &loadtests.Result{EmitsPerSecond: 100, Acks: &loadtests.Latency{P95Ms: 10}}},
+		{Name: "after", Target: "ws://app", Status: loadtests.StatusFinished, Result: &loadtests.Result{EmitsPerSecond: 100, Acks: &loadtests.Latency{P95Ms: 15}}},
+		{Name: "running", Target: "ws://app", Status: loadtests.StatusRunning},
+	} {
+		if err := lts.Create(run); err != nil {
+			t.Fatalf("Can not create test run: %s", err.Error())
+		}
+	}
+
+	cases := []struct {
+		id                        string
+		otherId                   string
+		responseCode              int
+		responseBodyShouldContain string
+	}{
+		{"1", "2", http.StatusOK, `{"metric":"acks.p95Ms","from":10,"to":15,"change":50,"worse":true}`},
+		{"1", "3", http.StatusUnprocessableEntity, `load test 3 has no result`},
+		{"1", "4", http.StatusNotFound, `Not found`},
+		{"x", "2", http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id", "otherId")
+		c.SetParamValues(item.id, item.otherId)
+
+		if err := h.CompareLoadTests(c); err != nil {
+			t.Errorf("[%d] Fail to compare load tests. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+// waitLoadTest polls the store until the run started in the background is over.
+func waitLoadTest(t *testing.T, lts loadtests.Store, id uint64) *loadtests.Run {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		run, err := lts.GetById(id)
+		if err != nil || run == nil {
+			t.Fatalf("Can not get load test %d: %v", id, err)
+		}
+		if run.Status != loadtests.StatusRunning {
+			return run
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Load test %d is not finished", id)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func setupLoadTestHandlerTest() (*echo.Echo, *Handler, *eventStore.Memory, *loadTestStore.Memory) {
+	e := router.New()
+
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	lts := loadTestStore.NewMemory(&loadTestStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	h := New(&Config{
+		Logger:                 e.Logger,
+		EventStore:             es,
+		LoadTestStore:          lts,
+		AllowedHosts:           socket.AllowedHosts{"127.0.0.1"},
+		WsHub:                  ws.NewHubMock(),
+		LoadTestMaxConnections: 10,
+	})
+
+	return e, h, es, lts
+}
@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/monitors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// defaultUptimePeriod is the period of the uptime without the period param
+const defaultUptimePeriod = 24 * time.Hour
+
+func (h *Handler) GetMonitor(c echo.Context) error {
+	m, code, err := h.monitorFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: m,
+	})
+}
+
+func (h *Handler) ListMonitors(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	monitorsList, total, err := h.monitorsOf(c).List(offset, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  monitorsList,
+		Total: total,
+	})
+}
+
+func (h *Handler) CreateMonitor(c echo.Context) error {
+	req := &monitorCreateRequest{}
+	m := &monitors.Monitor{}
+	if err := req.bind(c, m); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkMonitor(c, m); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.monitorsOf(c).Create(m); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityMonitor,
+		EntityId:  m.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(m),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: m,
+	})
+}
+
+// UpdateMonitor replaces the settings of the monitor, its status is kept.
+func (h *Handler) UpdateMonitor(c echo.Context) error {
+	req := &monitorUpdateRequest{}
+	m := &monitors.Monitor{}
+	if err := req.bind(c, m); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.monitorsOf(c).GetById(m.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	if code, err := h.checkMonitor(c, m); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	m.Status = existing.Status
+	m.StatusChangedAt = existing.StatusChangedAt
+	m.LastCheckedAt = existing.LastCheckedAt
+	before := audit.Snapshot(existing)
+	if err := h.monitorsOf(c).Update(m); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityMonitor,
+		EntityId:  m.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionUpdate,
+		Before:    before,
+		After:     audit.Snapshot(m),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: m,
+	})
+}
+
+func (h *Handler) DeleteMonitor(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.monitorsOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.monitorsOf(c).Delete(&monitors.Monitor{ID: id}); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing != nil {
+		h.record(c, &audit.Entry{
+			Entity:    audit.EntityMonitor,
+			EntityId:  id,
+			ProjectId: h.projectIdOf(c),
+			Action:    audit.ActionDelete,
+			Before:    audit.Snapshot(existing),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// ListMonitorChecks responds with the checks of the monitor, newest first.
+func (h *Handler) ListMonitorChecks(c echo.Context) error {
+	m, code, err := h.monitorFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	checksList, total, err := h.monitorsOf(c).ListChecks(m.ID, offset, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  checksList,
+		Total: total,
+	})
+}
+
+// GetMonitorUptime summarizes the checks of the monitor over the period param (like 1h or 168h), 24h by default.
+func (h *Handler) GetMonitorUptime(c echo.Context) error {
+	m, code, err := h.monitorFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	period := defaultUptimePeriod
+	if len(c.QueryParam("period")) > 0 {
+		if period, err = time.ParseDuration(c.QueryParam("period")); err != nil || period <= 0 {
+			return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+				Error: fmt.Sprintf("invalid period %q", c.QueryParam("period")),
+			})
+		}
+	}
+	since := time.Now().Add(-period)
+	checksList, err := h.monitorsOf(c).ListChecksSince(m.ID, since)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: monitors.Summarize(m.ID, since, checksList),
+	})
+}
+
+// GetMonitorLastFailure responds with the latest failed check of the monitor, the data is null without failures.
+func (h *Handler) GetMonitorLastFailure(c echo.Context) error {
+	m, code, err := h.monitorFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	check, err := h.monitorsOf(c).LastFailure(m.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: check,
+	})
+}
+
+// checkMonitor validates the name, the scenario and the environment of the monitor.
+func (h *Handler) checkMonitor(c echo.Context, m *monitors.Monitor) (int, error) {
+	existing, err := h.monitorsOf(c).GetByName(m.Name)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if existing != nil && existing.ID != m.ID {
+		return http.StatusConflict, fmt.Errorf("monitor with name %q already exists", m.Name)
+	}
+	sc, err := h.scenariosOf(c).GetById(m.ScenarioId)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if sc == nil {
+		return http.StatusUnprocessableEntity, fmt.Errorf("scenario %d does not exist", m.ScenarioId)
+	}
+	e, err := h.environmentsOf(c).GetByName(m.Environment)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if e == nil {
+		return http.StatusUnprocessableEntity, fmt.Errorf("environment %q does not exist", m.Environment)
+	}
+	return http.StatusOK, nil
+}
+
+func (h *Handler) monitorFromParam(c echo.Context) (*monitors.Monitor, int, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	m, err := h.monitorsOf(c).GetById(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if m == nil {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	return m, http.StatusOK, nil
+}
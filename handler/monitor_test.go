@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/environments"
+	environmentStore "github.com/nskondratev/api-page-go-back/environments/store"
+	"github.com/nskondratev/api-page-go-back/monitors"
+	monitorStore "github.com/nskondratev/api-page-go-back/monitors/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	scenarioStore "github.com/nskondratev/api-page-go-back/scenarios/store"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_CreateMonitor(t *testing.T) {
+	e, h, _ := setupMonitorHandlerTest(t)
+
+	cases := []handlerCreateTestCase{
+		{`{"name":"login","scenarioId":1,"environment":"stage","intervalMs":60000,"enabled":true}`, http.StatusOK, `"status":"unknown"`},
+		{`{"name":"login","scenarioId":1,"environment":"stage","intervalMs":60000}`, http.StatusConflict, `monitor with name \"login\" already exists`},
+		{`{"name":"logout","scenarioId":2,"environment":"stage","intervalMs":60000}`, http.StatusUnprocessableEntity, `scenario 2 does not exist`},
+		{`{"name":"logout","scenarioId":1,"environment":"prod","intervalMs":60000}`, http.StatusUnprocessableEntity, `environment \"prod\" does not exist`},
+		{`{"name":"logout","scenarioId":1,"environment":"stage","intervalMs":10}`, http.StatusUnprocessableEntity, `min`},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.CreateMonitor(c); err != nil {
+			t.Errorf("[%d] Fail to create monitor. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_GetMonitorUptime(t *testing.T) {
+	e, h, mns := setupMonitorHandlerTest(t)
+
+	m := &monitors.Monitor{Name: "login", ScenarioId: 1, Environment: "stage", IntervalMs: 60000, Status: monitors.StatusDown}
+	if err := mns.Create(m); err != nil {
+		t.Fatalf("Can not create test monitor: %s", err.Error())
+	}
+	now := time.Now()
+	for i, passed := range []bool{false, true, true, false} {
+		check := &monitors.Check{MonitorId: m.ID, Passed: passed, DurationMs: 100, Message: "check", StartedAt: now.Add(-time.Duration(48-i*15) * time.Hour)}
+		if err := mns.CreateCheck(check); err != nil {
+			t.Fatalf("Can not create test check: %s", err.Error())
+		}
+	}
+
+	cases := []struct {
+		id     string
+		period string
+		handlerCreateTestCase
+	}{
+		{"1", "", handlerCreateTestCase{``, http.StatusOK, `"checks":2,"passed":1,"percent":50`}},
+		{"1", "72h", handlerCreateTestCase{``, http.StatusOK, `"checks":4,"passed":2,"percent":50`}},
+		{"1", "1h", handlerCreateTestCase{``, http.StatusOK, `"checks":0,"passed":0,"percent":100`}},
+		{"1", "day", handlerCreateTestCase{``, http.StatusUnprocessableEntity, `invalid period \"day\"`}},
+		{"2", "", handlerCreateTestCase{``, http.StatusNotFound, `Not found`}},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/?period="+item.period, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(item.id)
+
+		if err := h.GetMonitorUptime(c); err != nil {
+			t.Errorf("[%d] Fail to get uptime. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+	if err := h.GetMonitorLastFailure(c); err != nil || !strings.Contains(rec.Body.String(), `"id":4`) {
+		t.Errorf("Unexpected last failure: %s, error: %v", rec.Body.String(), err)
+	}
+}
+
+func setupMonitorHandlerTest(t *testing.T) (*echo.Echo, *Handler, *monitorStore.Memory) {
+	e := router.New()
+
+	scs := scenarioStore.NewMemory(&scenarioStore.MemoryConfig{Logger: e.Logger})
+	ens := environmentStore.NewMemory(&environmentStore.MemoryConfig{Logger: e.Logger})
+	mns := monitorStore.NewMemory(&monitorStore.MemoryConfig{Logger: e.Logger})
+
+	if err := scs.Create(&scenarios.Scenario{Name: "Login"}); err != nil {
+		t.Fatalf("Can not create test scenario: %s", err.Error())
+	}
+	if err := ens.Create(&environments.Environment{Name: "stage", BaseUrl: "wss://stage.example.com"}); err != nil {
+		t.Fatalf("Can not create test environment: %s", err.Error())
+	}
+
+	h := New(&Config{
+		Logger:           e.Logger,
+		ScenarioStore:    scs,
+		EnvironmentStore: ens,
+		MonitorStore:     mns,
+		WsHub:            ws.NewHubMock(),
+	})
+
+	return e, h, mns
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
 	"github.com/nskondratev/api-page-go-back/pages"
 	"github.com/nskondratev/api-page-go-back/ws"
 	"net/http"
@@ -15,7 +16,7 @@ func (h *Handler) GetPage(c echo.Context) error {
 			Error: err.Error(),
 		})
 	}
-	page, err := h.pageStore.GetById(id)
+	page, err := h.pagesOf(c).GetById(id)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
 			Error: err.Error(),
@@ -47,7 +48,7 @@ func (h *Handler) ListPages(c echo.Context) error {
 		sort = "createdAt"
 		descending = true
 	}
-	pagesList, total, err := h.pageStore.List(offset, limit, sort, descending, query)
+	pagesList, total, err := h.pagesOf(c).List(offset, limit, sort, descending, query)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
 			Error: err.Error(),
@@ -67,18 +68,27 @@ func (h *Handler) CreatePage(c echo.Context) error {
 			Error: err.Error(),
 		})
 	}
-	if err := h.pageStore.Create(page); err != nil {
+	page.CreatedBy = actorOf(c)
+	page.UpdatedBy = page.CreatedBy
+	if err := h.pagesOf(c).Create(page); err != nil {
 		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
 			Error: err.Error(),
 		})
 	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityPage,
+		EntityId:  page.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(page),
+	})
 	wsMessage := &ws.ApPageMessage{
 		EventConst: ws.PageCreated,
 		Data: &ws.ApMessagePageEnvelope{
 			Page: page,
 		},
 	}
-	if err := h.wsHub.Broadcast(wsMessage); err != nil {
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
 		h.logger.Warnf("Error while broadcasting PAGE_CREATED to ws: %s", err.Error())
 	}
 	return c.JSON(http.StatusOK, &responseEnvelope{
@@ -94,18 +104,34 @@ func (h *Handler) UpdatePage(c echo.Context) error {
 			Error: err.Error(),
 		})
 	}
-	if err := h.pageStore.Update(page); err != nil {
+	existing, err := h.pagesOf(c).GetById(page.ID)
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
 			Error: err.Error(),
 		})
 	}
+	before := audit.Snapshot(existing)
+	page.UpdatedBy = actorOf(c)
+	if err := h.pagesOf(c).Update(page); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityPage,
+		EntityId:  page.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionUpdate,
+		Before:    before,
+		After:     audit.Snapshot(page),
+	})
 	wsMessage := &ws.ApPageMessage{
 		EventConst: ws.PageUpdated,
 		Data: &ws.ApMessagePageEnvelope{
 			Page: page,
 		},
 	}
-	if err := h.wsHub.Broadcast(wsMessage); err != nil {
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
 		h.logger.Warnf("Error while broadcasting PAGE_UPDATED to ws: %s", err.Error())
 	}
 	return c.JSON(http.StatusOK, &responseEnvelope{
@@ -120,21 +146,36 @@ func (h *Handler) DeletePage(c echo.Context) error {
 			Error: err.Error(),
 		})
 	}
+	existing, err := h.pagesOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
 	p := &pages.Page{
 		ID: id,
 	}
-	if err := h.pageStore.Delete(p); err != nil {
+	if err := h.pagesOf(c).Delete(p); err != nil {
 		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
 			Error: err.Error(),
 		})
 	}
+	if existing != nil {
+		h.record(c, &audit.Entry{
+			Entity:    audit.EntityPage,
+			EntityId:  id,
+			ProjectId: h.projectIdOf(c),
+			Action:    audit.ActionDelete,
+			Before:    audit.Snapshot(existing),
+		})
+	}
 	wsMessage := &ws.ApIdMessage{
 		EventConst: ws.PageDeleted,
 		Data: &ws.ApMessageOnlyIdEnvelope{
 			ID: p.ID,
 		},
 	}
-	if err := h.wsHub.Broadcast(wsMessage); err != nil {
+	if err := h.wsHub.BroadcastTo(h.projectIdOf(c), wsMessage); err != nil {
 		h.logger.Warnf("Error while broadcasting PAGE_DELETED to ws: %s", err.Error())
 	}
 	return c.NoContent(http.StatusOK)
@@ -19,7 +19,7 @@ func TestHandler_CreatePage(t *testing.T) {
 	e, h, _ := setupPageHandlerTest()
 
 	cases := []handlerCreateTestCase{
-		{`{"title":"page1","text":"Page 1 text"}`, http.StatusOK, `"id":1,"title":"page1","text":"Page 1 text"`},
+		{`{"title":"page1","text":"Page 1 text"}`, http.StatusOK, `"id":1,"projectId":1,"title":"page1","text":"Page 1 text"`},
 		{`{"title":"page1","text":"Page 1 text}`, http.StatusUnprocessableEntity, emptyStr},
 		{`{"text":"Page 1 text"}`, http.StatusUnprocessableEntity, emptyStr},
 	}
@@ -55,7 +55,7 @@ func TestHandler_GetPage(t *testing.T) {
 	})
 
 	cases := []handlerGetTestCase{
-		{"1", http.StatusOK, `"id":1,"title":"Page 1","text":"Page 1 text"`},
+		{"1", http.StatusOK, `"id":1,"projectId":1,"title":"Page 1","text":"Page 1 text"`},
 		{"badparam", http.StatusUnprocessableEntity, emptyStr},
 		{"45", http.StatusNotFound, `"error":"Not found"`},
 	}
@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/environments"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/fuzz"
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	"github.com/nskondratev/api-page-go-back/monitors"
+	"github.com/nskondratev/api-page-go-back/pages"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) GetProject(c echo.Context) error {
+	p, code, err := h.projectFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: p,
+	})
+}
+
+func (h *Handler) ListProjects(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	projectsList, total, err := h.projectStore.List(offset, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  projectsList,
+		Total: total,
+	})
+}
+
+func (h *Handler) CreateProject(c echo.Context) error {
+	req := &projectCreateRequest{}
+	p := &projects.Project{}
+	if err := req.bind(c, p); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkProjectSlugIsFree(p); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.projectStore.Create(p); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityProject,
+		EntityId: p.ID,
+		Action:   audit.ActionCreate,
+		After:    audit.Snapshot(p),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: p,
+	})
+}
+
+func (h *Handler) UpdateProject(c echo.Context) error {
+	req := &projectUpdateRequest{}
+	p := &projects.Project{}
+	if err := req.bind(c, p); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.projectStore.GetById(p.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	if code, err := h.checkProjectSlugIsFree(p); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	before := audit.Snapshot(existing)
+	if err := h.projectStore.Update(p); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityProject,
+		EntityId: p.ID,
+		Action:   audit.ActionUpdate,
+		Before:   before,
+		After:    audit.Snapshot(p),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: p,
+	})
+}
+
+// DeleteProject deletes the project without events, pages and monitors. The default project can not be deleted.
+func (h *Handler) DeleteProject(c echo.Context) error {
+	p, code, err := h.projectFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if p.ID == projects.DefaultId {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: "default project can not be deleted",
+		})
+	}
+	_, eventsTotal, err := h.eventStore.ForProject(p.ID).List(0, 1, "", false, "", "")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	_, pagesTotal, err := h.pageStore.ForProject(p.ID).List(0, 1, "", false, "")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	// Monitors keep checking the project in the background
+	_, monitorsTotal, err := h.monitorStore.ForProject(p.ID).List(0, 1)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if eventsTotal > 0 || pagesTotal > 0 || monitorsTotal > 0 {
+		return c.JSON(http.StatusConflict, &errorResponseEnvelope{
+			Error: fmt.Sprintf("project has %d events, %d pages and %d monitors", eventsTotal, pagesTotal, monitorsTotal),
+		})
+	}
+	before := audit.Snapshot(p)
+	if err := h.projectStore.Delete(p); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityProject,
+		EntityId: p.ID,
+		Action:   audit.ActionDelete,
+		Before:   before,
+	})
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) checkProjectSlugIsFree(p *projects.Project) (int, error) {
+	existing, err := h.projectStore.GetBySlug(p.Slug)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if existing != nil && existing.ID != p.ID {
+		return http.StatusConflict, fmt.Errorf("project with slug %q already exists", p.Slug)
+	}
+	return http.StatusOK, nil
+}
+
+func (h *Handler) projectFromParam(c echo.Context) (*projects.Project, int, error) {
+	id, err := strconv.ParseUint(c.Param("project"), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	p, err := h.projectStore.GetById(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if p == nil {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	return p, http.StatusOK, nil
+}
+
+// projectScope resolves the project of the :project slug and passes its ID to the handlers
+// in the request context.
+func (h *Handler) projectScope(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		p, err := h.projectStore.GetBySlug(c.Param("project"))
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+				Error: err.Error(),
+			})
+		}
+		if p == nil {
+			return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+				Error: fmt.Sprintf("project %q does not exist", c.Param("project")),
+			})
+		}
+		r := c.Request()
+		c.SetRequest(r.WithContext(projects.NewContext(r.Context(), p.ID)))
+		return next(c)
+	}
+}
+
+// projectIdOf returns the project of the request, the default one for the routes without the project prefix.
+func (h *Handler) projectIdOf(c echo.Context) uint64 {
+	return projects.IdFromContext(c.Request().Context())
+}
+
+func (h *Handler) eventsOf(c echo.Context) events.Store {
+	return h.eventStore.ForProject(h.projectIdOf(c))
+}
+
+func (h *Handler) pagesOf(c echo.Context) pages.Store {
+	return h.pageStore.ForProject(h.projectIdOf(c))
+}
+
+func (h *Handler) snapshotsOf(c echo.Context) snapshots.Store {
+	return h.snapshotStore.ForProject(h.projectIdOf(c))
+}
+
+func (h *Handler) recordingsOf(c echo.Context) recordings.Store {
+	return h.recordingStore.ForProject(h.projectIdOf(c))
+}
+
+func (h *Handler) scenariosOf(c echo.Context) scenarios.Store {
+	return h.scenarioStore.ForProject(h.projectIdOf(c))
+}
+
+func (h *Handler) loadTestsOf(c echo.Context) loadtests.Store {
+	return h.loadTestStore.ForProject(h.projectIdOf(c))
+}
+
+func (h *Handler) fuzzRunsOf(c echo.Context) fuzz.Store {
+	return h.fuzzStore.ForProject(h.projectIdOf(c))
+}
+
+func (h *Handler) environmentsOf(c echo.Context) environments.Store {
+	return h.environmentStore.ForProject(h.projectIdOf(c))
+}
+
+func (h *Handler) monitorsOf(c echo.Context) monitors.Store {
+	return h.monitorStore.ForProject(h.projectIdOf(c))
+}
+
+// eventsUsingType returns the events of all projects referencing the shared type.
+func (h *Handler) eventsUsingType(typeId uint64) ([]*events.Event, error) {
+	ids := []uint64{projects.DefaultId}
+	if h.projectStore != nil {
+		projectsList, _, err := h.projectStore.List(0, -1)
+		if err != nil {
+			return nil, err
+		}
+		ids = ids[:0]
+		for _, p := range projectsList {
+			ids = append(ids, p.ID)
+		}
+	}
+	usedBy := make([]*events.Event, 0)
+	for _, id := range ids {
+		list, err := h.eventStore.ForProject(id).GetByTypeId(typeId)
+		if err != nil {
+			return nil, err
+		}
+		usedBy = append(usedBy, list...)
+	}
+	return usedBy, nil
+}
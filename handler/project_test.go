@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	environmentStore "github.com/nskondratev/api-page-go-back/environments/store"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/gql"
+	monitorStore "github.com/nskondratev/api-page-go-back/monitors/store"
+	"github.com/nskondratev/api-page-go-back/pages"
+	pageStore "github.com/nskondratev/api-page-go-back/pages/store"
+	"github.com/nskondratev/api-page-go-back/projects"
+	projectStore "github.com/nskondratev/api-page-go-back/projects/store"
+	recordingStore "github.com/nskondratev/api-page-go-back/recordings/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	scenarioStore "github.com/nskondratev/api-page-go-back/scenarios/store"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_CreateProject(t *testing.T) {
+	e, h, _ := setupProjectHandlerTest(t)
+
+	cases := []handlerCreateTestCase{
+		{`{"name":"Chat","slug":"chat"}`, http.StatusOK, `"id":2,"name":"Chat","slug":"chat"`},
+		{`{"name":"Chat v2","slug":"chat"}`, http.StatusConflict, `project with slug \"chat\" already exists`},
+		{`{"name":"Chat v2","slug":"Chat V2"}`, http.StatusUnprocessableEntity, `slug \"Chat V2\" may contain only lowercase letters, digits and dashes`},
+		{`{"slug":"chat-v2"}`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.CreateProject(c); err != nil {
+			t.Errorf("[%d] Fail to create project. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_ProjectRoutes(t *testing.T) {
+	e, _, prs := setupProjectHandlerTest(t)
+
+	if err := prs.Create(&projects.Project{Name: "Chat", Slug: "chat"}); err != nil {
+		t.Fatalf("Can not create test project: %s", err.Error())
+	}
+
+	cases := []struct {
+		method string
+		path   string
+		handlerCreateTestCase
+	}{
+		{http.MethodPost, "/api/events", handlerCreateTestCase{`{"constant":"LOGIN","value":"login","type":"client","description":"Test"}`, http.StatusOK, `"id":1,"projectId":1`}},
+		{http.MethodPost, "/api/projects/chat/events", handlerCreateTestCase{`{"constant":"MESSAGE","value":"message","type":"client","description":"Test"}`, http.StatusOK, `"id":2,"projectId":2`}},
+		{http.MethodPost, "/api/projects/chat/events", handlerCreateTestCase{`{"constant":"REPLY","value":"reply","type":"frontend","description":"Test","responseEventId":1}`, http.StatusUnprocessableEntity, `response event with id = 1 does not exist`}},
+		{http.MethodPost, "/api/projects/chat/pages", handlerCreateTestCase{`{"title":"Chat","text":"Chat API"}`, http.StatusOK, `"projectId":2`}},
+		{http.MethodGet, "/api/events?query=message", handlerCreateTestCase{``, http.StatusOK, `"total":0`}},
+		{http.MethodGet, "/api/projects/chat/events?query=message", handlerCreateTestCase{``, http.StatusOK, `"total":1`}},
+		{http.MethodGet, "/api/events/2", handlerCreateTestCase{``, http.StatusNotFound, `Not found`}},
+		{http.MethodGet, "/api/projects/chat/events/2", handlerCreateTestCase{``, http.StatusOK, `"value":"message"`}},
+		{http.MethodGet, "/api/projects/chat/events/1", handlerCreateTestCase{``, http.StatusNotFound, `Not found`}},
+		{http.MethodDelete, "/api/projects/chat/events/1", handlerCreateTestCase{``, http.StatusOK, emptyStr}},
+		{http.MethodGet, "/api/events/1", handlerCreateTestCase{``, http.StatusOK, `"value":"login"`}},
+		{http.MethodGet, "/api/pages", handlerCreateTestCase{``, http.StatusOK, `"total":0`}},
+		{http.MethodGet, "/api/projects/default/pages", handlerCreateTestCase{``, http.StatusOK, `"total":0`}},
+		{http.MethodPost, "/api/projects/chat/graphql", handlerCreateTestCase{`{"query":"{ event(id: 2) { value } }"}`, http.StatusOK, `{"event":{"value":"message"}}`}},
+		{http.MethodPost, "/api/graphql", handlerCreateTestCase{`{"query":"{ event(id: 2) { value } }"}`, http.StatusOK, `{"event":null}`}},
+		{http.MethodGet, "/api/projects/billing/events", handlerCreateTestCase{``, http.StatusNotFound, `project \"billing\" does not exist`}},
+		{http.MethodGet, "/api/projects/2", handlerCreateTestCase{``, http.StatusOK, `"slug":"chat"`}},
+		{http.MethodDelete, "/api/projects/2", handlerCreateTestCase{``, http.StatusConflict, `project has 1 events, 1 pages and 0 monitors`}},
+		{http.MethodDelete, "/api/projects/1", handlerCreateTestCase{``, http.StatusUnprocessableEntity, `default project can not be deleted`}},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(item.method, item.path, strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code of %s %s. Wanted: %d, received: %d, response body: %s", caseNum, item.method, item.path, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_ProjectTestRoutes(t *testing.T) {
+	e, h, prs := setupProjectHandlerTest(t)
+
+	if err := prs.Create(&projects.Project{Name: "Chat", Slug: "chat"}); err != nil {
+		t.Fatalf("Can not create test project: %s", err.Error())
+	}
+	newTestRecording(t, h.recordingStore.ForProject(2))
+
+	scenario := `{"name":"Login","steps":[{"action":"emit","event":"login","payload":{"name":"John"},"ack":true}]}`
+	cases := []struct {
+		method string
+		path   string
+		handlerCreateTestCase
+	}{
+		{http.MethodPost, "/api/projects/chat/events", handlerCreateTestCase{`{"constant":"LOGIN","value":"login","type":"client","description":"Test"}`, http.StatusOK, `"projectId":2`}},
+		{http.MethodGet, "/api/recordings", handlerCreateTestCase{``, http.StatusOK, `"total":0`}},
+		{http.MethodGet, "/api/projects/chat/recordings", handlerCreateTestCase{``, http.StatusOK, `"total":1`}},
+		{http.MethodGet, "/api/recordings/1", handlerCreateTestCase{``, http.StatusNotFound, `Not found`}},
+		{http.MethodGet, "/api/coverage", handlerCreateTestCase{``, http.StatusOK, `"messages":0,"catalogEvents":0`}},
+		{http.MethodGet, "/api/projects/chat/coverage", handlerCreateTestCase{``, http.StatusOK, `"messages":1,"catalogEvents":1,"observedEvents":1`}},
+		{http.MethodPost, "/api/projects/chat/scenarios", handlerCreateTestCase{scenario, http.StatusOK, `"id":1,"projectId":2,"name":"Login"`}},
+		{http.MethodPost, "/api/scenarios", handlerCreateTestCase{scenario, http.StatusOK, `"id":2,"projectId":1,"name":"Login"`}},
+		{http.MethodGet, "/api/projects/chat/scenarios/2", handlerCreateTestCase{``, http.StatusNotFound, `Not found`}},
+		{http.MethodPost, "/api/projects/chat/environments", handlerCreateTestCase{`{"name":"stage","baseUrl":"wss://stage.example.com"}`, http.StatusOK, `"projectId":2,"name":"stage"`}},
+		{http.MethodGet, "/api/environments", handlerCreateTestCase{``, http.StatusOK, `"total":0`}},
+		{http.MethodPost, "/api/monitors", handlerCreateTestCase{`{"name":"login","scenarioId":1,"environment":"stage","intervalMs":60000}`, http.StatusUnprocessableEntity, `scenario 1 does not exist`}},
+		{http.MethodPost, "/api/projects/chat/monitors", handlerCreateTestCase{`{"name":"login","scenarioId":1,"environment":"stage","intervalMs":60000}`, http.StatusOK, `"projectId":2,"name":"login"`}},
+		{http.MethodDelete, "/api/projects/2", handlerCreateTestCase{``, http.StatusConflict, `project has 1 events, 0 pages and 1 monitors`}},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(item.method, item.path, strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+
+		e.ServeHTTP(rec, req)
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code of %s %s. Wanted: %d, received: %d, response body: %s", caseNum, item.method, item.path, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func setupProjectHandlerTest(t *testing.T) (*echo.Echo, *Handler, *projectStore.Memory) {
+	e := router.New()
+
+	prs := projectStore.NewMemory(&projectStore.MemoryConfig{Logger: e.Logger})
+	if err := projects.EnsureDefault(prs); err != nil {
+		t.Fatalf("Can not create default project: %s", err.Error())
+	}
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+	ps := pageStore.NewMemory(&pageStore.MemoryConfig{Logger: e.Logger})
+
+	gqlHub := gql.NewGraphQLHub()
+	gqlHub.AddType(pages.GraphQLType)
+	gqlHub.AddType(events.GraphQLType)
+	if err := pages.RegisterGraphQLQueries(ps, gqlHub); err != nil {
+		t.Fatalf("Can not register page queries: %s", err.Error())
+	}
+	if err := events.RegisterGraphQLQueries(es, gqlHub); err != nil {
+		t.Fatalf("Can not register event queries: %s", err.Error())
+	}
+	if err := gqlHub.Compile(); err != nil {
+		t.Fatalf("Can not compile graphql schema: %s", err.Error())
+	}
+
+	h := New(&Config{
+		Logger:           e.Logger,
+		ProjectStore:     prs,
+		EventStore:       es,
+		PageStore:        ps,
+		RecordingStore:   recordingStore.NewMemory(&recordingStore.MemoryConfig{Logger: e.Logger}),
+		ScenarioStore:    scenarioStore.NewMemory(&scenarioStore.MemoryConfig{Logger: e.Logger}),
+		EnvironmentStore: environmentStore.NewMemory(&environmentStore.MemoryConfig{Logger: e.Logger}),
+		MonitorStore:     monitorStore.NewMemory(&monitorStore.MemoryConfig{Logger: e.Logger}),
+		WsHub:            ws.NewHubMock(),
+		GraphQLHub:       gqlHub,
+	})
+	h.Register(e.Group("/api"), e.Group(""))
+
+	return e, h, prs
+}
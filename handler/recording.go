@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"net/http"
+	"strconv"
+)
+
+// ndjsonContentType is the content type of exported recordings
+const ndjsonContentType = "application/x-ndjson"
+
+func (h *Handler) GetRecording(c echo.Context) error {
+	session, code, err := h.recordingFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: session,
+	})
+}
+
+func (h *Handler) ListRecordings(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	sessionsList, total, err := h.recordingsOf(c).List(offset, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  sessionsList,
+		Total: total,
+	})
+}
+
+func (h *Handler) DeleteRecording(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.recordingsOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.recordingsOf(c).Delete(&recordings.Session{ID: id}); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing != nil {
+		h.record(c, &audit.Entry{
+			Entity:    audit.EntityRecording,
+			EntityId:  id,
+			ProjectId: h.projectIdOf(c),
+			Action:    audit.ActionDelete,
+			Before:    audit.Snapshot(existing.Summary()),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// ExportRecording writes the messages of the session as NDJSON.
+func (h *Handler) ExportRecording(c echo.Context) error {
+	session, code, err := h.recordingFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=recording-"+c.Param("id")+".ndjson")
+	c.Response().Header().Set(echo.HeaderContentType, ndjsonContentType)
+	c.Response().WriteHeader(http.StatusOK)
+	return recordings.WriteNDJSON(c.Response(), session.Messages)
+}
+
+// ReplayRecording starts sending the recorded messages to the target and returns the running replay session.
+// The traffic and the status of the replay are stored in the background.
+func (h *Handler) ReplayRecording(c echo.Context) error {
+	session, code, err := h.recordingFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	req := &recordingReplayRequest{}
+	rc := &recordings.ReplayConfig{}
+	if err := req.bind(c, rc); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkTarget(rc.Target); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	replay, err := recordings.NewReplay(session, rc)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	store := h.recordingsOf(c)
+	if err := store.Create(replay); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityRecording,
+		EntityId:  replay.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(replay.Summary()),
+	})
+	// The replay is finished on a copy, the response encodes the created one
+	finished := *replay
+	go func() {
+		if err := recordings.FinishReplay(store, session, &finished, rc); err != nil {
+			h.logger.Warnf("Error while storing replay %d of session %d: %s", finished.ID, session.ID, err.Error())
+		}
+	}()
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: replay,
+	})
+}
+
+func (h *Handler) recordingFromParam(c echo.Context) (*recordings.Session, int, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	session, err := h.recordingsOf(c).GetById(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if session == nil {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	return session, http.StatusOK, nil
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	recordingStore "github.com/nskondratev/api-page-go-back/recordings/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_GetRecording(t *testing.T) {
+	e, h, rs := setupRecordingHandlerTest()
+	newTestRecording(t, rs)
+
+	cases := []struct {
+		id                        string
+		responseCode              int
+		responseBodyShouldContain string
+	}{
+		{"1", http.StatusOK, `"name":"Login","target":"ws://localhost:3000","protocol":"socketio","messagesCount":2,"status":"finished","error":"","messages":[{"id":1,"sessionId":1,"direction":"out"`},
+		{"42", http.StatusNotFound, `Not found`},
+		{"abc", http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(item.id)
+
+		if err := h.GetRecording(c); err != nil {
+			t.Errorf("[%d] Fail to get recording. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_ExportRecording(t *testing.T) {
+	e, h, rs := setupRecordingHandlerTest()
+	newTestRecording(t, rs)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("1")
+
+	if err := h.ExportRecording(c); err != nil {
+		t.Fatalf("Fail to export recording. Error: %s", err.Error())
+	}
+
+	if rec.Code != http.StatusOK || rec.Header().Get(echo.HeaderContentType) != ndjsonContentType {
+		t.Errorf("Unexpected response. Code: %d, content type: %s", rec.Code, rec.Header().Get(echo.HeaderContentType))
+	}
+
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"direction":"out","time":"2019-05-01T10:00:00Z","kind":"event","event":"login","ackId":1,"data":{"name":"John"}`) || !strings.Contains(lines[1], `"direction":"in"`) {
+		t.Errorf("Unexpected NDJSON export: %s", rec.Body.String())
+	}
+}
+
+func TestHandler_ReplayRecordingValidation(t *testing.T) {
+	e, h, rs := setupRecordingHandlerTest()
+	newTestRecording(t, rs)
+
+	cases := []handlerCreateTestCase{
+		{`{}`, http.StatusUnprocessableEntity, emptyStr},
+		{`{"target":"ws://localhost:3000","scale":-1}`, http.StatusUnprocessableEntity, emptyStr},
+		{`{"target":"ftp://127.0.0.1"}`, http.StatusUnprocessableEntity, `unsupported target scheme`},
+		{`{"target":"ws://127.0.0.1:3000","protocol":"mqtt"}`, http.StatusUnprocessableEntity, `oneof`},
+		{`{"target":"ws://internal.example.com"}`, http.StatusForbidden, `target host is not allowed: internal.example.com`},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		if err := h.ReplayRecording(c); err != nil {
+			t.Errorf("[%d] Fail to replay recording. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_ReplayRecording(t *testing.T) {
+	e, h, rs := setupRecordingHandlerTest()
+	newTestRecording(t, rs)
+
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	cases := []struct {
+		inputData string
+		status    string
+		error     string
+		messages  int
+	}{
+		{`{"target":"` + target.URL + `","scale":0,"waitMs":100}`, recordings.StatusFinished, emptyStr, 3},
+		{`{"target":"ws://127.0.0.1:1","scale":0}`, recordings.StatusFailed, `connection refused`, 0},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		if err := h.ReplayRecording(c); err != nil {
+			t.Fatalf("[%d] Fail to replay recording. Error: %s", caseNum, err.Error())
+		}
+
+		id := uint64(caseNum + 2)
+		if wanted := fmt.Sprintf(`"id":%d,"projectId":1,"name":"Replay of Login","target":`, id); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), wanted) || !strings.Contains(rec.Body.String(), `"status":"running"`) {
+			t.Errorf("[%d] Unexpected response. Code: %d, body: %s", caseNum, rec.Code, rec.Body.String())
+		}
+
+		replay := waitReplay(t, rs, id)
+		if replay.Status != item.status || !strings.Contains(replay.Error, item.error) || replay.MessagesCount != item.messages {
+			t.Errorf("[%d] Unexpected finished replay: %+v", caseNum, replay)
+		}
+	}
+}
+
+func waitReplay(t *testing.T, rs recordings.Store, id uint64) *recordings.Session {
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		session, err := rs.GetById(id)
+		if err != nil || session == nil {
+			t.Fatalf("Can not get replay %d: %v", id, err)
+		}
+		if session.Status != recordings.StatusRunning {
+			return session
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Replay %d is not finished", id)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func setupRecordingHandlerTest() (*echo.Echo, *Handler, *recordingStore.Memory) {
+	e := router.New()
+
+	rs := recordingStore.NewMemory(&recordingStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	h := New(&Config{
+		Logger:         e.Logger,
+		RecordingStore: rs,
+		AllowedHosts:   socket.AllowedHosts{"127.0.0.1"},
+		WsHub:          ws.NewHubMock(),
+	})
+
+	return e, h, rs
+}
+
+func newTestRecording(t *testing.T, rs recordings.Store) *recordings.Session {
+	start := time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC)
+	session := &recordings.Session{Name: "Login", Target: "ws://localhost:3000", Protocol: "socketio", Messages: []recordings.Message{
+		{Direction: recordings.DirectionOut, Time: start, Kind: "event", Event: "login", Data: util.RawJSON(`{"name":"John"}`), AckId: 1, Raw: `421["login",{"name":"John"}]`},
+		{Direction: recordings.DirectionIn, Time: start.Add(time.Second), Kind: "ack", Data: util.RawJSON(`{"ok":true}`), AckId: 1, Raw: `431[{"ok":true}]`},
+	}}
+	if err := rs.Create(session); err != nil {
+		t.Fatalf("Can not create test recording: %s", err.Error())
+	}
+	return session
+}
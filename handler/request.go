@@ -1,11 +1,30 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/collections"
+	"github.com/nskondratev/api-page-go-back/environments"
 	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/fuzz"
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	"github.com/nskondratev/api-page-go-back/monitors"
 	"github.com/nskondratev/api-page-go-back/pages"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	"github.com/nskondratev/api-page-go-back/users"
 	"github.com/nskondratev/api-page-go-back/util"
+	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type pageUpdateRequest struct {
@@ -50,19 +69,133 @@ func (r *pageCreateRequest) bind(c echo.Context, p *pages.Page) error {
 }
 
 type fieldsRequest struct {
-	Key         util.NullString `json:"key" validate:"required"`
-	Type        string          `json:"type" validate:"required"`
-	Required    bool            `json:"required" validate:"required"`
-	Description string          `json:"description" validate:"required"`
+	ID           uint64           `json:"id"`
+	Key          util.NullString  `json:"key" validate:"required"`
+	Type         string           `json:"type" validate:"required"`
+	TypeId       util.NullInt64   `json:"typeId"`
+	Required     bool             `json:"required" validate:"required"`
+	Description  string           `json:"description" validate:"required"`
+	Minimum      util.NullFloat64 `json:"minimum"`
+	Maximum      util.NullFloat64 `json:"maximum"`
+	MinLength    util.NullInt64   `json:"minLength"`
+	MaxLength    util.NullInt64   `json:"maxLength"`
+	Pattern      string           `json:"pattern"`
+	Format       string           `json:"format"`
+	Enum         util.StringList  `json:"enum"`
+	DefaultValue util.RawJSON     `json:"default"`
+}
+
+func fieldsFromRequest(fr []fieldsRequest) []events.Field {
+	fields := make([]events.Field, len(fr), len(fr))
+	for index, element := range fr {
+		fields[index] = events.Field{
+			ID:           element.ID,
+			Key:          element.Key,
+			Type:         element.Type,
+			TypeId:       element.TypeId,
+			Required:     element.Required,
+			Description:  element.Description,
+			Minimum:      element.Minimum,
+			Maximum:      element.Maximum,
+			MinLength:    element.MinLength,
+			MaxLength:    element.MaxLength,
+			Pattern:      element.Pattern,
+			Format:       element.Format,
+			Enum:         element.Enum,
+			DefaultValue: element.DefaultValue,
+		}
+	}
+	return fields
+}
+
+// fieldPatchRequest updates only the keys of a field which are present in the request body.
+type fieldPatchRequest struct {
+	fieldsRequest
+}
+
+func (r *fieldPatchRequest) bind(c echo.Context, f *events.Field) error {
+	r.fieldsRequest = fieldsRequest{
+		ID:           f.ID,
+		Key:          f.Key,
+		Type:         f.Type,
+		TypeId:       f.TypeId,
+		Required:     f.Required,
+		Description:  f.Description,
+		Minimum:      f.Minimum,
+		Maximum:      f.Maximum,
+		MinLength:    f.MinLength,
+		MaxLength:    f.MaxLength,
+		Pattern:      f.Pattern,
+		Format:       f.Format,
+		Enum:         f.Enum,
+		DefaultValue: f.DefaultValue,
+	}
+	if err := json.NewDecoder(c.Request().Body).Decode(&r.fieldsRequest); err != nil {
+		return err
+	}
+	// The merged field must still have the keys required on create
+	if !r.Key.Valid || len(strings.TrimSpace(r.Key.String)) < 1 {
+		return errors.New("key is required")
+	}
+	if len(strings.TrimSpace(r.Type)) < 1 {
+		return errors.New("type is required")
+	}
+	patched := fieldsFromRequest([]fieldsRequest{r.fieldsRequest})[0]
+	patched.ID = f.ID
+	patched.EventId = f.EventId
+	if err := patched.CheckConstraints(); err != nil {
+		return err
+	}
+	*f = patched
+	return nil
+}
+
+type eventInferRequest struct {
+	Value   string            `json:"value" validate:"required"`
+	Type    string            `json:"type" validate:"omitempty,oneof=frontend client"`
+	Ack     bool              `json:"ack"`
+	Samples []json.RawMessage `json:"samples" validate:"required,min=1"`
+	Apply   bool              `json:"apply"`
+}
+
+func (r *eventInferRequest) bind(c echo.Context) ([]interface{}, error) {
+	if err := c.Bind(r); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(r); err != nil {
+		return nil, err
+	}
+	samples := make([]interface{}, len(r.Samples))
+	for i, raw := range r.Samples {
+		if err := json.Unmarshal(raw, &samples[i]); err != nil {
+			return nil, err
+		}
+	}
+	return samples, nil
+}
+
+// checkFieldsConstraints reports the first field whose constraints can not be applied.
+func checkFieldsConstraints(e *events.Event) error {
+	for _, list := range [][]events.Field{e.Fields, e.AckFields} {
+		for i := range list {
+			if err := list[i].CheckConstraints(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
 
 type eventCreateRequest struct {
-	Label       util.NullString `json:"label"`
-	Constant    string          `json:"constant" validate:"required"`
-	Value       string          `json:"value" validate:"required"`
-	Description string          `json:"description" validate:"required"`
-	Type        string          `json:"type" validate:"required"`
-	Fields      []fieldsRequest `json:"fields"`
+	Label           util.NullString `json:"label"`
+	Constant        string          `json:"constant" validate:"required"`
+	Value           string          `json:"value" validate:"required"`
+	Description     string          `json:"description" validate:"required"`
+	Type            string          `json:"type" validate:"required"`
+	Fields          []fieldsRequest `json:"fields"`
+	AckFields       []fieldsRequest `json:"ackFields"`
+	ResponseEventId util.NullInt64  `json:"responseEventId"`
+	ResponseTimeout int             `json:"responseTimeout" validate:"min=0"`
 }
 
 func (r *eventCreateRequest) bind(c echo.Context, e *events.Event) error {
@@ -77,27 +210,24 @@ func (r *eventCreateRequest) bind(c echo.Context, e *events.Event) error {
 	e.Value = r.Value
 	e.Description = r.Description
 	e.Type = r.Type
-	e.Fields = make([]events.Field, len(r.Fields), len(r.Fields))
-	for index, element := range r.Fields {
-		f := events.Field{
-			Key:         element.Key,
-			Type:        element.Type,
-			Required:    element.Required,
-			Description: element.Description,
-		}
-		e.Fields[index] = f
-	}
-	return nil
+	e.Fields = fieldsFromRequest(r.Fields)
+	e.AckFields = fieldsFromRequest(r.AckFields)
+	e.ResponseEventId = r.ResponseEventId
+	e.ResponseTimeout = r.ResponseTimeout
+	return checkFieldsConstraints(e)
 }
 
 type eventUpdateRequest struct {
-	ID          uint64          `json:"id" validate:"required"`
-	Label       util.NullString `json:"label"`
-	Constant    string          `json:"constant" validate:"required"`
-	Value       string          `json:"value" validate:"required"`
-	Description string          `json:"description" validate:"required"`
-	Type        string          `json:"type" validate:"required"`
-	Fields      []fieldsRequest `json:"fields"`
+	ID              uint64          `json:"id" validate:"required"`
+	Label           util.NullString `json:"label"`
+	Constant        string          `json:"constant" validate:"required"`
+	Value           string          `json:"value" validate:"required"`
+	Description     string          `json:"description" validate:"required"`
+	Type            string          `json:"type" validate:"required"`
+	Fields          []fieldsRequest `json:"fields"`
+	AckFields       []fieldsRequest `json:"ackFields"`
+	ResponseEventId util.NullInt64  `json:"responseEventId"`
+	ResponseTimeout int             `json:"responseTimeout" validate:"min=0"`
 }
 
 func (r *eventUpdateRequest) bind(c echo.Context, e *events.Event) error {
@@ -118,15 +248,751 @@ func (r *eventUpdateRequest) bind(c echo.Context, e *events.Event) error {
 	e.Value = r.Value
 	e.Description = r.Description
 	e.Type = r.Type
-	e.Fields = make([]events.Field, len(r.Fields), len(r.Fields))
-	for index, element := range r.Fields {
-		f := events.Field{
-			Key:         element.Key,
-			Type:        element.Type,
-			Required:    element.Required,
-			Description: element.Description,
+	e.Fields = fieldsFromRequest(r.Fields)
+	e.AckFields = fieldsFromRequest(r.AckFields)
+	e.ResponseEventId = r.ResponseEventId
+	e.ResponseTimeout = r.ResponseTimeout
+	return checkFieldsConstraints(e)
+}
+
+type snapshotCreateRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Description string `json:"description"`
+}
+
+func (r *snapshotCreateRequest) bind(c echo.Context, s *snapshots.Snapshot) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	s.Name = r.Name
+	s.Description = r.Description
+	return nil
+}
+
+type typeFieldRequest struct {
+	Key         util.NullString `json:"key"`
+	Type        string          `json:"type"`
+	Required    bool            `json:"required"`
+	Description string          `json:"description"`
+}
+
+type enumValueRequest struct {
+	Value       string `json:"value" validate:"required"`
+	Description string `json:"description"`
+}
+
+type typeCreateRequest struct {
+	Name        string             `json:"name" validate:"required"`
+	Kind        string             `json:"kind" validate:"required,oneof=object enum"`
+	Description string             `json:"description"`
+	Fields      []typeFieldRequest `json:"fields"`
+	Values      []enumValueRequest `json:"values" validate:"dive"`
+}
+
+func (r *typeCreateRequest) bind(c echo.Context, t *registry.Type) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	t.Name = r.Name
+	t.Kind = r.Kind
+	t.Description = r.Description
+	t.Fields, t.Values = typeMembersFromRequest(r.Kind, r.Fields, r.Values)
+	return nil
+}
+
+type typeUpdateRequest struct {
+	ID          uint64             `json:"id" validate:"required"`
+	Name        string             `json:"name" validate:"required"`
+	Kind        string             `json:"kind" validate:"required,oneof=object enum"`
+	Description string             `json:"description"`
+	Fields      []typeFieldRequest `json:"fields"`
+	Values      []enumValueRequest `json:"values" validate:"dive"`
+}
+
+func (r *typeUpdateRequest) bind(c echo.Context, t *registry.Type) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	t.ID = r.ID
+	t.Name = r.Name
+	t.Kind = r.Kind
+	t.Description = r.Description
+	t.Fields, t.Values = typeMembersFromRequest(r.Kind, r.Fields, r.Values)
+	return nil
+}
+
+// typeMembersFromRequest keeps only the members which make sense for the kind: fields for objects and values for enums.
+func typeMembersFromRequest(kind string, fr []typeFieldRequest, vr []enumValueRequest) ([]registry.TypeField, []registry.EnumValue) {
+	fields, values := make([]registry.TypeField, 0), make([]registry.EnumValue, 0)
+	if kind == registry.KindObject {
+		for _, element := range fr {
+			fields = append(fields, registry.TypeField{
+				Key:         element.Key,
+				Type:        element.Type,
+				Required:    element.Required,
+				Description: element.Description,
+			})
+		}
+	}
+	if kind == registry.KindEnum {
+		for _, element := range vr {
+			values = append(values, registry.EnumValue{
+				Value:       element.Value,
+				Description: element.Description,
+			})
+		}
+	}
+	return fields, values
+}
+
+type recordingReplayRequest struct {
+	Target   string            `json:"target" validate:"required"`
+	Protocol string            `json:"protocol" validate:"omitempty,oneof=socketio json"`
+	Headers  map[string]string `json:"headers"`
+	Scale    *float64          `json:"scale" validate:"omitempty,min=0"`
+	WaitMs   int               `json:"waitMs" validate:"min=0,max=60000"`
+}
+
+func (r *recordingReplayRequest) bind(c echo.Context, rc *recordings.ReplayConfig) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	rc.Target = r.Target
+	rc.Protocol = r.Protocol
+	rc.Header = http.Header{}
+	for name, value := range r.Headers {
+		rc.Header.Set(name, value)
+	}
+	// Original timing by default
+	rc.Scale = 1
+	if r.Scale != nil {
+		rc.Scale = *r.Scale
+	}
+	rc.Wait = time.Duration(r.WaitMs) * time.Millisecond
+	return nil
+}
+
+type scenarioCreateRequest struct {
+	Name        string            `json:"name" validate:"required"`
+	Description string            `json:"description"`
+	Steps       []*scenarios.Step `json:"steps" validate:"required,min=1,dive,required"`
+}
+
+func (r *scenarioCreateRequest) bind(c echo.Context, s *scenarios.Scenario) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	s.Name = r.Name
+	s.Description = r.Description
+	s.Steps = r.Steps
+	return nil
+}
+
+type scenarioUpdateRequest struct {
+	ID          uint64            `json:"id" validate:"required"`
+	Name        string            `json:"name" validate:"required"`
+	Description string            `json:"description"`
+	Steps       []*scenarios.Step `json:"steps" validate:"required,min=1,dive,required"`
+}
+
+func (r *scenarioUpdateRequest) bind(c echo.Context, s *scenarios.Scenario) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	s.ID = r.ID
+	s.Name = r.Name
+	s.Description = r.Description
+	s.Steps = r.Steps
+	return nil
+}
+
+// scenarioRunRequest is also bound to run saved requests. The target, the protocol and the headers
+// override the ones of the environment.
+type scenarioRunRequest struct {
+	Target      string            `json:"target"`
+	Protocol    string            `json:"protocol" validate:"omitempty,oneof=socketio json"`
+	Headers     map[string]string `json:"headers"`
+	Environment string            `json:"environment"`
+}
+
+func (r *scenarioRunRequest) bind(c echo.Context, rc *scenarios.RunConfig) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	rc.Target = r.Target
+	rc.Protocol = r.Protocol
+	rc.Header = http.Header{}
+	for name, value := range r.Headers {
+		rc.Header.Set(name, value)
+	}
+	return nil
+}
+
+type loadTestCreateRequest struct {
+	Name        string            `json:"name"`
+	Target      string            `json:"target" validate:"required"`
+	Protocol    string            `json:"protocol" validate:"omitempty,oneof=socketio json"`
+	Headers     map[string]string `json:"headers"`
+	Connections int               `json:"connections" validate:"required,min=1"`
+	Rate        float64           `json:"rate" validate:"required,gt=0"`
+	DurationMs  int               `json:"durationMs" validate:"required,min=1"`
+	RampUpMs    int               `json:"rampUpMs" validate:"min=0"`
+	TimeoutMs   int               `json:"timeoutMs" validate:"min=0"`
+	Events      []string          `json:"events"`
+}
+
+func (r *loadTestCreateRequest) bind(c echo.Context, lc *loadtests.Config) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	lc.Target = r.Target
+	lc.Protocol = r.Protocol
+	lc.Header = http.Header{}
+	for name, value := range r.Headers {
+		lc.Header.Set(name, value)
+	}
+	lc.Connections = r.Connections
+	lc.Rate = r.Rate
+	lc.Duration = time.Duration(r.DurationMs) * time.Millisecond
+	lc.RampUp = time.Duration(r.RampUpMs) * time.Millisecond
+	lc.Timeout = time.Duration(r.TimeoutMs) * time.Millisecond
+	lc.Events = r.Events
+	return nil
+}
+
+type eventFuzzRequest struct {
+	Target          string            `json:"target" validate:"required"`
+	Protocol        string            `json:"protocol" validate:"omitempty,oneof=socketio json"`
+	Headers         map[string]string `json:"headers"`
+	TimeoutMs       int               `json:"timeoutMs" validate:"min=0,max=60000"`
+	QuietMs         int               `json:"quietMs" validate:"min=0,max=10000"`
+	Kinds           []string          `json:"kinds" validate:"dive,oneof=valid no-payload missing null wrong-type oversized extra-key deep-nesting"`
+	ErrorEvents     []string          `json:"errorEvents"`
+	MinimizeRuns    int               `json:"minimizeRuns" validate:"min=0,max=1000"`
+	OversizedLength int               `json:"oversizedLength" validate:"min=0,max=16777216"`
+	NestingDepth    int               `json:"nestingDepth" validate:"min=0,max=10000"`
+}
+
+func (r *eventFuzzRequest) bind(c echo.Context, fc *fuzz.Config) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	fc.Target = r.Target
+	fc.Protocol = r.Protocol
+	fc.Header = http.Header{}
+	for name, value := range r.Headers {
+		fc.Header.Set(name, value)
+	}
+	fc.Timeout = time.Duration(r.TimeoutMs) * time.Millisecond
+	fc.Quiet = time.Duration(r.QuietMs) * time.Millisecond
+	fc.Kinds = r.Kinds
+	fc.ErrorEvents = r.ErrorEvents
+	fc.MinimizeRuns = r.MinimizeRuns
+	fc.OversizedLength = r.OversizedLength
+	fc.NestingDepth = r.NestingDepth
+	return nil
+}
+
+type savedRequestCreateRequest struct {
+	Name             string         `json:"name" validate:"required"`
+	Description      string         `json:"description"`
+	FolderId         util.NullInt64 `json:"folderId"`
+	Environment      string         `json:"environment"`
+	Payload          util.RawJSON   `json:"payload"`
+	Ack              bool           `json:"ack"`
+	ExpectedResponse util.RawJSON   `json:"expectedResponse"`
+}
+
+func (r *savedRequestCreateRequest) bind(c echo.Context, sr *collections.Request) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	sr.Name = r.Name
+	sr.Description = r.Description
+	sr.FolderId = r.FolderId
+	sr.Environment = r.Environment
+	sr.Payload = r.Payload
+	sr.Ack = r.Ack
+	sr.ExpectedResponse = r.ExpectedResponse
+	return nil
+}
+
+type savedRequestUpdateRequest struct {
+	ID               uint64         `json:"id" validate:"required"`
+	Name             string         `json:"name" validate:"required"`
+	Description      string         `json:"description"`
+	FolderId         util.NullInt64 `json:"folderId"`
+	Environment      string         `json:"environment"`
+	Payload          util.RawJSON   `json:"payload"`
+	Ack              bool           `json:"ack"`
+	ExpectedResponse util.RawJSON   `json:"expectedResponse"`
+}
+
+func (r *savedRequestUpdateRequest) bind(c echo.Context, sr *collections.Request) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(c.Param("requestId"), 10, 64)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	sr.ID = r.ID
+	sr.Name = r.Name
+	sr.Description = r.Description
+	sr.FolderId = r.FolderId
+	sr.Environment = r.Environment
+	sr.Payload = r.Payload
+	sr.Ack = r.Ack
+	sr.ExpectedResponse = r.ExpectedResponse
+	return nil
+}
+
+type requestFolderCreateRequest struct {
+	Name     string         `json:"name" validate:"required"`
+	ParentId util.NullInt64 `json:"parentId"`
+}
+
+func (r *requestFolderCreateRequest) bind(c echo.Context, f *collections.Folder) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	f.Name = r.Name
+	f.ParentId = r.ParentId
+	return nil
+}
+
+type requestFolderUpdateRequest struct {
+	ID       uint64         `json:"id" validate:"required"`
+	Name     string         `json:"name" validate:"required"`
+	ParentId util.NullInt64 `json:"parentId"`
+}
+
+func (r *requestFolderUpdateRequest) bind(c echo.Context, f *collections.Folder) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(c.Param("folderId"), 10, 64)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	f.ID = r.ID
+	f.Name = r.Name
+	f.ParentId = r.ParentId
+	return nil
+}
+
+type environmentCreateRequest struct {
+	Name        string                `json:"name" validate:"required"`
+	Description string                `json:"description"`
+	BaseUrl     string                `json:"baseUrl" validate:"required"`
+	Protocol    string                `json:"protocol" validate:"omitempty,oneof=socketio json"`
+	Query       []*environments.Param `json:"query" validate:"dive,required"`
+	Headers     []*environments.Param `json:"headers" validate:"dive,required"`
+	Variables   []*environments.Param `json:"variables" validate:"dive,required"`
+}
+
+func (r *environmentCreateRequest) bind(c echo.Context, e *environments.Environment) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	e.Name = r.Name
+	e.Description = r.Description
+	e.BaseUrl = r.BaseUrl
+	e.Protocol = r.Protocol
+	e.Query = emptyParams(r.Query)
+	e.Headers = emptyParams(r.Headers)
+	e.Variables = emptyParams(r.Variables)
+	return nil
+}
+
+type environmentUpdateRequest struct {
+	ID          uint64                `json:"id" validate:"required"`
+	Name        string                `json:"name" validate:"required"`
+	Description string                `json:"description"`
+	BaseUrl     string                `json:"baseUrl" validate:"required"`
+	Protocol    string                `json:"protocol" validate:"omitempty,oneof=socketio json"`
+	Query       []*environments.Param `json:"query" validate:"dive,required"`
+	Headers     []*environments.Param `json:"headers" validate:"dive,required"`
+	Variables   []*environments.Param `json:"variables" validate:"dive,required"`
+}
+
+func (r *environmentUpdateRequest) bind(c echo.Context, e *environments.Environment) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	e.ID = r.ID
+	e.Name = r.Name
+	e.Description = r.Description
+	e.BaseUrl = r.BaseUrl
+	e.Protocol = r.Protocol
+	e.Query = emptyParams(r.Query)
+	e.Headers = emptyParams(r.Headers)
+	e.Variables = emptyParams(r.Variables)
+	return nil
+}
+
+func emptyParams(list []*environments.Param) []*environments.Param {
+	if list == nil {
+		return make([]*environments.Param, 0)
+	}
+	return list
+}
+
+type monitorCreateRequest struct {
+	Name        string `json:"name" validate:"required"`
+	ScenarioId  uint64 `json:"scenarioId" validate:"required"`
+	Environment string `json:"environment" validate:"required"`
+	IntervalMs  int64  `json:"intervalMs" validate:"required,min=1000"`
+	Enabled     bool   `json:"enabled"`
+}
+
+func (r *monitorCreateRequest) bind(c echo.Context, m *monitors.Monitor) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	m.Name = r.Name
+	m.ScenarioId = r.ScenarioId
+	m.Environment = r.Environment
+	m.IntervalMs = r.IntervalMs
+	m.Enabled = r.Enabled
+	m.Status = monitors.StatusUnknown
+	return nil
+}
+
+type monitorUpdateRequest struct {
+	ID          uint64 `json:"id" validate:"required"`
+	Name        string `json:"name" validate:"required"`
+	ScenarioId  uint64 `json:"scenarioId" validate:"required"`
+	Environment string `json:"environment" validate:"required"`
+	IntervalMs  int64  `json:"intervalMs" validate:"required,min=1000"`
+	Enabled     bool   `json:"enabled"`
+}
+
+func (r *monitorUpdateRequest) bind(c echo.Context, m *monitors.Monitor) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	m.ID = r.ID
+	m.Name = r.Name
+	m.ScenarioId = r.ScenarioId
+	m.Environment = r.Environment
+	m.IntervalMs = r.IntervalMs
+	m.Enabled = r.Enabled
+	return nil
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+type projectCreateRequest struct {
+	Name        string `json:"name" validate:"required"`
+	Slug        string `json:"slug" validate:"required,max=64"`
+	Description string `json:"description"`
+}
+
+func (r *projectCreateRequest) bind(c echo.Context, p *projects.Project) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	if !slugPattern.MatchString(r.Slug) {
+		return fmt.Errorf("slug %q may contain only lowercase letters, digits and dashes", r.Slug)
+	}
+	p.Name = r.Name
+	p.Slug = r.Slug
+	p.Description = r.Description
+	return nil
+}
+
+type projectUpdateRequest struct {
+	ID          uint64 `json:"id" validate:"required"`
+	Name        string `json:"name" validate:"required"`
+	Slug        string `json:"slug" validate:"required,max=64"`
+	Description string `json:"description"`
+}
+
+func (r *projectUpdateRequest) bind(c echo.Context, p *projects.Project) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(c.Param("project"), 10, 64)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	if !slugPattern.MatchString(r.Slug) {
+		return fmt.Errorf("slug %q may contain only lowercase letters, digits and dashes", r.Slug)
+	}
+	p.ID = r.ID
+	p.Name = r.Name
+	p.Slug = r.Slug
+	p.Description = r.Description
+	return nil
+}
+
+type loginRequest struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+func (r *loginRequest) bind(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	return c.Validate(r)
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+func (r *refreshTokenRequest) bind(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	return c.Validate(r)
+}
+
+type userCreateRequest struct {
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Name     string `json:"name" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+	// Role is reader when empty
+	Role string `json:"role"`
+}
+
+func (r *userCreateRequest) bind(c echo.Context, u *users.User) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	if len(r.Role) < 1 {
+		r.Role = users.RoleReader
+	}
+	if !users.ValidRole(r.Role) {
+		return fmt.Errorf("unknown role %q", r.Role)
+	}
+	u.Email = r.Email
+	u.Name = r.Name
+	u.Role = r.Role
+	return u.SetPassword(r.Password)
+}
+
+type userUpdateRequest struct {
+	ID    uint64 `json:"id" validate:"required"`
+	Email string `json:"email" validate:"required,email,max=255"`
+	Name  string `json:"name" validate:"required"`
+	// Password is kept when empty
+	Password string `json:"password" validate:"omitempty,min=8"`
+}
+
+func (r *userUpdateRequest) bind(c echo.Context, u *users.User) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	u.ID = r.ID
+	u.Email = r.Email
+	u.Name = r.Name
+	if len(r.Password) > 0 {
+		return u.SetPassword(r.Password)
+	}
+	return nil
+}
+
+type roleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+func (r *roleRequest) bind(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	if !users.ValidRole(r.Role) {
+		return fmt.Errorf("unknown role %q", r.Role)
+	}
+	return nil
+}
+
+type apiTokenCreateRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+	// Kind is personal when empty
+	Kind   string   `json:"kind" validate:"omitempty,oneof=personal service"`
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+	// ExpiresAt is optional, tokens without it are valid until revoked
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+func (r *apiTokenCreateRequest) bind(c echo.Context) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	if len(r.Kind) < 1 {
+		r.Kind = users.APITokenPersonal
+	}
+	for _, scope := range r.Scopes {
+		if !users.ValidScope(scope) {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(time.Now()) {
+		return errors.New("expiresAt must be in the future")
+	}
+	return nil
+}
+
+type auditFilterRequest struct {
+	Entity    string `query:"entity"`
+	EntityId  string `query:"entityId"`
+	ProjectId string `query:"projectId"`
+	ActorId   string `query:"actorId"`
+	Action    string `query:"action" validate:"omitempty,oneof=create update delete"`
+	Source    string `query:"source" validate:"omitempty,oneof=rest graphql import"`
+	RequestId string `query:"requestId"`
+	// Bounds of the creation time in RFC 3339
+	From string `query:"from"`
+	To   string `query:"to"`
+}
+
+func (r *auditFilterRequest) bind(c echo.Context, f *audit.Filter) error {
+	if err := c.Bind(r); err != nil {
+		return err
+	}
+	if err := c.Validate(r); err != nil {
+		return err
+	}
+	f.Entity = r.Entity
+	f.Action = r.Action
+	f.Source = r.Source
+	f.RequestId = r.RequestId
+	ids := []struct {
+		name  string
+		value string
+		dst   *uint64
+	}{
+		{"entityId", r.EntityId, &f.EntityId},
+		{"projectId", r.ProjectId, &f.ProjectId},
+		{"actorId", r.ActorId, &f.ActorId},
+	}
+	for _, id := range ids {
+		if len(id.value) < 1 {
+			continue
+		}
+		v, err := strconv.ParseUint(id.value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s must be a number", id.name)
+		}
+		*id.dst = v
+	}
+	times := []struct {
+		name  string
+		value string
+		dst   *time.Time
+	}{
+		{"from", r.From, &f.From},
+		{"to", r.To, &f.To},
+	}
+	for _, t := range times {
+		if len(t.value) < 1 {
+			continue
+		}
+		v, err := time.Parse(time.RFC3339, t.value)
+		if err != nil {
+			return fmt.Errorf("%s must be a time in RFC 3339", t.name)
 		}
-		e.Fields[index] = f
+		*t.dst = v
 	}
 	return nil
 }
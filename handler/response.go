@@ -1,5 +1,7 @@
 package handler
 
+import "github.com/nskondratev/api-page-go-back/users"
+
 type responseEnvelope struct {
 	Data interface{} `json:"data"`
 }
@@ -12,3 +14,9 @@ type paginationResponseEnvelope struct {
 type errorResponseEnvelope struct {
 	Error string `json:"error"`
 }
+
+// permissionErrorResponseEnvelope names the permission missing for the request
+type permissionErrorResponseEnvelope struct {
+	Error      string           `json:"error"`
+	Permission users.Permission `json:"permission"`
+}
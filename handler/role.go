@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/users"
+	"net/http"
+	"strconv"
+)
+
+type userRoles struct {
+	Role     string               `json:"role"`
+	Projects []*users.ProjectRole `json:"projects"`
+}
+
+// GetUserRoles responds with the global role of the user and its overrides in projects.
+func (h *Handler) GetUserRoles(c echo.Context) error {
+	u, code, err := h.userFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return h.respondWithRoles(c, u)
+}
+
+// SetUserRole replaces the global role of the user. Users can not change their own role.
+func (h *Handler) SetUserRole(c echo.Context) error {
+	u, code, err := h.userFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	req := &roleRequest{}
+	if err := req.bind(c); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if current := users.FromContext(c.Request().Context()); current != nil && current.ID == u.ID {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: "you can not change your own role",
+		})
+	}
+	before := audit.Snapshot(u)
+	u.Role = req.Role
+	if err := h.userStore.Update(u); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityUser,
+		EntityId: u.ID,
+		Action:   audit.ActionUpdate,
+		Before:   before,
+		After:    audit.Snapshot(u),
+	})
+	return h.respondWithRoles(c, u)
+}
+
+// SetUserProjectRole overrides the role of the user in the project.
+func (h *Handler) SetUserProjectRole(c echo.Context) error {
+	u, projectId, code, err := h.userProjectFromParams(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	req := &roleRequest{}
+	if err := req.bind(c); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.projectRoleOf(u.ID, projectId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	role := &users.ProjectRole{UserId: u.ID, ProjectId: projectId, Role: req.Role}
+	if err := h.userStore.SetProjectRole(role); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	action := audit.ActionCreate
+	if existing != nil {
+		action = audit.ActionUpdate
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityProjectRole,
+		EntityId:  u.ID,
+		ProjectId: projectId,
+		Action:    action,
+		Before:    audit.Snapshot(existing),
+		After:     audit.Snapshot(role),
+	})
+	return h.respondWithRoles(c, u)
+}
+
+// DeleteUserProjectRole removes the override, the global role of the user applies to the project again.
+func (h *Handler) DeleteUserProjectRole(c echo.Context) error {
+	u, projectId, code, err := h.userProjectFromParams(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.projectRoleOf(u.ID, projectId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.userStore.DeleteProjectRole(u.ID, projectId); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing != nil {
+		h.record(c, &audit.Entry{
+			Entity:    audit.EntityProjectRole,
+			EntityId:  u.ID,
+			ProjectId: projectId,
+			Action:    audit.ActionDelete,
+			Before:    audit.Snapshot(existing),
+		})
+	}
+	return h.respondWithRoles(c, u)
+}
+
+func (h *Handler) respondWithRoles(c echo.Context, u *users.User) error {
+	overrides, err := h.userStore.ListProjectRoles(u.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: &userRoles{
+			Role:     u.Role,
+			Projects: overrides,
+		},
+	})
+}
+
+// projectRoleOf returns the override of the role of the user in the project, nil when there is none.
+func (h *Handler) projectRoleOf(userId, projectId uint64) (*users.ProjectRole, error) {
+	overrides, err := h.userStore.ListProjectRoles(userId)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range overrides {
+		if r.ProjectId == projectId {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (h *Handler) userProjectFromParams(c echo.Context) (*users.User, uint64, int, error) {
+	u, code, err := h.userFromParam(c)
+	if err != nil {
+		return nil, 0, code, err
+	}
+	projectId, err := strconv.ParseUint(c.Param("projectId"), 10, 64)
+	if err != nil {
+		return nil, 0, http.StatusUnprocessableEntity, err
+	}
+	if h.projectStore != nil {
+		p, err := h.projectStore.GetById(projectId)
+		if err != nil {
+			return nil, 0, http.StatusInternalServerError, err
+		}
+		if p == nil {
+			return nil, 0, http.StatusUnprocessableEntity, fmt.Errorf("project %d does not exist", projectId)
+		}
+	}
+	return u, projectId, http.StatusOK, nil
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
+	"github.com/nskondratev/api-page-go-back/users"
 	"strings"
 )
 
@@ -15,27 +16,186 @@ func (h *Handler) Register(rg *echo.Group, bg *echo.Group) {
 		Browse:  false,
 	}))
 
+	// Auth routes are open, the other routes need a user to change anything
+	if h.tokenIssuer != nil {
+		auth := rg.Group("/auth")
+		auth.POST("/login", h.Login)
+		auth.POST("/refresh", h.RefreshTokens)
+		auth.POST("/logout", h.Logout)
+		rg = rg.Group("", h.authenticate)
+		rg.GET("/auth/me", h.GetCurrentUser)
+
+		// Users routes
+		manageUsers := h.allow(users.PermUsersManage)
+		user := rg.Group("/users")
+		user.GET("", h.ListUsers, manageUsers)
+		user.POST("", h.CreateUser, manageUsers)
+		user.GET("/:id", h.GetUser, manageUsers)
+		user.POST("/:id", h.UpdateUser, manageUsers)
+		user.DELETE("/:id", h.DeleteUser, manageUsers)
+		user.GET("/:id/roles", h.GetUserRoles, manageUsers)
+		user.POST("/:id/roles", h.SetUserRole, manageUsers)
+		user.POST("/:id/roles/:projectId", h.SetUserProjectRole, manageUsers)
+		user.DELETE("/:id/roles/:projectId", h.DeleteUserProjectRole, manageUsers)
+
+		// API tokens routes, service tokens are checked by the handlers
+		apiToken := rg.Group("/tokens")
+		apiToken.GET("", h.ListAPITokens, h.requireSession)
+		apiToken.POST("", h.CreateAPIToken, h.requireSession)
+		apiToken.DELETE("/:id", h.RevokeAPIToken, h.requireSession)
+	}
+
+	// Routes of the default project
+	h.registerProjectRoutes(rg)
+
+	// Routes of other projects are prefixed with the slug of the project
+	if h.projectStore != nil {
+		h.registerProjectRoutes(rg.Group("/projects/:project"), h.projectScope)
+	}
+
+	// Projects routes. The ID param shares the name with the slug param of the prefixed routes,
+	// echo keeps one name per path segment.
+	project := rg.Group("/projects")
+	project.GET("", h.ListProjects, h.allow(users.PermProjectsRead))
+	project.POST("", h.CreateProject, h.allow(users.PermProjectsManage))
+	project.GET("/:project", h.GetProject, h.allow(users.PermProjectsRead))
+	project.POST("/:project", h.UpdateProject, h.allow(users.PermProjectsManage))
+	project.DELETE("/:project", h.DeleteProject, h.allow(users.PermProjectsManage))
+
+	// Audit log of the changes
+	rg.GET("/audit", h.ListAuditEntries, h.allow(users.PermAuditRead))
+
+	readEvents, writeEvents := h.allow(users.PermEventsRead), h.allow(users.PermEventsWrite)
+
+	// Shared types routes
+	sharedType := rg.Group("/types")
+	sharedType.GET("", h.ListTypes, readEvents)
+	sharedType.POST("", h.CreateType, writeEvents)
+	sharedType.GET("/:id", h.GetType, readEvents)
+	sharedType.POST("/:id", h.UpdateType, writeEvents)
+	sharedType.DELETE("/:id", h.DeleteType, writeEvents)
+
+	// Mock server routes. Socket.io clients add the trailing slash to the path.
+	if h.mockServer != nil {
+		rg.GET("/mock", h.HandleMock)
+		rg.GET("/mock/", h.HandleMock)
+	}
+
+}
+
+// registerProjectRoutes adds the routes of the pages, the events and the tests of a single project to the group.
+// Permissions are checked against the role of the user in the project.
+func (h *Handler) registerProjectRoutes(g *echo.Group, m ...echo.MiddlewareFunc) {
+	with := func(perms ...users.Permission) []echo.MiddlewareFunc {
+		list := append([]echo.MiddlewareFunc{}, m...)
+		for _, perm := range perms {
+			list = append(list, h.allowInProject(perm))
+		}
+		return list
+	}
+	readEvents, writeEvents := with(users.PermEventsRead), with(users.PermEventsWrite)
+	readTests, writeTests, runTests := with(users.PermTestsRead), with(users.PermTestsWrite), with(users.PermTestsRun)
+
 	// Events routes
-	event := rg.Group("/events")
-	event.GET("", h.ListEvents)
-	event.POST("", h.CreateEvent)
-	event.GET("/:id", h.GetEvent)
-	event.POST("/:id", h.UpdateEvent)
-	event.DELETE("/:id", h.DeleteEvent)
+	event := g.Group("/events")
+	event.GET("", h.ListEvents, readEvents...)
+	event.POST("", h.CreateEvent, writeEvents...)
+	event.GET("/:id", h.GetEvent, readEvents...)
+	event.POST("/:id", h.UpdateEvent, writeEvents...)
+	event.DELETE("/:id", h.DeleteEvent, writeEvents...)
+	event.POST("/:id/validate", h.ValidateEventPayload, readEvents...)
+	event.GET("/:id/example", h.GetEventExample, readEvents...)
+	event.GET("/:id/fuzz", h.ListFuzzRuns, readTests...)
+	event.POST("/:id/fuzz", h.FuzzEvent, runTests...)
+	event.GET("/:id/fuzz/:runId", h.GetFuzzRun, readTests...)
+	event.DELETE("/:id/fuzz/:runId", h.DeleteFuzzRun, writeTests...)
+	event.POST("/infer", h.InferEvent, readEvents...)
+	event.PATCH("/:id/fields/:fieldId", h.PatchEventField, writeEvents...)
+	event.GET("/:id/requests", h.ListSavedRequests, readTests...)
+	event.POST("/:id/requests", h.CreateSavedRequest, writeTests...)
+	event.GET("/:id/requests/export", h.ExportSavedRequests, readTests...)
+	event.POST("/:id/requests/import", h.ImportSavedRequests, writeTests...)
+	event.GET("/:id/requests/:requestId", h.GetSavedRequest, readTests...)
+	event.POST("/:id/requests/:requestId", h.UpdateSavedRequest, writeTests...)
+	event.DELETE("/:id/requests/:requestId", h.DeleteSavedRequest, writeTests...)
+	event.POST("/:id/requests/:requestId/run", h.RunSavedRequest, runTests...)
+	event.POST("/:id/folders", h.CreateRequestFolder, writeTests...)
+	event.POST("/:id/folders/:folderId", h.UpdateRequestFolder, writeTests...)
+	event.DELETE("/:id/folders/:folderId", h.DeleteRequestFolder, writeTests...)
 
 	// Pages routes
-	page := rg.Group("/pages")
-	page.GET("", h.ListPages)
-	page.POST("", h.CreatePage)
-	page.GET("/:id", h.GetPage)
-	page.POST("/:id", h.UpdatePage)
-	page.DELETE("/:id", h.DeletePage)
-
-	// WebSocket route
-	rg.GET("/ws", h.HandleWs)
-
-	// GraphQL route
-	rg.POST("/graphql", h.handleGraphQLQuery)
+	readPages, writePages := with(users.PermPagesRead), with(users.PermPagesWrite)
+	page := g.Group("/pages")
+	page.GET("", h.ListPages, readPages...)
+	page.POST("", h.CreatePage, writePages...)
+	page.GET("/:id", h.GetPage, readPages...)
+	page.POST("/:id", h.UpdatePage, writePages...)
+	page.DELETE("/:id", h.DeletePage, writePages...)
+
+	// Snapshots routes
+	snapshot := g.Group("/snapshots")
+	snapshot.GET("", h.ListSnapshots, readEvents...)
+	snapshot.POST("", h.CreateSnapshot, writeEvents...)
+	snapshot.GET("/diff", h.DiffSnapshots, readEvents...)
+	snapshot.GET("/:id", h.GetSnapshot, readEvents...)
+	snapshot.DELETE("/:id", h.DeleteSnapshot, writeEvents...)
+
+	// Recordings routes
+	recording := g.Group("/recordings")
+	recording.GET("", h.ListRecordings, readTests...)
+	recording.GET("/:id", h.GetRecording, readTests...)
+	recording.DELETE("/:id", h.DeleteRecording, writeTests...)
+	recording.GET("/:id/export", h.ExportRecording, readTests...)
+	recording.POST("/:id/replay", h.ReplayRecording, runTests...)
+
+	// Scenarios routes
+	scenario := g.Group("/scenarios")
+	scenario.GET("", h.ListScenarios, readTests...)
+	scenario.POST("", h.CreateScenario, writeTests...)
+	scenario.GET("/:id", h.GetScenario, readTests...)
+	scenario.POST("/:id", h.UpdateScenario, writeTests...)
+	scenario.DELETE("/:id", h.DeleteScenario, writeTests...)
+	scenario.POST("/:id/run", h.RunScenario, runTests...)
+
+	// Load tests routes
+	loadTest := g.Group("/loadtests")
+	loadTest.GET("", h.ListLoadTests, readTests...)
+	loadTest.POST("", h.CreateLoadTest, runTests...)
+	loadTest.GET("/:id", h.GetLoadTest, readTests...)
+	loadTest.DELETE("/:id", h.DeleteLoadTest, writeTests...)
+	loadTest.GET("/:id/compare/:otherId", h.CompareLoadTests, readTests...)
+
+	// Environments routes
+	environment := g.Group("/environments")
+	environment.GET("", h.ListEnvironments, readTests...)
+	environment.POST("", h.CreateEnvironment, writeTests...)
+	environment.GET("/:id", h.GetEnvironment, readTests...)
+	environment.POST("/:id", h.UpdateEnvironment, writeTests...)
+	environment.DELETE("/:id", h.DeleteEnvironment, writeTests...)
+
+	// Monitors routes
+	monitor := g.Group("/monitors")
+	monitor.GET("", h.ListMonitors, readTests...)
+	monitor.POST("", h.CreateMonitor, writeTests...)
+	monitor.GET("/:id", h.GetMonitor, readTests...)
+	monitor.POST("/:id", h.UpdateMonitor, writeTests...)
+	monitor.DELETE("/:id", h.DeleteMonitor, writeTests...)
+	monitor.GET("/:id/checks", h.ListMonitorChecks, readTests...)
+	monitor.GET("/:id/uptime", h.GetMonitorUptime, readTests...)
+	monitor.GET("/:id/last-failure", h.GetMonitorLastFailure, readTests...)
+
+	// Coverage routes
+	g.GET("/coverage", h.GetCoverage, readEvents...)
+	g.POST("/coverage", h.UploadCoverage, readEvents...)
+
+	// Docs routes
+	g.GET("/docs/events", h.GetEventsDocs, readEvents...)
+
+	// WebSocket route, the upgrade needs a user who reads pages and events
+	g.GET("/ws", h.HandleWs, append(with(users.PermPagesRead, users.PermEventsRead), h.requireUser)...)
+
+	// GraphQL route, the resolvers check the permissions
+	g.POST("/graphql", h.handleGraphQLQuery, m...)
 }
 
 func SPASkipper(c echo.Context) bool {
@@ -0,0 +1,390 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/labstack/echo"
+	auditStore "github.com/nskondratev/api-page-go-back/audit/store"
+	collectionStore "github.com/nskondratev/api-page-go-back/collections/store"
+	environmentStore "github.com/nskondratev/api-page-go-back/environments/store"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/gql"
+	loadTestStore "github.com/nskondratev/api-page-go-back/loadtests/store"
+	monitorStore "github.com/nskondratev/api-page-go-back/monitors/store"
+	"github.com/nskondratev/api-page-go-back/pages"
+	pageStore "github.com/nskondratev/api-page-go-back/pages/store"
+	"github.com/nskondratev/api-page-go-back/projects"
+	projectStore "github.com/nskondratev/api-page-go-back/projects/store"
+	recordingStore "github.com/nskondratev/api-page-go-back/recordings/store"
+	registryStore "github.com/nskondratev/api-page-go-back/registry/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	scenarioStore "github.com/nskondratev/api-page-go-back/scenarios/store"
+	snapshotStore "github.com/nskondratev/api-page-go-back/snapshots/store"
+	"github.com/nskondratev/api-page-go-back/users"
+	userStore "github.com/nskondratev/api-page-go-back/users/store"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// routePermissions lists the permissions required by every route in the order they are checked, routes
+// of projects are listed without the /projects/:project prefix. Routes without permissions check none.
+var routePermissions = map[string][]users.Permission{
+	"POST /api/auth/login":                   nil,
+	"POST /api/auth/refresh":                 nil,
+	"POST /api/auth/logout":                  nil,
+	"GET /api/auth/me":                       nil,
+	"GET /api/users":                         {users.PermUsersManage},
+	"POST /api/users":                        {users.PermUsersManage},
+	"GET /api/users/:id":                     {users.PermUsersManage},
+	"POST /api/users/:id":                    {users.PermUsersManage},
+	"DELETE /api/users/:id":                  {users.PermUsersManage},
+	"GET /api/users/:id/roles":               {users.PermUsersManage},
+	"POST /api/users/:id/roles":              {users.PermUsersManage},
+	"POST /api/users/:id/roles/:projectId":   {users.PermUsersManage},
+	"DELETE /api/users/:id/roles/:projectId": {users.PermUsersManage},
+	"GET /api/tokens":                        nil,
+	"POST /api/tokens":                       nil,
+	"DELETE /api/tokens/:id":                 nil,
+	"GET /api/audit":                         {users.PermAuditRead},
+
+	"GET /api/events":                              {users.PermEventsRead},
+	"POST /api/events":                             {users.PermEventsWrite},
+	"GET /api/events/:id":                          {users.PermEventsRead},
+	"POST /api/events/:id":                         {users.PermEventsWrite},
+	"DELETE /api/events/:id":                       {users.PermEventsWrite},
+	"POST /api/events/:id/validate":                {users.PermEventsRead},
+	"GET /api/events/:id/example":                  {users.PermEventsRead},
+	"GET /api/events/:id/fuzz":                     {users.PermTestsRead},
+	"POST /api/events/:id/fuzz":                    {users.PermTestsRun},
+	"GET /api/events/:id/fuzz/:runId":              {users.PermTestsRead},
+	"DELETE /api/events/:id/fuzz/:runId":           {users.PermTestsWrite},
+	"POST /api/events/infer":                       {users.PermEventsRead},
+	"PATCH /api/events/:id/fields/:fieldId":        {users.PermEventsWrite},
+	"GET /api/events/:id/requests":                 {users.PermTestsRead},
+	"POST /api/events/:id/requests":                {users.PermTestsWrite},
+	"GET /api/events/:id/requests/export":          {users.PermTestsRead},
+	"POST /api/events/:id/requests/import":         {users.PermTestsWrite},
+	"GET /api/events/:id/requests/:requestId":      {users.PermTestsRead},
+	"POST /api/events/:id/requests/:requestId":     {users.PermTestsWrite},
+	"DELETE /api/events/:id/requests/:requestId":   {users.PermTestsWrite},
+	"POST /api/events/:id/requests/:requestId/run": {users.PermTestsRun},
+	"POST /api/events/:id/folders":                 {users.PermTestsWrite},
+	"POST /api/events/:id/folders/:folderId":       {users.PermTestsWrite},
+	"DELETE /api/events/:id/folders/:folderId":     {users.PermTestsWrite},
+	"GET /api/pages":                               {users.PermPagesRead},
+	"POST /api/pages":                              {users.PermPagesWrite},
+	"GET /api/pages/:id":                           {users.PermPagesRead},
+	"POST /api/pages/:id":                          {users.PermPagesWrite},
+	"DELETE /api/pages/:id":                        {users.PermPagesWrite},
+	"GET /api/coverage":                            {users.PermEventsRead},
+	"POST /api/coverage":                           {users.PermEventsRead},
+	"GET /api/docs/events":                         {users.PermEventsRead},
+	"GET /api/ws":                                  {users.PermPagesRead, users.PermEventsRead},
+	"POST /api/graphql":                            nil,
+
+	"GET /api/projects":             {users.PermProjectsRead},
+	"POST /api/projects":            {users.PermProjectsManage},
+	"GET /api/projects/:project":    {users.PermProjectsRead},
+	"POST /api/projects/:project":   {users.PermProjectsManage},
+	"DELETE /api/projects/:project": {users.PermProjectsManage},
+
+	"GET /api/types":        {users.PermEventsRead},
+	"POST /api/types":       {users.PermEventsWrite},
+	"GET /api/types/:id":    {users.PermEventsRead},
+	"POST /api/types/:id":   {users.PermEventsWrite},
+	"DELETE /api/types/:id": {users.PermEventsWrite},
+
+	"GET /api/snapshots":        {users.PermEventsRead},
+	"POST /api/snapshots":       {users.PermEventsWrite},
+	"GET /api/snapshots/diff":   {users.PermEventsRead},
+	"GET /api/snapshots/:id":    {users.PermEventsRead},
+	"DELETE /api/snapshots/:id": {users.PermEventsWrite},
+
+	"GET /api/recordings":                     {users.PermTestsRead},
+	"GET /api/recordings/:id":                 {users.PermTestsRead},
+	"DELETE /api/recordings/:id":              {users.PermTestsWrite},
+	"GET /api/recordings/:id/export":          {users.PermTestsRead},
+	"POST /api/recordings/:id/replay":         {users.PermTestsRun},
+	"GET /api/scenarios":                      {users.PermTestsRead},
+	"POST /api/scenarios":                     {users.PermTestsWrite},
+	"GET /api/scenarios/:id":                  {users.PermTestsRead},
+	"POST /api/scenarios/:id":                 {users.PermTestsWrite},
+	"DELETE /api/scenarios/:id":               {users.PermTestsWrite},
+	"POST /api/scenarios/:id/run":             {users.PermTestsRun},
+	"GET /api/loadtests":                      {users.PermTestsRead},
+	"POST /api/loadtests":                     {users.PermTestsRun},
+	"GET /api/loadtests/:id":                  {users.PermTestsRead},
+	"DELETE /api/loadtests/:id":               {users.PermTestsWrite},
+	"GET /api/loadtests/:id/compare/:otherId": {users.PermTestsRead},
+	"GET /api/environments":                   {users.PermTestsRead},
+	"POST /api/environments":                  {users.PermTestsWrite},
+	"GET /api/environments/:id":               {users.PermTestsRead},
+	"POST /api/environments/:id":              {users.PermTestsWrite},
+	"DELETE /api/environments/:id":            {users.PermTestsWrite},
+	"GET /api/monitors":                       {users.PermTestsRead},
+	"POST /api/monitors":                      {users.PermTestsWrite},
+	"GET /api/monitors/:id":                   {users.PermTestsRead},
+	"POST /api/monitors/:id":                  {users.PermTestsWrite},
+	"DELETE /api/monitors/:id":                {users.PermTestsWrite},
+	"GET /api/monitors/:id/checks":            {users.PermTestsRead},
+	"GET /api/monitors/:id/uptime":            {users.PermTestsRead},
+	"GET /api/monitors/:id/last-failure":      {users.PermTestsRead},
+
+	"GET /api/mock":  nil,
+	"GET /api/mock/": nil,
+}
+
+// Anonymous requests to these routes are accepted although they change something
+var anonymousRoutes = map[string]bool{
+	"POST /api/auth/login":   true,
+	"POST /api/auth/refresh": true,
+	"POST /api/auth/logout":  true,
+}
+
+// Anonymous reads of these routes are rejected
+var signedInRoutes = map[string]bool{
+	"GET /api/auth/me": true,
+	"GET /api/tokens":  true,
+	"GET /api/ws":      true,
+}
+
+func TestHandler_RoutePermissions(t *testing.T) {
+	e, tokens := setupRoutesHandlerTest(t)
+
+	routes := e.Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Path+routes[i].Method < routes[j].Path+routes[j].Method
+	})
+	covered := make(map[string]bool)
+	for _, route := range routes {
+		// Catch-all routes added by echo to the groups with middleware
+		if strings.HasPrefix(route.Name, "github.com/labstack/echo.(*Group).Use") || strings.HasSuffix(route.Path, "*") {
+			continue
+		}
+		key := routeKey(route)
+		perms, ok := routePermissions[key]
+		if !ok {
+			t.Errorf("Route %s %s is missing in the permissions matrix", route.Method, route.Path)
+			continue
+		}
+		covered[key] = true
+
+		for _, role := range []string{"", users.RoleReader, users.RoleEditor, users.RoleAdmin} {
+			rec := serveAuthTestRequest(e, route.Method, routePath(route.Path, projects.DefaultSlug), `{}`, tokens[role])
+
+			anonymous := len(role) < 1
+			if anonymous {
+				role = users.RoleReader
+			}
+			switch {
+			case anonymous && !anonymousRoutes[key] && (!readOnly(route.Method) || signedInRoutes[key]):
+				if rec.Code != http.StatusUnauthorized {
+					t.Errorf("%s %s is not rejected for anonymous request. Response code: %d, response body: %s", route.Method, route.Path, rec.Code, rec.Body.String())
+				}
+			default:
+				checkRouteRole(t, route, perms, role, rec)
+			}
+		}
+	}
+
+	for key := range routePermissions {
+		if !covered[key] {
+			t.Errorf("Route %s of the permissions matrix is not registered", key)
+		}
+	}
+}
+
+// TestHandler_ProjectRoutePermissions checks the routes of a non-default project with the roles in the
+// project overriding the global ones: the reader edits the chat project and the editor reads it.
+func TestHandler_ProjectRoutePermissions(t *testing.T) {
+	e, tokens := setupRoutesHandlerTest(t)
+
+	const chatProjectId = 2
+	overrides := map[string]string{users.RoleReader: users.RoleEditor, users.RoleEditor: users.RoleReader}
+	ids := map[string]uint64{users.RoleReader: 2, users.RoleEditor: 3}
+	for role, override := range overrides {
+		path := fmt.Sprintf("/api/users/%d/roles/%d", ids[role], chatProjectId)
+		rec := serveAuthTestRequest(e, http.MethodPost, path, fmt.Sprintf(`{"role":"%s"}`, override), tokens[users.RoleAdmin])
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Can not override role of %s in the project. Response code: %d, response body: %s", role, rec.Code, rec.Body.String())
+		}
+	}
+
+	for _, route := range e.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/projects/:project/") || strings.HasPrefix(route.Name, "github.com/labstack/echo.(*Group).Use") {
+			continue
+		}
+		perms, ok := routePermissions[routeKey(route)]
+		if !ok {
+			t.Errorf("Route %s %s is missing in the permissions matrix", route.Method, route.Path)
+			continue
+		}
+		for role, override := range overrides {
+			rec := serveAuthTestRequest(e, route.Method, routePath(route.Path, "chat"), `{}`, tokens[role])
+			checkRouteRole(t, route, perms, override, rec)
+		}
+	}
+}
+
+func TestHandler_ProjectRoles(t *testing.T) {
+	e, tokens := setupRoutesHandlerTest(t)
+
+	cases := []struct {
+		method string
+		path   string
+		token  string
+		handlerCreateTestCase
+	}{
+		{http.MethodPost, "/api/projects/chat/pages", tokens[users.RoleReader], handlerCreateTestCase{`{"title":"Chat","text":"Chat API"}`, http.StatusForbidden, `missing permission \"pages.write\"`}},
+		{http.MethodGet, "/api/ws?target=ws%3A%2F%2Fstaging.example.com", tokens[users.RoleReader], handlerCreateTestCase{``, http.StatusForbidden, `"permission":"tests.run"`}},
+		{http.MethodGet, "/api/ws?target=ws%3A%2F%2Fstaging.example.com&record=Login", tokens[users.RoleEditor], handlerCreateTestCase{``, http.StatusOK, emptyStr}},
+		{http.MethodPost, "/api/events/infer", tokens[users.RoleReader], handlerCreateTestCase{`{"value":"logout","samples":[{"name":"John"}]}`, http.StatusOK, `"applied":false`}},
+		{http.MethodPost, "/api/events/infer", tokens[users.RoleReader], handlerCreateTestCase{`{"value":"logout","samples":[{"name":"John"}],"apply":true}`, http.StatusForbidden, `"permission":"events.write"`}},
+		{http.MethodPost, "/api/users/2/roles/2", tokens[users.RoleEditor], handlerCreateTestCase{`{"role":"editor"}`, http.StatusForbidden, `"permission":"users.manage"`}},
+		{http.MethodPost, "/api/users/2/roles/2", tokens[users.RoleAdmin], handlerCreateTestCase{`{"role":"owner"}`, http.StatusUnprocessableEntity, `unknown role \"owner\"`}},
+		{http.MethodPost, "/api/users/2/roles/5", tokens[users.RoleAdmin], handlerCreateTestCase{`{"role":"editor"}`, http.StatusUnprocessableEntity, `project 5 does not exist`}},
+		{http.MethodPost, "/api/users/2/roles/2", tokens[users.RoleAdmin], handlerCreateTestCase{`{"role":"editor"}`, http.StatusOK, `{"role":"reader","projects":[{"userId":2,"projectId":2,"role":"editor"`}},
+		{http.MethodPost, "/api/projects/chat/pages", tokens[users.RoleReader], handlerCreateTestCase{`{"title":"Chat","text":"Chat API"}`, http.StatusOK, `"createdBy":2`}},
+		{http.MethodPost, "/api/pages", tokens[users.RoleReader], handlerCreateTestCase{`{"title":"Default","text":"Default API"}`, http.StatusForbidden, `missing permission \"pages.write\"`}},
+		{http.MethodPost, "/api/types", tokens[users.RoleReader], handlerCreateTestCase{`{"name":"User"}`, http.StatusForbidden, `missing permission \"events.write\"`}},
+		{http.MethodDelete, "/api/users/2/roles/2", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `{"role":"reader","projects":[]}`}},
+		{http.MethodPost, "/api/projects/chat/pages", tokens[users.RoleReader], handlerCreateTestCase{`{"title":"Chat","text":"Chat API"}`, http.StatusForbidden, `missing permission \"pages.write\"`}},
+		{http.MethodPost, "/api/users/2/roles", tokens[users.RoleAdmin], handlerCreateTestCase{`{"role":"editor"}`, http.StatusOK, `{"role":"editor"`}},
+		{http.MethodPost, "/api/pages", tokens[users.RoleReader], handlerCreateTestCase{`{"title":"Default","text":"Default API"}`, http.StatusOK, `"createdBy":2`}},
+		{http.MethodPost, "/api/users/1/roles", tokens[users.RoleAdmin], handlerCreateTestCase{`{"role":"reader"}`, http.StatusUnprocessableEntity, `you can not change your own role`}},
+		{http.MethodGet, "/api/users/2/roles", tokens[users.RoleAdmin], handlerCreateTestCase{``, http.StatusOK, `{"role":"editor","projects":[]}`}},
+	}
+
+	for caseNum, item := range cases {
+		rec := serveAuthTestRequest(e, item.method, item.path, item.inputData, item.token)
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code of %s %s. Wanted: %d, received: %d, response body: %s", caseNum, item.method, item.path, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+// checkRouteRole checks the response of the route to the user with the role, which is forbidden
+// when the role misses one of the permissions of the route.
+func checkRouteRole(t *testing.T, route *echo.Route, perms []users.Permission, role string, rec *httptest.ResponseRecorder) {
+	for _, perm := range perms {
+		if !users.RoleAllows(role, perm) {
+			if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), fmt.Sprintf(`"permission":"%s"`, perm)) {
+				t.Errorf("%s %s is not forbidden for %s. Response code: %d, response body: %s", route.Method, route.Path, role, rec.Code, rec.Body.String())
+			}
+			return
+		}
+	}
+	if rec.Code == http.StatusUnauthorized || rec.Code == http.StatusForbidden {
+		t.Errorf("%s %s is rejected for %s. Response code: %d, response body: %s", route.Method, route.Path, role, rec.Code, rec.Body.String())
+	}
+}
+
+// routeKey returns the key of the route in the permissions matrix.
+func routeKey(route *echo.Route) string {
+	const projectPrefix = "/api/projects/:project/"
+	path := route.Path
+	if strings.HasPrefix(path, projectPrefix) {
+		path = "/api/" + path[len(projectPrefix):]
+	}
+	return route.Method + " " + path
+}
+
+// routePath fills the params of the route with the slug of the project. IDs do not match stored records,
+// so the allowed requests do not change the fixtures.
+func routePath(path, project string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if strings.HasPrefix(part, ":") {
+			parts[i] = "99"
+			if part == ":project" && i+1 < len(parts) {
+				parts[i] = project
+			}
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// setupRoutesHandlerTest registers all routes with memory stores and returns the access tokens by role.
+// The reader has the ID 2, the chat project has the ID 2.
+func setupRoutesHandlerTest(t *testing.T) (*echo.Echo, map[string]string) {
+	e := router.New()
+
+	prs := projectStore.NewMemory(&projectStore.MemoryConfig{Logger: e.Logger})
+	if err := projects.EnsureDefault(prs); err != nil {
+		t.Fatalf("Can not create default project: %s", err.Error())
+	}
+	if err := prs.Create(&projects.Project{Name: "Chat", Slug: "chat"}); err != nil {
+		t.Fatalf("Can not create test project: %s", err.Error())
+	}
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+	ps := pageStore.NewMemory(&pageStore.MemoryConfig{Logger: e.Logger})
+
+	us := userStore.NewMemory(&userStore.MemoryConfig{Logger: e.Logger})
+	issuer, err := users.NewIssuer(&users.IssuerConfig{Store: us, Secret: "test"})
+	if err != nil {
+		t.Fatalf("Can not create token issuer: %s", err.Error())
+	}
+	tokens := make(map[string]string)
+	for _, role := range []string{users.RoleAdmin, users.RoleReader, users.RoleEditor} {
+		u := &users.User{Email: role + "@example.com", Name: role, Role: role}
+		if err := us.Create(u); err != nil {
+			t.Fatalf("Can not create test user: %s", err.Error())
+		}
+		issued, err := issuer.Issue(u)
+		if err != nil {
+			t.Fatalf("Can not issue test tokens: %s", err.Error())
+		}
+		tokens[role] = issued.AccessToken
+	}
+
+	gqlHub := gql.NewGraphQLHub()
+	gqlHub.AddType(pages.GraphQLType)
+	gqlHub.AddType(events.GraphQLType)
+	if err := pages.RegisterGraphQLQueries(ps, gqlHub); err != nil {
+		t.Fatalf("Can not register page queries: %s", err.Error())
+	}
+	if err := events.RegisterGraphQLQueries(es, gqlHub); err != nil {
+		t.Fatalf("Can not register event queries: %s", err.Error())
+	}
+	if err := gqlHub.Compile(); err != nil {
+		t.Fatalf("Can not compile graphql schema: %s", err.Error())
+	}
+
+	h := New(&Config{
+		Logger:           e.Logger,
+		ProjectStore:     prs,
+		PageStore:        ps,
+		EventStore:       es,
+		SnapshotStore:    snapshotStore.NewMemory(&snapshotStore.MemoryConfig{Logger: e.Logger}),
+		TypeStore:        registryStore.NewMemory(&registryStore.MemoryConfig{Logger: e.Logger}),
+		RecordingStore:   recordingStore.NewMemory(&recordingStore.MemoryConfig{Logger: e.Logger}),
+		ScenarioStore:    scenarioStore.NewMemory(&scenarioStore.MemoryConfig{Logger: e.Logger}),
+		LoadTestStore:    loadTestStore.NewMemory(&loadTestStore.MemoryConfig{Logger: e.Logger}),
+		CollectionStore:  collectionStore.NewMemory(&collectionStore.MemoryConfig{Logger: e.Logger}),
+		EnvironmentStore: environmentStore.NewMemory(&environmentStore.MemoryConfig{Logger: e.Logger}),
+		MonitorStore:     monitorStore.NewMemory(&monitorStore.MemoryConfig{Logger: e.Logger}),
+		UserStore:        us,
+		AuditStore:       auditStore.NewMemory(&auditStore.MemoryConfig{Logger: e.Logger}),
+		TokenIssuer:      issuer,
+		WsHub:            ws.NewHubMock(),
+		GraphQLHub:       gqlHub,
+		MockServer: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+		Relay: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	})
+	h.Register(e.Group("/api"), e.Group(""))
+
+	return e, tokens
+}
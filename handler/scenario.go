@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) GetScenario(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	s, err := h.scenariosOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if s == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: s,
+	})
+}
+
+func (h *Handler) ListScenarios(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	scenariosList, total, err := h.scenariosOf(c).List(offset, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  scenariosList,
+		Total: total,
+	})
+}
+
+func (h *Handler) CreateScenario(c echo.Context) error {
+	req := &scenarioCreateRequest{}
+	s := &scenarios.Scenario{}
+	if err := req.bind(c, s); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkScenarioNameIsFree(c, s); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.scenariosOf(c).Create(s); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityScenario,
+		EntityId:  s.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(s),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: s,
+	})
+}
+
+func (h *Handler) UpdateScenario(c echo.Context) error {
+	req := &scenarioUpdateRequest{}
+	s := &scenarios.Scenario{}
+	if err := req.bind(c, s); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.scenariosOf(c).GetById(s.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	if code, err := h.checkScenarioNameIsFree(c, s); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	before := audit.Snapshot(existing)
+	if err := h.scenariosOf(c).Update(s); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntityScenario,
+		EntityId:  s.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionUpdate,
+		Before:    before,
+		After:     audit.Snapshot(s),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: s,
+	})
+}
+
+func (h *Handler) DeleteScenario(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.scenariosOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.scenariosOf(c).Delete(&scenarios.Scenario{ID: id}); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing != nil {
+		h.record(c, &audit.Entry{
+			Entity:    audit.EntityScenario,
+			EntityId:  id,
+			ProjectId: h.projectIdOf(c),
+			Action:    audit.ActionDelete,
+			Before:    audit.Snapshot(existing),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// RunScenario executes the scenario against the target and responds with the report,
+// as JUnit XML when the format query param is "junit". The target may come from the named environment.
+func (h *Handler) RunScenario(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	s, err := h.scenariosOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if s == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	req := &scenarioRunRequest{}
+	rc := &scenarios.RunConfig{}
+	if err := req.bind(c, rc); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	env, code, err := h.applyEnvironment(c, req.Environment, rc)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if rc.Catalog, err = h.eventsOf(c).GetAll(); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if rc.Types, err = events.ResolveEventTypes(h.typeStore, rc.Catalog...); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	report := scenarios.Run(s, rc)
+	maskReportTarget(report, env, req.Target)
+	if c.QueryParam("format") == "junit" {
+		data, err := report.JUnit()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+				Error: err.Error(),
+			})
+		}
+		return c.Blob(http.StatusOK, echo.MIMEApplicationXMLCharsetUTF8, data)
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: report,
+	})
+}
+
+func (h *Handler) checkScenarioNameIsFree(c echo.Context, s *scenarios.Scenario) (int, error) {
+	existing, err := h.scenariosOf(c).GetByName(s.Name)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if existing != nil && existing.ID != s.ID {
+		return http.StatusConflict, fmt.Errorf("scenario with name %q already exists", s.Name)
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/environments"
+	environmentStore "github.com/nskondratev/api-page-go-back/environments/store"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	scenarioStore "github.com/nskondratev/api-page-go-back/scenarios/store"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_CreateScenario(t *testing.T) {
+	e, h, _, _ := setupScenarioHandlerTest()
+
+	cases := []handlerCreateTestCase{
+		{`{"name":"Login","steps":[{"action":"emit","event":"login","payload":{"name":"John"},"ack":true,"match":{"ok":true}}]}`, http.StatusOK, `"id":1,"projectId":1,"name":"Login","description":"","steps":[{"action":"emit","event":"login","payload":{"name":"John"},"ack":true,"timeoutMs":0,"match":{"ok":true},"capture":null}]`},
+		{`{"name":"Login","steps":[{"action":"expect","event":"user"}]}`, http.StatusConflict, `already exists`},
+		{`{"name":"No steps","steps":[]}`, http.StatusUnprocessableEntity, emptyStr},
+		{`{"name":"Unknown action","steps":[{"action":"wait","event":"user"}]}`, http.StatusUnprocessableEntity, `oneof`},
+		{`{"name":"Negative timeout","steps":[{"action":"expect","event":"user","timeoutMs":-1}]}`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.CreateScenario(c); err != nil {
+			t.Errorf("[%d] Fail to create scenario. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_RunScenario(t *testing.T) {
+	e, h, es, scs := setupScenarioHandlerTest()
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+	if err := scs.Create(&scenarios.Scenario{Name: "Login", Steps: []*scenarios.Step{
+		{Action: scenarios.ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"John"}`)},
+		{Action: scenarios.ActionExpect, Event: "user"},
+	}}); err != nil {
+		t.Fatalf("Can not create test scenario: %s", err.Error())
+	}
+
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	cases := []struct {
+		id                        string
+		query                     string
+		inputData                 string
+		responseCode              int
+		responseBodyShouldContain string
+	}{
+		{"1", "", `{"target":"` + target.URL + `"}`, http.StatusOK, `"scenario":"Login","target":"` + target.URL + `","passed":true`},
+		{"1", "?format=junit", `{"target":"` + target.URL + `"}`, http.StatusOK, `<testcase name="2. expect user" classname="Login"`},
+		{"1", "", `{}`, http.StatusUnprocessableEntity, emptyStr},
+		{"1", "", `{"target":"ws://internal.example.com"}`, http.StatusForbidden, `target host is not allowed: internal.example.com`},
+		{"2", "", `{"target":"` + target.URL + `"}`, http.StatusNotFound, `Not found`},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/"+item.query, strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(item.id)
+
+		if err := h.RunScenario(c); err != nil {
+			t.Errorf("[%d] Fail to run scenario. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_RunScenarioMasksSecrets(t *testing.T) {
+	e := router.New()
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+	scs := scenarioStore.NewMemory(&scenarioStore.MemoryConfig{Logger: e.Logger})
+	ens := environmentStore.NewMemory(&environmentStore.MemoryConfig{Logger: e.Logger})
+	h := New(&Config{
+		Logger:           e.Logger,
+		EventStore:       es,
+		ScenarioStore:    scs,
+		EnvironmentStore: ens,
+		AllowedHosts:     socket.AllowedHosts{"127.0.0.1"},
+		WsHub:            ws.NewHubMock(),
+	})
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+	if err := scs.Create(&scenarios.Scenario{Name: "Login", Steps: []*scenarios.Step{
+		{Action: scenarios.ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"John"}`)},
+		{Action: scenarios.ActionExpect, Event: "user", Match: map[string]util.RawJSON{"name": util.RawJSON(`"${token}"`)}},
+	}}); err != nil {
+		t.Fatalf("Can not create test scenario: %s", err.Error())
+	}
+
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	if err := ens.Create(&environments.Environment{
+		Name:      "mock",
+		BaseUrl:   target.URL,
+		Variables: []*environments.Param{{Name: "token", Value: "s3cr3t", Secret: true}},
+	}); err != nil {
+		t.Fatalf("Can not create test environment: %s", err.Error())
+	}
+
+	for caseNum, item := range []struct {
+		query                     string
+		responseBodyShouldContain string
+	}{
+		{"", `"message":"name is \"name\", expected \"********\""`},
+		{"?format=junit", `<failure message="name is &#34;name&#34;, expected &#34;********&#34;">`},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/"+item.query, strings.NewReader(`{"environment":"mock"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		if err := h.RunScenario(c); err != nil {
+			t.Errorf("[%d] Fail to run scenario. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Unexpected response. Wanted: %s, received: %d %s", caseNum, item.responseBodyShouldContain, rec.Code, rec.Body.String())
+		}
+
+		if strings.Contains(rec.Body.String(), "s3cr3t") {
+			t.Errorf("[%d] Secret is in the response: %s", caseNum, rec.Body.String())
+		}
+	}
+}
+
+func setupScenarioHandlerTest() (*echo.Echo, *Handler, *eventStore.Memory, *scenarioStore.Memory) {
+	e := router.New()
+
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	scs := scenarioStore.NewMemory(&scenarioStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	h := New(&Config{
+		Logger:        e.Logger,
+		EventStore:    es,
+		ScenarioStore: scs,
+		AllowedHosts:  socket.AllowedHosts{"127.0.0.1"},
+		WsHub:         ws.NewHubMock(),
+	})
+
+	return e, h, es, scs
+}
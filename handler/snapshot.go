@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) GetSnapshot(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	snapshot, err := h.snapshotsOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if snapshot == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: snapshot,
+	})
+}
+
+func (h *Handler) ListSnapshots(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	snapshotsList, total, err := h.snapshotsOf(c).List(offset, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  snapshotsList,
+		Total: total,
+	})
+}
+
+func (h *Handler) CreateSnapshot(c echo.Context) error {
+	req := &snapshotCreateRequest{}
+	snapshot := &snapshots.Snapshot{}
+	if err := req.bind(c, snapshot); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.snapshotsOf(c).GetByName(snapshot.Name)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing != nil {
+		return c.JSON(http.StatusConflict, &errorResponseEnvelope{
+			Error: fmt.Sprintf("snapshot with name %q already exists", snapshot.Name),
+		})
+	}
+	catalog, err := h.eventsOf(c).GetAll()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	snapshot.Events = catalog
+	if snapshot.Types, err = events.ResolveEventTypes(h.typeStore, catalog...); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.snapshotsOf(c).Create(snapshot); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:    audit.EntitySnapshot,
+		EntityId:  snapshot.ID,
+		ProjectId: h.projectIdOf(c),
+		Action:    audit.ActionCreate,
+		After:     audit.Snapshot(snapshot.Summary()),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: snapshot,
+	})
+}
+
+func (h *Handler) DeleteSnapshot(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.snapshotsOf(c).GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.snapshotsOf(c).Delete(&snapshots.Snapshot{ID: id}); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing != nil {
+		h.record(c, &audit.Entry{
+			Entity:    audit.EntitySnapshot,
+			EntityId:  id,
+			ProjectId: h.projectIdOf(c),
+			Action:    audit.ActionDelete,
+			Before:    audit.Snapshot(existing.Summary()),
+		})
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+// DiffSnapshots compares two catalog references given by the from and to query params.
+// A reference is a snapshot id, a snapshot name or "live" for the current catalog.
+func (h *Handler) DiffSnapshots(c echo.Context) error {
+	from := c.QueryParam("from")
+	to := c.QueryParam("to")
+	if len(to) < 1 {
+		to = snapshots.LiveRef
+	}
+	if len(from) < 1 {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: "from query param is required",
+		})
+	}
+	diff, err := snapshots.Compare(h.snapshotsOf(c), h.eventsOf(c), h.typeStore, from, to)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: diff,
+	})
+}
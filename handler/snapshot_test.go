@@ -0,0 +1,335 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/registry"
+	registryStore "github.com/nskondratev/api-page-go-back/registry/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	snapshotStore "github.com/nskondratev/api-page-go-back/snapshots/store"
+	"github.com/nskondratev/api-page-go-back/testutils"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestHandler_CreateSnapshot(t *testing.T) {
+	e, h, _, _ := setupSnapshotHandlerTest()
+
+	cases := []handlerCreateTestCase{
+		{`{"name":"release-1","description":"First release"}`, http.StatusOK, `"id":1,"projectId":1,"name":"release-1","description":"First release","events":[]`},
+		{`{"name":"release-1"}`, http.StatusConflict, `already exists`},
+		{`{"description":"No name"}`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.CreateSnapshot(c); err != nil {
+			t.Errorf("[%d] Fail to create snapshot. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_CreateSnapshotWithTypes(t *testing.T) {
+	e, h, es, ss := setupSnapshotHandlerTest()
+
+	if err := h.typeStore.Create(&registry.Type{Name: "Status", Kind: registry.KindEnum, Values: []registry.EnumValue{{Value: "online"}}}); err != nil {
+		t.Fatalf("Can not create test type: %s", err.Error())
+	}
+
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"status"})
+
+	if err != nil {
+		t.Fatalf("Can not create keys from strings: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "USER", Value: "user", Type: "client", Fields: []events.Field{
+		{Key: keys[0], Type: "Status"},
+	}}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"release-1"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.CreateSnapshot(c); err != nil {
+		t.Fatalf("Fail to create snapshot. Error: %s", err.Error())
+	}
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected response code. Wanted: %d, received: %d, response body: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// Changes of the registry must not leak into the frozen copy
+	live, _ := h.typeStore.GetByName("Status")
+	live.Values = append(live.Values, registry.EnumValue{Value: "offline"})
+	if err := h.typeStore.Update(live); err != nil {
+		t.Fatalf("Can not update test type: %s", err.Error())
+	}
+
+	snapshot, _ := ss.GetByName("release-1")
+
+	if snapshot == nil || snapshot.Types["Status"] == nil {
+		t.Fatalf("Snapshot does not contain the referenced type: %+v", snapshot)
+	}
+
+	if len(snapshot.Types["Status"].Values) != 1 {
+		t.Errorf("Snapshot type was not frozen: %+v", snapshot.Types["Status"])
+	}
+}
+
+type diffSnapshotsResponse struct {
+	Data *events.Diff `json:"data"`
+}
+
+func TestHandler_DiffSnapshots(t *testing.T) {
+	e, h, es, ss := setupSnapshotHandlerTest()
+
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"id", "name", "avatar", "status"})
+
+	if err != nil {
+		t.Fatalf("Can not create keys from strings: %s", err.Error())
+	}
+
+	eventsToCreate := []*events.Event{
+		{Constant: "USER", Value: "user", Type: "frontend", Fields: []events.Field{
+			{Key: keys[0], Type: "number", Required: true},
+			{Key: keys[1], Type: "string"},
+			{Key: keys[3], Type: "string"},
+		}},
+		{Constant: "LOGOUT", Value: "logout", Type: "client"},
+	}
+
+	for _, ev := range eventsToCreate {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	snapshot := newTestSnapshot(t, es, "release-1")
+
+	if err := ss.Create(snapshot); err != nil {
+		t.Fatalf("Can not create test snapshot: %s", err.Error())
+	}
+
+	// Change the live catalog
+	if err := es.Update(&events.Event{ID: 1, Constant: "USER", Value: "user", Type: "frontend", Fields: []events.Field{
+		{Key: keys[0], Type: "string", Required: true},
+		{Key: keys[1], Type: "string", Required: true},
+		{Key: keys[2], Type: "string"},
+	}}); err != nil {
+		t.Fatalf("Can not update test event: %s", err.Error())
+	}
+
+	if err := es.Delete(&events.Event{ID: 2}); err != nil {
+		t.Fatalf("Can not delete test event: %s", err.Error())
+	}
+
+	expectedChanges := []events.Change{
+		{Kind: events.ChangeEventRemoved, Breaking: true, Event: "logout"},
+		{Kind: events.ChangeFieldAdded, Breaking: false, Event: "user", Field: "avatar", To: "string"},
+		{Kind: events.ChangeFieldTypeChanged, Breaking: true, Event: "user", Field: "id", From: "number", To: "string"},
+		{Kind: events.ChangeFieldBecameRequired, Breaking: true, Event: "user", Field: "name"},
+		{Kind: events.ChangeFieldRemoved, Breaking: true, Event: "user", Field: "status"},
+	}
+
+	cases := []struct {
+		queryParams  map[string]string
+		responseCode int
+		changes      []events.Change
+	}{
+		{map[string]string{"from": "release-1"}, http.StatusOK, expectedChanges},
+		{map[string]string{"from": "1", "to": "live"}, http.StatusOK, expectedChanges},
+		{map[string]string{"from": "release-1", "to": "release-1"}, http.StatusOK, []events.Change{}},
+		{map[string]string{"from": "unknown"}, http.StatusUnprocessableEntity, nil},
+		{emptyQueryParamsMap, http.StatusUnprocessableEntity, nil},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/", strings.NewReader(emptyStr))
+		qp := &url.Values{}
+		for key, val := range item.queryParams {
+			qp.Add(key, val)
+		}
+		req.URL.RawQuery = qp.Encode()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.DiffSnapshots(c); err != nil {
+			t.Errorf("[%d] Fail to diff snapshots. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if item.changes == nil {
+			continue
+		}
+
+		parsedResBody := &diffSnapshotsResponse{}
+		if err := json.Unmarshal(rec.Body.Bytes(), parsedResBody); err != nil {
+			t.Fatalf("[%d] Can not parse response body to json. Received: %s", caseNum, rec.Body.String())
+		}
+
+		if len(parsedResBody.Data.Changes) != len(item.changes) {
+			t.Fatalf("[%d] changes count mismatch. Want: %d, received: %s", caseNum, len(item.changes), rec.Body.String())
+		}
+
+		for i, change := range parsedResBody.Data.Changes {
+			if *change != item.changes[i] {
+				t.Errorf("[%d] change mismatch. Want: %+v, received: %+v", caseNum, item.changes[i], change)
+			}
+		}
+
+		if parsedResBody.Data.Breaking != (len(item.changes) > 0) {
+			t.Errorf("[%d] breaking flag mismatch. Received: %t", caseNum, parsedResBody.Data.Breaking)
+		}
+	}
+}
+
+func TestHandler_DiffSnapshotsSharedTypes(t *testing.T) {
+	e, h, es, ss := setupSnapshotHandlerTest()
+
+	typeKeys, err := testutils.NewArrayNullStringFromStrings([]string{"city", "zip", "parent"})
+
+	if err != nil {
+		t.Fatalf("Can not create keys from strings: %s", err.Error())
+	}
+
+	typesToCreate := []*registry.Type{
+		{Name: "Address", Kind: registry.KindObject, Fields: []registry.TypeField{
+			{Key: typeKeys[0], Type: "string", Required: true},
+			{Key: typeKeys[1], Type: "string"},
+			{Key: typeKeys[2], Type: "Address"},
+		}},
+		{Name: "Status", Kind: registry.KindEnum, Values: []registry.EnumValue{{Value: "online"}, {Value: "offline"}}},
+	}
+
+	for _, st := range typesToCreate {
+		if err := h.typeStore.Create(st); err != nil {
+			t.Fatalf("Can not create test type: %s", err.Error())
+		}
+	}
+
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"address", "status"})
+
+	if err != nil {
+		t.Fatalf("Can not create keys from strings: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "USER", Value: "user", Type: "client", Fields: []events.Field{
+		{Key: keys[0], Type: "Address", Required: true},
+		{Key: keys[1], Type: "Status"},
+	}}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+
+	snapshot := newTestSnapshot(t, es, "release-1")
+
+	if snapshot.Types, err = events.ResolveEventTypes(h.typeStore, snapshot.Events...); err != nil {
+		t.Fatalf("Can not resolve test types: %s", err.Error())
+	}
+
+	if err := ss.Create(snapshot); err != nil {
+		t.Fatalf("Can not create test snapshot: %s", err.Error())
+	}
+
+	// Change the shared types, the events stay the same
+	if err := h.typeStore.Update(&registry.Type{ID: 1, Name: "Address", Kind: registry.KindObject, Fields: []registry.TypeField{
+		{Key: typeKeys[1], Type: "string", Required: true},
+		{Key: typeKeys[2], Type: "Address"},
+	}}); err != nil {
+		t.Fatalf("Can not update test type: %s", err.Error())
+	}
+
+	if err := h.typeStore.Update(&registry.Type{ID: 2, Name: "Status", Kind: registry.KindEnum, Values: []registry.EnumValue{{Value: "online"}, {Value: "away"}}}); err != nil {
+		t.Fatalf("Can not update test type: %s", err.Error())
+	}
+
+	expectedChanges := []events.Change{
+		{Kind: events.ChangeFieldRemoved, Breaking: true, Event: "user", Field: "address.city"},
+		{Kind: events.ChangeFieldBecameRequired, Breaking: true, Event: "user", Field: "address.zip"},
+		{Kind: events.ChangeEnumValueRemoved, Breaking: true, Event: "user", Field: "status", From: "offline"},
+		{Kind: events.ChangeEnumValueAdded, Breaking: false, Event: "user", Field: "status", To: "away"},
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/?from=release-1", strings.NewReader(emptyStr))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.DiffSnapshots(c); err != nil {
+		t.Fatalf("Fail to diff snapshots. Error: %s", err.Error())
+	}
+
+	parsedResBody := &diffSnapshotsResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), parsedResBody); err != nil {
+		t.Fatalf("Can not parse response body to json. Received: %s", rec.Body.String())
+	}
+
+	if len(parsedResBody.Data.Changes) != len(expectedChanges) {
+		t.Fatalf("changes count mismatch. Want: %d, received: %s", len(expectedChanges), rec.Body.String())
+	}
+
+	for i, change := range parsedResBody.Data.Changes {
+		if *change != expectedChanges[i] {
+			t.Errorf("[%d] change mismatch. Want: %+v, received: %+v", i, expectedChanges[i], change)
+		}
+	}
+
+	if !parsedResBody.Data.Breaking {
+		t.Errorf("breaking flag mismatch. Received: %t", parsedResBody.Data.Breaking)
+	}
+}
+
+// Utility functions
+
+func setupSnapshotHandlerTest() (*echo.Echo, *Handler, *eventStore.Memory, *snapshotStore.Memory) {
+	e := router.New()
+
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	ss := snapshotStore.NewMemory(&snapshotStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	h := New(&Config{
+		Logger:        e.Logger,
+		EventStore:    es,
+		SnapshotStore: ss,
+		TypeStore:     registryStore.NewMemory(&registryStore.MemoryConfig{Logger: e.Logger}),
+		WsHub:         ws.NewHubMock(),
+	})
+
+	return e, h, es, ss
+}
+
+func newTestSnapshot(t *testing.T, es events.Store, name string) *snapshots.Snapshot {
+	catalog, err := es.GetAll()
+	if err != nil {
+		t.Fatalf("Can not get events catalog: %s", err.Error())
+	}
+	return &snapshots.Snapshot{Name: name, Events: catalog}
+}
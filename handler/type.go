@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"github.com/nskondratev/api-page-go-back/users"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (h *Handler) GetType(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	t, err := h.typeStore.GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if t == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: t,
+	})
+}
+
+func (h *Handler) ListTypes(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	typesList, total, err := h.typeStore.List(offset, limit, c.QueryParam("kind"), c.QueryParam("query"))
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  typesList,
+		Total: total,
+	})
+}
+
+func (h *Handler) CreateType(c echo.Context) error {
+	req := &typeCreateRequest{}
+	t := &registry.Type{}
+	if err := req.bind(c, t); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkTypeNameIsFree(t); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.typeStore.Create(t); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityType,
+		EntityId: t.ID,
+		Action:   audit.ActionCreate,
+		After:    audit.Snapshot(t),
+	})
+	wsMessage := &ws.ApTypeMessage{
+		EventConst: ws.TypeCreated,
+		Data: &ws.ApMessageTypeEnvelope{
+			Type: t,
+		},
+	}
+	if err := h.wsHub.Broadcast(wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting TYPE_CREATED to ws: %s", err.Error())
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: t,
+	})
+}
+
+func (h *Handler) UpdateType(c echo.Context) error {
+	req := &typeUpdateRequest{}
+	t := &registry.Type{}
+	if err := req.bind(c, t); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkTypeNameIsFree(t); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.typeStore.GetById(t.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	usedBy, err := h.eventsUsingType(t.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	// The rename is propagated to the events of every project
+	if err := h.checkInProjectsOf(c, usedBy, users.PermEventsWrite); err != nil {
+		return forbidden(c, users.PermEventsWrite, err)
+	}
+	before := audit.Snapshot(existing)
+	if err := h.typeStore.Update(t); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityType,
+		EntityId: t.ID,
+		Action:   audit.ActionUpdate,
+		Before:   before,
+		After:    audit.Snapshot(t),
+	})
+	wsMessage := &ws.ApTypeMessage{
+		EventConst: ws.TypeUpdated,
+		Data: &ws.ApMessageTypeEnvelope{
+			Type: t,
+		},
+	}
+	if err := h.wsHub.Broadcast(wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting TYPE_UPDATED to ws: %s", err.Error())
+	}
+	if err := h.propagateType(c, t, usedBy); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: t,
+	})
+}
+
+func (h *Handler) DeleteType(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	usedBy, err := h.eventsUsingType(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if len(usedBy) > 0 {
+		// Events of projects the user can not edit are only counted
+		values := make([]string, 0, len(usedBy))
+		for _, e := range usedBy {
+			if h.checkInProjectsOf(c, []*events.Event{e}, users.PermEventsWrite) == nil {
+				values = append(values, e.Value)
+			}
+		}
+		if hidden := len(usedBy) - len(values); hidden > 0 {
+			values = append(values, fmt.Sprintf("%d of other projects", hidden))
+		}
+		return c.JSON(http.StatusConflict, &errorResponseEnvelope{
+			Error: fmt.Sprintf("type is referenced by events: %s", strings.Join(values, ", ")),
+		})
+	}
+	existing, err := h.typeStore.GetById(id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	t := &registry.Type{
+		ID: id,
+	}
+	if err := h.typeStore.Delete(t); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing != nil {
+		h.record(c, &audit.Entry{
+			Entity:   audit.EntityType,
+			EntityId: id,
+			Action:   audit.ActionDelete,
+			Before:   audit.Snapshot(existing),
+		})
+	}
+	wsMessage := &ws.ApIdMessage{
+		EventConst: ws.TypeDeleted,
+		Data: &ws.ApMessageOnlyIdEnvelope{
+			ID: t.ID,
+		},
+	}
+	if err := h.wsHub.Broadcast(wsMessage); err != nil {
+		h.logger.Warnf("Error while broadcasting TYPE_DELETED to ws: %s", err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) checkTypeNameIsFree(t *registry.Type) (int, error) {
+	existing, err := h.typeStore.GetByName(t.Name)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if existing != nil && existing.ID != t.ID {
+		return http.StatusConflict, fmt.Errorf("type with name %q already exists", t.Name)
+	}
+	return http.StatusOK, nil
+}
+
+// checkInProjectsOf checks the permission against the role of the user in the projects of the events.
+func (h *Handler) checkInProjectsOf(c echo.Context, list []*events.Event, perm users.Permission) error {
+	if h.tokenIssuer == nil {
+		return nil
+	}
+	for _, e := range list {
+		if err := users.Check(c.Request().Context(), e.ProjectId, perm); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// propagateType refreshes the type name on every field of the events referencing t and broadcasts the updated events.
+func (h *Handler) propagateType(c echo.Context, t *registry.Type, usedBy []*events.Event) error {
+	for _, e := range usedBy {
+		before := audit.Snapshot(e)
+		for _, list := range [][]events.Field{e.Fields, e.AckFields} {
+			for i := range list {
+				if list[i].TypeId.Valid && uint64(list[i].TypeId.Int64) == t.ID {
+					list[i].Type = t.Name
+				}
+			}
+		}
+		if err := h.eventStore.ForProject(e.ProjectId).Update(e); err != nil {
+			return err
+		}
+		h.record(c, &audit.Entry{
+			Entity:    audit.EntityEvent,
+			EntityId:  e.ID,
+			ProjectId: e.ProjectId,
+			Action:    audit.ActionUpdate,
+			Before:    before,
+			After:     audit.Snapshot(e),
+		})
+		wsMessage := &ws.ApEventMessage{
+			EventConst: ws.EventUpdated,
+			Data: &ws.ApMessageEventEnvelope{
+				Event: e,
+			},
+		}
+		if err := h.wsHub.BroadcastTo(e.ProjectId, wsMessage); err != nil {
+			h.logger.Warnf("Error while broadcasting EVENT_UPDATED to ws: %s", err.Error())
+		}
+	}
+	return nil
+}
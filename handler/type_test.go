@@ -0,0 +1,263 @@
+package handler
+
+import (
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/projects"
+	projectStore "github.com/nskondratev/api-page-go-back/projects/store"
+	"github.com/nskondratev/api-page-go-back/registry"
+	registryStore "github.com/nskondratev/api-page-go-back/registry/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/testutils"
+	"github.com/nskondratev/api-page-go-back/users"
+	userStore "github.com/nskondratev/api-page-go-back/users/store"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_CreateType(t *testing.T) {
+	e, h, _, _ := setupTypeHandlerTest()
+
+	cases := []handlerCreateTestCase{
+		{`{"name":"User","kind":"object","fields":[{"key":"id","type":"number","required":true}],"values":[{"value":"ignored"}]}`, http.StatusOK, `"id":1,"name":"User","kind":"object","description":"","fields":[{"id":0,"typeId":0,"key":"id","type":"number","required":true,"description":""}],"values":[]`},
+		{`{"name":"Status","kind":"enum","values":[{"value":"online"},{"value":"offline"}]}`, http.StatusOK, `"values":[{"id":0,"typeId":0,"value":"online","description":""},{"id":0,"typeId":0,"value":"offline","description":""}]`},
+		{`{"name":"User","kind":"object"}`, http.StatusConflict, `type with name \"User\" already exists`},
+		{`{"name":"Bad","kind":"list"}`, http.StatusUnprocessableEntity, emptyStr},
+		{`{"name":"Empty","kind":"enum","values":[{"value":""}]}`, http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		if err := h.CreateType(c); err != nil {
+			t.Errorf("[%d] Fail to create type. Error: %s, input data: %s", caseNum, err.Error(), item.inputData)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+}
+
+func TestHandler_UpdateTypePropagatesToEvents(t *testing.T) {
+	e, h, es, ts := setupTypeHandlerTest()
+
+	if err := ts.Create(&registry.Type{Name: "Status", Kind: registry.KindEnum}); err != nil {
+		t.Fatalf("Can not create test type: %s", err.Error())
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"constant":"STATUS","value":"status","description":"Status","type":"frontend","fields":[{"key":"status","type":"","typeId":1,"required":true,"description":"Status"}]}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+
+	if err := h.CreateEvent(e.NewContext(req, rec)); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("Can not create test event. Response: %s", rec.Body.String())
+	}
+
+	if !strings.Contains(rec.Body.String(), `"type":"Status","typeId":1`) {
+		t.Errorf("Field type was not resolved from the registry: %s", rec.Body.String())
+	}
+
+	cases := []handlerUpdateTestCase{
+		{"1", `{"name":"UserStatus","kind":"enum","values":[{"value":"online"}]}`, http.StatusOK, `"name":"UserStatus"`},
+		{"badparam", `{"name":"UserStatus","kind":"enum"}`, http.StatusUnprocessableEntity, emptyStr},
+		{"5", `{"name":"Missing","kind":"enum"}`, http.StatusNotFound, `Not found`},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(item.inputData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/types/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(item.id)
+
+		if err := h.UpdateType(c); err != nil {
+			t.Errorf("[%d] Fail to update type. Error: %s, id: %s", caseNum, err.Error(), item.id)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	event, err := es.GetById(1)
+
+	if err != nil || event == nil {
+		t.Fatalf("Can not get test event: %v", err)
+	}
+
+	if event.Fields[0].Type != "UserStatus" {
+		t.Errorf("Type rename was not propagated. Want: UserStatus, received: %s", event.Fields[0].Type)
+	}
+}
+
+func TestHandler_DeleteType(t *testing.T) {
+	e, h, es, ts := setupTypeHandlerTest()
+
+	for _, name := range []string{"User", "Status"} {
+		if err := ts.Create(&registry.Type{Name: name, Kind: registry.KindObject}); err != nil {
+			t.Fatalf("Can not create test type: %s", err.Error())
+		}
+	}
+
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"user"})
+
+	if err != nil {
+		t.Fatalf("Can not create keys: %s", err.Error())
+	}
+
+	if err := es.Create(&events.Event{Constant: "USER", Value: "user", Type: "frontend", Fields: []events.Field{
+		{Key: keys[0], Type: "User", TypeId: util.NewNullInt64FromInt64(1)},
+	}}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+
+	cases := []handlerDeleteTestCase{
+		{"1", http.StatusConflict, `type is referenced by events: user`},
+		{"2", http.StatusOK, emptyStr},
+		{"badparam", http.StatusUnprocessableEntity, emptyStr},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(http.MethodDelete, "/", strings.NewReader(emptyStr))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetPath("/types/:id")
+		c.SetParamNames("id")
+		c.SetParamValues(item.id)
+
+		if err := h.DeleteType(c); err != nil {
+			t.Errorf("[%d] Fail to delete type. Error: %s, id: %s", caseNum, err.Error(), item.id)
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	if deleted, _ := ts.GetById(2); deleted != nil {
+		t.Errorf("Unreferenced type was not deleted")
+	}
+}
+
+func TestHandler_TypeProjectRoles(t *testing.T) {
+	e, h, es, ts := setupTypeHandlerTest()
+
+	prs := projectStore.NewMemory(&projectStore.MemoryConfig{Logger: e.Logger})
+	for _, p := range []*projects.Project{{Slug: "default", Name: "Default"}, {Slug: "chat", Name: "Chat"}} {
+		if err := prs.Create(p); err != nil {
+			t.Fatalf("Can not create test project: %s", err.Error())
+		}
+	}
+	issuer, err := users.NewIssuer(&users.IssuerConfig{Store: userStore.NewMemory(&userStore.MemoryConfig{Logger: e.Logger}), Secret: "test"})
+	if err != nil {
+		t.Fatalf("Can not create token issuer: %s", err.Error())
+	}
+	h.projectStore, h.tokenIssuer = prs, issuer
+
+	if err := ts.Create(&registry.Type{Name: "Status", Kind: registry.KindEnum}); err != nil {
+		t.Fatalf("Can not create test type: %s", err.Error())
+	}
+	keys, err := testutils.NewArrayNullStringFromStrings([]string{"status"})
+	if err != nil {
+		t.Fatalf("Can not create keys: %s", err.Error())
+	}
+	for i, value := range []string{"status", "presence"} {
+		if err := es.ForProject(uint64(i + 1)).Create(&events.Event{Constant: strings.ToUpper(value), Value: value, Type: "frontend", Fields: []events.Field{
+			{Key: keys[0], Type: "Status", TypeId: util.NewNullInt64FromInt64(1)},
+		}}); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+
+	// Editor of the default project reads the chat one
+	grants := &users.Grants{Role: users.RoleEditor, Projects: map[uint64]string{2: users.RoleReader}}
+
+	cases := []struct {
+		method                    string
+		grants                    *users.Grants
+		responseCode              int
+		responseBodyShouldContain string
+	}{
+		{http.MethodPost, grants, http.StatusForbidden, `missing permission \"events.write\"`},
+		{http.MethodDelete, grants, http.StatusConflict, `type is referenced by events: status, 1 of other projects`},
+		{http.MethodDelete, &users.Grants{Role: users.RoleEditor}, http.StatusConflict, `type is referenced by events: presence, status`},
+		{http.MethodPost, &users.Grants{Role: users.RoleEditor}, http.StatusOK, `"name":"UserStatus"`},
+	}
+
+	for caseNum, item := range cases {
+		req := httptest.NewRequest(item.method, "/", strings.NewReader(`{"name":"UserStatus","kind":"enum","values":[{"value":"online"}]}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(users.WithGrants(req.Context(), item.grants))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+
+		if item.method == http.MethodDelete {
+			err = h.DeleteType(c)
+		} else {
+			err = h.UpdateType(c)
+		}
+		if err != nil {
+			t.Errorf("[%d] Fail to change type. Error: %s", caseNum, err.Error())
+		}
+
+		if rec.Code != item.responseCode {
+			t.Errorf("[%d] Unexpected response code. Wanted: %d, received: %d, response body: %s", caseNum, item.responseCode, rec.Code, rec.Body.String())
+		}
+
+		if len(item.responseBodyShouldContain) > 0 && !strings.Contains(rec.Body.String(), item.responseBodyShouldContain) {
+			t.Errorf("[%d] Response body doesn't contain needed info. Wanted: %s, received: %s", caseNum, item.responseBodyShouldContain, rec.Body.String())
+		}
+	}
+
+	if presence, _ := es.ForProject(2).GetById(2); presence == nil || presence.Fields[0].Type != "UserStatus" {
+		t.Errorf("Type rename was not propagated to the chat project: %+v", presence)
+	}
+}
+
+// Utility functions
+
+func setupTypeHandlerTest() (*echo.Echo, *Handler, *eventStore.Memory, *registryStore.Memory) {
+	e := router.New()
+
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	ts := registryStore.NewMemory(&registryStore.MemoryConfig{
+		Logger: e.Logger,
+	})
+
+	h := New(&Config{
+		Logger:     e.Logger,
+		EventStore: es,
+		TypeStore:  ts,
+		WsHub:      ws.NewHubMock(),
+	})
+
+	return e, h, es, ts
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/audit"
+	"github.com/nskondratev/api-page-go-back/users"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) GetUser(c echo.Context) error {
+	u, code, err := h.userFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: u,
+	})
+}
+
+func (h *Handler) ListUsers(c echo.Context) error {
+	limit, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.Atoi(c.QueryParam("offset"))
+	if err != nil {
+		offset = 0
+	}
+	usersList, total, err := h.userStore.List(offset, limit)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	return c.JSON(http.StatusOK, &paginationResponseEnvelope{
+		Data:  usersList,
+		Total: total,
+	})
+}
+
+func (h *Handler) CreateUser(c echo.Context) error {
+	req := &userCreateRequest{}
+	u := &users.User{}
+	if err := req.bind(c, u); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if code, err := h.checkUserEmailIsFree(u); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if err := h.userStore.Create(u); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityUser,
+		EntityId: u.ID,
+		Action:   audit.ActionCreate,
+		After:    audit.Snapshot(u),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: u,
+	})
+}
+
+// UpdateUser replaces the email and the name of the user, the password is changed when it is passed.
+// Roles are changed with the roles routes.
+func (h *Handler) UpdateUser(c echo.Context) error {
+	req := &userUpdateRequest{}
+	u := &users.User{}
+	if err := req.bind(c, u); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	existing, err := h.userStore.GetById(u.ID)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if existing == nil {
+		return c.JSON(http.StatusNotFound, &errorResponseEnvelope{
+			Error: "Not found",
+		})
+	}
+	if code, err := h.checkUserEmailIsFree(u); err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if len(u.PasswordHash) < 1 {
+		u.PasswordHash = existing.PasswordHash
+	}
+	u.Role = existing.Role
+	if err := h.userStore.Update(u); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityUser,
+		EntityId: u.ID,
+		Action:   audit.ActionUpdate,
+		Before:   audit.Snapshot(existing),
+		After:    audit.Snapshot(u),
+	})
+	return c.JSON(http.StatusOK, &responseEnvelope{
+		Data: u,
+	})
+}
+
+// DeleteUser deletes the user with its refresh tokens. Users can not delete themselves.
+func (h *Handler) DeleteUser(c echo.Context) error {
+	u, code, err := h.userFromParam(c)
+	if err != nil {
+		return c.JSON(code, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	if current := users.FromContext(c.Request().Context()); current != nil && current.ID == u.ID {
+		return c.JSON(http.StatusUnprocessableEntity, &errorResponseEnvelope{
+			Error: "you can not delete yourself",
+		})
+	}
+	if err := h.userStore.Delete(u); err != nil {
+		return c.JSON(http.StatusInternalServerError, &errorResponseEnvelope{
+			Error: err.Error(),
+		})
+	}
+	h.record(c, &audit.Entry{
+		Entity:   audit.EntityUser,
+		EntityId: u.ID,
+		Action:   audit.ActionDelete,
+		Before:   audit.Snapshot(u),
+	})
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) checkUserEmailIsFree(u *users.User) (int, error) {
+	existing, err := h.userStore.GetByEmail(u.Email)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	}
+	if existing != nil && existing.ID != u.ID {
+		return http.StatusConflict, fmt.Errorf("user with email %q already exists", u.Email)
+	}
+	return http.StatusOK, nil
+}
+
+func (h *Handler) userFromParam(c echo.Context) (*users.User, int, error) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		return nil, http.StatusUnprocessableEntity, err
+	}
+	u, err := h.userStore.GetById(id)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if u == nil {
+		return nil, http.StatusNotFound, errors.New("Not found")
+	}
+	return u, http.StatusOK, nil
+}
@@ -2,9 +2,29 @@ package handler
 
 import (
 	"github.com/labstack/echo"
+	"github.com/nskondratev/api-page-go-back/users"
 )
 
 func (h *Handler) HandleWs(c echo.Context) error {
+	if len(c.QueryParam("target")) > 0 && h.relay != nil {
+		// The relay connects to the target on behalf of the user and records the traffic when asked to
+		perms := []users.Permission{users.PermTestsRun}
+		if len(c.QueryParam("record")) > 0 {
+			perms = append(perms, users.PermTestsWrite)
+		}
+		for _, perm := range perms {
+			if err := h.checkInProject(c, perm); err != nil {
+				return forbidden(c, perm, err)
+			}
+		}
+		h.relay.ServeHTTP(c.Response(), c.Request())
+		return nil
+	}
 	h.wsHub.ServeWs(c.Response(), c.Request())
 	return nil
 }
+
+func (h *Handler) HandleMock(c echo.Context) error {
+	h.mockServer.ServeHTTP(c.Response(), c.Request())
+	return nil
+}
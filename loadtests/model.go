@@ -0,0 +1,97 @@
+package loadtests
+
+import (
+	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/util"
+	"time"
+)
+
+// Statuses of runs
+const (
+	StatusRunning  = "running"
+	StatusFinished = "finished"
+	StatusFailed   = "failed"
+)
+
+// Run is a load test executed against the target. The result is stored when the run is over.
+type Run struct {
+	ID          uint64          `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId   uint64          `json:"projectId" gorm:"index;default:1;column:projectId"`
+	Name        string          `json:"name" gorm:"size:255;column:name"`
+	Target      string          `json:"target" gorm:"size:1024;index;column:target"`
+	Protocol    string          `json:"protocol" gorm:"size:32;column:protocol"`
+	Connections int             `json:"connections" gorm:"column:connections"`
+	Rate        float64         `json:"rate" gorm:"type:DOUBLE;column:rate"`
+	DurationMs  int64           `json:"durationMs" gorm:"column:durationMs"`
+	Events      util.StringList `json:"events" gorm:"type:text;column:events"`
+	Status      string          `json:"status" gorm:"type:ENUM('running','finished','failed');default:'running';column:status"`
+	Error       string          `json:"error" gorm:"type:text;column:error"`
+	Result      *Result         `json:"result" gorm:"-"`
+	Data        string          `json:"-" gorm:"type:longtext;column:result"`
+	FinishedAt  *time.Time      `json:"finishedAt" gorm:"column:finishedAt"`
+	CreatedAt   time.Time       `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+func (Run) TableName() string {
+	return "load_test_runs"
+}
+
+// BeforeSave serializes the result into the result column.
+func (r *Run) BeforeSave() error {
+	if r.Result == nil {
+		r.Data = ""
+		return nil
+	}
+	data, err := json.Marshal(r.Result)
+	if err != nil {
+		return err
+	}
+	r.Data = string(data)
+	return nil
+}
+
+// AfterFind restores the result from the result column.
+func (r *Run) AfterFind() error {
+	r.Result = nil
+	if len(r.Data) < 1 {
+		return nil
+	}
+	r.Result = &Result{}
+	return json.Unmarshal([]byte(r.Data), r.Result)
+}
+
+// Result holds the measurements of a run.
+type Result struct {
+	DurationMs int64 `json:"durationMs"`
+	// Connections attempted and established
+	Connections   int `json:"connections"`
+	Connected     int `json:"connected"`
+	ConnectErrors int `json:"connectErrors"`
+	// Disconnects counts connections closed by the target before the end of the run
+	Disconnects int `json:"disconnects"`
+	// Errors counts failed emits and frames which can not be decoded
+	Errors   int `json:"errors"`
+	Emitted  int `json:"emitted"`
+	Received int `json:"received"`
+	// Timeouts counts acks and responses not received within the timeout
+	Timeouts          int      `json:"timeouts"`
+	EmitsPerSecond    float64  `json:"emitsPerSecond"`
+	ReceivedPerSecond float64  `json:"receivedPerSecond"`
+	Acks              *Latency `json:"acks"`
+	Responses         *Latency `json:"responses"`
+	// ErrorSamples lists the first distinct error messages
+	ErrorSamples []string `json:"errorSamples"`
+}
+
+// Latency summarizes the delays between emits and their acks or response events.
+type Latency struct {
+	Count  int     `json:"count"`
+	MinMs  float64 `json:"minMs"`
+	MeanMs float64 `json:"meanMs"`
+	P50Ms  float64 `json:"p50Ms"`
+	P90Ms  float64 `json:"p90Ms"`
+	P95Ms  float64 `json:"p95Ms"`
+	P99Ms  float64 `json:"p99Ms"`
+	MaxMs  float64 `json:"maxMs"`
+}
@@ -0,0 +1,401 @@
+package loadtests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout limits the wait for acks and response events when the config does not set it.
+const DefaultTimeout = 5 * time.Second
+
+// Number of distinct error messages kept in the result
+const maxErrorSamples = 10
+
+type Config struct {
+	Target      string
+	Protocol    string
+	Header      http.Header
+	Connections int
+	// Rate of emits per second over all connections
+	Rate     float64
+	Duration time.Duration
+	// RampUp spreads the connects over the period
+	RampUp time.Duration
+	// Timeout of acks and response events, DefaultTimeout when zero
+	Timeout time.Duration
+	// Events lists values of the emitted events, all client events of the catalog when empty
+	Events  []string
+	Catalog []*events.Event
+	// Types are the shared types referenced by the catalog
+	Types events.Types
+}
+
+// emitted is a catalog event with the generated payload.
+type emitted struct {
+	event    *events.Event
+	data     json.RawMessage
+	response string
+}
+
+type runner struct {
+	c        *Config
+	codec    socket.Codec
+	emits    []*emitted
+	timeout  time.Duration
+	interval time.Duration
+	stop     time.Time
+
+	mu        *sync.Mutex
+	res       *Result
+	acks      []time.Duration
+	responses []time.Duration
+}
+
+// client is a connection of the run. Emits are matched with acks by ack id and with
+// response events in the order they were sent.
+type client struct {
+	r         *runner
+	conn      *socket.Conn
+	mu        *sync.Mutex
+	lastAckId int64
+	acks      map[int64]time.Time
+	responses map[string][]time.Time
+	closing   bool
+}
+
+// Execute opens the connections to the target and emits the events with generated payloads at the
+// configured rate until the duration is over. The connections wait for the pending acks and responses
+// up to the timeout before they are closed.
+func Execute(c *Config) (*Result, error) {
+	r, err := newRunner(c)
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	r.stop = started.Add(c.Duration)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < c.Connections; i++ {
+		wg.Add(1)
+		go r.runClient(i, wg)
+	}
+	wg.Wait()
+	r.res.DurationMs = int64(time.Since(started) / time.Millisecond)
+	r.summarize()
+	return r.res, nil
+}
+
+// Validate checks the limits of the run and that the emitted events are described in the catalog.
+func (c *Config) Validate() error {
+	if c.Connections < 1 {
+		return errors.New("number of connections must be positive")
+	}
+	if c.Rate <= 0 {
+		return errors.New("rate must be positive")
+	}
+	if c.Duration <= 0 {
+		return errors.New("duration must be positive")
+	}
+	if _, err := socket.NewCodec(c.Protocol); err != nil {
+		return err
+	}
+	values := c.emittedValues()
+	if len(values) < 1 {
+		return errors.New("there are no client events to emit")
+	}
+	known := make(map[string]bool, len(c.Catalog))
+	for _, e := range c.Catalog {
+		known[e.Value] = true
+	}
+	for _, value := range values {
+		if !known[value] {
+			return fmt.Errorf("event %s is not described in the catalog", value)
+		}
+	}
+	return nil
+}
+
+func (c *Config) emittedValues() []string {
+	if len(c.Events) > 0 {
+		return c.Events
+	}
+	values := make([]string, 0)
+	for _, e := range c.Catalog {
+		if e.Type == events.TypeClient {
+			values = append(values, e.Value)
+		}
+	}
+	return values
+}
+
+func newRunner(c *Config) (*runner, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	codec, err := socket.NewCodec(c.Protocol)
+	if err != nil {
+		return nil, err
+	}
+	byValue := make(map[string]*events.Event, len(c.Catalog))
+	byId := make(map[uint64]*events.Event, len(c.Catalog))
+	for _, e := range c.Catalog {
+		byValue[e.Value] = e
+		byId[e.ID] = e
+	}
+	r := &runner{
+		c:        c,
+		codec:    codec,
+		timeout:  c.Timeout,
+		interval: time.Duration(float64(time.Second) * float64(c.Connections) / c.Rate),
+		mu:       &sync.Mutex{},
+		res:      &Result{Connections: c.Connections, ErrorSamples: make([]string, 0)},
+	}
+	if r.timeout <= 0 {
+		r.timeout = DefaultTimeout
+	}
+	for _, value := range c.emittedValues() {
+		e := byValue[value]
+		data, err := json.Marshal(events.GenerateExample(e.Fields, c.Types))
+		if err != nil {
+			return nil, err
+		}
+		em := &emitted{event: e, data: data}
+		if response, ok := byId[uint64(e.ResponseEventId.Int64)]; ok && e.HasResponse() {
+			em.response = response.Value
+		}
+		r.emits = append(r.emits, em)
+	}
+	return r, nil
+}
+
+// NewRun describes the run of the config, the result is stored by Finish.
+func NewRun(name string, c *Config) *Run {
+	codec, _ := socket.NewCodec(c.Protocol)
+	run := &Run{
+		Name:        name,
+		Target:      c.Target,
+		Connections: c.Connections,
+		Rate:        c.Rate,
+		DurationMs:  int64(c.Duration / time.Millisecond),
+		Events:      c.emittedValues(),
+		Status:      StatusRunning,
+	}
+	if codec != nil {
+		run.Protocol = codec.Name()
+	}
+	return run
+}
+
+// Finish executes the created run and stores its result or the error.
+func Finish(s Store, run *Run, c *Config) error {
+	res, err := Execute(c)
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Result = res
+	run.Status = StatusFinished
+	if err != nil {
+		run.Status = StatusFailed
+		run.Error = err.Error()
+	}
+	return s.Update(run)
+}
+
+func (r *runner) runClient(i int, wg *sync.WaitGroup) {
+	defer wg.Done()
+	if r.c.RampUp > 0 {
+		time.Sleep(time.Duration(int64(r.c.RampUp) * int64(i) / int64(r.c.Connections)))
+	}
+	conn, err := socket.Dial(r.c.Target, r.codec, r.c.Header)
+	if err != nil {
+		r.count(&r.res.ConnectErrors, err)
+		return
+	}
+	r.count(&r.res.Connected, nil)
+	cl := &client{r: r, conn: conn, mu: &sync.Mutex{}, acks: make(map[int64]time.Time), responses: make(map[string][]time.Time)}
+	done := make(chan struct{})
+	go cl.readPump(done)
+
+	// The first emit is delayed randomly, so the connections do not emit at once
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(r.interval) + 1)))
+	defer timer.Stop()
+	for n := i; ; n++ {
+		select {
+		case <-done:
+			conn.Close()
+			cl.expire(time.Now().Add(r.timeout))
+			return
+		case now := <-timer.C:
+			if now.After(r.stop) {
+				cl.drain(done)
+				return
+			}
+			cl.emit(r.emits[n%len(r.emits)])
+			cl.expire(now)
+			timer.Reset(r.interval)
+		}
+	}
+}
+
+func (cl *client) emit(em *emitted) {
+	f := socket.NewEventFrame(em.event.Value, em.data)
+	now := time.Now()
+	cl.mu.Lock()
+	if len(em.event.AckFields) > 0 {
+		cl.lastAckId++
+		f.AckId = cl.lastAckId
+		cl.acks[f.AckId] = now
+	}
+	if len(em.response) > 0 {
+		cl.responses[em.response] = append(cl.responses[em.response], now)
+	}
+	cl.mu.Unlock()
+	if err := cl.conn.Send(f); err != nil {
+		cl.r.count(&cl.r.res.Errors, err)
+		return
+	}
+	cl.r.count(&cl.r.res.Emitted, nil)
+}
+
+// expire counts the acks and responses pending longer than the timeout.
+func (cl *client) expire(now time.Time) {
+	deadline := now.Add(-cl.r.timeout)
+	timeouts := 0
+	cl.mu.Lock()
+	for id, sent := range cl.acks {
+		if sent.Before(deadline) {
+			delete(cl.acks, id)
+			timeouts++
+		}
+	}
+	for value, list := range cl.responses {
+		for len(list) > 0 && list[0].Before(deadline) {
+			list = list[1:]
+			timeouts++
+		}
+		cl.responses[value] = list
+	}
+	cl.mu.Unlock()
+	cl.r.add(&cl.r.res.Timeouts, timeouts)
+}
+
+func (cl *client) pending() int {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	n := len(cl.acks)
+	for _, list := range cl.responses {
+		n += len(list)
+	}
+	return n
+}
+
+// drain waits for the pending acks and responses, then closes the connection.
+func (cl *client) drain(done chan struct{}) {
+	deadline := time.Now().Add(cl.r.timeout)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for cl.pending() > 0 && time.Now().Before(deadline) {
+		select {
+		case <-done:
+			cl.conn.Close()
+			cl.expire(time.Now().Add(cl.r.timeout))
+			return
+		case <-ticker.C:
+		}
+	}
+	cl.mu.Lock()
+	cl.closing = true
+	cl.mu.Unlock()
+	cl.conn.Close()
+	<-done
+	cl.expire(time.Now().Add(cl.r.timeout))
+}
+
+func (cl *client) readPump(done chan struct{}) {
+	defer close(done)
+	for {
+		f, raw, err := cl.conn.Read()
+		if err != nil {
+			if raw != nil && err != io.EOF {
+				cl.r.count(&cl.r.res.Errors, err)
+				continue
+			}
+			cl.mu.Lock()
+			closing := cl.closing
+			cl.mu.Unlock()
+			if !closing {
+				cl.r.count(&cl.r.res.Disconnects, err)
+			}
+			return
+		}
+		now := time.Now()
+		cl.r.count(&cl.r.res.Received, nil)
+		cl.mu.Lock()
+		var sent time.Time
+		var isAck bool
+		switch f.Kind {
+		case socket.FrameAck:
+			sent, isAck = cl.acks[f.AckId]
+			delete(cl.acks, f.AckId)
+		case socket.FrameEvent:
+			if list := cl.responses[f.Event]; len(list) > 0 {
+				sent = list[0]
+				cl.responses[f.Event] = list[1:]
+			}
+		}
+		cl.mu.Unlock()
+		if sent.IsZero() {
+			continue
+		}
+		cl.r.mu.Lock()
+		if isAck {
+			cl.r.acks = append(cl.r.acks, now.Sub(sent))
+		} else {
+			cl.r.responses = append(cl.r.responses, now.Sub(sent))
+		}
+		cl.r.mu.Unlock()
+	}
+}
+
+// count increments the counter of the result and keeps the error message as a sample.
+func (r *runner) count(counter *int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	*counter++
+	if err == nil || len(r.res.ErrorSamples) >= maxErrorSamples {
+		return
+	}
+	for _, s := range r.res.ErrorSamples {
+		if s == err.Error() {
+			return
+		}
+	}
+	r.res.ErrorSamples = append(r.res.ErrorSamples, err.Error())
+}
+
+func (r *runner) add(counter *int, n int) {
+	if n < 1 {
+		return
+	}
+	r.mu.Lock()
+	*counter += n
+	r.mu.Unlock()
+}
+
+// summarize computes the throughput over the emit period and the latency percentiles.
+func (r *runner) summarize() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if seconds := r.c.Duration.Seconds(); seconds > 0 {
+		r.res.EmitsPerSecond = float64(r.res.Emitted) / seconds
+		r.res.ReceivedPerSecond = float64(r.res.Received) / seconds
+	}
+	r.res.Acks = NewLatency(r.acks)
+	r.res.Responses = NewLatency(r.responses)
+}
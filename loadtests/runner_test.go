@@ -0,0 +1,147 @@
+package loadtests
+
+import (
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/util"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecute(t *testing.T) {
+	target, catalog := setupExecuteTest(t)
+	defer target.Close()
+
+	res, err := Execute(&Config{
+		Target:      target.URL,
+		Connections: 5,
+		Rate:        100,
+		Duration:    300 * time.Millisecond,
+		RampUp:      50 * time.Millisecond,
+		Timeout:     time.Second,
+		Catalog:     catalog,
+	})
+	if err != nil {
+		t.Fatalf("Can not execute load test: %s", err.Error())
+	}
+
+	if res.Connections != 5 || res.Connected != 5 || res.ConnectErrors != 0 || res.Disconnects != 0 || res.Errors != 0 || res.Timeouts != 0 {
+		t.Errorf("Unexpected counters: %+v", res)
+	}
+	if res.Emitted < 10 || res.Acks.Count != res.Emitted || res.Responses.Count != res.Emitted || res.Received != 2*res.Emitted {
+		t.Errorf("Emits were not answered: emitted %d, received %d, acks %+v, responses %+v", res.Emitted, res.Received, res.Acks, res.Responses)
+	}
+	if res.EmitsPerSecond <= 0 || res.Acks.P99Ms < res.Acks.P50Ms || res.Acks.MaxMs < res.Acks.P99Ms {
+		t.Errorf("Unexpected throughput or latency: %+v, acks: %+v", res, res.Acks)
+	}
+}
+
+func TestExecuteConnectErrors(t *testing.T) {
+	target, catalog := setupExecuteTest(t)
+	target.Close()
+
+	res, err := Execute(&Config{Target: target.URL, Connections: 3, Rate: 10, Duration: 50 * time.Millisecond, Catalog: catalog})
+	if err != nil {
+		t.Fatalf("Can not execute load test: %s", err.Error())
+	}
+	if res.ConnectErrors != 3 || res.Connected != 0 || len(res.ErrorSamples) != 1 {
+		t.Errorf("Unexpected counters: %+v", res)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	_, catalog := setupExecuteTest(t)
+
+	cases := []struct {
+		config *Config
+		err    string
+	}{
+		{&Config{Connections: 1, Rate: 1, Duration: time.Second, Catalog: catalog}, ""},
+		{&Config{Connections: 0, Rate: 1, Duration: time.Second, Catalog: catalog}, "number of connections must be positive"},
+		{&Config{Connections: 1, Rate: 0, Duration: time.Second, Catalog: catalog}, "rate must be positive"},
+		{&Config{Connections: 1, Rate: 1, Catalog: catalog}, "duration must be positive"},
+		{&Config{Connections: 1, Rate: 1, Duration: time.Second, Protocol: "mqtt", Catalog: catalog}, "mqtt"},
+		{&Config{Connections: 1, Rate: 1, Duration: time.Second, Events: []string{"logout"}, Catalog: catalog}, "event logout is not described in the catalog"},
+		{&Config{Connections: 1, Rate: 1, Duration: time.Second}, "there are no client events to emit"},
+	}
+
+	for caseNum, item := range cases {
+		err := item.config.Validate()
+		if len(item.err) < 1 && err != nil || len(item.err) > 0 && (err == nil || !strings.Contains(err.Error(), item.err)) {
+			t.Errorf("[%d] Unexpected validation error. Want: %s, received: %v", caseNum, item.err, err)
+		}
+	}
+}
+
+func TestNewLatency(t *testing.T) {
+	delays := make([]time.Duration, 0, 100)
+	for i := 100; i > 0; i-- {
+		delays = append(delays, time.Duration(i)*time.Millisecond)
+	}
+
+	l := NewLatency(delays)
+
+	if l.Count != 100 || l.MinMs != 1 || l.MaxMs != 100 || l.MeanMs != 50.5 || l.P50Ms != 50 || l.P90Ms != 90 || l.P95Ms != 95 || l.P99Ms != 99 {
+		t.Errorf("Unexpected latency: %+v", l)
+	}
+	if l = NewLatency(nil); l.Count != 0 || l.MaxMs != 0 {
+		t.Errorf("Unexpected latency of no delays: %+v", l)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	from := &Result{EmitsPerSecond: 100, Errors: 2, Acks: &Latency{P95Ms: 20}}
+	to := &Result{EmitsPerSecond: 80, Errors: 1, Acks: &Latency{P95Ms: 30}}
+
+	deltas := make(map[string]*Delta)
+	for _, d := range Compare(from, to) {
+		deltas[d.Metric] = d
+	}
+
+	if d := deltas["emitsPerSecond"]; d.Change != -20 || !d.Worse {
+		t.Errorf("Unexpected throughput delta: %+v", d)
+	}
+	if d := deltas["acks.p95Ms"]; d.Change != 50 || !d.Worse {
+		t.Errorf("Unexpected latency delta: %+v", d)
+	}
+	if d := deltas["errors"]; d.Change != -50 || d.Worse {
+		t.Errorf("Unexpected errors delta: %+v", d)
+	}
+	if d := deltas["responses.p95Ms"]; d.Change != 0 || d.Worse {
+		t.Errorf("Unexpected delta of missing latency: %+v", d)
+	}
+}
+
+func setupExecuteTest(t *testing.T) (*httptest.Server, []*events.Event) {
+	e := router.New()
+	es := store.NewMemory(&store.MemoryConfig{Logger: e.Logger})
+	name, _ := util.NewNullStringFromString("name")
+	ok, _ := util.NewNullStringFromString("ok")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{
+			{Key: name, Type: "string", Required: true},
+		}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient,
+			Fields:          []events.Field{{Key: name, Type: "string", Required: true}},
+			AckFields:       []events.Field{{Key: ok, Type: "boolean", Required: true}},
+			ResponseEventId: util.NewNullInt64FromInt64(1),
+		},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Error while creating event: %s", err.Error())
+		}
+	}
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Error while loading mock catalog: %s", err.Error())
+	}
+	catalog, err := es.GetAll()
+	if err != nil {
+		t.Fatalf("Error while getting catalog: %s", err.Error())
+	}
+	return httptest.NewServer(ms), catalog
+}
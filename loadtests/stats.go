@@ -0,0 +1,95 @@
+package loadtests
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// NewLatency summarizes the delays, percentiles are computed with the nearest-rank method.
+func NewLatency(delays []time.Duration) *Latency {
+	l := &Latency{Count: len(delays)}
+	if len(delays) < 1 {
+		return l
+	}
+	ms := make([]float64, len(delays))
+	sum := 0.0
+	for i, d := range delays {
+		ms[i] = float64(d) / float64(time.Millisecond)
+		sum += ms[i]
+	}
+	sort.Float64s(ms)
+	l.MinMs = ms[0]
+	l.MaxMs = ms[len(ms)-1]
+	l.MeanMs = sum / float64(len(ms))
+	l.P50Ms = percentile(ms, 50)
+	l.P90Ms = percentile(ms, 90)
+	l.P95Ms = percentile(ms, 95)
+	l.P99Ms = percentile(ms, 99)
+	return l
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// Delta is the change of a metric between two runs.
+type Delta struct {
+	Metric string  `json:"metric"`
+	From   float64 `json:"from"`
+	To     float64 `json:"to"`
+	// Change in percent of the from value, zero when the from value is zero
+	Change float64 `json:"change"`
+	// Worse is set when the metric got worse: latencies and failures grew or throughput dropped
+	Worse bool `json:"worse"`
+}
+
+type metric struct {
+	name         string
+	value        func(r *Result) float64
+	higherIsBest bool
+}
+
+var metrics = []*metric{
+	{name: "emitsPerSecond", value: func(r *Result) float64 { return r.EmitsPerSecond }, higherIsBest: true},
+	{name: "receivedPerSecond", value: func(r *Result) float64 { return r.ReceivedPerSecond }, higherIsBest: true},
+	{name: "acks.p50Ms", value: func(r *Result) float64 { return latencyOf(r.Acks).P50Ms }},
+	{name: "acks.p95Ms", value: func(r *Result) float64 { return latencyOf(r.Acks).P95Ms }},
+	{name: "acks.p99Ms", value: func(r *Result) float64 { return latencyOf(r.Acks).P99Ms }},
+	{name: "responses.p50Ms", value: func(r *Result) float64 { return latencyOf(r.Responses).P50Ms }},
+	{name: "responses.p95Ms", value: func(r *Result) float64 { return latencyOf(r.Responses).P95Ms }},
+	{name: "responses.p99Ms", value: func(r *Result) float64 { return latencyOf(r.Responses).P99Ms }},
+	{name: "connectErrors", value: func(r *Result) float64 { return float64(r.ConnectErrors) }},
+	{name: "disconnects", value: func(r *Result) float64 { return float64(r.Disconnects) }},
+	{name: "errors", value: func(r *Result) float64 { return float64(r.Errors) }},
+	{name: "timeouts", value: func(r *Result) float64 { return float64(r.Timeouts) }},
+}
+
+func latencyOf(l *Latency) *Latency {
+	if l == nil {
+		return &Latency{}
+	}
+	return l
+}
+
+// Compare lists the changes of the key metrics between the results of two runs.
+func Compare(from, to *Result) []*Delta {
+	deltas := make([]*Delta, 0, len(metrics))
+	for _, m := range metrics {
+		d := &Delta{Metric: m.name, From: m.value(from), To: m.value(to)}
+		if d.From != 0 {
+			d.Change = (d.To - d.From) * 100 / d.From
+		}
+		if m.higherIsBest {
+			d.Worse = d.To < d.From
+		} else {
+			d.Worse = d.To > d.From
+		}
+		deltas = append(deltas, d)
+	}
+	return deltas
+}
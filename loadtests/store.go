@@ -0,0 +1,13 @@
+package loadtests
+
+// Store keeps the load test runs of a single project. Runs of other projects are not visible to it.
+type Store interface {
+	GetById(uint64) (*Run, error)
+	// List returns runs newest first, of the target when it is not empty
+	List(offset, limit int, target string) ([]*Run, int, error)
+	Create(*Run) error
+	Update(*Run) error
+	Delete(*Run) error
+	// ForProject returns the store of the runs of the project sharing the storage with this one
+	ForProject(projectId uint64) Store
+}
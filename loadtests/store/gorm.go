@@ -0,0 +1,103 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+)
+
+type Gorm struct {
+	db        *gorm.DB
+	logger    logger.Logger
+	projectId uint64
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+	// ProjectId is the project of the load test runs, the default project when zero
+	ProjectId uint64
+}
+
+func NewGorm(c *GormConfig) loadtests.Store {
+	s := &Gorm{
+		db:        c.DB,
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Gorm) ForProject(projectId uint64) loadtests.Store {
+	return NewGorm(&GormConfig{DB: s.db, Logger: s.logger, ProjectId: projectId})
+}
+
+// scoped limits the query to the load test runs of the project.
+func (s *Gorm) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("`projectId` = ?", s.projectId)
+}
+
+func (s *Gorm) GetById(id uint64) (*loadtests.Run, error) {
+	var run loadtests.Run
+	if err := s.scoped(s.db).First(&run, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (s *Gorm) List(offset, limit int, target string) ([]*loadtests.Run, int, error) {
+	runsList, total := make([]*loadtests.Run, 0), 0
+	qb := s.scoped(s.db.Model(&runsList))
+	if len(target) > 0 {
+		qb = qb.Where("`target` = ?", target)
+	}
+	if err := qb.Count(&total).Error; err != nil {
+		return runsList, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("createdAt desc").Find(&runsList).Error
+	return runsList, total, err
+}
+
+func (s *Gorm) Create(run *loadtests.Run) error {
+	run.ProjectId = s.projectId
+	return s.db.Create(run).Error
+}
+
+func (s *Gorm) Update(run *loadtests.Run) error {
+	existing := &loadtests.Run{}
+	if err := s.scoped(s.db).First(existing, run.ID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("[loadtests.store.gorm] run with id = %d does not exist", run.ID)
+		}
+		return err
+	}
+	run.CreatedAt = existing.CreatedAt
+	run.ProjectId = existing.ProjectId
+	res := s.db.Save(run)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[loadtests.store.gorm] run with id = %d was not updated", run.ID)
+	}
+	return nil
+}
+
+func (s *Gorm) Delete(run *loadtests.Run) error {
+	res := s.scoped(s.db).Delete(run)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[loadtests.store.gorm] run with id = %d was not deleted", run.ID)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"sync"
+	"time"
+)
+
+// Memory keeps copies of the runs, they are updated by the goroutines executing them.
+type Memory struct {
+	logger    logger.Logger
+	projectId uint64
+	// Records of all projects are shared by the stores returned by ForProject
+	*memoryRecords
+}
+
+type memoryRecords struct {
+	records []*loadtests.Run
+	lastId  uint64
+	mu      *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+	// ProjectId is the project of the load test runs, the default project when zero
+	ProjectId uint64
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	s := &Memory{
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+		memoryRecords: &memoryRecords{
+			records: make([]*loadtests.Run, 0),
+			mu:      &sync.Mutex{},
+		},
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Memory) ForProject(projectId uint64) loadtests.Store {
+	return &Memory{
+		logger:        s.logger,
+		projectId:     projectId,
+		memoryRecords: s.memoryRecords,
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*loadtests.Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id && el.ProjectId == s.projectId {
+			run := *el
+			return &run, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int, target string) ([]*loadtests.Run, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runsList := make([]*loadtests.Run, 0, len(s.records))
+	// Newest first, like the gorm store
+	for i := len(s.records) - 1; i >= 0; i-- {
+		if s.records[i].ProjectId != s.projectId || (len(target) > 0 && s.records[i].Target != target) {
+			continue
+		}
+		run := *s.records[i]
+		runsList = append(runsList, &run)
+	}
+	total := len(runsList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return runsList[offset : offset+l], total, nil
+}
+
+func (s *Memory) Create(run *loadtests.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastId++
+	run.ID = s.lastId
+	run.ProjectId = s.projectId
+	run.CreatedAt = time.Now()
+	run.UpdatedAt = run.CreatedAt
+	stored := *run
+	s.records = append(s.records, &stored)
+	return nil
+}
+
+func (s *Memory) Update(run *loadtests.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, el := range s.records {
+		if el.ID == run.ID && el.ProjectId == s.projectId {
+			run.CreatedAt = el.CreatedAt
+			run.ProjectId = el.ProjectId
+			run.UpdatedAt = time.Now()
+			stored := *run
+			s.records[i] = &stored
+			break
+		}
+	}
+	return nil
+}
+
+func (s *Memory) Delete(run *loadtests.Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, el := range s.records {
+		if el.ID == run.ID && el.ProjectId == s.projectId {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			break
+		}
+	}
+	return nil
+}
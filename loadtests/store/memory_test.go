@@ -0,0 +1,97 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	"github.com/nskondratev/api-page-go-back/testutils"
+	"testing"
+)
+
+func TestMemory_Create(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	cases := []testutils.MemoryCreateTestCase{
+		{ItemToCreate: &loadtests.Run{Name: "Launch", Target: "ws://localhost:3000"}, TotalRows: 1, LastItemID: 1},
+		{ItemToCreate: &loadtests.Run{Name: "Launch again", Target: "ws://localhost:3000"}, TotalRows: 2, LastItemID: 2},
+	}
+
+	for caseNum, item := range cases {
+		runToCreate, ok := item.ItemToCreate.(*loadtests.Run)
+
+		if !ok {
+			t.Errorf("[%d] Can not convert test case item to create to *loadtests.Run type", caseNum)
+		}
+
+		if err := s.Create(runToCreate); err != nil {
+			t.Errorf("[%d] error while creating run %+v", caseNum, runToCreate)
+		}
+
+		if len(s.records) != item.TotalRows {
+			t.Errorf("[%d] total rows mismatch. Want %d, received %d", caseNum, item.TotalRows, len(s.records))
+		}
+
+		if s.records[len(s.records)-1].ID != item.LastItemID {
+			t.Errorf("[%d] last run id mismatch. Want %d, received %d", caseNum, item.LastItemID, s.records[len(s.records)-1].ID)
+		}
+	}
+}
+
+func TestMemory_Update(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	run := &loadtests.Run{Name: "Launch", Status: loadtests.StatusRunning}
+	if err := s.Create(run); err != nil {
+		t.Fatalf("Can not create test run: %s", err.Error())
+	}
+
+	run.Status = loadtests.StatusFinished
+	run.Result = &loadtests.Result{Emitted: 10}
+	if err := s.Update(run); err != nil {
+		t.Fatalf("Can not update run: %s", err.Error())
+	}
+	run.Status = loadtests.StatusFailed
+
+	stored, err := s.GetById(run.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("Updated run was not found: %v", err)
+	}
+	if stored.Status != loadtests.StatusFinished || stored.Result.Emitted != 10 {
+		t.Errorf("Stored run should not change with the updated one: %+v", stored)
+	}
+}
+
+func TestMemory_List(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	for _, target := range []string{"ws://a", "ws://b", "ws://a", "ws://a"} {
+		if err := s.Create(&loadtests.Run{Target: target}); err != nil {
+			t.Fatalf("Can not create test run: %s", err.Error())
+		}
+	}
+
+	list, total, err := s.List(1, 5, "ws://a")
+	if err != nil {
+		t.Fatalf("Can not list runs: %s", err.Error())
+	}
+	if total != 3 || len(list) != 2 || list[0].ID != 3 || list[1].ID != 1 {
+		t.Errorf("Unexpected runs list: %+v, total: %d", list, total)
+	}
+
+	if _, total, _ = s.List(0, -1, ""); total != 4 {
+		t.Errorf("Unexpected total of all runs: %d", total)
+	}
+}
+
+func TestMemory_Delete(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	run := &loadtests.Run{Name: "Launch"}
+	if err := s.Create(run); err != nil {
+		t.Fatalf("Can not create test run: %s", err.Error())
+	}
+	if err := s.Delete(run); err != nil {
+		t.Fatalf("Can not delete run: %s", err.Error())
+	}
+	if stored, _ := s.GetById(run.ID); stored != nil {
+		t.Errorf("Run was not deleted: %+v", stored)
+	}
+}
@@ -2,16 +2,46 @@ package main
 
 import (
 	"github.com/facebookgo/grace/gracehttp"
+	"github.com/nskondratev/api-page-go-back/audit"
+	auditStore "github.com/nskondratev/api-page-go-back/audit/store"
+	"github.com/nskondratev/api-page-go-back/cli"
+	"github.com/nskondratev/api-page-go-back/collections"
+	collectionStore "github.com/nskondratev/api-page-go-back/collections/store"
 	"github.com/nskondratev/api-page-go-back/conf"
 	"github.com/nskondratev/api-page-go-back/db"
+	"github.com/nskondratev/api-page-go-back/environments"
+	environmentStore "github.com/nskondratev/api-page-go-back/environments/store"
+	"github.com/nskondratev/api-page-go-back/events"
 	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/fuzz"
+	fuzzStore "github.com/nskondratev/api-page-go-back/fuzz/store"
 	"github.com/nskondratev/api-page-go-back/gql"
 	"github.com/nskondratev/api-page-go-back/handler"
+	"github.com/nskondratev/api-page-go-back/loadtests"
+	loadTestStore "github.com/nskondratev/api-page-go-back/loadtests/store"
 	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/monitors"
+	"github.com/nskondratev/api-page-go-back/monitors/scheduler"
+	monitorStore "github.com/nskondratev/api-page-go-back/monitors/store"
 	"github.com/nskondratev/api-page-go-back/pages"
 	pageStore "github.com/nskondratev/api-page-go-back/pages/store"
+	"github.com/nskondratev/api-page-go-back/projects"
+	projectStore "github.com/nskondratev/api-page-go-back/projects/store"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	recordingStore "github.com/nskondratev/api-page-go-back/recordings/store"
+	"github.com/nskondratev/api-page-go-back/registry"
+	registryStore "github.com/nskondratev/api-page-go-back/registry/store"
+	"github.com/nskondratev/api-page-go-back/relay"
 	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	scenarioStore "github.com/nskondratev/api-page-go-back/scenarios/store"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	snapshotStore "github.com/nskondratev/api-page-go-back/snapshots/store"
+	"github.com/nskondratev/api-page-go-back/users"
+	userStore "github.com/nskondratev/api-page-go-back/users/store"
 	"github.com/nskondratev/api-page-go-back/ws"
+	"os"
 )
 
 func main() {
@@ -35,6 +65,50 @@ func main() {
 		r.Logger.Fatal(err)
 	}
 
+	if err := d.AutoMigrate(
+		&projects.Project{},
+		&pages.Page{},
+		&events.Event{},
+		&events.Field{},
+		&snapshots.Snapshot{},
+		&registry.Type{},
+		&registry.TypeField{},
+		&registry.EnumValue{},
+		&recordings.Session{},
+		&recordings.Message{},
+		&scenarios.Scenario{},
+		&loadtests.Run{},
+		&fuzz.Run{},
+		&collections.Folder{},
+		&collections.Request{},
+		&environments.Environment{},
+		&monitors.Monitor{},
+		&monitors.Check{},
+		&users.User{},
+		&users.RefreshToken{},
+		&users.ProjectRole{},
+		&users.APIToken{},
+		&audit.Entry{},
+	).Error; err != nil {
+		r.Logger.Fatal(err)
+	}
+
+	// Names are unique within the project, the former indexes kept them unique across all projects
+	for _, table := range []string{"snapshots", "scenarios", "environments", "monitors"} {
+		if err := db.DropIndex(d, table, "uix_"+table+"_name"); err != nil {
+			r.Logger.Fatal(err)
+		}
+	}
+
+	prs := projectStore.NewGorm(&projectStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	if err := projects.EnsureDefault(prs); err != nil {
+		r.Logger.Fatal(err)
+	}
+
 	ps := pageStore.NewGorm(&pageStore.GormConfig{
 		DB:     d,
 		Logger: l,
@@ -45,28 +119,194 @@ func main() {
 		Logger: l,
 	})
 
-	wsHub := ws.NewHub()
-	go wsHub.Run()
+	ss := snapshotStore.NewGorm(&snapshotStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	ts := registryStore.NewGorm(&registryStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	rs := recordingStore.NewGorm(&recordingStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	scs := scenarioStore.NewGorm(&scenarioStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	lts := loadTestStore.NewGorm(&loadTestStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	fzs := fuzzStore.NewGorm(&fuzzStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	cs := collectionStore.NewGorm(&collectionStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	cipher, err := environments.NewCipher(c.SecretKey)
+	if err != nil {
+		r.Logger.Fatal(err)
+	}
+
+	ens := environmentStore.NewGorm(&environmentStore.GormConfig{
+		DB:     d,
+		Logger: l,
+		Cipher: cipher,
+	})
+
+	mns := monitorStore.NewGorm(&monitorStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	us := userStore.NewGorm(&userStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	as := auditStore.NewGorm(&auditStore.GormConfig{
+		DB:     d,
+		Logger: l,
+	})
+
+	if len(c.Args) > 0 {
+		os.Exit(cli.Run(&cli.Config{
+			Logger:           l,
+			ProjectStore:     prs,
+			EventStore:       es,
+			SnapshotStore:    ss,
+			TypeStore:        ts,
+			ScenarioStore:    scs,
+			RecordingStore:   rs,
+			LoadTestStore:    lts,
+			EnvironmentStore: ens,
+			UserStore:        us,
+			Out:              os.Stdout,
+		}, c.Args))
+	}
+
+	issuer, err := users.NewIssuer(&users.IssuerConfig{
+		Store:     us,
+		Secret:    c.JwtSecret,
+		AccessTTL: c.AccessTokenTTL,
+	})
+	if err != nil {
+		r.Logger.Fatalf("Error while creating token issuer, set JWT_SECRET: %s", err.Error())
+	}
 
 	gqlHub := gql.NewGraphQLHub()
 
 	gqlHub.AddType(pages.GraphQLType)
+	gqlHub.AddType(events.GraphQLType)
+	gqlHub.AddType(environments.GraphQLType)
 
 	if err := pages.RegisterGraphQLQueries(ps, gqlHub); err != nil {
 		r.Logger.Fatalf("Error while registering graphql queries from pages: %s", err.Error())
 	}
 
+	if err := events.RegisterGraphQLQueries(es, gqlHub); err != nil {
+		r.Logger.Fatalf("Error while registering graphql queries from events: %s", err.Error())
+	}
+
+	if err := environments.RegisterGraphQLQueries(ens, gqlHub); err != nil {
+		r.Logger.Fatalf("Error while registering graphql queries from environments: %s", err.Error())
+	}
+
 	if err := gqlHub.Compile(); err != nil {
 		r.Logger.Fatalf("Error while compiling graphql schema: %s", err.Error())
 	}
 
-	h := handler.New(&handler.Config{
-		Logger:     l,
+	wsHub := ws.NewHub(&ws.HubConfig{
 		PageStore:  ps,
 		EventStore: es,
-		WsHub:      wsHub,
 		GraphQLHub: gqlHub,
+		ReplaySize: c.WsReplaySize,
 	})
+	go wsHub.Run()
+
+	var mockServer *mock.Server
+	if c.MockEnabled {
+		schedule, err := mock.ParseSchedule(c.MockSchedule)
+		if err != nil {
+			r.Logger.Fatal(err)
+		}
+		mockProjectId, err := projects.IdBySlug(prs, c.MockProject)
+		if err != nil {
+			r.Logger.Fatal(err)
+		}
+		mockServer = mock.New(&mock.Config{
+			EventStore: es,
+			TypeStore:  ts,
+			Logger:     l,
+			ProjectId:  mockProjectId,
+			Interval:   c.MockInterval,
+			Schedule:   schedule,
+		})
+		go mockServer.Run()
+		wsHub = mock.NewReloadingHub(wsHub, mockServer)
+	}
+
+	go scheduler.New(&scheduler.Config{
+		MonitorStore:     mns,
+		ScenarioStore:    scs,
+		EventStore:       es,
+		TypeStore:        ts,
+		EnvironmentStore: ens,
+		AllowedHosts:     c.AllowedHosts,
+		WsHub:            wsHub,
+		Logger:           l,
+	}).Run()
+
+	go audit.NewRetention(&audit.RetentionConfig{
+		Store:     as,
+		Logger:    l,
+		Retention: c.AuditRetention,
+	}).Run()
+
+	hc := &handler.Config{
+		Logger:           l,
+		ProjectStore:     prs,
+		PageStore:        ps,
+		EventStore:       es,
+		SnapshotStore:    ss,
+		TypeStore:        ts,
+		RecordingStore:   rs,
+		ScenarioStore:    scs,
+		LoadTestStore:    lts,
+		FuzzStore:        fzs,
+		CollectionStore:  cs,
+		EnvironmentStore: ens,
+		MonitorStore:     mns,
+		UserStore:        us,
+		AuditStore:       as,
+		TokenIssuer:      issuer,
+		WsHub:            wsHub,
+		GraphQLHub:       gqlHub,
+		Relay: relay.New(&relay.Config{
+			EventStore:     es,
+			TypeStore:      ts,
+			RecordingStore: rs,
+			Logger:         l,
+			AllowedHosts:   c.AllowedHosts,
+		}),
+		AllowedHosts:           c.AllowedHosts,
+		LoadTestMaxConnections: c.LoadTestMaxConnections,
+	}
+	if mockServer != nil {
+		hc.MockServer = mockServer
+	}
+	h := handler.New(hc)
 	h.Register(apiGroup, baseGroup)
 
 	r.Server.Addr = c.Addr
@@ -0,0 +1,106 @@
+package mock
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"time"
+)
+
+const (
+	// Time allowed to write a message to the peer.
+	writeWait = 10 * time.Second
+
+	// Time allowed to read the next message from the peer.
+	readWait = 60 * time.Second
+
+	// Interval of the Engine.IO pings sent by Socket.io clients.
+	pingInterval = 25 * time.Second
+)
+
+// conn is a client connection of the mock server.
+type conn struct {
+	server *Server
+	ws     *websocket.Conn
+	codec  socket.Codec
+	send   chan []byte
+}
+
+type openPayload struct {
+	Sid          string   `json:"sid"`
+	Upgrades     []string `json:"upgrades"`
+	PingInterval int64    `json:"pingInterval"`
+	PingTimeout  int64    `json:"pingTimeout"`
+}
+
+// handshake sends the Engine.IO open packet followed by the connect packet of the default namespace.
+func (c *conn) handshake() {
+	data, err := json.Marshal(&openPayload{
+		Sid:          newSid(),
+		Upgrades:     []string{},
+		PingInterval: int64(pingInterval / time.Millisecond),
+		PingTimeout:  int64(readWait / time.Millisecond),
+	})
+	if err != nil {
+		return
+	}
+	c.emit(&socket.Frame{Kind: socket.FrameOpen, Data: data, AckId: -1})
+	c.emit(&socket.Frame{Kind: socket.FrameConnect, AckId: -1})
+}
+
+// emit queues the frame for sending. Frames are dropped when the client does not keep up.
+// It is called from the read pump or under the server lock, so the send channel is still open.
+func (c *conn) emit(f *socket.Frame) {
+	msg, err := c.codec.Encode(f)
+	if err != nil {
+		c.server.logger.Warnf("Error while encoding mock server frame: %s", err.Error())
+		return
+	}
+	select {
+	case c.send <- msg:
+	default:
+	}
+}
+
+func (c *conn) emitError(p *errorPayload) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	c.emit(socket.NewEventFrame(ErrorEvent, data))
+}
+
+func (c *conn) readPump() {
+	defer func() {
+		c.server.unregister(c)
+		c.ws.Close()
+	}()
+	for {
+		c.ws.SetReadDeadline(time.Now().Add(readWait))
+		_, msg, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		f, err := c.codec.Decode(msg)
+		if err != nil {
+			c.emitError(&errorPayload{Message: err.Error()})
+			continue
+		}
+		if f.Kind == socket.FrameClose {
+			return
+		}
+		c.server.handle(c, f)
+	}
+}
+
+func (c *conn) writePump() {
+	defer c.ws.Close()
+	for msg := range c.send {
+		c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := c.ws.WriteMessage(websocket.TextMessage, msg); err != nil {
+			return
+		}
+	}
+	c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	c.ws.WriteMessage(websocket.CloseMessage, []byte{})
+}
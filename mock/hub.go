@@ -0,0 +1,43 @@
+package mock
+
+import (
+	"github.com/nskondratev/api-page-go-back/ws"
+)
+
+// reloadingHub passes messages to the wrapped hub and reloads the mock server
+// when a message reports a change of the catalog.
+type reloadingHub struct {
+	ws.IHub
+	server *Server
+}
+
+func NewReloadingHub(h ws.IHub, s *Server) ws.IHub {
+	return &reloadingHub{IHub: h, server: s}
+}
+
+func (h *reloadingHub) Broadcast(message ws.ApMessage) error {
+	err := h.IHub.Broadcast(message)
+	if changesCatalog(message) {
+		h.server.RequestReload()
+	}
+	return err
+}
+
+// BroadcastTo reloads the catalog on changes of the events of the project imitated by the mock only.
+func (h *reloadingHub) BroadcastTo(projectId uint64, message ws.ApMessage) error {
+	err := h.IHub.BroadcastTo(projectId, message)
+	if projectId == h.server.projectId && changesCatalog(message) {
+		h.server.RequestReload()
+	}
+	return err
+}
+
+func changesCatalog(message ws.ApMessage) bool {
+	switch m := message.(type) {
+	case *ws.ApEventMessage, *ws.ApTypeMessage:
+		return true
+	case *ws.ApIdMessage:
+		return m.EventConst == ws.EventDeleted || m.EventConst == ws.TypeDeleted
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package mock
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseSchedule parses the list of emit intervals in the form "value=5s,other=1m".
+func ParseSchedule(s string) (map[string]time.Duration, error) {
+	schedule := make(map[string]time.Duration)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) < 1 {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || len(strings.TrimSpace(parts[0])) < 1 {
+			return nil, fmt.Errorf("invalid schedule item: %s", item)
+		}
+		interval, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval of %s: %s", parts[0], err.Error())
+		}
+		schedule[strings.TrimSpace(parts[0])] = interval
+	}
+	return schedule, nil
+}
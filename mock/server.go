@@ -0,0 +1,280 @@
+package mock
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrorEvent is emitted by the mock when an incoming event can not be answered.
+const ErrorEvent = "mock_error"
+
+// Resolution of the emit schedule
+var scheduleTick = 100 * time.Millisecond
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// Server imitates the real-time API described by the events catalog.
+//
+// Incoming client events are validated against their fields. A valid event is answered with
+// an example payload of the ack fields when the emit requests an acknowledgement, and with
+// an example of the linked response event. Frontend events are emitted to all connections
+// according to the schedule.
+type Server struct {
+	eventStore events.Store
+	typeStore  registry.Store
+	logger     logger.Logger
+	projectId  uint64
+	interval   time.Duration
+	schedule   map[string]time.Duration
+	tick       time.Duration
+
+	mu      *sync.RWMutex
+	byValue map[string]*events.Event
+	byId    map[uint64]*events.Event
+	types   events.Types
+	due     map[string]time.Time
+	clients map[*conn]bool
+	reload  chan struct{}
+}
+
+type Config struct {
+	EventStore events.Store
+	// TypeStore resolves the shared types of the fields, their values are not checked without it
+	TypeStore registry.Store
+	Logger    logger.Logger
+	// ProjectId is the project of the imitated catalog, the default project when zero
+	ProjectId uint64
+	// Interval between emits of every frontend event. Zero disables the emits.
+	Interval time.Duration
+	// Schedule overrides the interval for the events by value.
+	Schedule map[string]time.Duration
+}
+
+type errorPayload struct {
+	Event   string                    `json:"event"`
+	Message string                    `json:"message"`
+	Errors  []*events.ValidationError `json:"errors,omitempty"`
+}
+
+func New(c *Config) *Server {
+	schedule := c.Schedule
+	if schedule == nil {
+		schedule = make(map[string]time.Duration)
+	}
+	projectId := c.ProjectId
+	if projectId < 1 {
+		projectId = projects.DefaultId
+	}
+	return &Server{
+		eventStore: c.EventStore,
+		typeStore:  c.TypeStore,
+		logger:     c.Logger,
+		projectId:  projectId,
+		interval:   c.Interval,
+		schedule:   schedule,
+		tick:       scheduleTick,
+		mu:         &sync.RWMutex{},
+		byValue:    make(map[string]*events.Event),
+		byId:       make(map[uint64]*events.Event),
+		due:        make(map[string]time.Time),
+		clients:    make(map[*conn]bool),
+		reload:     make(chan struct{}, 1),
+	}
+}
+
+// Reload reads the catalog of the project from the events store.
+func (s *Server) Reload() error {
+	list, err := s.eventStore.ForProject(s.projectId).GetAll()
+	if err != nil {
+		return err
+	}
+	types, err := events.ResolveEventTypes(s.typeStore, list...)
+	if err != nil {
+		return err
+	}
+	byValue := make(map[string]*events.Event, len(list))
+	byId := make(map[uint64]*events.Event, len(list))
+	for _, e := range list {
+		byValue[e.Value] = e
+		byId[e.ID] = e
+	}
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.byValue = byValue
+	s.byId = byId
+	s.types = types
+	due := make(map[string]time.Time)
+	for _, e := range list {
+		interval := s.intervalOf(e.Value)
+		if e.Type != events.TypeFrontend || interval <= 0 {
+			continue
+		}
+		if at, ok := s.due[e.Value]; ok {
+			due[e.Value] = at
+		} else {
+			due[e.Value] = now.Add(interval)
+		}
+	}
+	s.due = due
+	return nil
+}
+
+// RequestReload schedules the reload of the catalog without blocking the caller.
+func (s *Server) RequestReload() {
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+}
+
+// Run loads the catalog, then serves reload requests and emits the scheduled events.
+func (s *Server) Run() {
+	if err := s.Reload(); err != nil {
+		s.logger.Errorf("Error while loading events catalog for mock server: %s", err.Error())
+	}
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.reload:
+			if err := s.Reload(); err != nil {
+				s.logger.Errorf("Error while reloading events catalog for mock server: %s", err.Error())
+			}
+		case now := <-ticker.C:
+			s.emitScheduled(now)
+		}
+	}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	codecName := r.URL.Query().Get("protocol")
+	codec, err := socket.NewCodec(codecName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.logger.Warnf("Error while upgrading mock server connection: %s", err.Error())
+		return
+	}
+	c := &conn{server: s, ws: ws, codec: codec, send: make(chan []byte, 256)}
+	s.mu.Lock()
+	s.clients[c] = true
+	s.mu.Unlock()
+
+	if codec.Name() == socket.CodecSocketIO {
+		c.handshake()
+	}
+	go c.writePump()
+	go c.readPump()
+}
+
+func (s *Server) unregister(c *conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.clients[c]; ok {
+		delete(s.clients, c)
+		close(c.send)
+	}
+}
+
+func (s *Server) intervalOf(value string) time.Duration {
+	if interval, ok := s.schedule[value]; ok {
+		return interval
+	}
+	return s.interval
+}
+
+func (s *Server) emitScheduled(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for value, at := range s.due {
+		if now.Before(at) {
+			continue
+		}
+		s.due[value] = now.Add(s.intervalOf(value))
+		e := s.byValue[value]
+		frame := socket.NewEventFrame(e.Value, exampleOf(e.Fields, s.types))
+		for c := range s.clients {
+			c.emit(frame)
+		}
+	}
+}
+
+// handle answers the frame received from the connection.
+func (s *Server) handle(c *conn, f *socket.Frame) {
+	switch f.Kind {
+	case socket.FramePing:
+		c.emit(&socket.Frame{Kind: socket.FramePong, AckId: -1})
+		return
+	case socket.FrameEvent:
+	default:
+		return
+	}
+
+	s.mu.RLock()
+	e, ok := s.byValue[f.Event]
+	var response *events.Event
+	if ok && e.HasResponse() {
+		response = s.byId[uint64(e.ResponseEventId.Int64)]
+	}
+	types := s.types
+	s.mu.RUnlock()
+
+	if !ok {
+		c.emitError(&errorPayload{Event: f.Event, Message: "unknown event"})
+		return
+	}
+	if e.Type != events.TypeClient {
+		c.emitError(&errorPayload{Event: f.Event, Message: "event is not emitted by clients"})
+		return
+	}
+	if errs := events.ValidateRawPayload(e.Fields, types, f.Data); len(errs) > 0 {
+		c.emitError(&errorPayload{Event: f.Event, Message: "payload does not match the event fields", Errors: errs})
+		return
+	}
+	if f.HasAck() {
+		var data json.RawMessage
+		if len(e.AckFields) > 0 {
+			data = exampleOf(e.AckFields, types)
+		}
+		c.emit(socket.NewAckFrame(f.AckId, data))
+	}
+	if response != nil {
+		c.emit(socket.NewEventFrame(response.Value, exampleOf(response.Fields, types)))
+	}
+}
+
+func exampleOf(fields []events.Field, types events.Types) json.RawMessage {
+	data, err := json.Marshal(events.GenerateExample(fields, types))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func newSid() string {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "mock"
+	}
+	return hex.EncodeToString(b)
+}
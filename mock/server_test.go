@@ -0,0 +1,148 @@
+package mock
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServer_ClientEvents(t *testing.T) {
+	_, srv, _ := setupMockTest(t, 0, nil)
+	defer srv.Close()
+	conn := dialMock(t, srv, "")
+	defer conn.Close()
+
+	expectMessage(t, conn, `0{"sid":`)
+	expectMessage(t, conn, `40`)
+
+	testCases := []struct {
+		in       string
+		expected []string
+	}{
+		{`2`, []string{`3`}},
+		{`421["login",{"name":"John","age":30}]`, []string{`431[{"ok":true}]`, `42["user",{"name":"name"}]`}},
+		{`42["login",{"age":30}]`, []string{`42["mock_error",{"event":"login","message":"payload does not match the event fields","errors":[{"path":"name","message":"required key is missing"}]}]`}},
+		{`42["user",{}]`, []string{`42["mock_error",{"event":"user","message":"event is not emitted by clients"}]`}},
+		{`42["unknown"]`, []string{`42["mock_error",{"event":"unknown","message":"unknown event"}]`}},
+	}
+	for _, tc := range testCases {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(tc.in)); err != nil {
+			t.Fatalf("Error while writing message: %s", err.Error())
+		}
+		for _, expected := range tc.expected {
+			expectMessage(t, conn, expected)
+		}
+	}
+}
+
+func TestServer_JSONProtocol(t *testing.T) {
+	_, srv, _ := setupMockTest(t, 0, nil)
+	defer srv.Close()
+	conn := dialMock(t, srv, "?protocol=json")
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"login","data":{"name":"John"},"ackId":5}`)); err != nil {
+		t.Fatalf("Error while writing message: %s", err.Error())
+	}
+	expectMessage(t, conn, `{"data":{"ok":true},"ackId":5,"ack":true}`)
+	expectMessage(t, conn, `{"event":"user","data":{"name":"name"}}`)
+}
+
+func TestServer_ScheduleAndReload(t *testing.T) {
+	scheduleTick = 10 * time.Millisecond
+	s, srv, es := setupMockTest(t, time.Hour, map[string]time.Duration{"user": 20 * time.Millisecond})
+	defer srv.Close()
+	conn := dialMock(t, srv, "?protocol=json")
+
+	expectMessage(t, conn, `{"event":"user","data":{"name":"name"}}`)
+
+	// Catalog changes reported through the hub reload the mock
+	hub := NewReloadingHub(ws.NewHubMock(), s)
+	name, _ := util.NewNullStringFromString("name")
+	e := &events.Event{ID: 1, Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{
+		{Key: name, Type: "number", Required: true},
+	}}
+	if err := es.Update(e); err != nil {
+		t.Fatalf("Error while updating event: %s", err.Error())
+	}
+	if err := hub.Broadcast(&ws.ApEventMessage{EventConst: ws.EventUpdated, Data: &ws.ApMessageEventEnvelope{Event: e}}); err != nil {
+		t.Fatalf("Error while broadcasting message: %s", err.Error())
+	}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		msg := readMessage(t, conn)
+		if msg == `{"event":"user","data":{"name":1}}` {
+			return
+		}
+	}
+	t.Error("Mock server has not reloaded the catalog")
+}
+
+func TestParseSchedule(t *testing.T) {
+	schedule, err := ParseSchedule("status=5s, tick = 1m,")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(schedule) != 2 || schedule["status"] != 5*time.Second || schedule["tick"] != time.Minute {
+		t.Errorf("Unexpected schedule: %v", schedule)
+	}
+	for _, in := range []string{"status", "=5s", "status=often"} {
+		if _, err := ParseSchedule(in); err == nil {
+			t.Errorf("Expected error while parsing %s", in)
+		}
+	}
+}
+
+func setupMockTest(t *testing.T, interval time.Duration, schedule map[string]time.Duration) (*Server, *httptest.Server, *store.Memory) {
+	e := router.New()
+	es := store.NewMemory(&store.MemoryConfig{Logger: e.Logger})
+	name, _ := util.NewNullStringFromString("name")
+	ok, _ := util.NewNullStringFromString("ok")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{
+			{Key: name, Type: "string", Required: true},
+		}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient,
+			Fields:          []events.Field{{Key: name, Type: "string", Required: true}},
+			AckFields:       []events.Field{{Key: ok, Type: "boolean", Required: true}},
+			ResponseEventId: util.NewNullInt64FromInt64(1),
+		},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Error while creating event: %s", err.Error())
+		}
+	}
+	s := New(&Config{EventStore: es, Logger: e.Logger, Interval: interval, Schedule: schedule})
+	go s.Run()
+	srv := httptest.NewServer(s)
+	return s, srv, es
+}
+
+func dialMock(t *testing.T, srv *httptest.Server, query string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/"+query, nil)
+	if err != nil {
+		t.Fatalf("Error while connecting to mock server: %s", err.Error())
+	}
+	return conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) string {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, msg, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Error while reading message: %s", err.Error())
+	}
+	return string(msg)
+}
+
+func expectMessage(t *testing.T, conn *websocket.Conn, expected string) {
+	if msg := readMessage(t, conn); !strings.HasPrefix(msg, expected) {
+		t.Errorf("Unexpected message. Expected: %s, actual: %s", expected, msg)
+	}
+}
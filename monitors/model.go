@@ -0,0 +1,89 @@
+package monitors
+
+import (
+	"time"
+)
+
+// Statuses of monitors
+const (
+	// StatusUnknown is the status of the monitor before the first check
+	StatusUnknown = "unknown"
+	StatusUp      = "up"
+	StatusDown    = "down"
+)
+
+// Monitor runs the scenario against the environment every interval. The status is the result of the last check.
+type Monitor struct {
+	ID          uint64 `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId   uint64 `json:"projectId" gorm:"unique_index:idx_monitors_project_name;default:1;column:projectId"`
+	Name        string `json:"name" gorm:"size:255;unique_index:idx_monitors_project_name;column:name"`
+	ScenarioId  uint64 `json:"scenarioId" gorm:"index;column:scenarioId"`
+	Environment string `json:"environment" gorm:"size:255;column:environment"`
+	IntervalMs  int64  `json:"intervalMs" gorm:"column:intervalMs"`
+	Enabled     bool   `json:"enabled" gorm:"column:enabled"`
+	Status      string `json:"status" gorm:"type:ENUM('unknown','up','down');default:'unknown';column:status"`
+	// StatusChangedAt is the time of the check which changed the status
+	StatusChangedAt *time.Time `json:"statusChangedAt" gorm:"column:statusChangedAt"`
+	LastCheckedAt   *time.Time `json:"lastCheckedAt" gorm:"column:lastCheckedAt"`
+	CreatedAt       time.Time  `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+// Check is the result of a single run of the monitor scenario.
+type Check struct {
+	ID         uint64 `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	MonitorId  uint64 `json:"monitorId" gorm:"index;column:monitorId"`
+	Passed     bool   `json:"passed" gorm:"column:passed"`
+	DurationMs int64  `json:"durationMs" gorm:"column:durationMs"`
+	// Message describes the failure: the connection error or the failed step
+	Message   string    `json:"message" gorm:"type:text;column:message"`
+	StartedAt time.Time `json:"startedAt" gorm:"index;column:startedAt"`
+}
+
+func (Monitor) TableName() string {
+	return "monitors"
+}
+
+func (Check) TableName() string {
+	return "monitor_checks"
+}
+
+// Due reports whether the interval has passed since the last check.
+func (m *Monitor) Due(now time.Time) bool {
+	return m.LastCheckedAt == nil || !now.Before(m.LastCheckedAt.Add(time.Duration(m.IntervalMs)*time.Millisecond))
+}
+
+// Uptime summarizes the checks of the monitor over the period.
+type Uptime struct {
+	MonitorId uint64    `json:"monitorId"`
+	Since     time.Time `json:"since"`
+	Checks    int       `json:"checks"`
+	Passed    int       `json:"passed"`
+	// Percent of passed checks, 100 without checks
+	Percent           float64 `json:"percent"`
+	AverageDurationMs int64   `json:"averageDurationMs"`
+	MaxDurationMs     int64   `json:"maxDurationMs"`
+	LastFailure       *Check  `json:"lastFailure"`
+}
+
+// Summarize builds the uptime of the checks made since the time.
+func Summarize(monitorId uint64, since time.Time, checks []*Check) *Uptime {
+	u := &Uptime{MonitorId: monitorId, Since: since, Checks: len(checks), Percent: 100}
+	var total int64
+	for _, c := range checks {
+		total += c.DurationMs
+		if c.DurationMs > u.MaxDurationMs {
+			u.MaxDurationMs = c.DurationMs
+		}
+		if c.Passed {
+			u.Passed++
+		} else if u.LastFailure == nil || c.StartedAt.After(u.LastFailure.StartedAt) {
+			u.LastFailure = c
+		}
+	}
+	if len(checks) > 0 {
+		u.Percent = float64(u.Passed) * 100 / float64(len(checks))
+		u.AverageDurationMs = total / int64(len(checks))
+	}
+	return u
+}
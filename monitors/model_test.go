@@ -0,0 +1,45 @@
+package monitors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonitor_Due(t *testing.T) {
+	now := time.Now()
+	checked := now.Add(-30 * time.Second)
+	cases := []struct {
+		monitor *Monitor
+		due     bool
+	}{
+		{&Monitor{IntervalMs: 60000}, true},
+		{&Monitor{IntervalMs: 60000, LastCheckedAt: &checked}, false},
+		{&Monitor{IntervalMs: 30000, LastCheckedAt: &checked}, true},
+	}
+
+	for caseNum, item := range cases {
+		if due := item.monitor.Due(now); due != item.due {
+			t.Errorf("[%d] Unexpected due. Want %v, received %v", caseNum, item.due, due)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	now := time.Now()
+	u := Summarize(1, now, []*Check{
+		{ID: 1, Passed: true, DurationMs: 100, StartedAt: now},
+		{ID: 2, Passed: false, DurationMs: 400, StartedAt: now.Add(time.Minute)},
+		{ID: 3, Passed: false, DurationMs: 300, StartedAt: now.Add(2 * time.Minute)},
+		{ID: 4, Passed: true, DurationMs: 200, StartedAt: now.Add(3 * time.Minute)},
+	})
+	if u.Checks != 4 || u.Passed != 2 || u.Percent != 50 || u.AverageDurationMs != 250 || u.MaxDurationMs != 400 {
+		t.Errorf("Unexpected uptime: %+v", u)
+	}
+	if u.LastFailure == nil || u.LastFailure.ID != 3 {
+		t.Errorf("Unexpected last failure: %+v", u.LastFailure)
+	}
+
+	if u = Summarize(1, now, nil); u.Percent != 100 || u.LastFailure != nil {
+		t.Errorf("Unexpected uptime without checks: %+v", u)
+	}
+}
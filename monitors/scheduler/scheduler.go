@@ -0,0 +1,198 @@
+package scheduler
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/environments"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/monitors"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"sync"
+	"time"
+)
+
+// DefaultTick is the period of looking for due monitors
+const DefaultTick = time.Second
+
+type Config struct {
+	MonitorStore  monitors.Store
+	ScenarioStore scenarios.Store
+	EventStore    events.Store
+	// TypeStore resolves the shared types of the catalog
+	TypeStore        registry.Store
+	EnvironmentStore environments.Store
+	// AllowedHosts restricts the targets of the environments, every target is denied when empty
+	AllowedHosts socket.AllowedHosts
+	// WsHub is notified when a check changes the status of the monitor
+	WsHub  ws.IHub
+	Logger logger.Logger
+	Tick   time.Duration
+}
+
+// Scheduler checks enabled monitors when their interval passes. Checks of different monitors run
+// concurrently, a monitor is not checked again while its check is running.
+type Scheduler struct {
+	c       *Config
+	tick    time.Duration
+	running map[uint64]bool
+	mu      *sync.Mutex
+}
+
+func New(c *Config) *Scheduler {
+	s := &Scheduler{
+		c:       c,
+		tick:    c.Tick,
+		running: make(map[uint64]bool),
+		mu:      &sync.Mutex{},
+	}
+	if s.tick <= 0 {
+		s.tick = DefaultTick
+	}
+	return s
+}
+
+func (s *Scheduler) Run() {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		s.checkDue(now)
+	}
+}
+
+func (s *Scheduler) checkDue(now time.Time) {
+	list, err := s.c.MonitorStore.ListEnabled()
+	if err != nil {
+		s.c.Logger.Errorf("Error while listing monitors: %s", err.Error())
+		return
+	}
+	for _, m := range list {
+		if !m.Due(now) || !s.start(m.ID) {
+			continue
+		}
+		monitor := *m
+		go func() {
+			defer s.finish(monitor.ID)
+			if _, err := s.Check(&monitor); err != nil {
+				s.c.Logger.Errorf("Error while checking monitor %s: %s", monitor.Name, err.Error())
+			}
+		}()
+	}
+}
+
+func (s *Scheduler) start(id uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[id] {
+		return false
+	}
+	s.running[id] = true
+	return true
+}
+
+func (s *Scheduler) finish(id uint64) {
+	s.mu.Lock()
+	delete(s.running, id)
+	s.mu.Unlock()
+}
+
+// Check runs the scenario of the monitor against its environment, stores the check and the status of
+// the monitor and broadcasts the status change to the project of the monitor. The scenario, the environment
+// and the catalog are those of the project of the monitor, missing scenarios and environments fail the check.
+func (s *Scheduler) Check(m *monitors.Monitor) (*monitors.Check, error) {
+	ms := s.c.MonitorStore.ForProject(m.ProjectId)
+	check := &monitors.Check{MonitorId: m.ID, StartedAt: time.Now()}
+	report, err := s.run(m)
+	if err != nil {
+		return nil, err
+	}
+	check.Passed = report.Passed
+	check.DurationMs = report.DurationMs
+	check.Message = failure(report)
+	if err := ms.CreateCheck(check); err != nil {
+		return nil, err
+	}
+
+	status := monitors.StatusDown
+	if check.Passed {
+		status = monitors.StatusUp
+	}
+	changed := m.Status != status
+	m.LastCheckedAt = &check.StartedAt
+	if changed {
+		m.Status = status
+		m.StatusChangedAt = &check.StartedAt
+	}
+	if err := ms.UpdateState(m); err != nil {
+		return check, err
+	}
+	if changed {
+		wsMessage := &ws.ApMonitorMessage{
+			EventConst: ws.MonitorStatusChanged,
+			Data: &ws.ApMessageMonitorEnvelope{
+				Monitor: m,
+				Check:   check,
+			},
+		}
+		if err := s.c.WsHub.BroadcastTo(m.ProjectId, wsMessage); err != nil {
+			s.c.Logger.Warnf("Error while broadcasting MONITOR_STATUS_CHANGED to ws: %s", err.Error())
+		}
+	}
+	return check, nil
+}
+
+// run returns the report of the scenario run with values of secrets of the environment masked. Errors are
+// returned for failures of the stores only.
+func (s *Scheduler) run(m *monitors.Monitor) (*scenarios.Report, error) {
+	sc, err := s.c.ScenarioStore.ForProject(m.ProjectId).GetById(m.ScenarioId)
+	if err != nil {
+		return nil, err
+	}
+	if sc == nil {
+		return failed(fmt.Sprintf("scenario %d does not exist", m.ScenarioId)), nil
+	}
+	e, err := s.c.EnvironmentStore.ForProject(m.ProjectId).GetByName(m.Environment)
+	if err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return failed(fmt.Sprintf("environment %q does not exist", m.Environment)), nil
+	}
+	// Messages of the check are stored and broadcast, the target and the vars may hold secrets
+	rc := &scenarios.RunConfig{}
+	if err := rc.ApplyEnvironment(e); err != nil {
+		return failed(scenarios.MaskSecrets(err.Error(), rc.Secrets)), nil
+	}
+	if err := s.c.AllowedHosts.Check(rc.Target); err != nil {
+		return failed(scenarios.MaskSecrets(err.Error(), rc.Secrets)), nil
+	}
+	if rc.Catalog, err = s.c.EventStore.ForProject(m.ProjectId).GetAll(); err != nil {
+		return nil, err
+	}
+	if rc.Types, err = events.ResolveEventTypes(s.c.TypeStore, rc.Catalog...); err != nil {
+		return nil, err
+	}
+	return scenarios.Run(sc, rc), nil
+}
+
+func failed(message string) *scenarios.Report {
+	return &scenarios.Report{Error: message}
+}
+
+// failure describes why the run failed, empty for passed runs.
+func failure(report *scenarios.Report) string {
+	if report.Passed {
+		return ""
+	}
+	if len(report.Error) > 0 {
+		return report.Error
+	}
+	for _, step := range report.Steps {
+		if step.Status == scenarios.StatusFailed {
+			return fmt.Sprintf("%d. %s %s: %s", step.Index, step.Action, step.Event, step.Message)
+		}
+	}
+	return "scenario failed"
+}
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"github.com/nskondratev/api-page-go-back/environments"
+	environmentStore "github.com/nskondratev/api-page-go-back/environments/store"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/monitors"
+	monitorStore "github.com/nskondratev/api-page-go-back/monitors/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	scenarioStore "github.com/nskondratev/api-page-go-back/scenarios/store"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/util"
+	"github.com/nskondratev/api-page-go-back/ws"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recordingHub struct {
+	messages []ws.ApMessage
+}
+
+func (h *recordingHub) Run() {}
+
+func (h *recordingHub) Broadcast(message ws.ApMessage) error {
+	h.messages = append(h.messages, message)
+	return nil
+}
+
+func (h *recordingHub) BroadcastTo(projectId uint64, message ws.ApMessage) error {
+	return h.Broadcast(message)
+}
+
+func (h *recordingHub) ServeWs(w http.ResponseWriter, r *http.Request) {}
+
+func TestScheduler_Check(t *testing.T) {
+	e := router.New()
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{Logger: e.Logger})
+	scs := scenarioStore.NewMemory(&scenarioStore.MemoryConfig{Logger: e.Logger})
+	ens := environmentStore.NewMemory(&environmentStore.MemoryConfig{Logger: e.Logger})
+	mns := monitorStore.NewMemory(&monitorStore.MemoryConfig{Logger: e.Logger})
+	hub := &recordingHub{}
+
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{{Key: name, Type: "string", Required: true}}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient, Fields: []events.Field{{Key: name, Type: "string", Required: true}}, ResponseEventId: util.NewNullInt64FromInt64(1)},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Can not create test event: %s", err.Error())
+		}
+	}
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Can not load mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	if err := ens.Create(&environments.Environment{Name: "mock", BaseUrl: target.URL, Variables: []*environments.Param{{Name: "user", Value: "John"}}}); err != nil {
+		t.Fatalf("Can not create test environment: %s", err.Error())
+	}
+	if err := ens.Create(&environments.Environment{Name: "internal", BaseUrl: "ws://internal.example.com"}); err != nil {
+		t.Fatalf("Can not create test environment: %s", err.Error())
+	}
+	if err := ens.Create(&environments.Environment{Name: "nohost", BaseUrl: "ws:///", Query: []*environments.Param{{Name: "token", Value: "s3cr3t", Secret: true}}}); err != nil {
+		t.Fatalf("Can not create test environment: %s", err.Error())
+	}
+	sc := &scenarios.Scenario{Name: "Login", Steps: []*scenarios.Step{
+		{Action: scenarios.ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"${user}"}`)},
+		{Action: scenarios.ActionExpect, Event: "user"},
+	}}
+	if err := scs.Create(sc); err != nil {
+		t.Fatalf("Can not create test scenario: %s", err.Error())
+	}
+	m := &monitors.Monitor{Name: "login", ScenarioId: sc.ID, Environment: "mock", IntervalMs: 60000, Enabled: true, Status: monitors.StatusUnknown}
+	if err := mns.Create(m); err != nil {
+		t.Fatalf("Can not create test monitor: %s", err.Error())
+	}
+
+	s := New(&Config{
+		MonitorStore:     mns,
+		ScenarioStore:    scs,
+		EventStore:       es,
+		EnvironmentStore: ens,
+		AllowedHosts:     socket.AllowedHosts{"127.0.0.1"},
+		WsHub:            hub,
+		Logger:           e.Logger,
+	})
+
+	cases := []struct {
+		environment string
+		passed      bool
+		status      string
+		broadcasts  int
+		message     string
+	}{
+		{"mock", true, monitors.StatusUp, 1, ""},
+		{"mock", true, monitors.StatusUp, 1, ""},
+		{"prod", false, monitors.StatusDown, 2, `environment "prod" does not exist`},
+		{"internal", false, monitors.StatusDown, 2, `target host is not allowed: internal.example.com`},
+		{"nohost", false, monitors.StatusDown, 2, `target has no host: ws:///?token=********`},
+		{"mock", true, monitors.StatusUp, 3, ""},
+	}
+
+	for caseNum, item := range cases {
+		m.Environment = item.environment
+		check, err := s.Check(m)
+		if err != nil {
+			t.Fatalf("[%d] Check failed: %s", caseNum, err.Error())
+		}
+		if check.Passed != item.passed || check.Message != item.message {
+			t.Errorf("[%d] Unexpected check: %+v", caseNum, check)
+		}
+		if stored, _ := mns.GetById(m.ID); stored.Status != item.status || stored.LastCheckedAt == nil {
+			t.Errorf("[%d] Unexpected stored monitor: %+v", caseNum, stored)
+		}
+		if len(hub.messages) != item.broadcasts {
+			t.Errorf("[%d] Unexpected number of broadcasts. Want %d, received %d", caseNum, item.broadcasts, len(hub.messages))
+		}
+	}
+
+	if _, total, _ := mns.ListChecks(m.ID, 0, 10); total != len(cases) {
+		t.Errorf("Unexpected number of stored checks: %d", total)
+	}
+}
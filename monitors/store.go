@@ -0,0 +1,27 @@
+package monitors
+
+import "time"
+
+// Store keeps the monitors of a single project. Monitors of other projects are not visible to it,
+// except for ListEnabled which returns the enabled monitors of all projects for the scheduler.
+type Store interface {
+	GetById(uint64) (*Monitor, error)
+	GetByName(string) (*Monitor, error)
+	List(offset, limit int) ([]*Monitor, int, error)
+	// ListEnabled returns the enabled monitors of all projects
+	ListEnabled() ([]*Monitor, error)
+	Create(*Monitor) error
+	Update(*Monitor) error
+	// UpdateState stores the status and the check times of the monitor only
+	UpdateState(*Monitor) error
+	// Delete removes the monitor with its checks
+	Delete(*Monitor) error
+	CreateCheck(*Check) error
+	// ListChecks returns checks of the monitor newest first
+	ListChecks(monitorId uint64, offset, limit int) ([]*Check, int, error)
+	ListChecksSince(monitorId uint64, since time.Time) ([]*Check, error)
+	// LastFailure returns the latest failed check of the monitor, nil when there is none
+	LastFailure(monitorId uint64) (*Check, error)
+	// ForProject returns the store of the monitors of the project sharing the storage with this one
+	ForProject(projectId uint64) Store
+}
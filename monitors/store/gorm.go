@@ -0,0 +1,160 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/db"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/monitors"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"time"
+)
+
+type Gorm struct {
+	db        *gorm.DB
+	logger    logger.Logger
+	projectId uint64
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+	// ProjectId is the project of the monitors, the default project when zero
+	ProjectId uint64
+}
+
+func NewGorm(c *GormConfig) monitors.Store {
+	s := &Gorm{
+		db:        c.DB,
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Gorm) ForProject(projectId uint64) monitors.Store {
+	return NewGorm(&GormConfig{DB: s.db, Logger: s.logger, ProjectId: projectId})
+}
+
+// scoped limits the query to the monitors of the project.
+func (s *Gorm) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("`projectId` = ?", s.projectId)
+}
+
+func (s *Gorm) GetById(id uint64) (*monitors.Monitor, error) {
+	var monitor monitors.Monitor
+	if err := s.scoped(s.db).First(&monitor, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &monitor, nil
+}
+
+func (s *Gorm) GetByName(name string) (*monitors.Monitor, error) {
+	var monitor monitors.Monitor
+	if err := s.scoped(s.db).Where("`name` = ?", name).First(&monitor).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &monitor, nil
+}
+
+func (s *Gorm) List(offset, limit int) ([]*monitors.Monitor, int, error) {
+	monitorsList, total := make([]*monitors.Monitor, 0), 0
+	qb := s.scoped(s.db.Model(&monitorsList))
+	if err := qb.Count(&total).Error; err != nil {
+		return monitorsList, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("name asc").Find(&monitorsList).Error
+	return monitorsList, total, err
+}
+
+func (s *Gorm) ListEnabled() ([]*monitors.Monitor, error) {
+	monitorsList := make([]*monitors.Monitor, 0)
+	err := s.db.Where("`enabled` = ?", true).Find(&monitorsList).Error
+	return monitorsList, err
+}
+
+func (s *Gorm) Create(monitor *monitors.Monitor) error {
+	monitor.ProjectId = s.projectId
+	return s.db.Create(monitor).Error
+}
+
+func (s *Gorm) Update(monitor *monitors.Monitor) error {
+	existing := &monitors.Monitor{}
+	if err := s.scoped(s.db).First(existing, monitor.ID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("[monitors.store.gorm] monitor with id = %d does not exist", monitor.ID)
+		}
+		return err
+	}
+	monitor.CreatedAt = existing.CreatedAt
+	monitor.ProjectId = existing.ProjectId
+	res := s.db.Save(monitor)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[monitors.store.gorm] monitor with id = %d was not updated", monitor.ID)
+	}
+	return nil
+}
+
+func (s *Gorm) UpdateState(monitor *monitors.Monitor) error {
+	return s.scoped(s.db.Model(&monitors.Monitor{})).Where("`id` = ?", monitor.ID).UpdateColumns(map[string]interface{}{
+		"status":          monitor.Status,
+		"statusChangedAt": monitor.StatusChangedAt,
+		"lastCheckedAt":   monitor.LastCheckedAt,
+	}).Error
+}
+
+func (s *Gorm) Delete(monitor *monitors.Monitor) error {
+	return db.Transaction(s.db, func(tx *gorm.DB) error {
+		res := s.scoped(tx).Delete(monitor)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected < 1 {
+			return fmt.Errorf("[monitors.store.gorm] monitor with id = %d was not deleted", monitor.ID)
+		}
+		return tx.Where("`monitorId` = ?", monitor.ID).Delete(&monitors.Check{}).Error
+	})
+}
+
+func (s *Gorm) CreateCheck(check *monitors.Check) error {
+	return s.db.Create(check).Error
+}
+
+func (s *Gorm) ListChecks(monitorId uint64, offset, limit int) ([]*monitors.Check, int, error) {
+	checksList, total := make([]*monitors.Check, 0), 0
+	qb := s.scoped(s.db.Model(&checksList)).Where("`monitorId` = ?", monitorId)
+	if err := qb.Count(&total).Error; err != nil {
+		return checksList, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("startedAt desc").Find(&checksList).Error
+	return checksList, total, err
+}
+
+func (s *Gorm) ListChecksSince(monitorId uint64, since time.Time) ([]*monitors.Check, error) {
+	checksList := make([]*monitors.Check, 0)
+	err := s.scoped(s.db).Where("`monitorId` = ? AND `startedAt` >= ?", monitorId, since).Order("startedAt asc").Find(&checksList).Error
+	return checksList, err
+}
+
+func (s *Gorm) LastFailure(monitorId uint64) (*monitors.Check, error) {
+	var check monitors.Check
+	if err := s.scoped(s.db).Where("`monitorId` = ? AND `passed` = ?", monitorId, false).Order("startedAt desc").First(&check).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &check, nil
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/monitors"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger    logger.Logger
+	projectId uint64
+	// Records of all projects are shared by the stores returned by ForProject
+	*memoryRecords
+}
+
+type memoryRecords struct {
+	records     []*monitors.Monitor
+	checks      []*monitors.Check
+	lastId      uint64
+	lastCheckId uint64
+	mu          *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+	// ProjectId is the project of the monitors, the default project when zero
+	ProjectId uint64
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	s := &Memory{
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+		memoryRecords: &memoryRecords{
+			records: make([]*monitors.Monitor, 0),
+			checks:  make([]*monitors.Check, 0),
+			mu:      &sync.Mutex{},
+		},
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Memory) ForProject(projectId uint64) monitors.Store {
+	return &Memory{
+		logger:        s.logger,
+		projectId:     projectId,
+		memoryRecords: s.memoryRecords,
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*monitors.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id && el.ProjectId == s.projectId {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) GetByName(name string) (*monitors.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.Name == name && el.ProjectId == s.projectId {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int) ([]*monitors.Monitor, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitorsList := make([]*monitors.Monitor, 0, len(s.records))
+	for _, el := range s.records {
+		if el.ProjectId == s.projectId {
+			monitorsList = append(monitorsList, el)
+		}
+	}
+	// Ordered by name, like the gorm store
+	sort.SliceStable(monitorsList, func(i, j int) bool {
+		return monitorsList[i].Name < monitorsList[j].Name
+	})
+	total := len(monitorsList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return monitorsList[offset : offset+l], total, nil
+}
+
+func (s *Memory) ListEnabled() ([]*monitors.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitorsList := make([]*monitors.Monitor, 0)
+	for _, el := range s.records {
+		if el.Enabled {
+			monitorsList = append(monitorsList, el)
+		}
+	}
+	return monitorsList, nil
+}
+
+func (s *Memory) Create(monitor *monitors.Monitor) error {
+	s.mu.Lock()
+	s.lastId++
+	monitor.ID = s.lastId
+	monitor.ProjectId = s.projectId
+	monitor.CreatedAt = time.Now()
+	monitor.UpdatedAt = monitor.CreatedAt
+	s.records = append(s.records, monitor)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Update(monitor *monitors.Monitor) error {
+	monitor.UpdatedAt = time.Now()
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == monitor.ID && el.ProjectId == s.projectId {
+			monitor.CreatedAt = el.CreatedAt
+			monitor.ProjectId = el.ProjectId
+			s.records[i] = monitor
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) UpdateState(monitor *monitors.Monitor) error {
+	s.mu.Lock()
+	for _, el := range s.records {
+		if el.ID == monitor.ID && el.ProjectId == s.projectId {
+			el.Status = monitor.Status
+			el.StatusChangedAt = monitor.StatusChangedAt
+			el.LastCheckedAt = monitor.LastCheckedAt
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Delete(monitor *monitors.Monitor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := false
+	for i, el := range s.records {
+		if el.ID == monitor.ID && el.ProjectId == s.projectId {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			deleted = true
+			break
+		}
+	}
+	if !deleted {
+		return nil
+	}
+	checks := s.checks[:0]
+	for _, el := range s.checks {
+		if el.MonitorId != monitor.ID {
+			checks = append(checks, el)
+		}
+	}
+	s.checks = checks
+	return nil
+}
+
+func (s *Memory) CreateCheck(check *monitors.Check) error {
+	s.mu.Lock()
+	s.lastCheckId++
+	check.ID = s.lastCheckId
+	s.checks = append(s.checks, check)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) ListChecks(monitorId uint64, offset, limit int) ([]*monitors.Check, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checksList := make([]*monitors.Check, 0)
+	for _, el := range s.checks {
+		if el.MonitorId == monitorId {
+			checksList = append(checksList, el)
+		}
+	}
+	// Newest first, like the gorm store
+	sort.SliceStable(checksList, func(i, j int) bool {
+		return checksList[i].StartedAt.After(checksList[j].StartedAt)
+	})
+	total := len(checksList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return checksList[offset : offset+l], total, nil
+}
+
+func (s *Memory) ListChecksSince(monitorId uint64, since time.Time) ([]*monitors.Check, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	checksList := make([]*monitors.Check, 0)
+	for _, el := range s.checks {
+		if el.MonitorId == monitorId && !el.StartedAt.Before(since) {
+			checksList = append(checksList, el)
+		}
+	}
+	sort.SliceStable(checksList, func(i, j int) bool {
+		return checksList[i].StartedAt.Before(checksList[j].StartedAt)
+	})
+	return checksList, nil
+}
+
+func (s *Memory) LastFailure(monitorId uint64) (*monitors.Check, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var last *monitors.Check
+	for _, el := range s.checks {
+		if el.MonitorId == monitorId && !el.Passed && (last == nil || el.StartedAt.After(last.StartedAt)) {
+			last = el
+		}
+	}
+	return last, nil
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/monitors"
+	"testing"
+	"time"
+)
+
+func TestMemory_Checks(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	m := &monitors.Monitor{Name: "login", Enabled: true, Status: monitors.StatusUnknown}
+	if err := s.Create(m); err != nil {
+		t.Fatalf("Can not create test monitor: %s", err.Error())
+	}
+	now := time.Now()
+	for i, passed := range []bool{true, false, false, true} {
+		check := &monitors.Check{MonitorId: m.ID, Passed: passed, StartedAt: now.Add(time.Duration(i-3) * time.Hour)}
+		if err := s.CreateCheck(check); err != nil {
+			t.Fatalf("Can not create test check: %s", err.Error())
+		}
+	}
+
+	list, total, err := s.ListChecks(m.ID, 0, 2)
+	if err != nil {
+		t.Fatalf("Can not list checks: %s", err.Error())
+	}
+	if total != 4 || len(list) != 2 || list[0].ID != 4 || list[1].ID != 3 {
+		t.Errorf("Unexpected checks list: total %d, %+v", total, list)
+	}
+
+	since, err := s.ListChecksSince(m.ID, now.Add(-90*time.Minute))
+	if err != nil || len(since) != 2 || since[0].ID != 3 {
+		t.Errorf("Unexpected checks since: %+v, %v", since, err)
+	}
+
+	failure, err := s.LastFailure(m.ID)
+	if err != nil || failure == nil || failure.ID != 3 {
+		t.Errorf("Unexpected last failure: %+v, %v", failure, err)
+	}
+
+	if err := s.Delete(m); err != nil {
+		t.Fatalf("Can not delete monitor: %s", err.Error())
+	}
+	if _, total, _ := s.ListChecks(m.ID, 0, 10); total != 0 {
+		t.Errorf("Checks of the deleted monitor are kept: %d", total)
+	}
+}
+
+func TestMemory_UpdateState(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	if err := s.Create(&monitors.Monitor{Name: "login", IntervalMs: 60000, Status: monitors.StatusUnknown}); err != nil {
+		t.Fatalf("Can not create test monitor: %s", err.Error())
+	}
+	now := time.Now()
+	if err := s.UpdateState(&monitors.Monitor{ID: 1, Name: "other", Status: monitors.StatusUp, LastCheckedAt: &now}); err != nil {
+		t.Fatalf("Can not update state: %s", err.Error())
+	}
+	m, _ := s.GetById(1)
+	if m.Name != "login" || m.IntervalMs != 60000 || m.Status != monitors.StatusUp || m.LastCheckedAt == nil {
+		t.Errorf("Unexpected monitor after the state update: %+v", m)
+	}
+}
+
+func TestMemory_ForProject(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+	other := s.ForProject(2)
+
+	for _, store := range []monitors.Store{s, other} {
+		if err := store.Create(&monitors.Monitor{Name: "login", Enabled: true}); err != nil {
+			t.Fatalf("Can not create test monitor: %s", err.Error())
+		}
+	}
+	if err := s.CreateCheck(&monitors.Check{MonitorId: 1, Passed: true, StartedAt: time.Now()}); err != nil {
+		t.Fatalf("Can not create test check: %s", err.Error())
+	}
+
+	if list, total, _ := other.List(0, 10); total != 1 || list[0].ID != 2 {
+		t.Errorf("Unexpected monitors of the other project: %+v", list)
+	}
+	if list, _ := other.ListEnabled(); len(list) != 2 {
+		t.Errorf("Enabled monitors of all projects are expected: %+v", list)
+	}
+	if err := other.Delete(&monitors.Monitor{ID: 1}); err != nil {
+		t.Fatalf("Can not delete monitor: %s", err.Error())
+	}
+	if m, _ := s.GetById(1); m == nil {
+		t.Error("Monitor of the default project was deleted")
+	}
+	if checks, total, _ := s.ListChecks(1, 0, 10); total != 1 {
+		t.Errorf("Checks of the monitor of the default project were deleted: %+v", checks)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"github.com/graphql-go/graphql"
 	"github.com/nskondratev/api-page-go-back/gql"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/users"
 )
 
 var GraphQLType = graphql.NewObject(
@@ -52,7 +54,11 @@ func getByIdResolver(ps Store) func(graphql.ResolveParams) (interface{}, error)
 		if !ok {
 			return nil, errors.New("graphql: cannot parse id argument")
 		}
-		page, err := ps.GetById(uint64(id))
+		projectId := projects.IdFromContext(p.Context)
+		if err := users.Check(p.Context, projectId, users.PermPagesRead); err != nil {
+			return nil, err
+		}
+		page, err := ps.ForProject(projectId).GetById(uint64(id))
 		if err != nil {
 			return nil, err
 		}
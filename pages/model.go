@@ -1,6 +1,7 @@
 package pages
 
 import (
+	"github.com/nskondratev/api-page-go-back/util"
 	"time"
 )
 
@@ -8,15 +9,20 @@ import (
 
 //reform:page
 type Page struct {
-	ID        uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key" reform:"id,pk"`
-	Title     string    `json:"title" gorm:"size:255;column:title" reform:"title"`
-	Text      string    `json:"text" gorm:"type:text;column:text" reform:"text"`
-	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt" reform:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updatedAt" reform:"updatedAt"`
+	ID        uint64 `json:"id" gorm:"AUTO_INCREMENT;primary_key" reform:"id,pk"`
+	ProjectId uint64 `json:"projectId" gorm:"index;default:1;column:projectId" reform:"projectId"`
+	Title     string `json:"title" gorm:"size:255;column:title" reform:"title"`
+	Text      string `json:"text" gorm:"type:text;column:text" reform:"text"`
+	// Users who created and last updated the page, null for changes made before users were introduced
+	CreatedBy util.NullInt64 `json:"createdBy" gorm:"type:BIGINT;column:createdBy" reform:"createdBy"`
+	UpdatedBy util.NullInt64 `json:"updatedBy" gorm:"type:BIGINT;column:updatedBy" reform:"updatedBy"`
+	CreatedAt time.Time      `json:"createdAt" gorm:"column:createdAt" reform:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt" gorm:"column:updatedAt" reform:"updatedAt"`
 }
 
 type PageList struct {
 	ID        uint64    `json:"id" form:"id" gorm:"column:id;AUTO_INCREMENT;primary_key"`
+	ProjectId uint64    `json:"projectId" gorm:"index;default:1;column:projectId"`
 	Title     string    `json:"title" gorm:"size:255;column:title"`
 	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
 	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updatedAt"`
@@ -1,9 +1,12 @@
 package pages
 
+// Store keeps the pages of a single project. Pages of other projects are not visible to it.
 type Store interface {
 	GetById(uint64) (*Page, error)
 	List(offset, limit int, sort string, descending bool, query string) ([]*PageList, int, error)
 	Update(*Page) error
 	Delete(*Page) error
 	Create(*Page) error
+	// ForProject returns the store of the pages of the project sharing the storage with this one
+	ForProject(projectId uint64) Store
 }
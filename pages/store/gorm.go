@@ -5,29 +5,47 @@ import (
 	"github.com/jinzhu/gorm"
 	"github.com/nskondratev/api-page-go-back/logger"
 	"github.com/nskondratev/api-page-go-back/pages"
+	"github.com/nskondratev/api-page-go-back/projects"
 	"strings"
 )
 
 type Gorm struct {
-	db     *gorm.DB
-	logger logger.Logger
+	db        *gorm.DB
+	logger    logger.Logger
+	projectId uint64
 }
 
 type GormConfig struct {
 	DB     *gorm.DB
 	Logger logger.Logger
+	// ProjectId is the project of the pages, the default project when zero
+	ProjectId uint64
 }
 
 func NewGorm(c *GormConfig) pages.Store {
-	return &Gorm{
-		db:     c.DB,
-		logger: c.Logger,
+	ps := &Gorm{
+		db:        c.DB,
+		logger:    c.Logger,
+		projectId: c.ProjectId,
 	}
+	if ps.projectId < 1 {
+		ps.projectId = projects.DefaultId
+	}
+	return ps
+}
+
+func (ps *Gorm) ForProject(projectId uint64) pages.Store {
+	return NewGorm(&GormConfig{DB: ps.db, Logger: ps.logger, ProjectId: projectId})
+}
+
+// scoped limits the query to the pages of the project.
+func (ps *Gorm) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("`projectId` = ?", ps.projectId)
 }
 
 func (ps *Gorm) GetById(id uint64) (*pages.Page, error) {
 	var page pages.Page
-	if err := ps.db.First(&page, id).Error; err != nil {
+	if err := ps.scoped(ps.db).First(&page, id).Error; err != nil {
 		if gorm.IsRecordNotFoundError(err) {
 			return nil, nil
 		}
@@ -49,7 +67,7 @@ func (ps *Gorm) List(offset, limit int, sort string, descending bool, query stri
 		orderDirection = " desc"
 	}
 	bSort.WriteString(orderDirection)
-	qb := ps.db.Model(&pagesList)
+	qb := ps.scoped(ps.db.Model(&pagesList))
 	if len(query) > 0 {
 		qb = qb.Where("`title` LIKE ?", "%"+query+"%")
 	}
@@ -61,7 +79,8 @@ func (ps *Gorm) List(offset, limit int, sort string, descending bool, query stri
 }
 
 func (ps *Gorm) Update(p *pages.Page) error {
-	res := ps.db.First(&pages.Page{}, p.ID)
+	existing := &pages.Page{}
+	res := ps.scoped(ps.db).First(existing, p.ID)
 
 	if res.Error != nil {
 		if gorm.IsRecordNotFoundError(res.Error) {
@@ -70,6 +89,8 @@ func (ps *Gorm) Update(p *pages.Page) error {
 		return res.Error
 	}
 
+	p.ProjectId = ps.projectId
+	p.CreatedBy = existing.CreatedBy
 	res = ps.db.Save(&p)
 
 	if res.Error != nil {
@@ -84,7 +105,7 @@ func (ps *Gorm) Update(p *pages.Page) error {
 }
 
 func (ps *Gorm) Delete(p *pages.Page) error {
-	res := ps.db.Delete(p)
+	res := ps.scoped(ps.db).Delete(p)
 	if res.Error != nil {
 		return res.Error
 	}
@@ -95,5 +116,6 @@ func (ps *Gorm) Delete(p *pages.Page) error {
 }
 
 func (ps *Gorm) Create(p *pages.Page) error {
+	p.ProjectId = ps.projectId
 	return ps.db.Create(p).Error
 }
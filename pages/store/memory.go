@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/nskondratev/api-page-go-back/logger"
 	"github.com/nskondratev/api-page-go-back/pages"
+	"github.com/nskondratev/api-page-go-back/projects"
 	"sort"
 	"strings"
 	"sync"
@@ -11,26 +12,49 @@ import (
 )
 
 type Memory struct {
-	logger  logger.Logger
+	logger    logger.Logger
+	projectId uint64
+	// Records of all projects are shared by the stores returned by ForProject
+	*memoryRecords
+}
+
+type memoryRecords struct {
 	records []*pages.Page
 	mu      *sync.Mutex
 }
 
 type MemoryConfig struct {
 	Logger logger.Logger
+	// ProjectId is the project of the pages, the default project when zero
+	ProjectId uint64
 }
 
 func NewMemory(c *MemoryConfig) *Memory {
+	s := &Memory{
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+		memoryRecords: &memoryRecords{
+			records: make([]*pages.Page, 0),
+			mu:      &sync.Mutex{},
+		},
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Memory) ForProject(projectId uint64) pages.Store {
 	return &Memory{
-		logger:  c.Logger,
-		records: make([]*pages.Page, 0),
-		mu:      &sync.Mutex{},
+		logger:        s.logger,
+		projectId:     projectId,
+		memoryRecords: s.memoryRecords,
 	}
 }
 
 func (s *Memory) GetById(id uint64) (*pages.Page, error) {
 	for _, p := range s.records {
-		if p.ID == id {
+		if p.ID == id && p.ProjectId == s.projectId {
 			return p, nil
 		}
 	}
@@ -41,7 +65,7 @@ func (s *Memory) List(offset, limit int, sort string, descending bool, query str
 	pagesList, total := make([]*pages.PageList, 0), 0
 	q := strings.ToLower(query)
 	for _, el := range s.records {
-		if len(q) < 1 || strings.Contains(strings.ToLower(el.Title), q) {
+		if el.ProjectId == s.projectId && (len(q) < 1 || strings.Contains(strings.ToLower(el.Title), q)) {
 			pagesList = append(pagesList, PageToPageList(el))
 		}
 	}
@@ -68,7 +92,9 @@ func (s *Memory) Update(p *pages.Page) error {
 	p.UpdatedAt = time.Now()
 	s.mu.Lock()
 	for i, el := range s.records {
-		if el.ID == p.ID {
+		if el.ID == p.ID && el.ProjectId == s.projectId {
+			p.ProjectId = el.ProjectId
+			p.CreatedBy = el.CreatedBy
 			s.records[i] = p
 			break
 		}
@@ -80,7 +106,7 @@ func (s *Memory) Update(p *pages.Page) error {
 func (s *Memory) Delete(p *pages.Page) error {
 	s.mu.Lock()
 	for i, el := range s.records {
-		if el.ID == p.ID {
+		if el.ID == p.ID && el.ProjectId == s.projectId {
 			copy(s.records[i:], s.records[i+1:])
 			s.records[len(s.records)-1] = nil
 			s.records = s.records[:len(s.records)-1]
@@ -94,6 +120,7 @@ func (s *Memory) Delete(p *pages.Page) error {
 func (s *Memory) Create(p *pages.Page) error {
 	s.mu.Lock()
 	p.ID = uint64(len(s.records) + 1)
+	p.ProjectId = s.projectId
 	p.CreatedAt = time.Now()
 	p.UpdatedAt = time.Now()
 	s.records = append(s.records, p)
@@ -169,6 +196,7 @@ func getSorterByKey(key string) (by, error) {
 func PageToPageList(p *pages.Page) *pages.PageList {
 	return &pages.PageList{
 		ID:        p.ID,
+		ProjectId: p.ProjectId,
 		Title:     p.Title,
 		CreatedAt: p.CreatedAt,
 		UpdatedAt: p.UpdatedAt,
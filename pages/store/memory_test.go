@@ -144,12 +144,14 @@ func TestPageToPageList(t *testing.T) {
 
 	pl1 := &pages.PageList{
 		ID:        p1s.ID,
+		ProjectId: p1s.ProjectId,
 		Title:     p1s.Title,
 		CreatedAt: p1s.CreatedAt,
 		UpdatedAt: p1s.UpdatedAt,
 	}
 	pl2 := &pages.PageList{
 		ID:        p2s.ID,
+		ProjectId: p2s.ProjectId,
 		Title:     p2s.Title,
 		CreatedAt: p2s.CreatedAt,
 		UpdatedAt: p2s.UpdatedAt,
@@ -255,12 +257,14 @@ func TestMemory_List(t *testing.T) {
 
 	pl1 := &pages.PageList{
 		ID:        p1s.ID,
+		ProjectId: p1s.ProjectId,
 		Title:     p1s.Title,
 		CreatedAt: p1s.CreatedAt,
 		UpdatedAt: p1s.UpdatedAt,
 	}
 	pl2 := &pages.PageList{
 		ID:        p2s.ID,
+		ProjectId: p2s.ProjectId,
 		Title:     p2s.Title,
 		CreatedAt: p2s.CreatedAt,
 		UpdatedAt: p2s.UpdatedAt,
@@ -268,6 +272,7 @@ func TestMemory_List(t *testing.T) {
 
 	pl3 := &pages.PageList{
 		ID:        p3s.ID,
+		ProjectId: p3s.ProjectId,
 		Title:     p3s.Title,
 		CreatedAt: p3s.CreatedAt,
 		UpdatedAt: p3s.UpdatedAt,
@@ -305,3 +310,28 @@ func TestMemory_List(t *testing.T) {
 		}
 	}
 }
+
+func TestMemory_ForProject(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+	other := s.ForProject(2)
+
+	if err := s.Create(&pages.Page{Title: "Intro"}); err != nil {
+		t.Fatalf("Can not create test page: %s", err.Error())
+	}
+	if err := other.Create(&pages.Page{Title: "Intro"}); err != nil {
+		t.Fatalf("Can not create test page: %s", err.Error())
+	}
+
+	if list, total, _ := s.List(0, 10, "", false, "intro"); total != 1 || list[0].ID != 1 {
+		t.Errorf("Unexpected pages of the default project: %+v", list)
+	}
+	if p, _ := other.GetById(1); p != nil {
+		t.Errorf("Page of the default project is visible: %+v", p)
+	}
+	if err := other.Update(&pages.Page{ID: 1, Title: "Changed"}); err != nil {
+		t.Fatalf("Can not update page: %s", err.Error())
+	}
+	if p, _ := s.GetById(1); p.Title != "Intro" {
+		t.Errorf("Page of the default project was changed: %+v", p)
+	}
+}
@@ -0,0 +1,69 @@
+package projects
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultId is the ID of the project of the routes without the project prefix. Pages and events
+// stored before projects were introduced belong to it.
+const DefaultId uint64 = 1
+
+// DefaultSlug is the slug of the default project
+const DefaultSlug = "default"
+
+// Project is a namespace of pages and events, served under /api/projects/:slug.
+type Project struct {
+	ID          uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	Name        string    `json:"name" gorm:"size:255;column:name"`
+	Slug        string    `json:"slug" gorm:"size:64;unique_index;column:slug"`
+	Description string    `json:"description" gorm:"type:text;column:description"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+func (Project) TableName() string {
+	return "projects"
+}
+
+type contextKey struct{}
+
+// NewContext returns the copy of the context carrying the project ID.
+func NewContext(ctx context.Context, projectId uint64) context.Context {
+	return context.WithValue(ctx, contextKey{}, projectId)
+}
+
+// IdFromContext returns the project ID carried by the context, DefaultId when there is none.
+func IdFromContext(ctx context.Context) uint64 {
+	if ctx != nil {
+		if id, ok := ctx.Value(contextKey{}).(uint64); ok && id > 0 {
+			return id
+		}
+	}
+	return DefaultId
+}
+
+// EnsureDefault creates the default project when it does not exist.
+func EnsureDefault(s Store) error {
+	p, err := s.GetById(DefaultId)
+	if err != nil || p != nil {
+		return err
+	}
+	return s.Create(&Project{ID: DefaultId, Name: "Default", Slug: DefaultSlug})
+}
+
+// IdBySlug returns the ID of the project with the slug, DefaultId when the slug is empty.
+func IdBySlug(s Store, slug string) (uint64, error) {
+	if len(slug) < 1 {
+		return DefaultId, nil
+	}
+	p, err := s.GetBySlug(slug)
+	if err != nil {
+		return 0, err
+	}
+	if p == nil {
+		return 0, fmt.Errorf("project %q does not exist", slug)
+	}
+	return p.ID, nil
+}
@@ -0,0 +1,10 @@
+package projects
+
+type Store interface {
+	GetById(uint64) (*Project, error)
+	GetBySlug(string) (*Project, error)
+	List(offset, limit int) ([]*Project, int, error)
+	Create(*Project) error
+	Update(*Project) error
+	Delete(*Project) error
+}
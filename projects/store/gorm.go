@@ -0,0 +1,91 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+)
+
+type Gorm struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+}
+
+func NewGorm(c *GormConfig) projects.Store {
+	return &Gorm{
+		db:     c.DB,
+		logger: c.Logger,
+	}
+}
+
+func (s *Gorm) GetById(id uint64) (*projects.Project, error) {
+	var project projects.Project
+	if err := s.db.First(&project, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (s *Gorm) GetBySlug(slug string) (*projects.Project, error) {
+	var project projects.Project
+	if err := s.db.Where("`slug` = ?", slug).First(&project).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (s *Gorm) List(offset, limit int) ([]*projects.Project, int, error) {
+	projectsList, total := make([]*projects.Project, 0), 0
+	qb := s.db.Model(&projectsList)
+	if err := qb.Count(&total).Error; err != nil {
+		return projectsList, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("name asc").Find(&projectsList).Error
+	return projectsList, total, err
+}
+
+func (s *Gorm) Create(project *projects.Project) error {
+	return s.db.Create(project).Error
+}
+
+func (s *Gorm) Update(project *projects.Project) error {
+	existing := &projects.Project{}
+	if err := s.db.First(existing, project.ID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("[projects.store.gorm] project with id = %d does not exist", project.ID)
+		}
+		return err
+	}
+	project.CreatedAt = existing.CreatedAt
+	res := s.db.Save(project)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[projects.store.gorm] project with id = %d was not updated", project.ID)
+	}
+	return nil
+}
+
+func (s *Gorm) Delete(project *projects.Project) error {
+	res := s.db.Delete(project)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[projects.store.gorm] project with id = %d was not deleted", project.ID)
+	}
+	return nil
+}
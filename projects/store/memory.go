@@ -0,0 +1,114 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger  logger.Logger
+	records []*projects.Project
+	lastId  uint64
+	mu      *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	return &Memory{
+		logger:  c.Logger,
+		records: make([]*projects.Project, 0),
+		mu:      &sync.Mutex{},
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*projects.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) GetBySlug(slug string) (*projects.Project, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.Slug == slug {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int) ([]*projects.Project, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	projectsList := make([]*projects.Project, len(s.records))
+	copy(projectsList, s.records)
+	// Ordered by name, like the gorm store
+	sort.SliceStable(projectsList, func(i, j int) bool {
+		return projectsList[i].Name < projectsList[j].Name
+	})
+	total := len(projectsList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return projectsList[offset : offset+l], total, nil
+}
+
+// Create keeps the ID of the project when it is set, like the gorm store.
+func (s *Memory) Create(project *projects.Project) error {
+	s.mu.Lock()
+	if project.ID == 0 {
+		s.lastId++
+		project.ID = s.lastId
+	} else if project.ID > s.lastId {
+		s.lastId = project.ID
+	}
+	project.CreatedAt = time.Now()
+	project.UpdatedAt = project.CreatedAt
+	s.records = append(s.records, project)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Update(project *projects.Project) error {
+	project.UpdatedAt = time.Now()
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == project.ID {
+			project.CreatedAt = el.CreatedAt
+			s.records[i] = project
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Delete(project *projects.Project) error {
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == project.ID {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/projects"
+	"testing"
+)
+
+func TestMemory_Create(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	if err := projects.EnsureDefault(s); err != nil {
+		t.Fatalf("Can not create default project: %s", err.Error())
+	}
+	if err := projects.EnsureDefault(s); err != nil {
+		t.Fatalf("Can not ensure default project: %s", err.Error())
+	}
+	chat := &projects.Project{Name: "Chat", Slug: "chat"}
+	if err := s.Create(chat); err != nil {
+		t.Fatalf("Can not create test project: %s", err.Error())
+	}
+
+	list, total, err := s.List(0, -1)
+	if err != nil {
+		t.Fatalf("Can not list projects: %s", err.Error())
+	}
+	if total != 2 || list[0].Slug != "chat" || list[1].ID != projects.DefaultId || chat.ID != 2 {
+		t.Errorf("Unexpected projects list: total %d, %+v", total, list)
+	}
+
+	found, err := s.GetBySlug("chat")
+	if err != nil || found == nil || found.ID != chat.ID {
+		t.Errorf("Unexpected project by slug: %+v, %v", found, err)
+	}
+}
@@ -0,0 +1,108 @@
+package recordings
+
+import (
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/util"
+	"time"
+)
+
+// Directions of recorded messages
+const (
+	// DirectionOut messages were sent to the target
+	DirectionOut = "out"
+	// DirectionIn messages were received from the target
+	DirectionIn = "in"
+)
+
+// Statuses of sessions, replays are running until the traffic of the replay is stored
+const (
+	StatusRunning  = "running"
+	StatusFinished = "finished"
+	StatusFailed   = "failed"
+)
+
+// Session is the recorded traffic of a connection to the target real-time API.
+type Session struct {
+	ID            uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId     uint64    `json:"projectId" gorm:"index;default:1;column:projectId"`
+	Name          string    `json:"name" gorm:"size:255;column:name"`
+	Target        string    `json:"target" gorm:"size:1024;column:target"`
+	Protocol      string    `json:"protocol" gorm:"size:32;column:protocol"`
+	MessagesCount int       `json:"messagesCount" gorm:"column:messagesCount;default:0"`
+	Status        string    `json:"status" gorm:"type:ENUM('running','finished','failed');default:'finished';column:status"`
+	Error         string    `json:"error" gorm:"type:text;column:error"`
+	Messages      []Message `json:"messages" gorm:"foreignKey:sessionId;"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+type SessionList struct {
+	ID            uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId     uint64    `json:"projectId" gorm:"column:projectId"`
+	Name          string    `json:"name" gorm:"size:255;column:name"`
+	Target        string    `json:"target" gorm:"size:1024;column:target"`
+	Protocol      string    `json:"protocol" gorm:"size:32;column:protocol"`
+	MessagesCount int       `json:"messagesCount" gorm:"column:messagesCount"`
+	Status        string    `json:"status" gorm:"column:status"`
+	Error         string    `json:"error" gorm:"column:error"`
+	CreatedAt     time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+// Message is a single frame of the recorded session.
+type Message struct {
+	ID        uint64       `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	SessionId uint64       `json:"sessionId" gorm:"index;column:sessionId"`
+	Direction string       `json:"direction" gorm:"type:ENUM('in','out');column:direction"`
+	Time      time.Time    `json:"time" gorm:"type:DATETIME(6);column:time"`
+	Kind      string       `json:"kind" gorm:"size:32;column:kind"`
+	Event     string       `json:"event" gorm:"size:255;column:event"`
+	AckId     int64        `json:"ackId" gorm:"column:ackId;default:-1"`
+	Data      util.RawJSON `json:"data" gorm:"type:longtext;column:data"`
+	Raw       string       `json:"raw" gorm:"type:longtext;column:raw"`
+}
+
+func (Session) TableName() string {
+	return "recorded_sessions"
+}
+
+func (SessionList) TableName() string {
+	return "recorded_sessions"
+}
+
+// Summary returns the session without the messages.
+func (s *Session) Summary() *SessionList {
+	return &SessionList{
+		ID:            s.ID,
+		ProjectId:     s.ProjectId,
+		Name:          s.Name,
+		Target:        s.Target,
+		Protocol:      s.Protocol,
+		MessagesCount: s.MessagesCount,
+		Status:        s.Status,
+		Error:         s.Error,
+		CreatedAt:     s.CreatedAt,
+		UpdatedAt:     s.UpdatedAt,
+	}
+}
+
+func (Message) TableName() string {
+	return "recorded_messages"
+}
+
+func NewMessage(direction string, f *socket.Frame, raw []byte, t time.Time) *Message {
+	return &Message{
+		Direction: direction,
+		Time:      t,
+		Kind:      f.Kind,
+		Event:     f.Event,
+		AckId:     f.AckId,
+		Data:      util.RawJSON(f.Data),
+		Raw:       string(raw),
+	}
+}
+
+// Frame restores the frame of the message.
+func (m *Message) Frame() *socket.Frame {
+	return &socket.Frame{Kind: m.Kind, Event: m.Event, Data: []byte(m.Data), AckId: m.AckId}
+}
@@ -0,0 +1,47 @@
+package recordings
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxLineSize limits the size of a single NDJSON line
+const maxLineSize = 16 * 1024 * 1024
+
+// WriteNDJSON writes the messages as newline delimited JSON, one message per line.
+func WriteNDJSON(w io.Writer, messages []Message) error {
+	enc := json.NewEncoder(w)
+	for i := range messages {
+		if err := enc.Encode(&messages[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadNDJSON reads messages written by WriteNDJSON. Empty lines are skipped.
+func ReadNDJSON(r io.Reader) ([]Message, error) {
+	messages := make([]Message, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) < 1 {
+			continue
+		}
+		m := Message{AckId: -1}
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err.Error())
+		}
+		if m.Direction != DirectionIn && m.Direction != DirectionOut {
+			return nil, fmt.Errorf("line %d: unknown direction %q", line, m.Direction)
+		}
+		messages = append(messages, m)
+	}
+	return messages, scanner.Err()
+}
@@ -0,0 +1,159 @@
+package recordings
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReplayConfig describes how the recorded session is replayed.
+type ReplayConfig struct {
+	Target string
+	// Protocol of the target, the protocol of the session by default
+	Protocol string
+	Header   http.Header
+	// Scale multiplies the recorded delays between messages, 0 sends the messages without delays
+	Scale float64
+	// Wait is the time to receive the frames of the target after the last message was sent
+	Wait time.Duration
+}
+
+// NewReplay validates the config and returns the running session storing the traffic of the replay.
+func NewReplay(session *Session, c *ReplayConfig) (*Session, error) {
+	codec, err := replayCodec(session, c)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := socket.TargetURL(c.Target, codec); err != nil {
+		return nil, err
+	}
+	return &Session{
+		Name:     fmt.Sprintf("Replay of %s", session.Name),
+		Target:   c.Target,
+		Protocol: codec.Name(),
+		Status:   StatusRunning,
+		Messages: make([]Message, 0),
+	}, nil
+}
+
+// FinishReplay replays the session and stores the traffic and the status of the replay
+// created by NewReplay.
+func FinishReplay(s Store, session, replay *Session, c *ReplayConfig) error {
+	res, err := Replay(session, c)
+	replay.Status = StatusFinished
+	if err != nil {
+		replay.Status = StatusFailed
+		replay.Error = err.Error()
+	}
+	if res != nil {
+		for i := range res.Messages {
+			m := res.Messages[i]
+			m.SessionId = replay.ID
+			if err := s.AddMessage(&m); err != nil {
+				replay.Status = StatusFailed
+				replay.Error = err.Error()
+				break
+			}
+		}
+	}
+	return s.Update(replay)
+}
+
+// Replay sends the outgoing messages of the session to the target keeping the scaled timing
+// and returns the traffic of the replay as a new session.
+func Replay(session *Session, c *ReplayConfig) (*Session, error) {
+	codec, err := replayCodec(session, c)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := socket.Dial(c.Target, codec, c.Header)
+	if err != nil {
+		return nil, err
+	}
+	result := &Session{
+		Name:     fmt.Sprintf("Replay of %s", session.Name),
+		Target:   c.Target,
+		Protocol: codec.Name(),
+		Messages: make([]Message, 0, len(session.Messages)),
+	}
+	mu := &sync.Mutex{}
+	record := func(m *Message) {
+		mu.Lock()
+		result.Messages = append(result.Messages, *m)
+		mu.Unlock()
+	}
+
+	// done is closed when the target stops sending frames
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			f, raw, err := conn.Read()
+			if err != nil {
+				if err == io.EOF || raw == nil {
+					return
+				}
+				continue
+			}
+			record(NewMessage(DirectionIn, f, raw, time.Now()))
+		}
+	}()
+
+	start := time.Now()
+	var sendErr error
+	for i := range session.Messages {
+		m := &session.Messages[i]
+		if m.Direction != DirectionOut {
+			continue
+		}
+		if delay := scaledOffset(session.Messages[0].Time, m.Time, c.Scale) - time.Since(start); delay > 0 {
+			time.Sleep(delay)
+		}
+		f := m.Frame()
+		raw, err := codec.Encode(f)
+		if err != nil {
+			sendErr = err
+			break
+		}
+		if err := conn.Send(f); err != nil {
+			sendErr = err
+			break
+		}
+		record(NewMessage(DirectionOut, f, raw, time.Now()))
+	}
+	if sendErr == nil && c.Wait > 0 {
+		select {
+		case <-done:
+		case <-time.After(c.Wait):
+		}
+	}
+	conn.Close()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	sort.SliceStable(result.Messages, func(i, j int) bool {
+		return result.Messages[i].Time.Before(result.Messages[j].Time)
+	})
+	result.MessagesCount = len(result.Messages)
+	return result, sendErr
+}
+
+func replayCodec(session *Session, c *ReplayConfig) (socket.Codec, error) {
+	protocol := c.Protocol
+	if len(protocol) < 1 {
+		protocol = session.Protocol
+	}
+	return socket.NewCodec(protocol)
+}
+
+func scaledOffset(first, t time.Time, scale float64) time.Duration {
+	if scale <= 0 || t.Before(first) {
+		return 0
+	}
+	return time.Duration(float64(t.Sub(first)) * scale)
+}
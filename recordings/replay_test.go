@@ -0,0 +1,95 @@
+package recordings
+
+import (
+	"bytes"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/util"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	e := router.New()
+	es := store.NewMemory(&store.MemoryConfig{Logger: e.Logger})
+	name, _ := util.NewNullStringFromString("name")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{
+			{Key: name, Type: "string", Required: true},
+		}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient,
+			Fields:          []events.Field{{Key: name, Type: "string", Required: true}},
+			ResponseEventId: util.NewNullInt64FromInt64(1),
+		},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Error while creating event: %s", err.Error())
+		}
+	}
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Error while loading mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+
+	start := time.Now()
+	session := &Session{Name: "Login", Protocol: "socketio", Messages: []Message{
+		{Direction: DirectionOut, Time: start, Kind: "event", Event: "login", Data: util.RawJSON(`{"name":"John"}`), AckId: -1},
+		{Direction: DirectionIn, Time: start.Add(10 * time.Millisecond), Kind: "event", Event: "user", Data: util.RawJSON(`{"name":"John"}`), AckId: -1},
+		{Direction: DirectionOut, Time: start.Add(200 * time.Millisecond), Kind: "event", Event: "login", Data: util.RawJSON(`{"name":"Jane"}`), AckId: -1},
+	}}
+
+	replay, err := Replay(session, &ReplayConfig{Target: target.URL, Scale: 0.5, Wait: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Unexpected replay error: %s", err.Error())
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("Scaled timing was not kept, replay took %s", elapsed)
+	}
+	expected := []string{
+		`out 42["login",{"name":"John"}]`,
+		`in 42["user",{"name":"name"}]`,
+		`out 42["login",{"name":"Jane"}]`,
+		`in 42["user",{"name":"name"}]`,
+	}
+	if len(replay.Messages) != len(expected) || replay.MessagesCount != len(expected) {
+		t.Fatalf("Unexpected replayed messages: %+v", replay.Messages)
+	}
+	for i, m := range replay.Messages {
+		if actual := m.Direction + " " + m.Raw; actual != expected[i] {
+			t.Errorf("[%d] Unexpected message. Want %s, received %s", i, expected[i], actual)
+		}
+	}
+	if replay.Name != "Replay of Login" || replay.Target != target.URL {
+		t.Errorf("Unexpected replay session: %+v", replay)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	messages := []Message{
+		{Direction: DirectionOut, Time: time.Date(2019, 5, 1, 10, 0, 0, 0, time.UTC), Kind: "event", Event: "login", Data: util.RawJSON(`{"name":"John"}`), AckId: 1},
+		{Direction: DirectionIn, Time: time.Date(2019, 5, 1, 10, 0, 1, 0, time.UTC), Kind: "ack", AckId: 1},
+	}
+	b := &bytes.Buffer{}
+	if err := WriteNDJSON(b, messages); err != nil {
+		t.Fatalf("Unexpected error while writing: %s", err.Error())
+	}
+	if lines := strings.Count(b.String(), "\n"); lines != 2 {
+		t.Errorf("Unexpected lines count: %d", lines)
+	}
+	read, err := ReadNDJSON(b)
+	if err != nil {
+		t.Fatalf("Unexpected error while reading: %s", err.Error())
+	}
+	if len(read) != 2 || string(read[0].Data) != `{"name":"John"}` || read[1].Kind != "ack" || !read[1].Time.Equal(messages[1].Time) {
+		t.Errorf("Unexpected messages: %+v", read)
+	}
+	if _, err := ReadNDJSON(strings.NewReader("{\"direction\":\"sideways\"}\n")); err == nil {
+		t.Error("Expected error for unknown direction")
+	}
+}
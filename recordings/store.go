@@ -0,0 +1,14 @@
+package recordings
+
+// Store keeps the recorded sessions of a single project. Sessions of other projects are not visible to it.
+type Store interface {
+	GetById(uint64) (*Session, error)
+	List(offset, limit int) ([]*SessionList, int, error)
+	Create(*Session) error
+	AddMessage(*Message) error
+	// Update stores the status and the error of the session
+	Update(*Session) error
+	Delete(*Session) error
+	// ForProject returns the store of the sessions of the project sharing the storage with this one
+	ForProject(projectId uint64) Store
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/db"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/recordings"
+)
+
+type Gorm struct {
+	db        *gorm.DB
+	logger    logger.Logger
+	projectId uint64
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+	// ProjectId is the project of the sessions, the default project when zero
+	ProjectId uint64
+}
+
+func NewGorm(c *GormConfig) recordings.Store {
+	s := &Gorm{
+		db:        c.DB,
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Gorm) ForProject(projectId uint64) recordings.Store {
+	return NewGorm(&GormConfig{DB: s.db, Logger: s.logger, ProjectId: projectId})
+}
+
+// scoped limits the query to the sessions of the project.
+func (s *Gorm) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("`projectId` = ?", s.projectId)
+}
+
+func (s *Gorm) GetById(id uint64) (*recordings.Session, error) {
+	var session recordings.Session
+	err := s.scoped(s.db).Preload("Messages", func(d *gorm.DB) *gorm.DB {
+		return d.Order("`time` asc, `id` asc")
+	}).First(&session, id).Error
+	if err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *Gorm) List(offset, limit int) ([]*recordings.SessionList, int, error) {
+	sessionsList, total := make([]*recordings.SessionList, 0), 0
+	qb := s.scoped(s.db.Model(&sessionsList))
+	if err := qb.Count(&total).Error; err != nil {
+		return sessionsList, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("createdAt desc").Find(&sessionsList).Error
+	return sessionsList, total, err
+}
+
+func (s *Gorm) Create(session *recordings.Session) error {
+	session.MessagesCount = len(session.Messages)
+	session.ProjectId = s.projectId
+	return s.db.Create(session).Error
+}
+
+func (s *Gorm) AddMessage(message *recordings.Message) error {
+	return db.Transaction(s.db, func(tx *gorm.DB) error {
+		// The counter is updated first, so messages are not added to the sessions of other projects
+		res := s.scoped(tx.Model(&recordings.Session{ID: message.SessionId})).
+			UpdateColumn("messagesCount", gorm.Expr("messagesCount + 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected < 1 {
+			return fmt.Errorf("[recordings.store.gorm] session with id = %d does not exist", message.SessionId)
+		}
+		return tx.Create(message).Error
+	})
+}
+
+func (s *Gorm) Update(session *recordings.Session) error {
+	res := s.scoped(s.db.Model(&recordings.Session{ID: session.ID})).Updates(map[string]interface{}{
+		"status": session.Status,
+		"error":  session.Error,
+	})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[recordings.store.gorm] session with id = %d does not exist", session.ID)
+	}
+	return nil
+}
+
+func (s *Gorm) Delete(session *recordings.Session) error {
+	return db.Transaction(s.db, func(tx *gorm.DB) error {
+		// The session is deleted first, so messages of the sessions of other projects are kept
+		res := s.scoped(tx).Delete(session)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected < 1 {
+			return fmt.Errorf("[recordings.store.gorm] session with id = %d was not deleted", session.ID)
+		}
+		return tx.Where("sessionId = ?", session.ID).Delete(&recordings.Message{}).Error
+	})
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger    logger.Logger
+	projectId uint64
+	// Records of all projects are shared by the stores returned by ForProject
+	*memoryRecords
+}
+
+type memoryRecords struct {
+	records       []*recordings.Session
+	lastId        uint64
+	lastMessageId uint64
+	mu            *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+	// ProjectId is the project of the sessions, the default project when zero
+	ProjectId uint64
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	s := &Memory{
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+		memoryRecords: &memoryRecords{
+			records: make([]*recordings.Session, 0),
+			mu:      &sync.Mutex{},
+		},
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Memory) ForProject(projectId uint64) recordings.Store {
+	return &Memory{
+		logger:        s.logger,
+		projectId:     projectId,
+		memoryRecords: s.memoryRecords,
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*recordings.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id && el.ProjectId == s.projectId {
+			// Copy the messages, they are appended while the session is recorded
+			session := *el
+			session.Messages = append([]recordings.Message{}, el.Messages...)
+			return &session, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int) ([]*recordings.SessionList, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sessionsList := make([]*recordings.SessionList, 0, len(s.records))
+	// Newest first, like the gorm store
+	for i := len(s.records) - 1; i >= 0; i-- {
+		el := s.records[i]
+		if el.ProjectId != s.projectId {
+			continue
+		}
+		sessionsList = append(sessionsList, &recordings.SessionList{
+			ID:            el.ID,
+			ProjectId:     el.ProjectId,
+			Name:          el.Name,
+			Target:        el.Target,
+			Protocol:      el.Protocol,
+			MessagesCount: el.MessagesCount,
+			Status:        el.Status,
+			Error:         el.Error,
+			CreatedAt:     el.CreatedAt,
+			UpdatedAt:     el.UpdatedAt,
+		})
+	}
+	total := len(sessionsList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return sessionsList[offset : offset+l], total, nil
+}
+
+func (s *Memory) Create(session *recordings.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastId++
+	session.ID = s.lastId
+	session.ProjectId = s.projectId
+	for i := range session.Messages {
+		s.lastMessageId++
+		session.Messages[i].ID = s.lastMessageId
+		session.Messages[i].SessionId = session.ID
+	}
+	session.MessagesCount = len(session.Messages)
+	if len(session.Status) < 1 {
+		session.Status = recordings.StatusFinished
+	}
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = session.CreatedAt
+	stored := *session
+	stored.Messages = append([]recordings.Message{}, session.Messages...)
+	s.records = append(s.records, &stored)
+	return nil
+}
+
+func (s *Memory) AddMessage(message *recordings.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == message.SessionId && el.ProjectId == s.projectId {
+			s.lastMessageId++
+			message.ID = s.lastMessageId
+			el.Messages = append(el.Messages, *message)
+			el.MessagesCount++
+			el.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("[recordings.store.memory] session with id = %d does not exist", message.SessionId)
+}
+
+func (s *Memory) Update(session *recordings.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == session.ID && el.ProjectId == s.projectId {
+			el.Status = session.Status
+			el.Error = session.Error
+			el.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("[recordings.store.memory] session with id = %d does not exist", session.ID)
+}
+
+func (s *Memory) Delete(session *recordings.Session) error {
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == session.ID && el.ProjectId == s.projectId {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"github.com/nskondratev/api-page-go-back/testutils"
+	"testing"
+	"time"
+)
+
+func TestMemory_Create(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	cases := []testutils.MemoryCreateTestCase{
+		{ItemToCreate: &recordings.Session{Name: "Login", Target: "ws://localhost:3000"}, TotalRows: 1, LastItemID: 1},
+		{ItemToCreate: &recordings.Session{Name: "Import", Messages: []recordings.Message{
+			{Direction: recordings.DirectionOut, Time: time.Now(), Kind: "event", Event: "login", AckId: -1},
+		}}, TotalRows: 2, LastItemID: 2},
+	}
+
+	for caseNum, item := range cases {
+		sessionToCreate, ok := item.ItemToCreate.(*recordings.Session)
+
+		if !ok {
+			t.Errorf("[%d] Can not convert test case item to create to *recordings.Session type", caseNum)
+		}
+
+		if err := s.Create(sessionToCreate); err != nil {
+			t.Errorf("[%d] error while creating session %+v", caseNum, sessionToCreate)
+		}
+
+		if len(s.records) != item.TotalRows {
+			t.Errorf("[%d] total rows mismatch. Want %d, received %d", caseNum, item.TotalRows, len(s.records))
+		}
+
+		if s.records[len(s.records)-1].ID != item.LastItemID {
+			t.Errorf("[%d] last session id mismatch. Want %d, received %d", caseNum, item.LastItemID, s.records[len(s.records)-1].ID)
+		}
+	}
+
+	if imported := s.records[1]; imported.MessagesCount != 1 || imported.Messages[0].SessionId != 2 {
+		t.Errorf("messages of the created session were not stored: %+v", imported)
+	}
+}
+
+func TestMemory_AddMessage(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	session := &recordings.Session{Name: "Login"}
+	if err := s.Create(session); err != nil {
+		t.Fatalf("Can not create test session: %s", err.Error())
+	}
+
+	for i := 0; i < 3; i++ {
+		m := &recordings.Message{SessionId: session.ID, Direction: recordings.DirectionIn, Time: time.Now(), Kind: "event", Event: "user"}
+		if err := s.AddMessage(m); err != nil {
+			t.Fatalf("Can not add message: %s", err.Error())
+		}
+		if m.ID != uint64(i+1) {
+			t.Errorf("Unexpected message id. Want %d, received %d", i+1, m.ID)
+		}
+	}
+
+	if err := s.AddMessage(&recordings.Message{SessionId: 42}); err == nil {
+		t.Error("Message of unknown session was added")
+	}
+
+	stored, err := s.GetById(session.ID)
+	if err != nil || stored == nil {
+		t.Fatalf("Can not get session: %v", err)
+	}
+	if stored.MessagesCount != 3 || len(stored.Messages) != 3 {
+		t.Errorf("Unexpected messages of the session: %+v", stored)
+	}
+
+	list, total, err := s.List(0, 10)
+	if err != nil || total != 1 || list[0].MessagesCount != 3 {
+		t.Errorf("Unexpected sessions list: %+v, total: %d, error: %v", list, total, err)
+	}
+}
+
+func TestMemory_Delete(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	for _, name := range []string{"First", "Second"} {
+		if err := s.Create(&recordings.Session{Name: name}); err != nil {
+			t.Fatalf("Can not create test session: %s", err.Error())
+		}
+	}
+
+	cases := []testutils.MemoryDeleteTestCase{
+		{ItemToDelete: &recordings.Session{ID: 1}, TotalRows: 1},
+		{ItemToDelete: &recordings.Session{ID: 42}, TotalRows: 1},
+	}
+
+	for caseNum, item := range cases {
+		if err := s.Delete(item.ItemToDelete.(*recordings.Session)); err != nil {
+			t.Errorf("[%d] error while deleting session: %s", caseNum, err.Error())
+		}
+		if len(s.records) != item.TotalRows {
+			t.Errorf("[%d] total rows mismatch. Want %d, received %d", caseNum, item.TotalRows, len(s.records))
+		}
+	}
+}
+
+func TestMemory_Update(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	session := &recordings.Session{Name: "Replay of Login", Status: recordings.StatusRunning}
+	if err := s.Create(session); err != nil {
+		t.Fatalf("Can not create test session: %s", err.Error())
+	}
+
+	session.Status, session.Error = recordings.StatusFailed, "connection refused"
+	if err := s.Update(session); err != nil {
+		t.Fatalf("Can not update session: %s", err.Error())
+	}
+	if err := s.Update(&recordings.Session{ID: 42}); err == nil {
+		t.Error("Session with unknown id was updated")
+	}
+
+	list, _, err := s.List(0, 10)
+	if err != nil || list[0].Status != recordings.StatusFailed || list[0].Error != "connection refused" {
+		t.Errorf("Unexpected sessions list: %+v, error: %v", list, err)
+	}
+}
+
+func TestMemory_ForProject(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+	other := s.ForProject(2)
+
+	session := &recordings.Session{Name: "Login"}
+	if err := s.Create(session); err != nil {
+		t.Fatalf("Can not create test session: %s", err.Error())
+	}
+
+	if list, total, _ := other.List(0, 10); total != 0 {
+		t.Errorf("Sessions of the default project are visible: %+v", list)
+	}
+	if err := other.AddMessage(&recordings.Message{SessionId: session.ID, Event: "login"}); err == nil {
+		t.Error("Message was added to the session of the default project")
+	}
+	if err := other.Delete(session); err != nil {
+		t.Fatalf("Can not delete session: %s", err.Error())
+	}
+	if got, _ := s.GetById(session.ID); got == nil || got.ProjectId != 1 {
+		t.Errorf("Unexpected session of the default project: %+v", got)
+	}
+}
@@ -0,0 +1,64 @@
+package registry
+
+import (
+	"github.com/nskondratev/api-page-go-back/util"
+	"time"
+)
+
+// Kinds of the shared types
+const (
+	KindObject = "object"
+	KindEnum   = "enum"
+)
+
+// Type is a named object type or enum which can be referenced by event fields.
+type Type struct {
+	ID          uint64      `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	Name        string      `json:"name" gorm:"size:255;unique_index;column:name"`
+	Kind        string      `json:"kind" gorm:"type:ENUM('object','enum');default:'object';column:kind"`
+	Description string      `json:"description" gorm:"type:text;column:description"`
+	Fields      []TypeField `json:"fields" gorm:"foreignKey:typeId;"`
+	Values      []EnumValue `json:"values" gorm:"foreignKey:typeId;"`
+	CreatedAt   time.Time   `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+type TypeField struct {
+	ID          uint64          `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	TypeId      uint64          `json:"typeId" gorm:"column:typeId"`
+	Key         util.NullString `json:"key" gorm:"size:255;column:key"`
+	Type        string          `json:"type" gorm:"size:255;column:type"`
+	Required    bool            `json:"required" gorm:"type:TINYINT(1);default:0;column:required"`
+	Description string          `json:"description" gorm:"type:text;column:description"`
+}
+
+type EnumValue struct {
+	ID          uint64 `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	TypeId      uint64 `json:"typeId" gorm:"column:typeId"`
+	Value       string `json:"value" gorm:"size:255;column:value"`
+	Description string `json:"description" gorm:"type:text;column:description"`
+}
+
+type TypeList struct {
+	ID        uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	Name      string    `json:"name" gorm:"size:255;column:name"`
+	Kind      string    `json:"kind" gorm:"column:kind"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+func (Type) TableName() string {
+	return "types"
+}
+
+func (TypeField) TableName() string {
+	return "type_fields"
+}
+
+func (EnumValue) TableName() string {
+	return "type_enum_values"
+}
+
+func (TypeList) TableName() string {
+	return "types"
+}
@@ -0,0 +1,10 @@
+package registry
+
+type Store interface {
+	GetById(uint64) (*Type, error)
+	GetByName(string) (*Type, error)
+	List(offset, limit int, kind string, query string) ([]*TypeList, int, error)
+	Create(*Type) error
+	Update(*Type) error
+	Delete(*Type) error
+}
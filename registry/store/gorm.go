@@ -0,0 +1,115 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/db"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/registry"
+)
+
+type Gorm struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+}
+
+func NewGorm(c *GormConfig) registry.Store {
+	return &Gorm{
+		db:     c.DB,
+		logger: c.Logger,
+	}
+}
+
+func (s *Gorm) GetById(id uint64) (*registry.Type, error) {
+	var t registry.Type
+	if err := s.db.Preload("Fields").Preload("Values").First(&t, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *Gorm) GetByName(name string) (*registry.Type, error) {
+	var t registry.Type
+	if err := s.db.Preload("Fields").Preload("Values").Where("`name` = ?", name).First(&t).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (s *Gorm) List(offset, limit int, kind string, query string) ([]*registry.TypeList, int, error) {
+	typesList, total := make([]*registry.TypeList, 0), 0
+	qb := s.db.Model(&typesList)
+	if len(query) > 0 {
+		qb = qb.Where("`name` LIKE ?", "%"+query+"%")
+	}
+	if len(kind) > 0 {
+		qb = qb.Where("`kind` = ?", kind)
+	}
+	if err := qb.Count(&total).Error; err != nil {
+		return typesList, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("name asc").Find(&typesList).Error
+	return typesList, total, err
+}
+
+func (s *Gorm) Create(t *registry.Type) error {
+	return s.db.Create(t).Error
+}
+
+func (s *Gorm) Update(t *registry.Type) error {
+	res := s.db.First(&registry.Type{}, t.ID)
+
+	if res.Error != nil {
+		if gorm.IsRecordNotFoundError(res.Error) {
+			return fmt.Errorf("[registry.store.gorm] type with id = %d does not exist", t.ID)
+		}
+		return res.Error
+	}
+
+	return db.Transaction(s.db, func(tx *gorm.DB) error {
+		if err := tx.Delete(&registry.TypeField{}, "typeId = ?", t.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&registry.EnumValue{}, "typeId = ?", t.ID).Error; err != nil {
+			return err
+		}
+		res := tx.Save(t)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected < 1 {
+			return fmt.Errorf("[registry.store.gorm] type with id = %d was not updated", t.ID)
+		}
+		return nil
+	})
+}
+
+func (s *Gorm) Delete(t *registry.Type) error {
+	return db.Transaction(s.db, func(tx *gorm.DB) error {
+		if err := tx.Delete(&registry.TypeField{}, "typeId = ?", t.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&registry.EnumValue{}, "typeId = ?", t.ID).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(t)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected < 1 {
+			return fmt.Errorf("[registry.store.gorm] type with id = %d was not deleted", t.ID)
+		}
+		return nil
+	})
+}
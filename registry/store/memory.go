@@ -0,0 +1,120 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger  logger.Logger
+	records []*registry.Type
+	lastId  uint64
+	mu      *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	return &Memory{
+		logger:  c.Logger,
+		records: make([]*registry.Type, 0),
+		mu:      &sync.Mutex{},
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*registry.Type, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) GetByName(name string) (*registry.Type, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.Name == name {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int, kind string, query string) ([]*registry.TypeList, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	typesList := make([]*registry.TypeList, 0)
+	q := strings.ToLower(query)
+	for _, el := range s.records {
+		if (len(q) < 1 || strings.Contains(strings.ToLower(el.Name), q)) && (len(kind) < 1 || kind == el.Kind) {
+			typesList = append(typesList, &registry.TypeList{
+				ID:        el.ID,
+				Name:      el.Name,
+				Kind:      el.Kind,
+				CreatedAt: el.CreatedAt,
+				UpdatedAt: el.UpdatedAt,
+			})
+		}
+	}
+	sort.Slice(typesList, func(i, j int) bool {
+		return typesList[i].Name < typesList[j].Name
+	})
+	total := len(typesList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return typesList[offset : offset+l], total, nil
+}
+
+func (s *Memory) Create(t *registry.Type) error {
+	s.mu.Lock()
+	s.lastId++
+	t.ID = s.lastId
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = time.Now()
+	s.records = append(s.records, t)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Update(t *registry.Type) error {
+	t.UpdatedAt = time.Now()
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == t.ID {
+			t.CreatedAt = el.CreatedAt
+			s.records[i] = t
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Delete(t *registry.Type) error {
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == t.ID {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
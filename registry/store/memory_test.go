@@ -0,0 +1,80 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/registry"
+	"testing"
+)
+
+func TestMemory_List(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	typesToCreate := []*registry.Type{
+		{Name: "User", Kind: registry.KindObject},
+		{Name: "Status", Kind: registry.KindEnum},
+		{Name: "UserRole", Kind: registry.KindEnum},
+	}
+
+	for _, typeToCreate := range typesToCreate {
+		if err := s.Create(typeToCreate); err != nil {
+			t.Fatalf("Can not create test type: %s", err.Error())
+		}
+	}
+
+	cases := []struct {
+		offset, limit int
+		kind, query   string
+		expectedNames []string
+		expectedTotal int
+	}{
+		{0, 10, "", "", []string{"Status", "User", "UserRole"}, 3},
+		{0, 10, registry.KindEnum, "", []string{"Status", "UserRole"}, 2},
+		{0, 10, "", "user", []string{"User", "UserRole"}, 2},
+		{1, 1, "", "", []string{"User"}, 3},
+		{5, -1, "", "", []string{}, 3},
+	}
+
+	for caseNum, item := range cases {
+		list, total, err := s.List(item.offset, item.limit, item.kind, item.query)
+		if err != nil {
+			t.Errorf("[%d] error while fetching list: %s", caseNum, err.Error())
+		}
+
+		if total != item.expectedTotal {
+			t.Errorf("[%d] total mismatch. want: %d, received: %d", caseNum, item.expectedTotal, total)
+		}
+
+		if len(list) != len(item.expectedNames) {
+			t.Fatalf("[%d] list length mismatch. want: %d, received: %d", caseNum, len(item.expectedNames), len(list))
+		}
+
+		for i, name := range item.expectedNames {
+			if list[i].Name != name {
+				t.Errorf("[%d] list mismatch at %d. want: %s, received: %s", caseNum, i, name, list[i].Name)
+			}
+		}
+	}
+}
+
+func TestMemory_Update(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	original := &registry.Type{Name: "Status", Kind: registry.KindEnum}
+
+	if err := s.Create(original); err != nil {
+		t.Fatalf("Can not create test type: %s", err.Error())
+	}
+
+	if err := s.Update(&registry.Type{ID: 1, Name: "UserStatus", Kind: registry.KindEnum}); err != nil {
+		t.Fatalf("Can not update test type: %s", err.Error())
+	}
+
+	updated, err := s.GetByName("UserStatus")
+
+	if err != nil || updated == nil {
+		t.Fatalf("Updated type was not found: %v", err)
+	}
+
+	if !updated.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("createdAt should be kept. want: %s, received: %s", original.CreatedAt, updated.CreatedAt)
+	}
+}
@@ -0,0 +1,78 @@
+package relay
+
+import (
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"sync"
+)
+
+// Directions of frames, the same as of recorded messages
+const (
+	DirectionOut = recordings.DirectionOut
+	DirectionIn  = recordings.DirectionIn
+)
+
+// Catalog matches frames of a session with the events catalog.
+// Acks are matched with the event of the emit which requested them.
+type Catalog struct {
+	byValue map[string]*events.Event
+	types   events.Types
+	mu      *sync.Mutex
+	// Events waiting for the ack by direction of the ack and ack id
+	acks map[string]map[int64]*events.Event
+}
+
+func NewCatalog(list []*events.Event, types events.Types) *Catalog {
+	byValue := make(map[string]*events.Event, len(list))
+	for _, e := range list {
+		byValue[e.Value] = e
+	}
+	return &Catalog{
+		byValue: byValue,
+		types:   types,
+		mu:      &sync.Mutex{},
+		acks: map[string]map[int64]*events.Event{
+			DirectionIn:  make(map[int64]*events.Event),
+			DirectionOut: make(map[int64]*events.Event),
+		},
+	}
+}
+
+func (c *Catalog) Event(value string) *events.Event {
+	return c.byValue[value]
+}
+
+// Annotate returns the catalog event of the frame and the validation errors of its payload.
+func (c *Catalog) Annotate(direction string, f *socket.Frame) (*events.Event, []*events.ValidationError) {
+	switch f.Kind {
+	case socket.FrameEvent:
+		e, ok := c.byValue[f.Event]
+		if !ok {
+			return nil, []*events.ValidationError{{Message: "event is not described in the catalog"}}
+		}
+		if f.HasAck() {
+			c.mu.Lock()
+			c.acks[opposite(direction)][f.AckId] = e
+			c.mu.Unlock()
+		}
+		return e, events.ValidateRawPayload(e.Fields, c.types, f.Data)
+	case socket.FrameAck:
+		c.mu.Lock()
+		e, ok := c.acks[direction][f.AckId]
+		delete(c.acks[direction], f.AckId)
+		c.mu.Unlock()
+		if !ok {
+			return nil, []*events.ValidationError{{Message: "ack does not answer any emit"}}
+		}
+		return e, events.ValidateRawPayload(e.AckFields, c.types, f.Data)
+	}
+	return nil, nil
+}
+
+func opposite(direction string) string {
+	if direction == DirectionIn {
+		return DirectionOut
+	}
+	return DirectionIn
+}
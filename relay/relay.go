@@ -0,0 +1,261 @@
+package relay
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/recordings"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Types of messages sent to the browser
+const (
+	MessageConnected = "connected"
+	MessageFrame     = "frame"
+	MessageError     = "error"
+	MessageClosed    = "closed"
+)
+
+const (
+	// Time allowed to write a message to the browser.
+	writeWait = 10 * time.Second
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin: func(r *http.Request) bool {
+		return true
+	},
+}
+
+// Message is sent to the browser for every frame of the session.
+type Message struct {
+	Type      string                    `json:"type"`
+	Direction string                    `json:"direction,omitempty"`
+	Time      time.Time                 `json:"time"`
+	Frame     *socket.Frame             `json:"frame,omitempty"`
+	Raw       string                    `json:"raw,omitempty"`
+	Event     *events.Event             `json:"event,omitempty"`
+	Errors    []*events.ValidationError `json:"errors,omitempty"`
+	Error     string                    `json:"error,omitempty"`
+	SessionId uint64                    `json:"sessionId,omitempty"`
+}
+
+// Relay connects browser sessions to the target real-time API.
+//
+// The browser names the target with the "target" query parameter, the codec of the target with
+// "protocol" (socketio by default) and the headers of the handshake with "header" parameters in
+// the "Name: value" form. When the "record" parameter is set, the traffic is recorded as a session
+// with the given name and the id of the session is reported in the connected message. The browser sends frames in the JSON codec form, every frame sent to or
+// received from the target is reported back to the browser annotated with the catalog event.
+// The catalog and the recordings are those of the project of the request context.
+type Relay struct {
+	eventStore     events.Store
+	typeStore      registry.Store
+	recordingStore recordings.Store
+	logger         logger.Logger
+	allowedHosts   socket.AllowedHosts
+}
+
+type Config struct {
+	EventStore events.Store
+	// TypeStore resolves the shared types of the fields, their values are not checked without it
+	TypeStore      registry.Store
+	RecordingStore recordings.Store
+	Logger         logger.Logger
+	// AllowedHosts restricts the targets of the relay, every target is denied when empty
+	AllowedHosts socket.AllowedHosts
+}
+
+// session is a relayed connection of the browser.
+type session struct {
+	relay   *Relay
+	browser *websocket.Conn
+	target  *socket.Conn
+	catalog *Catalog
+	// recording is the session the traffic is recorded to, nil when the traffic is not recorded
+	recording *recordings.Session
+	// recordings is the store of the recordings of the project of the session
+	recordings recordings.Store
+	mu         *sync.Mutex
+	once       *sync.Once
+}
+
+func New(c *Config) *Relay {
+	return &Relay{
+		eventStore:     c.EventStore,
+		typeStore:      c.TypeStore,
+		recordingStore: c.RecordingStore,
+		logger:         c.Logger,
+		allowedHosts:   c.AllowedHosts,
+	}
+}
+
+func (rl *Relay) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	codec, err := socket.NewCodec(q.Get("protocol"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	target := q.Get("target")
+	if err := rl.allowedHosts.Check(target); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	header, err := parseHeaders(q["header"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	projectId := projects.IdFromContext(r.Context())
+	list, err := rl.eventStore.ForProject(projectId).GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	types, err := events.ResolveEventTypes(rl.typeStore, list...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	browser, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		rl.logger.Warnf("Error while upgrading relay connection: %s", err.Error())
+		return
+	}
+	s := &session{relay: rl, browser: browser, catalog: NewCatalog(list, types), mu: &sync.Mutex{}, once: &sync.Once{}}
+
+	conn, err := socket.Dial(target, codec, header)
+	if err != nil {
+		s.send(&Message{Type: MessageError, Error: "can not connect to the target: " + err.Error()})
+		browser.Close()
+		return
+	}
+	s.target = conn
+	if q.Get("record") != "" && rl.recordingStore != nil {
+		s.recording = &recordings.Session{Name: q.Get("record"), Target: target, Protocol: codec.Name()}
+		s.recordings = rl.recordingStore.ForProject(projectId)
+		if err := s.recordings.Create(s.recording); err != nil {
+			s.send(&Message{Type: MessageError, Error: "can not record the session: " + err.Error()})
+			s.recording = nil
+		}
+	}
+	connected := &Message{Type: MessageConnected}
+	if s.recording != nil {
+		connected.SessionId = s.recording.ID
+	}
+	s.send(connected)
+	go s.targetPump()
+	go s.browserPump()
+}
+
+func parseHeaders(values []string) (http.Header, error) {
+	header := http.Header{}
+	for _, v := range values {
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 || len(strings.TrimSpace(parts[0])) < 1 {
+			return nil, fmt.Errorf("invalid header: %s", v)
+		}
+		header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+	return header, nil
+}
+
+// targetPump reports the frames received from the target to the browser.
+func (s *session) targetPump() {
+	defer s.close()
+	for {
+		f, raw, err := s.target.Read()
+		if err != nil {
+			if err == io.EOF || raw == nil {
+				return
+			}
+			s.send(&Message{Type: MessageError, Direction: DirectionIn, Raw: string(raw), Error: err.Error()})
+			continue
+		}
+		s.report(DirectionIn, f, raw)
+	}
+}
+
+// browserPump sends the frames of the browser to the target.
+func (s *session) browserPump() {
+	defer s.close()
+	codec := &socket.JSONCodec{}
+	for {
+		_, msg, err := s.browser.ReadMessage()
+		if err != nil {
+			return
+		}
+		f, err := codec.Decode(msg)
+		if err != nil {
+			s.send(&Message{Type: MessageError, Direction: DirectionOut, Raw: string(msg), Error: err.Error()})
+			continue
+		}
+		raw, err := s.target.Codec().Encode(f)
+		if err != nil {
+			s.send(&Message{Type: MessageError, Direction: DirectionOut, Raw: string(msg), Error: err.Error()})
+			continue
+		}
+		s.report(DirectionOut, f, raw)
+		if err := s.target.Send(f); err != nil {
+			s.send(&Message{Type: MessageError, Direction: DirectionOut, Error: err.Error()})
+			return
+		}
+	}
+}
+
+func (s *session) report(direction string, f *socket.Frame, raw []byte) {
+	e, errs := s.catalog.Annotate(direction, f)
+	m := &Message{
+		Type:      MessageFrame,
+		Direction: direction,
+		Time:      time.Now(),
+		Frame:     f,
+		Raw:       string(raw),
+		Event:     e,
+		Errors:    errs,
+	}
+	if s.recording != nil {
+		rm := recordings.NewMessage(direction, f, raw, m.Time)
+		rm.SessionId = s.recording.ID
+		if err := s.recordings.AddMessage(rm); err != nil {
+			s.relay.logger.Warnf("Error while recording message of session %d: %s", s.recording.ID, err.Error())
+		}
+	}
+	s.send(m)
+}
+
+func (s *session) send(m *Message) {
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		s.relay.logger.Warnf("Error while encoding relay message: %s", err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.browser.SetWriteDeadline(time.Now().Add(writeWait))
+	s.browser.WriteMessage(websocket.TextMessage, data)
+}
+
+// close is called by both pumps, the first call reports the end of the session.
+func (s *session) close() {
+	s.once.Do(func() {
+		s.send(&Message{Type: MessageClosed})
+		s.target.Close()
+		s.browser.Close()
+	})
+}
@@ -0,0 +1,118 @@
+package relay
+
+import (
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	recordingStore "github.com/nskondratev/api-page-go-back/recordings/store"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"github.com/nskondratev/api-page-go-back/util"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRelay_ForwardsAndAnnotatesFrames(t *testing.T) {
+	e := router.New()
+	es := store.NewMemory(&store.MemoryConfig{Logger: e.Logger})
+	name, _ := util.NewNullStringFromString("name")
+	ok, _ := util.NewNullStringFromString("ok")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{
+			{Key: name, Type: "string", Required: true},
+		}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient,
+			Fields:          []events.Field{{Key: name, Type: "string", Required: true}},
+			AckFields:       []events.Field{{Key: ok, Type: "boolean", Required: true}},
+			ResponseEventId: util.NewNullInt64FromInt64(1),
+		},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Error while creating event: %s", err.Error())
+		}
+	}
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Error while loading mock catalog: %s", err.Error())
+	}
+	target := httptest.NewServer(ms)
+	defer target.Close()
+	recordings := recordingStore.NewMemory(&recordingStore.MemoryConfig{Logger: e.Logger})
+	rs := httptest.NewServer(New(&Config{EventStore: es, RecordingStore: recordings, Logger: e.Logger, AllowedHosts: socket.AllowedHosts{socket.AnyHost}}))
+	defer rs.Close()
+
+	u := "ws" + strings.TrimPrefix(rs.URL, "http") + "/?record=Login&target=" + url.QueryEscape(target.URL)
+	conn, _, err := websocket.DefaultDialer.Dial(u, nil)
+	if err != nil {
+		t.Fatalf("Error while connecting to relay: %s", err.Error())
+	}
+	defer conn.Close()
+
+	expectMessage(t, conn, MessageConnected, "", "", "", 0)
+	send(t, conn, `{"event":"login","data":{"name":"John"},"ackId":1}`)
+	expectMessage(t, conn, MessageFrame, DirectionOut, `421["login",{"name":"John"}]`, "login", 0)
+	expectMessage(t, conn, MessageFrame, DirectionIn, `431[{"ok":true}]`, "login", 0)
+	expectMessage(t, conn, MessageFrame, DirectionIn, `42["user",{"name":"name"}]`, "user", 0)
+
+	send(t, conn, `{"event":"login","data":{}}`)
+	expectMessage(t, conn, MessageFrame, DirectionOut, `42["login",{}]`, "login", 1)
+	expectMessage(t, conn, MessageFrame, DirectionIn, `42["mock_error"`, "", 1)
+
+	send(t, conn, `{"data":{}}`)
+	expectMessage(t, conn, MessageError, DirectionOut, `{"data":{}}`, "", 0)
+
+	session, err := recordings.GetById(1)
+	if err != nil || session == nil {
+		t.Fatalf("Recorded session was not found: %v", err)
+	}
+	if session.Name != "Login" || session.Target != target.URL || session.MessagesCount != 5 || session.Messages[1].Raw != `431[{"ok":true}]` {
+		t.Errorf("Unexpected recorded session: %+v", session)
+	}
+}
+
+func TestRelay_DeniesTargetsByDefault(t *testing.T) {
+	e := router.New()
+	es := store.NewMemory(&store.MemoryConfig{Logger: e.Logger})
+	rs := httptest.NewServer(New(&Config{EventStore: es, Logger: e.Logger}))
+	defer rs.Close()
+
+	u := "ws" + strings.TrimPrefix(rs.URL, "http") + "/?target=" + url.QueryEscape("ws://localhost:3000")
+	_, res, err := websocket.DefaultDialer.Dial(u, nil)
+	if err == nil {
+		t.Fatalf("Relay connected to the target which is not allowed")
+	}
+	if res == nil || res.StatusCode != http.StatusForbidden {
+		t.Errorf("Unexpected response of the relay: %+v", res)
+	}
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg string) {
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		t.Fatalf("Error while writing message: %s", err.Error())
+	}
+}
+
+func expectMessage(t *testing.T, conn *websocket.Conn, typ, direction, raw, event string, errs int) {
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("Error while reading message: %s", err.Error())
+	}
+	m := &Message{}
+	if err := json.Unmarshal(data, m); err != nil {
+		t.Fatalf("Error while decoding message %s: %s", data, err.Error())
+	}
+	actualEvent := ""
+	if m.Event != nil {
+		actualEvent = m.Event.Value
+	}
+	if m.Type != typ || m.Direction != direction || !strings.HasPrefix(m.Raw, raw) || actualEvent != event || len(m.Errors) != errs {
+		t.Errorf("Unexpected message: %s", data)
+	}
+}
@@ -0,0 +1,102 @@
+package scanner
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+)
+
+// Position of the event name in the arguments of socket.io server and client methods
+var goEmitArgs = map[string]int{
+	"Emit":                 0,
+	"BroadcastToRoom":      2,
+	"BroadcastToNamespace": 1,
+}
+
+var goOnArgs = map[string]int{
+	"On":      0,
+	"Once":    0,
+	"OnEvent": 1,
+}
+
+// Names of message struct fields holding the event name in gorilla/websocket protocols
+var goEventFields = map[string]bool{
+	"Event":      true,
+	"EventConst": true,
+}
+
+// scanGo finds calls of socket.io libraries. Messages of gorilla/websocket protocols are found by
+// their event field: composite literals setting it are emits, switches over it are subscriptions.
+func scanGo(file string, src []byte, c consts) ([]*Call, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	calls := make([]*Call, 0)
+	add := func(kind string, expr ast.Expr) {
+		call := &Call{Kind: kind, File: file, Line: fset.Position(expr.Pos()).Line}
+		switch x := expr.(type) {
+		case *ast.BasicLit:
+			if x.Kind != token.STRING {
+				return
+			}
+			s, err := strconv.Unquote(x.Value)
+			if err != nil {
+				return
+			}
+			call.Event = s
+		case *ast.Ident:
+			call.Event, call.Identifier = x.Name, true
+		case *ast.SelectorExpr:
+			call.Event, call.Identifier = x.Sel.Name, true
+		default:
+			return
+		}
+		calls = append(calls, call)
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.ValueSpec:
+			for i, name := range x.Names {
+				if i < len(x.Values) {
+					if lit, ok := x.Values[i].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+						if s, err := strconv.Unquote(lit.Value); err == nil {
+							c.add(name.Name, s)
+						}
+					}
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := x.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			if i, ok := goEmitArgs[sel.Sel.Name]; ok && i < len(x.Args) {
+				add(KindEmit, x.Args[i])
+			}
+			if i, ok := goOnArgs[sel.Sel.Name]; ok && i < len(x.Args) {
+				add(KindOn, x.Args[i])
+			}
+		case *ast.KeyValueExpr:
+			if key, ok := x.Key.(*ast.Ident); ok && goEventFields[key.Name] {
+				add(KindEmit, x.Value)
+			}
+		case *ast.SwitchStmt:
+			sel, ok := x.Tag.(*ast.SelectorExpr)
+			if !ok || !goEventFields[sel.Sel.Name] {
+				return true
+			}
+			for _, stmt := range x.Body.List {
+				if cc, ok := stmt.(*ast.CaseClause); ok {
+					for _, expr := range cc.List {
+						add(KindOn, expr)
+					}
+				}
+			}
+		}
+		return true
+	})
+	return calls, nil
+}
@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// Calls of emit, on and once with a string literal, a template literal without substitutions or an identifier
+var jsCallRegexp = regexp.MustCompile(`\.(emit|on|once)\s*\(\s*(?:'((?:\\.|[^'\\\n])*)'|"((?:\\.|[^"\\\n])*)"|` + "`([^`$]*)`" + `|([A-Za-z_$][\w$]*(?:\.[A-Za-z_$][\w$]*)*)\s*[,)])`)
+
+// Constants and object keys with string values: const LOGIN = 'login', LOGIN: "login"
+var jsConstRegexp = regexp.MustCompile(`([A-Za-z_$][\w$]*)\s*[:=]\s*['"]([^'"\n]*)['"]`)
+
+func scanJS(file string, src []byte, c consts) []*Call {
+	calls := make([]*Call, 0)
+	l := &jsLexer{src: src}
+	for _, m := range jsCallRegexp.FindAllSubmatchIndex(src, -1) {
+		if l.inComment(m[0]) {
+			continue
+		}
+		call := &Call{Kind: KindOn, File: file, Line: lineOf(src, m[0])}
+		if string(src[m[2]:m[3]]) == "emit" {
+			call.Kind = KindEmit
+		}
+		switch {
+		case m[4] >= 0:
+			call.Event = string(src[m[4]:m[5]])
+		case m[6] >= 0:
+			call.Event = string(src[m[6]:m[7]])
+		case m[8] >= 0:
+			call.Event = string(src[m[8]:m[9]])
+		default:
+			call.Event = lastSegment(string(src[m[10]:m[11]]))
+			call.Identifier = true
+		}
+		calls = append(calls, call)
+	}
+	for _, m := range jsConstRegexp.FindAllSubmatch(src, -1) {
+		c.add(string(m[1]), string(m[2]))
+	}
+	return calls
+}
+
+func lineOf(src []byte, offset int) int {
+	return bytes.Count(src[:offset], []byte("\n")) + 1
+}
+
+// jsLexer tracks string literals, template literals and comments of the source up to an offset.
+type jsLexer struct {
+	src []byte
+	pos int
+	// quote of the open string or template literal
+	quote byte
+	line  bool
+	block bool
+}
+
+// inComment reports whether the offset is inside a line or a block comment. Offsets must not decrease between calls.
+func (l *jsLexer) inComment(offset int) bool {
+	for ; l.pos < offset; l.pos++ {
+		ch := l.src[l.pos]
+		switch {
+		case l.line:
+			l.line = ch != '\n'
+		case l.block:
+			if ch == '*' && l.next() == '/' {
+				l.block = false
+				l.pos++
+			}
+		case l.quote != 0:
+			if ch == '\\' {
+				l.pos++
+			} else if ch == l.quote || ch == '\n' && l.quote != '`' {
+				l.quote = 0
+			}
+		case ch == '\'' || ch == '"' || ch == '`':
+			l.quote = ch
+		case ch == '/' && l.next() == '/':
+			l.line = true
+			l.pos++
+		case ch == '/' && l.next() == '*':
+			l.block = true
+			l.pos++
+		}
+	}
+	return l.line || l.block
+}
+
+func (l *jsLexer) next() byte {
+	if l.pos+1 < len(l.src) {
+		return l.src[l.pos+1]
+	}
+	return 0
+}
@@ -0,0 +1,101 @@
+package scanner
+
+import (
+	"github.com/nskondratev/api-page-go-back/events"
+	"sort"
+)
+
+// Sides of the scanned code
+const (
+	SideClient = "client"
+	SideServer = "server"
+)
+
+// ReservedEvents are emitted by Socket.io and Node.js streams themselves and are never reported as undocumented.
+var ReservedEvents = []string{
+	"connect", "connection", "connect_error", "connect_timeout", "disconnect", "disconnecting", "error",
+	"reconnect", "reconnect_attempt", "reconnecting", "reconnect_error", "reconnect_failed",
+	"ping", "pong", "newListener", "removeListener", "data", "end", "close", "finish", "exit",
+}
+
+// Mismatch is a call which emits an event the side is expected to receive or the other way around.
+type Mismatch struct {
+	*Call
+	Type string `json:"type"`
+}
+
+// Report compares the calls found in the sources with the events catalog.
+type Report struct {
+	Files int `json:"files"`
+	Calls int `json:"calls"`
+	// Undocumented lists calls with events missing from the catalog
+	Undocumented []*Call `json:"undocumented"`
+	// Unused lists values of catalog events which are never emitted or received
+	Unused []string `json:"unused"`
+	// Mismatched lists calls which do not match the type of the event for the side
+	Mismatched []*Mismatch `json:"mismatched"`
+	Errors     []string    `json:"errors,omitempty"`
+}
+
+// Check matches event names of the calls with values and constants of the catalog events.
+// Client code is expected to emit client events and receive frontend ones, server code the other way
+// around. The types are not checked when the side is empty.
+func Check(res *Result, catalog []*events.Event, side string, ignore []string) *Report {
+	byValue := make(map[string]*events.Event, len(catalog))
+	byConstant := make(map[string]*events.Event, len(catalog))
+	for _, e := range catalog {
+		byValue[e.Value] = e
+		byConstant[e.Constant] = e
+	}
+	ignored := make(map[string]bool, len(ReservedEvents)+len(ignore))
+	for _, list := range [][]string{ReservedEvents, ignore} {
+		for _, name := range list {
+			ignored[name] = true
+		}
+	}
+	r := &Report{
+		Files:        res.Files,
+		Calls:        len(res.Calls),
+		Undocumented: make([]*Call, 0),
+		Unused:       make([]string, 0),
+		Mismatched:   make([]*Mismatch, 0),
+		Errors:       res.Errors,
+	}
+	used := make(map[string]bool)
+	for _, call := range res.Calls {
+		e, ok := byValue[call.Event]
+		if !ok {
+			e, ok = byConstant[call.Event]
+		}
+		if !ok {
+			if !ignored[call.Event] {
+				r.Undocumented = append(r.Undocumented, call)
+			}
+			continue
+		}
+		used[e.Value] = true
+		if expected := expectedKind(side, e.Type); len(expected) > 0 && call.Kind != expected {
+			r.Mismatched = append(r.Mismatched, &Mismatch{Call: call, Type: e.Type})
+		}
+	}
+	for _, e := range catalog {
+		if !used[e.Value] {
+			r.Unused = append(r.Unused, e.Value)
+		}
+	}
+	sort.Strings(r.Unused)
+	return r
+}
+
+// expectedKind returns the kind of calls the side makes with events of the type.
+func expectedKind(side, eventType string) string {
+	emitted := side == SideClient && eventType == events.TypeClient || side == SideServer && eventType == events.TypeFrontend
+	received := side == SideClient && eventType == events.TypeFrontend || side == SideServer && eventType == events.TypeClient
+	switch {
+	case emitted:
+		return KindEmit
+	case received:
+		return KindOn
+	}
+	return ""
+}
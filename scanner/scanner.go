@@ -0,0 +1,143 @@
+package scanner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kinds of socket calls
+const (
+	KindEmit = "emit"
+	KindOn   = "on"
+)
+
+// Call is an emit or a subscription found in the sources. Event holds the string literal or the
+// value of the constant passed as the event name. When the name is passed with an identifier
+// whose value is unknown, Event holds the name of the identifier and Identifier is set.
+type Call struct {
+	Kind       string `json:"kind"`
+	Event      string `json:"event"`
+	Identifier bool   `json:"identifier,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+}
+
+// Result holds the calls found in a source tree.
+type Result struct {
+	Files  int      `json:"files"`
+	Calls  []*Call  `json:"calls"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Directories which never contain the sources of the service
+var skippedDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+}
+
+var jsExtensions = map[string]bool{
+	".js":  true,
+	".jsx": true,
+	".mjs": true,
+	".ts":  true,
+	".tsx": true,
+	".vue": true,
+}
+
+// consts maps names of string constants to their values. Names declared with different values are ambiguous.
+type consts map[string]*string
+
+func (c consts) add(name, value string) {
+	if old, ok := c[name]; ok {
+		if old != nil && *old != value {
+			c[name] = nil
+		}
+		return
+	}
+	c[name] = &value
+}
+
+// resolve replaces identifiers of the calls with the values of the constants they name.
+func (c consts) resolve(calls []*Call) {
+	for _, call := range calls {
+		if !call.Identifier {
+			continue
+		}
+		if v, ok := c[call.Event]; ok && v != nil {
+			call.Event = *v
+			call.Identifier = false
+		}
+	}
+}
+
+// Scan finds socket calls in the JavaScript, TypeScript and Go files of the directory.
+// File paths of the calls are relative to the directory.
+func Scan(dir string) (*Result, error) {
+	res := &Result{Calls: make([]*Call, 0)}
+	c := make(consts)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && skippedDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".go" && !jsExtensions[ext] {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			rel = path
+		}
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		res.Files++
+		var calls []*Call
+		if ext == ".go" {
+			calls, err = scanGo(rel, src, c)
+			if err != nil {
+				res.Errors = append(res.Errors, err.Error())
+				return nil
+			}
+		} else {
+			calls = scanJS(rel, src, c)
+		}
+		res.Calls = append(res.Calls, calls...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	c.resolve(res.Calls)
+	sortCalls(res.Calls)
+	return res, nil
+}
+
+func sortCalls(calls []*Call) {
+	sort.SliceStable(calls, func(i, j int) bool {
+		if calls[i].File != calls[j].File {
+			return calls[i].File < calls[j].File
+		}
+		return calls[i].Line < calls[j].Line
+	})
+}
+
+// lastSegment returns the name of the identifier without the package or the object, e.g. LOGIN for EVENTS.LOGIN.
+func lastSegment(name string) string {
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		return name[idx+1:]
+	}
+	return name
+}
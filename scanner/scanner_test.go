@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/events"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	res, err := Scan("testdata/src")
+	if err != nil {
+		t.Fatalf("Can not scan sources: %s", err.Error())
+	}
+
+	want := []string{
+		"server/main.go:11 on login",
+		"server/main.go:12 emit user",
+		"server/main.go:14 emit chat_message",
+		"server/main.go:15 emit notice",
+		"server/main.go:18 on typing",
+		"server/main.go:18 on user",
+		"web/app.ts:3 on connect",
+		"web/app.ts:4 emit login",
+		"web/app.ts:6 on user",
+		"web/app.ts:7 on typing",
+		"web/app.ts:9 emit logout",
+		"web/app.ts:11 emit user",
+		"web/app.ts:12 emit ping",
+		"web/app.ts:13 on pong",
+	}
+	got := make([]string, 0, len(res.Calls))
+	for _, c := range res.Calls {
+		got = append(got, fmt.Sprintf("%s:%d %s %s", c.File, c.Line, c.Kind, c.Event))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected calls.\nWant:\n%s\nReceived:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	if res.Files != 3 || len(res.Errors) != 0 {
+		t.Errorf("Unexpected files: %d, errors: %v", res.Files, res.Errors)
+	}
+
+	if _, err := scanGo("broken.go", []byte("package main\n\nfunc broken( {\n"), make(consts)); err == nil || !strings.Contains(err.Error(), "broken.go:3") {
+		t.Errorf("Parse error should be reported with the position, got: %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	res := &Result{Files: 1, Calls: []*Call{
+		{Kind: KindEmit, Event: "login", File: "app.js", Line: 1},
+		{Kind: KindOn, Event: "USER", Identifier: true, File: "app.js", Line: 2},
+		{Kind: KindOn, Event: "login", File: "app.js", Line: 3},
+		{Kind: KindOn, Event: "typing", File: "app.js", Line: 4},
+		{Kind: KindOn, Event: "disconnect", File: "app.js", Line: 5},
+		{Kind: KindEmit, Event: "ignored", File: "app.js", Line: 6},
+	}}
+	catalog := []*events.Event{
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient},
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend},
+		{Constant: "LOGOUT", Value: "logout", Type: events.TypeClient},
+	}
+
+	r := Check(res, catalog, SideClient, []string{"ignored"})
+
+	if len(r.Undocumented) != 1 || r.Undocumented[0].Event != "typing" {
+		t.Errorf("Unexpected undocumented calls: %v", r.Undocumented)
+	}
+	if strings.Join(r.Unused, ",") != "logout" {
+		t.Errorf("Unexpected unused events: %v", r.Unused)
+	}
+	if len(r.Mismatched) != 1 || r.Mismatched[0].Line != 3 || r.Mismatched[0].Type != events.TypeClient {
+		t.Errorf("Unexpected mismatched calls: %v", r.Mismatched)
+	}
+
+	if r = Check(res, catalog, SideServer, []string{"ignored"}); len(r.Mismatched) != 2 {
+		t.Errorf("Unexpected mismatched calls of the server: %v", r.Mismatched)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+const EventUser = "user"
+
+type message struct {
+	Event string
+	Data  interface{}
+}
+
+func serve(server *Server, conn *Conn) {
+	server.OnEvent("/", "login", func(s Conn, msg string) {
+		s.Emit(EventUser, msg)
+	})
+	server.BroadcastToRoom("/", "chat", "chat_message", "hi")
+	conn.WriteJSON(&message{Event: "notice"})
+	var m message
+	switch m.Event {
+	case "typing", EventUser:
+	}
+}
@@ -0,0 +1,15 @@
+import { EVENTS } from './events'
+
+socket.on('connect', () => {
+  socket.emit(EVENTS.LOGIN, { name: 'John' })
+})
+socket.on(EVENTS.USER, (user) => render(user))
+socket.on("typing", () => {})
+// socket.emit('commented')
+this.$socket.emit(`logout`)
+socket.emit(`room:${id}`)
+socket.emit('user')
+fetch('http://example.com').then(() => socket.emit('ping'))
+const url = `wss://${host}//`; socket.on('pong', () => {})
+/* socket.emit('block') */
+log("a//b") // socket.emit('trailing')
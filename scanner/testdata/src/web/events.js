@@ -0,0 +1,4 @@
+export const EVENTS = {
+  LOGIN: 'login',
+  USER: "user",
+}
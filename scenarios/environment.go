@@ -0,0 +1,31 @@
+package scenarios
+
+import "github.com/nskondratev/api-page-go-back/environments"
+
+// ApplyEnvironment fills the target, the protocol, the headers and the vars of the run from the environment.
+// The target, the protocol and the headers set in the config take precedence. Values of secrets are masked
+// in the report. Secrets are sent to the target of the environment only, they are dropped when the config
+// overrides the target.
+func (c *RunConfig) ApplyEnvironment(e *environments.Environment) error {
+	if len(c.Target) > 0 {
+		e = e.WithoutSecrets()
+	}
+	c.Secrets = e.SecretValues()
+	if len(c.Target) < 1 {
+		target, err := e.Target()
+		if err != nil {
+			return err
+		}
+		c.Target = target
+	}
+	if len(c.Protocol) < 1 {
+		c.Protocol = e.Protocol
+	}
+	header := e.Header()
+	for name, values := range c.Header {
+		header[name] = values
+	}
+	c.Header = header
+	c.Vars = e.Values()
+	return nil
+}
@@ -0,0 +1,82 @@
+package scenarios
+
+import (
+	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/util"
+	"time"
+)
+
+// Actions of steps
+const (
+	// ActionEmit sends the event to the target
+	ActionEmit = "emit"
+	// ActionExpect waits for the event from the target
+	ActionExpect = "expect"
+)
+
+// Scenario is an ordered list of steps executed against the target real-time API.
+type Scenario struct {
+	ID          uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId   uint64    `json:"projectId" gorm:"unique_index:idx_scenarios_project_name;default:1;column:projectId"`
+	Name        string    `json:"name" gorm:"size:255;unique_index:idx_scenarios_project_name;column:name"`
+	Description string    `json:"description" gorm:"type:text;column:description"`
+	Steps       []*Step   `json:"steps" gorm:"-"`
+	Data        string    `json:"-" gorm:"type:longtext;column:steps"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+type ScenarioList struct {
+	ID          uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId   uint64    `json:"projectId" gorm:"column:projectId"`
+	Name        string    `json:"name" gorm:"size:255;column:name"`
+	Description string    `json:"description" gorm:"type:text;column:description"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+// Step is a single action of the scenario.
+//
+// An emit step sends the payload as the event, with Ack set it waits for the acknowledgement.
+// An expect step waits for the event. Match and Capture of an emit step apply to the ack payload,
+// of an expect step to the payload of the received event. Strings of the payload and match values
+// may reference captured variables as ${name}.
+type Step struct {
+	Action  string       `json:"action" validate:"required,oneof=emit expect"`
+	Event   string       `json:"event" validate:"required"`
+	Payload util.RawJSON `json:"payload"`
+	Ack     bool         `json:"ack"`
+	// TimeoutMs limits the wait for the event or the ack, DefaultTimeout when zero
+	TimeoutMs int `json:"timeoutMs" validate:"min=0"`
+	// Match maps paths of the payload to the expected values
+	Match map[string]util.RawJSON `json:"match"`
+	// Capture maps names of variables to paths of the payload
+	Capture map[string]string `json:"capture"`
+}
+
+func (Scenario) TableName() string {
+	return "scenarios"
+}
+
+func (ScenarioList) TableName() string {
+	return "scenarios"
+}
+
+// BeforeSave serializes the steps into the steps column.
+func (s *Scenario) BeforeSave() error {
+	data, err := json.Marshal(s.Steps)
+	if err != nil {
+		return err
+	}
+	s.Data = string(data)
+	return nil
+}
+
+// AfterFind restores the steps from the steps column.
+func (s *Scenario) AfterFind() error {
+	s.Steps = make([]*Step, 0)
+	if len(s.Data) < 1 {
+		return nil
+	}
+	return json.Unmarshal([]byte(s.Data), &s.Steps)
+}
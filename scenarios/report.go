@@ -0,0 +1,129 @@
+package scenarios
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/environments"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// JUnit renders the report as JUnit XML, every step is a test case.
+// A connection error is reported as an error of the suite.
+func (r *Report) JUnit() ([]byte, error) {
+	suite := junitTestSuite{
+		Name:      r.Scenario,
+		Tests:     len(r.Steps),
+		Time:      seconds(r.DurationMs),
+		Timestamp: r.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+		Cases:     make([]junitTestCase, 0, len(r.Steps)+1),
+	}
+	if len(r.Error) > 0 {
+		suite.Tests++
+		suite.Errors++
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      "connect",
+			ClassName: r.Scenario,
+			Time:      seconds(0),
+			Error:     &junitMessage{Message: r.Error},
+		})
+	}
+	for _, step := range r.Steps {
+		tc := junitTestCase{
+			Name:      fmt.Sprintf("%d. %s %s", step.Index, step.Action, step.Event),
+			ClassName: r.Scenario,
+			Time:      seconds(step.DurationMs),
+		}
+		switch step.Status {
+		case StatusFailed:
+			suite.Failures++
+			details := make([]string, 0, len(step.Errors))
+			for _, e := range step.Errors {
+				details = append(details, e.Error())
+			}
+			tc.Failure = &junitMessage{Message: step.Message, Text: strings.Join(details, "\n")}
+		case StatusSkipped:
+			suite.Skipped++
+			tc.Skipped = &junitMessage{}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	data, err := xml.MarshalIndent(&junitTestSuites{Suites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
+
+// MaskSecrets replaces the secrets in the error and the failure messages of the steps with the mask.
+func (r *Report) MaskSecrets(secrets []string) {
+	if len(secrets) < 1 {
+		return
+	}
+	r.Error = MaskSecrets(r.Error, secrets)
+	for _, step := range r.Steps {
+		step.Message = MaskSecrets(step.Message, secrets)
+		for _, e := range step.Errors {
+			e.Message = MaskSecrets(e.Message, secrets)
+		}
+	}
+}
+
+// MaskSecrets replaces the secrets in the message with the mask. Secrets are also looked for in the forms
+// they take in the messages: quoted as JSON strings by the matches and escaped in the query of the target.
+func MaskSecrets(message string, secrets []string) string {
+	forms := make([]string, 0, 3*len(secrets))
+	for _, secret := range secrets {
+		if len(secret) < 1 {
+			continue
+		}
+		forms = append(forms, secret, url.QueryEscape(secret))
+		if quoted, err := json.Marshal(secret); err == nil {
+			forms = append(forms, string(quoted[1:len(quoted)-1]))
+		}
+	}
+	// Longer forms go first, a secret may contain another one
+	sort.Slice(forms, func(i, j int) bool {
+		return len(forms[i]) > len(forms[j])
+	})
+	for _, form := range forms {
+		message = strings.Replace(message, form, environments.Mask, -1)
+	}
+	return message
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
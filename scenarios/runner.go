@@ -0,0 +1,294 @@
+package scenarios
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/socket"
+	"net/http"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// DefaultTimeout limits the wait of a step without a timeout
+const DefaultTimeout = 5 * time.Second
+
+// Statuses of steps
+const (
+	StatusPassed  = "passed"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+type RunConfig struct {
+	Target string
+	// Protocol of the target, socketio by default
+	Protocol string
+	Header   http.Header
+	// Catalog provides the definitions the payloads are validated against
+	Catalog []*events.Event
+	// Types are the shared types referenced by the catalog
+	Types events.Types
+	// Vars are referenced by the steps along with the captured ones, like variables of the environment.
+	// They are not listed in the report.
+	Vars Vars
+	// Secrets are the values masked in the messages of the report, like values of secrets of the environment
+	Secrets []string
+}
+
+// Report is the result of the scenario run.
+type Report struct {
+	Scenario   string        `json:"scenario"`
+	Target     string        `json:"target"`
+	Passed     bool          `json:"passed"`
+	StartedAt  time.Time     `json:"startedAt"`
+	DurationMs int64         `json:"durationMs"`
+	Error      string        `json:"error,omitempty"`
+	Steps      []*StepResult `json:"steps"`
+	Vars       Vars          `json:"vars"`
+}
+
+type StepResult struct {
+	Index      int                       `json:"index"`
+	Action     string                    `json:"action"`
+	Event      string                    `json:"event"`
+	Status     string                    `json:"status"`
+	DurationMs int64                     `json:"durationMs"`
+	Message    string                    `json:"message,omitempty"`
+	Errors     []*events.ValidationError `json:"errors,omitempty"`
+}
+
+// runner keeps the state of a single run.
+type runner struct {
+	conn    *socket.Conn
+	frames  chan *socket.Frame
+	done    chan struct{}
+	pending []*socket.Frame
+	catalog map[string]*events.Event
+	types   events.Types
+	vars    Vars
+	// captured are the vars of the report
+	captured Vars
+	ackId    int64
+}
+
+// stepError fails the step with the message and optional validation errors.
+type stepError struct {
+	message string
+	errors  []*events.ValidationError
+}
+
+func (e *stepError) Error() string {
+	return e.message
+}
+
+func failf(format string, args ...interface{}) *stepError {
+	return &stepError{message: fmt.Sprintf(format, args...)}
+}
+
+// Run executes the steps of the scenario against the target. Steps after the first failed one are skipped.
+func Run(s *Scenario, c *RunConfig) *Report {
+	report := &Report{Scenario: s.Name, Target: c.Target, StartedAt: time.Now(), Steps: make([]*StepResult, len(s.Steps)), Vars: Vars{}}
+	for i, step := range s.Steps {
+		report.Steps[i] = &StepResult{Index: i + 1, Action: step.Action, Event: step.Event, Status: StatusSkipped}
+	}
+	defer func() {
+		report.DurationMs = int64(time.Since(report.StartedAt) / time.Millisecond)
+		report.MaskSecrets(c.Secrets)
+	}()
+
+	codec, err := socket.NewCodec(c.Protocol)
+	if err != nil {
+		report.Error = err.Error()
+		return report
+	}
+	conn, err := socket.Dial(c.Target, codec, c.Header)
+	if err != nil {
+		report.Error = "can not connect to the target: " + err.Error()
+		return report
+	}
+	defer conn.Close()
+
+	r := &runner{
+		conn:     conn,
+		frames:   make(chan *socket.Frame, 256),
+		done:     make(chan struct{}),
+		catalog:  make(map[string]*events.Event, len(c.Catalog)),
+		types:    c.Types,
+		vars:     Vars{},
+		captured: report.Vars,
+	}
+	for name, value := range c.Vars {
+		r.vars[name] = value
+	}
+	for _, e := range c.Catalog {
+		r.catalog[e.Value] = e
+	}
+	defer close(r.done)
+	go r.readPump()
+
+	for i, step := range s.Steps {
+		result := report.Steps[i]
+		started := time.Now()
+		err := r.run(step)
+		result.DurationMs = int64(time.Since(started) / time.Millisecond)
+		if err != nil {
+			result.Status = StatusFailed
+			result.Message = err.message
+			result.Errors = err.errors
+			return report
+		}
+		result.Status = StatusPassed
+	}
+	report.Passed = true
+	return report
+}
+
+func (r *runner) readPump() {
+	defer close(r.frames)
+	for {
+		f, raw, err := r.conn.Read()
+		if err != nil {
+			if raw == nil {
+				return
+			}
+			continue
+		}
+		select {
+		case r.frames <- f:
+		case <-r.done:
+			return
+		}
+	}
+}
+
+func (r *runner) run(step *Step) *stepError {
+	e, ok := r.catalog[step.Event]
+	if !ok {
+		return failf("event %s is not described in the catalog", step.Event)
+	}
+	timeout := DefaultTimeout
+	if step.TimeoutMs > 0 {
+		timeout = time.Duration(step.TimeoutMs) * time.Millisecond
+	}
+	switch step.Action {
+	case ActionEmit:
+		payload, err := r.vars.Expand(step.Payload)
+		if err != nil {
+			return failf("invalid payload: %s", err.Error())
+		}
+		if errs := events.ValidatePayload(e.Fields, r.types, payload); len(errs) > 0 {
+			return &stepError{message: "payload does not match the event fields", errors: errs}
+		}
+		f := socket.NewEventFrame(e.Value, nil)
+		if payload != nil {
+			if f.Data, err = json.Marshal(payload); err != nil {
+				return failf("invalid payload: %s", err.Error())
+			}
+		}
+		if step.Ack {
+			r.ackId++
+			f.AckId = r.ackId
+		}
+		if err := r.conn.Send(f); err != nil {
+			return failf("can not send the event: %s", err.Error())
+		}
+		if !step.Ack {
+			return nil
+		}
+		ack := r.wait(timeout, func(f *socket.Frame) bool {
+			return f.Kind == socket.FrameAck && f.AckId == r.ackId
+		})
+		if ack == nil {
+			return failf("ack was not received within %s", timeout)
+		}
+		return r.check(step, e.AckFields, ack.Data)
+	case ActionExpect:
+		received := r.wait(timeout, func(f *socket.Frame) bool {
+			return f.Kind == socket.FrameEvent && f.Event == step.Event
+		})
+		if received == nil {
+			return failf("event %s was not received within %s", step.Event, timeout)
+		}
+		return r.check(step, e.Fields, received.Data)
+	}
+	return failf("unknown action: %s", step.Action)
+}
+
+// wait returns the first received frame satisfying the predicate. Other frames are kept for the next steps.
+func (r *runner) wait(timeout time.Duration, match func(*socket.Frame) bool) *socket.Frame {
+	for i, f := range r.pending {
+		if match(f) {
+			r.pending = append(r.pending[:i], r.pending[i+1:]...)
+			return f
+		}
+	}
+	deadline := time.After(timeout)
+	for {
+		select {
+		case f, ok := <-r.frames:
+			if !ok {
+				return nil
+			}
+			if match(f) {
+				return f
+			}
+			r.pending = append(r.pending, f)
+		case <-deadline:
+			return nil
+		}
+	}
+}
+
+// check validates the received payload against the catalog fields, then applies the matches and captures of the step.
+func (r *runner) check(step *Step, fields []events.Field, data json.RawMessage) *stepError {
+	if errs := events.ValidateRawPayload(fields, r.types, data); len(errs) > 0 {
+		return &stepError{message: "received payload does not match the catalog", errors: errs}
+	}
+	var payload interface{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return failf("invalid received payload: %s", err.Error())
+		}
+	}
+	paths := make([]string, 0, len(step.Match))
+	for path := range step.Match {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		expected, err := r.vars.Expand(step.Match[path])
+		if err != nil {
+			return failf("invalid match of %s: %s", path, err.Error())
+		}
+		label := path
+		if len(label) < 1 {
+			label = "payload"
+		}
+		actual, ok := valueAt(payload, path)
+		if !ok {
+			return failf("%s is missing, expected %s", label, jsonString(expected))
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			return failf("%s is %s, expected %s", label, jsonString(actual), jsonString(expected))
+		}
+	}
+	for name, path := range step.Capture {
+		value, ok := valueAt(payload, path)
+		if !ok {
+			return failf("can not capture %s: %s is missing", name, path)
+		}
+		r.vars[name] = value
+		r.captured[name] = value
+	}
+	return nil
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
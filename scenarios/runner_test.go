@@ -0,0 +1,205 @@
+package scenarios
+
+import (
+	"encoding/xml"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/mock"
+	"github.com/nskondratev/api-page-go-back/router"
+	"github.com/nskondratev/api-page-go-back/util"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	target, catalog := setupRunTest(t)
+	defer target.Close()
+
+	cases := []struct {
+		steps    []*Step
+		passed   bool
+		statuses []string
+		message  string
+	}{
+		{
+			steps: []*Step{
+				{Action: ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"John"}`), Ack: true, Match: map[string]util.RawJSON{"ok": util.RawJSON(`true`)}},
+				{Action: ActionExpect, Event: "user", Capture: map[string]string{"userName": "name"}},
+				{Action: ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"${userName}"}`)},
+				{Action: ActionExpect, Event: "user", Match: map[string]util.RawJSON{"name": util.RawJSON(`"${userName}"`)}},
+			},
+			passed:   true,
+			statuses: []string{StatusPassed, StatusPassed, StatusPassed, StatusPassed},
+		},
+		{
+			steps: []*Step{
+				{Action: ActionEmit, Event: "login", Payload: util.RawJSON(`{}`)},
+				{Action: ActionExpect, Event: "user"},
+			},
+			statuses: []string{StatusFailed, StatusSkipped},
+			message:  "payload does not match the event fields",
+		},
+		{
+			steps: []*Step{
+				{Action: ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"John"}`)},
+				{Action: ActionExpect, Event: "user", Match: map[string]util.RawJSON{"name": util.RawJSON(`"John"`)}},
+			},
+			statuses: []string{StatusPassed, StatusFailed},
+			message:  `name is "name", expected "John"`,
+		},
+		{
+			steps: []*Step{
+				{Action: ActionExpect, Event: "user", TimeoutMs: 50},
+			},
+			statuses: []string{StatusFailed},
+			message:  "event user was not received within 50ms",
+		},
+		{
+			steps: []*Step{
+				{Action: ActionEmit, Event: "logout"},
+			},
+			statuses: []string{StatusFailed},
+			message:  "event logout is not described in the catalog",
+		},
+	}
+
+	for caseNum, item := range cases {
+		report := Run(&Scenario{Name: "Login", Steps: item.steps}, &RunConfig{Target: target.URL, Catalog: catalog})
+		if report.Passed != item.passed {
+			t.Errorf("[%d] Unexpected result. Want passed = %v, report: %+v", caseNum, item.passed, report)
+		}
+		for i, status := range item.statuses {
+			if report.Steps[i].Status != status {
+				t.Errorf("[%d] Unexpected status of step %d. Want %s, received %s (%s)", caseNum, i+1, status, report.Steps[i].Status, report.Steps[i].Message)
+			}
+		}
+		if len(item.message) > 0 {
+			found := false
+			for _, step := range report.Steps {
+				found = found || step.Message == item.message
+			}
+			if !found {
+				t.Errorf("[%d] Failure message %q was not reported: %+v", caseNum, item.message, report.Steps)
+			}
+		}
+	}
+}
+
+func TestRun_Vars(t *testing.T) {
+	target, catalog := setupRunTest(t)
+	defer target.Close()
+
+	report := Run(&Scenario{Name: "Login", Steps: []*Step{
+		{Action: ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"${user}"}`)},
+		{Action: ActionExpect, Event: "user", Capture: map[string]string{"userName": "name"}},
+	}}, &RunConfig{Target: target.URL, Catalog: catalog, Vars: Vars{"user": "John"}})
+
+	if !report.Passed {
+		t.Fatalf("Scenario with vars failed: %+v", report.Steps[0])
+	}
+	if _, ok := report.Vars["user"]; ok || len(report.Vars) != 1 {
+		t.Errorf("Unexpected vars of the report: %+v", report.Vars)
+	}
+}
+
+func TestRun_Secrets(t *testing.T) {
+	target, catalog := setupRunTest(t)
+	defer target.Close()
+
+	report := Run(&Scenario{Name: "Login", Steps: []*Step{
+		{Action: ActionEmit, Event: "login", Payload: util.RawJSON(`{"name":"John"}`)},
+		{Action: ActionExpect, Event: "user", Match: map[string]util.RawJSON{"name": util.RawJSON(`"${token}"`)}},
+	}}, &RunConfig{Target: target.URL, Catalog: catalog, Vars: Vars{"token": "s3cr3t<&>"}, Secrets: []string{"s3cr3t<&>"}})
+
+	if report.Passed || report.Steps[1].Message != `name is "name", expected "********"` {
+		t.Errorf("Unexpected failure of the match of the secret: %+v", report.Steps[1])
+	}
+	data, err := report.JUnit()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if strings.Contains(string(data), "s3cr3t") {
+		t.Errorf("Secret is in the report: %s", data)
+	}
+}
+
+func TestMaskSecrets(t *testing.T) {
+	cases := []struct {
+		message  string
+		secrets  []string
+		expected string
+	}{
+		{"token is s3cr3t", []string{"s3cr3t"}, "token is ********"},
+		{`expected "a\u0026b"`, []string{"a&b"}, `expected "********"`},
+		{"ws://example.com/?token=a%26b", []string{"a&b"}, "ws://example.com/?token=********"},
+		{"secrets abc and ab", []string{"ab", "abc"}, "secrets ******** and ********"},
+		{"nothing to mask", []string{""}, "nothing to mask"},
+	}
+	for caseNum, item := range cases {
+		if masked := MaskSecrets(item.message, item.secrets); masked != item.expected {
+			t.Errorf("[%d] Unexpected masked message. Want %q, received %q", caseNum, item.expected, masked)
+		}
+	}
+}
+
+func TestReport_JUnit(t *testing.T) {
+	target, catalog := setupRunTest(t)
+	defer target.Close()
+
+	report := Run(&Scenario{Name: "Login", Steps: []*Step{
+		{Action: ActionEmit, Event: "login", Payload: util.RawJSON(`{"age":"30"}`)},
+		{Action: ActionExpect, Event: "user"},
+	}}, &RunConfig{Target: target.URL, Catalog: catalog})
+
+	data, err := report.JUnit()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	suites := &junitTestSuites{}
+	if err := xml.Unmarshal(data, suites); err != nil {
+		t.Fatalf("Report is not a valid XML: %s", err.Error())
+	}
+	suite := suites.Suites[0]
+	if suite.Name != "Login" || suite.Tests != 2 || suite.Failures != 1 || suite.Skipped != 1 {
+		t.Errorf("Unexpected suite: %+v", suite)
+	}
+	if !strings.Contains(string(data), `<failure message="payload does not match the event fields">name: required key is missing</failure>`) {
+		t.Errorf("Failure details are missing: %s", data)
+	}
+
+	report = Run(&Scenario{Name: "Offline"}, &RunConfig{Target: "ws://127.0.0.1:1", Catalog: catalog})
+	if data, _ = report.JUnit(); !strings.Contains(string(data), `errors="1"`) || report.Passed {
+		t.Errorf("Connection error was not reported: %s", data)
+	}
+}
+
+func setupRunTest(t *testing.T) (*httptest.Server, []*events.Event) {
+	e := router.New()
+	es := store.NewMemory(&store.MemoryConfig{Logger: e.Logger})
+	name, _ := util.NewNullStringFromString("name")
+	ok, _ := util.NewNullStringFromString("ok")
+	for _, ev := range []*events.Event{
+		{Constant: "USER", Value: "user", Type: events.TypeFrontend, Fields: []events.Field{
+			{Key: name, Type: "string", Required: true},
+		}},
+		{Constant: "LOGIN", Value: "login", Type: events.TypeClient,
+			Fields:          []events.Field{{Key: name, Type: "string", Required: true}},
+			AckFields:       []events.Field{{Key: ok, Type: "boolean", Required: true}},
+			ResponseEventId: util.NewNullInt64FromInt64(1),
+		},
+	} {
+		if err := es.Create(ev); err != nil {
+			t.Fatalf("Error while creating event: %s", err.Error())
+		}
+	}
+	ms := mock.New(&mock.Config{EventStore: es, Logger: e.Logger})
+	if err := ms.Reload(); err != nil {
+		t.Fatalf("Error while loading mock catalog: %s", err.Error())
+	}
+	catalog, err := es.GetAll()
+	if err != nil {
+		t.Fatalf("Error while getting catalog: %s", err.Error())
+	}
+	return httptest.NewServer(ms), catalog
+}
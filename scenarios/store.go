@@ -0,0 +1,13 @@
+package scenarios
+
+// Store keeps the scenarios of a single project. Scenarios of other projects are not visible to it.
+type Store interface {
+	GetById(uint64) (*Scenario, error)
+	GetByName(string) (*Scenario, error)
+	List(offset, limit int) ([]*ScenarioList, int, error)
+	Create(*Scenario) error
+	Update(*Scenario) error
+	Delete(*Scenario) error
+	// ForProject returns the store of the scenarios of the project sharing the storage with this one
+	ForProject(projectId uint64) Store
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+)
+
+type Gorm struct {
+	db        *gorm.DB
+	logger    logger.Logger
+	projectId uint64
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+	// ProjectId is the project of the scenarios, the default project when zero
+	ProjectId uint64
+}
+
+func NewGorm(c *GormConfig) scenarios.Store {
+	s := &Gorm{
+		db:        c.DB,
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Gorm) ForProject(projectId uint64) scenarios.Store {
+	return NewGorm(&GormConfig{DB: s.db, Logger: s.logger, ProjectId: projectId})
+}
+
+// scoped limits the query to the scenarios of the project.
+func (s *Gorm) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("`projectId` = ?", s.projectId)
+}
+
+func (s *Gorm) GetById(id uint64) (*scenarios.Scenario, error) {
+	var scenario scenarios.Scenario
+	if err := s.scoped(s.db).First(&scenario, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+func (s *Gorm) GetByName(name string) (*scenarios.Scenario, error) {
+	var scenario scenarios.Scenario
+	if err := s.scoped(s.db).Where("`name` = ?", name).First(&scenario).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+func (s *Gorm) List(offset, limit int) ([]*scenarios.ScenarioList, int, error) {
+	scenariosList, total := make([]*scenarios.ScenarioList, 0), 0
+	qb := s.scoped(s.db.Model(&scenariosList))
+	if err := qb.Count(&total).Error; err != nil {
+		return scenariosList, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("name asc").Find(&scenariosList).Error
+	return scenariosList, total, err
+}
+
+func (s *Gorm) Create(scenario *scenarios.Scenario) error {
+	scenario.ProjectId = s.projectId
+	return s.db.Create(scenario).Error
+}
+
+func (s *Gorm) Update(scenario *scenarios.Scenario) error {
+	existing := &scenarios.Scenario{}
+	if err := s.scoped(s.db).First(existing, scenario.ID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("[scenarios.store.gorm] scenario with id = %d does not exist", scenario.ID)
+		}
+		return err
+	}
+	scenario.CreatedAt = existing.CreatedAt
+	scenario.ProjectId = existing.ProjectId
+	res := s.db.Save(scenario)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[scenarios.store.gorm] scenario with id = %d was not updated", scenario.ID)
+	}
+	return nil
+}
+
+func (s *Gorm) Delete(scenario *scenarios.Scenario) error {
+	res := s.scoped(s.db).Delete(scenario)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[scenarios.store.gorm] scenario with id = %d was not deleted", scenario.ID)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger    logger.Logger
+	projectId uint64
+	// Records of all projects are shared by the stores returned by ForProject
+	*memoryRecords
+}
+
+type memoryRecords struct {
+	records []*scenarios.Scenario
+	lastId  uint64
+	mu      *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+	// ProjectId is the project of the scenarios, the default project when zero
+	ProjectId uint64
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	s := &Memory{
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+		memoryRecords: &memoryRecords{
+			records: make([]*scenarios.Scenario, 0),
+			mu:      &sync.Mutex{},
+		},
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Memory) ForProject(projectId uint64) scenarios.Store {
+	return &Memory{
+		logger:        s.logger,
+		projectId:     projectId,
+		memoryRecords: s.memoryRecords,
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*scenarios.Scenario, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id && el.ProjectId == s.projectId {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) GetByName(name string) (*scenarios.Scenario, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.Name == name && el.ProjectId == s.projectId {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int) ([]*scenarios.ScenarioList, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	scenariosList := make([]*scenarios.ScenarioList, 0, len(s.records))
+	for _, el := range s.records {
+		if el.ProjectId != s.projectId {
+			continue
+		}
+		scenariosList = append(scenariosList, &scenarios.ScenarioList{
+			ID:          el.ID,
+			ProjectId:   el.ProjectId,
+			Name:        el.Name,
+			Description: el.Description,
+			CreatedAt:   el.CreatedAt,
+			UpdatedAt:   el.UpdatedAt,
+		})
+	}
+	// Ordered by name, like the gorm store
+	sort.SliceStable(scenariosList, func(i, j int) bool {
+		return scenariosList[i].Name < scenariosList[j].Name
+	})
+	total := len(scenariosList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return scenariosList[offset : offset+l], total, nil
+}
+
+func (s *Memory) Create(scenario *scenarios.Scenario) error {
+	s.mu.Lock()
+	s.lastId++
+	scenario.ID = s.lastId
+	scenario.ProjectId = s.projectId
+	scenario.CreatedAt = time.Now()
+	scenario.UpdatedAt = scenario.CreatedAt
+	s.records = append(s.records, scenario)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Update(scenario *scenarios.Scenario) error {
+	scenario.UpdatedAt = time.Now()
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == scenario.ID && el.ProjectId == s.projectId {
+			scenario.CreatedAt = el.CreatedAt
+			scenario.ProjectId = el.ProjectId
+			s.records[i] = scenario
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Delete(scenario *scenarios.Scenario) error {
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == scenario.ID && el.ProjectId == s.projectId {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
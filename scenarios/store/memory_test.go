@@ -0,0 +1,106 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/scenarios"
+	"github.com/nskondratev/api-page-go-back/testutils"
+	"testing"
+)
+
+func TestMemory_Create(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	cases := []testutils.MemoryCreateTestCase{
+		{ItemToCreate: &scenarios.Scenario{Name: "Login"}, TotalRows: 1, LastItemID: 1},
+		{ItemToCreate: &scenarios.Scenario{Name: "Logout"}, TotalRows: 2, LastItemID: 2},
+	}
+
+	for caseNum, item := range cases {
+		scenarioToCreate, ok := item.ItemToCreate.(*scenarios.Scenario)
+
+		if !ok {
+			t.Errorf("[%d] Can not convert test case item to create to *scenarios.Scenario type", caseNum)
+		}
+
+		if err := s.Create(scenarioToCreate); err != nil {
+			t.Errorf("[%d] error while creating scenario %+v", caseNum, scenarioToCreate)
+		}
+
+		if len(s.records) != item.TotalRows {
+			t.Errorf("[%d] total rows mismatch. Want %d, received %d", caseNum, item.TotalRows, len(s.records))
+		}
+
+		if s.records[len(s.records)-1].ID != item.LastItemID {
+			t.Errorf("[%d] last scenario id mismatch. Want %d, received %d", caseNum, item.LastItemID, s.records[len(s.records)-1].ID)
+		}
+	}
+}
+
+func TestMemory_Update(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	created := &scenarios.Scenario{Name: "Login"}
+	if err := s.Create(created); err != nil {
+		t.Fatalf("Can not create test scenario: %s", err.Error())
+	}
+
+	updated := &scenarios.Scenario{ID: created.ID, Name: "Login with ack", Steps: []*scenarios.Step{
+		{Action: scenarios.ActionEmit, Event: "login", Ack: true},
+	}}
+	if err := s.Update(updated); err != nil {
+		t.Fatalf("Can not update scenario: %s", err.Error())
+	}
+
+	stored, err := s.GetByName("Login with ack")
+	if err != nil || stored == nil {
+		t.Fatalf("Updated scenario was not found: %v", err)
+	}
+	if !stored.CreatedAt.Equal(created.CreatedAt) || len(stored.Steps) != 1 {
+		t.Errorf("Unexpected updated scenario: %+v", stored)
+	}
+}
+
+func TestMemory_List(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	for _, name := range []string{"Logout", "Chat", "Login"} {
+		if err := s.Create(&scenarios.Scenario{Name: name}); err != nil {
+			t.Fatalf("Can not create test scenario: %s", err.Error())
+		}
+	}
+
+	list, total, err := s.List(1, 5)
+	if err != nil {
+		t.Fatalf("Can not list scenarios: %s", err.Error())
+	}
+	if total != 3 || len(list) != 2 || list[0].Name != "Login" || list[1].Name != "Logout" {
+		t.Errorf("Unexpected scenarios list: %+v, total: %d", list, total)
+	}
+}
+
+func TestMemory_ForProject(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+	other := s.ForProject(2)
+
+	if err := s.Create(&scenarios.Scenario{Name: "Login"}); err != nil {
+		t.Fatalf("Can not create test scenario: %s", err.Error())
+	}
+	if err := other.Create(&scenarios.Scenario{Name: "Login"}); err != nil {
+		t.Fatalf("Can not create test scenario: %s", err.Error())
+	}
+
+	if sc, _ := other.GetByName("Login"); sc == nil || sc.ID != 2 || sc.ProjectId != 2 {
+		t.Errorf("Unexpected scenario of the other project: %+v", sc)
+	}
+	if list, total, _ := s.List(0, 10); total != 1 || list[0].ID != 1 {
+		t.Errorf("Unexpected scenarios of the default project: %+v", list)
+	}
+	if sc, _ := other.GetById(1); sc != nil {
+		t.Errorf("Scenario of the default project is visible: %+v", sc)
+	}
+	if err := other.Delete(&scenarios.Scenario{ID: 1}); err != nil {
+		t.Fatalf("Can not delete scenario: %s", err.Error())
+	}
+	if sc, _ := s.GetById(1); sc == nil {
+		t.Error("Scenario of the default project was deleted")
+	}
+}
@@ -0,0 +1,110 @@
+package scenarios
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	varPattern     = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+	segmentPattern = regexp.MustCompile(`^([^\[\]]*)((?:\[\d+\])*)$`)
+)
+
+// Vars are the values captured by the steps of a run.
+type Vars map[string]interface{}
+
+// Expand decodes the JSON value replacing the variable references in its strings.
+// A string consisting of a single reference is replaced with the captured value keeping its type.
+func (v Vars) Expand(raw []byte) (interface{}, error) {
+	if len(raw) < 1 {
+		return nil, nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+	return v.substitute(value)
+}
+
+func (v Vars) substitute(value interface{}) (interface{}, error) {
+	switch x := value.(type) {
+	case string:
+		if m := varPattern.FindStringSubmatch(x); m != nil && m[0] == x {
+			captured, ok := v[m[1]]
+			if !ok {
+				return nil, fmt.Errorf("variable %s is not captured", m[1])
+			}
+			return captured, nil
+		}
+		var err error
+		res := varPattern.ReplaceAllStringFunc(x, func(ref string) string {
+			name := varPattern.FindStringSubmatch(ref)[1]
+			captured, ok := v[name]
+			if !ok {
+				err = fmt.Errorf("variable %s is not captured", name)
+				return ref
+			}
+			if s, ok := captured.(string); ok {
+				return s
+			}
+			data, _ := json.Marshal(captured)
+			return string(data)
+		})
+		return res, err
+	case map[string]interface{}:
+		for key, el := range x {
+			res, err := v.substitute(el)
+			if err != nil {
+				return nil, err
+			}
+			x[key] = res
+		}
+	case []interface{}:
+		for i, el := range x {
+			res, err := v.substitute(el)
+			if err != nil {
+				return nil, err
+			}
+			x[i] = res
+		}
+	}
+	return value, nil
+}
+
+// valueAt returns the value by the path like "user.name" or "items[0].id". An empty path addresses the payload itself.
+func valueAt(payload interface{}, path string) (interface{}, bool) {
+	if len(path) < 1 {
+		return payload, true
+	}
+	current := payload
+	for _, segment := range strings.Split(path, ".") {
+		m := segmentPattern.FindStringSubmatch(segment)
+		if m == nil {
+			return nil, false
+		}
+		if len(m[1]) > 0 {
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = obj[m[1]]; !ok {
+				return nil, false
+			}
+		}
+		for _, idx := range strings.Split(strings.Trim(m[2], "[]"), "][") {
+			if len(idx) < 1 {
+				continue
+			}
+			i, _ := strconv.Atoi(idx)
+			arr, ok := current.([]interface{})
+			if !ok || i >= len(arr) {
+				return nil, false
+			}
+			current = arr[i]
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,57 @@
+package scenarios
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestVars_Expand(t *testing.T) {
+	vars := Vars{"id": float64(42), "name": "John"}
+	cases := []struct {
+		in       string
+		expected interface{}
+		err      bool
+	}{
+		{`"${id}"`, float64(42), false},
+		{`{"user":{"id":"${id}","title":"${name} #${id}"},"tags":["${name}"]}`, map[string]interface{}{
+			"user": map[string]interface{}{"id": float64(42), "title": "John #42"},
+			"tags": []interface{}{"John"},
+		}, false},
+		{`"${missing}"`, nil, true},
+		{`"a ${missing}"`, nil, true},
+	}
+	for caseNum, item := range cases {
+		actual, err := vars.Expand([]byte(item.in))
+		if (err != nil) != item.err {
+			t.Errorf("[%d] Unexpected error: %v", caseNum, err)
+			continue
+		}
+		if !item.err && !reflect.DeepEqual(actual, item.expected) {
+			t.Errorf("[%d] Unexpected value. Want %#v, received %#v", caseNum, item.expected, actual)
+		}
+	}
+}
+
+func TestValueAt(t *testing.T) {
+	payload := map[string]interface{}{
+		"user":  map[string]interface{}{"name": "John"},
+		"items": []interface{}{map[string]interface{}{"id": float64(1)}, map[string]interface{}{"id": float64(2)}},
+	}
+	cases := []struct {
+		path     string
+		expected interface{}
+		found    bool
+	}{
+		{"user.name", "John", true},
+		{"items[1].id", float64(2), true},
+		{"items[2].id", nil, false},
+		{"user.age", nil, false},
+		{"user[0]", nil, false},
+	}
+	for caseNum, item := range cases {
+		actual, found := valueAt(payload, item.path)
+		if found != item.found || !reflect.DeepEqual(actual, item.expected) {
+			t.Errorf("[%d] Unexpected value of %s: %#v, found: %v", caseNum, item.path, actual, found)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package snapshots
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"strconv"
+)
+
+// LiveRef refers to the current state of the events catalog instead of a snapshot.
+const LiveRef = "live"
+
+// Catalog returns the events and the shared types referenced by ref: LiveRef, a snapshot id or a snapshot name.
+// The types of the live catalog are resolved from the registry.
+func Catalog(ss Store, es events.Store, ts registry.Store, ref string) ([]*events.Event, events.Types, error) {
+	if ref == LiveRef {
+		catalog, err := es.GetAll()
+		if err != nil {
+			return nil, nil, err
+		}
+		types, err := events.ResolveEventTypes(ts, catalog...)
+		if err != nil {
+			return nil, nil, err
+		}
+		return catalog, types, nil
+	}
+	snapshot, err := find(ss, ref)
+	if err != nil {
+		return nil, nil, err
+	}
+	if snapshot == nil {
+		return nil, nil, fmt.Errorf("snapshot %q does not exist", ref)
+	}
+	return snapshot.Events, snapshot.Types, nil
+}
+
+// Compare builds the diff between two catalog references.
+func Compare(ss Store, es events.Store, ts registry.Store, from, to string) (*events.Diff, error) {
+	fromEvents, fromTypes, err := Catalog(ss, es, ts, from)
+	if err != nil {
+		return nil, err
+	}
+	toEvents, toTypes, err := Catalog(ss, es, ts, to)
+	if err != nil {
+		return nil, err
+	}
+	return events.Compare(fromEvents, toEvents, fromTypes, toTypes), nil
+}
+
+func find(ss Store, ref string) (*Snapshot, error) {
+	if id, err := strconv.ParseUint(ref, 10, 64); err == nil {
+		return ss.GetById(id)
+	}
+	return ss.GetByName(ref)
+}
@@ -0,0 +1,81 @@
+package snapshots
+
+import (
+	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/events"
+	"time"
+)
+
+// Snapshot is a named frozen copy of the whole events catalog along with the shared types it references.
+type Snapshot struct {
+	ID          uint64          `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId   uint64          `json:"projectId" gorm:"unique_index:idx_snapshots_project_name;default:1;column:projectId"`
+	Name        string          `json:"name" gorm:"size:255;unique_index:idx_snapshots_project_name;column:name"`
+	Description string          `json:"description" gorm:"type:text;column:description"`
+	Events      []*events.Event `json:"events" gorm:"-"`
+	Types       events.Types    `json:"types" gorm:"-"`
+	Data        string          `json:"-" gorm:"type:longtext;column:data"`
+	TypesData   string          `json:"-" gorm:"type:longtext;column:typesData"`
+	CreatedAt   time.Time       `json:"createdAt" gorm:"column:createdAt"`
+}
+
+type SnapshotList struct {
+	ID          uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	ProjectId   uint64    `json:"projectId" gorm:"column:projectId"`
+	Name        string    `json:"name" gorm:"size:255;column:name"`
+	Description string    `json:"description" gorm:"type:text;column:description"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"column:createdAt"`
+}
+
+func (Snapshot) TableName() string {
+	return "snapshots"
+}
+
+func (SnapshotList) TableName() string {
+	return "snapshots"
+}
+
+// Summary returns the snapshot without the catalog.
+func (s *Snapshot) Summary() *SnapshotList {
+	return &SnapshotList{
+		ID:          s.ID,
+		ProjectId:   s.ProjectId,
+		Name:        s.Name,
+		Description: s.Description,
+		CreatedAt:   s.CreatedAt,
+	}
+}
+
+// BeforeSave serializes the events and the types into the data columns.
+func (s *Snapshot) BeforeSave() error {
+	data, err := json.Marshal(s.Events)
+	if err != nil {
+		return err
+	}
+	s.Data = string(data)
+	if s.Types == nil {
+		s.Types = make(events.Types)
+	}
+	typesData, err := json.Marshal(s.Types)
+	if err != nil {
+		return err
+	}
+	s.TypesData = string(typesData)
+	return nil
+}
+
+// AfterFind restores the events and the types from the data columns. Snapshots taken before the types
+// were stored have no types.
+func (s *Snapshot) AfterFind() error {
+	s.Events = make([]*events.Event, 0)
+	s.Types = make(events.Types)
+	if len(s.Data) > 0 {
+		if err := json.Unmarshal([]byte(s.Data), &s.Events); err != nil {
+			return err
+		}
+	}
+	if len(s.TypesData) < 1 {
+		return nil
+	}
+	return json.Unmarshal([]byte(s.TypesData), &s.Types)
+}
@@ -0,0 +1,12 @@
+package snapshots
+
+// Store keeps the snapshots of a single project. Snapshots of other projects are not visible to it.
+type Store interface {
+	GetById(uint64) (*Snapshot, error)
+	GetByName(string) (*Snapshot, error)
+	List(offset, limit int) ([]*SnapshotList, int, error)
+	Create(*Snapshot) error
+	Delete(*Snapshot) error
+	// ForProject returns the store of the snapshots of the project sharing the storage with this one
+	ForProject(projectId uint64) Store
+}
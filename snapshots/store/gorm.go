@@ -0,0 +1,91 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+)
+
+type Gorm struct {
+	db        *gorm.DB
+	logger    logger.Logger
+	projectId uint64
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+	// ProjectId is the project of the snapshots, the default project when zero
+	ProjectId uint64
+}
+
+func NewGorm(c *GormConfig) snapshots.Store {
+	s := &Gorm{
+		db:        c.DB,
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Gorm) ForProject(projectId uint64) snapshots.Store {
+	return NewGorm(&GormConfig{DB: s.db, Logger: s.logger, ProjectId: projectId})
+}
+
+// scoped limits the query to the snapshots of the project.
+func (s *Gorm) scoped(db *gorm.DB) *gorm.DB {
+	return db.Where("`projectId` = ?", s.projectId)
+}
+
+func (s *Gorm) GetById(id uint64) (*snapshots.Snapshot, error) {
+	var snapshot snapshots.Snapshot
+	if err := s.scoped(s.db).First(&snapshot, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (s *Gorm) GetByName(name string) (*snapshots.Snapshot, error) {
+	var snapshot snapshots.Snapshot
+	if err := s.scoped(s.db).Where("`name` = ?", name).First(&snapshot).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (s *Gorm) List(offset, limit int) ([]*snapshots.SnapshotList, int, error) {
+	snapshotsList, total := make([]*snapshots.SnapshotList, 0), 0
+	qb := s.scoped(s.db.Model(&snapshotsList))
+	if err := qb.Count(&total).Error; err != nil {
+		return snapshotsList, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("createdAt desc").Find(&snapshotsList).Error
+	return snapshotsList, total, err
+}
+
+func (s *Gorm) Create(snapshot *snapshots.Snapshot) error {
+	snapshot.ProjectId = s.projectId
+	return s.db.Create(snapshot).Error
+}
+
+func (s *Gorm) Delete(snapshot *snapshots.Snapshot) error {
+	res := s.scoped(s.db).Delete(snapshot)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[snapshots.store.gorm] snapshot with id = %d was not deleted", snapshot.ID)
+	}
+	return nil
+}
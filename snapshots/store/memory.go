@@ -0,0 +1,134 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger    logger.Logger
+	projectId uint64
+	// Records of all projects are shared by the stores returned by ForProject
+	*memoryRecords
+}
+
+type memoryRecords struct {
+	records []*snapshots.Snapshot
+	lastId  uint64
+	mu      *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+	// ProjectId is the project of the snapshots, the default project when zero
+	ProjectId uint64
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	s := &Memory{
+		logger:    c.Logger,
+		projectId: c.ProjectId,
+		memoryRecords: &memoryRecords{
+			records: make([]*snapshots.Snapshot, 0),
+			mu:      &sync.Mutex{},
+		},
+	}
+	if s.projectId < 1 {
+		s.projectId = projects.DefaultId
+	}
+	return s
+}
+
+func (s *Memory) ForProject(projectId uint64) snapshots.Store {
+	return &Memory{
+		logger:        s.logger,
+		projectId:     projectId,
+		memoryRecords: s.memoryRecords,
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*snapshots.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id && el.ProjectId == s.projectId {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) GetByName(name string) (*snapshots.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.Name == name && el.ProjectId == s.projectId {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int) ([]*snapshots.SnapshotList, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snapshotsList := make([]*snapshots.SnapshotList, 0, len(s.records))
+	// Newest first, like the gorm store
+	for i := len(s.records) - 1; i >= 0; i-- {
+		el := s.records[i]
+		if el.ProjectId != s.projectId {
+			continue
+		}
+		snapshotsList = append(snapshotsList, &snapshots.SnapshotList{
+			ID:          el.ID,
+			ProjectId:   el.ProjectId,
+			Name:        el.Name,
+			Description: el.Description,
+			CreatedAt:   el.CreatedAt,
+		})
+	}
+	total := len(snapshotsList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return snapshotsList[offset : offset+l], total, nil
+}
+
+func (s *Memory) Create(snapshot *snapshots.Snapshot) error {
+	// Serialize and restore the events to freeze them like the gorm store does
+	if err := snapshot.BeforeSave(); err != nil {
+		return err
+	}
+	if err := snapshot.AfterFind(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.lastId++
+	snapshot.ID = s.lastId
+	snapshot.ProjectId = s.projectId
+	snapshot.CreatedAt = time.Now()
+	s.records = append(s.records, snapshot)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Delete(snapshot *snapshots.Snapshot) error {
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == snapshot.ID && el.ProjectId == s.projectId {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/snapshots"
+	"github.com/nskondratev/api-page-go-back/testutils"
+	"testing"
+)
+
+func TestMemory_Create(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	labels, err := testutils.NewArrayNullStringFromStrings([]string{"Label 1"})
+
+	if err != nil {
+		t.Fatalf("Can not create NullString labels from strings: %s", err.Error())
+	}
+
+	liveEvent := &events.Event{ID: 1, Constant: "Constant 1", Label: labels[0], Value: "Value 1"}
+
+	cases := []testutils.MemoryCreateTestCase{
+		{ItemToCreate: &snapshots.Snapshot{Name: "v1", Events: []*events.Event{liveEvent}}, TotalRows: 1, LastItemID: 1},
+		{ItemToCreate: &snapshots.Snapshot{Name: "v2", Events: []*events.Event{liveEvent}}, TotalRows: 2, LastItemID: 2},
+	}
+
+	for caseNum, item := range cases {
+		snapshotToCreate, ok := item.ItemToCreate.(*snapshots.Snapshot)
+
+		if !ok {
+			t.Errorf("[%d] Can not convert test case item to create to *snapshots.Snapshot type", caseNum)
+		}
+
+		if err := s.Create(snapshotToCreate); err != nil {
+			t.Errorf("[%d] error while creating snapshot %+v", caseNum, snapshotToCreate)
+		}
+
+		if len(s.records) != item.TotalRows {
+			t.Errorf("[%d] total rows mismatch. Want %d, received %d", caseNum, item.TotalRows, len(s.records))
+		}
+
+		if s.records[len(s.records)-1].ID != item.LastItemID {
+			t.Errorf("[%d] last snapshot id mismatch. Want %d, received %d", caseNum, item.LastItemID, s.records[len(s.records)-1].ID)
+		}
+	}
+
+	// Changes of the live catalog must not leak into the frozen copy
+	liveEvent.Value = "Value 1 updated"
+
+	for _, snapshot := range s.records {
+		if snapshot.Events[0].Value != "Value 1" {
+			t.Errorf("snapshot %s was not frozen: %+v", snapshot.Name, snapshot.Events[0])
+		}
+	}
+}
+
+func TestMemory_GetByName(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	if err := s.Create(&snapshots.Snapshot{Name: "release-1"}); err != nil {
+		t.Fatalf("Can not create test snapshot: %s", err.Error())
+	}
+
+	cases := []struct {
+		name       string
+		expectedID uint64
+	}{
+		{"release-1", 1},
+		{"release-2", 0},
+	}
+
+	for caseNum, item := range cases {
+		snapshot, err := s.GetByName(item.name)
+		if err != nil {
+			t.Errorf("[%d] error while getting snapshot: %s", caseNum, err.Error())
+		}
+
+		if item.expectedID == 0 && snapshot != nil {
+			t.Errorf("[%d] snapshot should not exist, received %+v", caseNum, snapshot)
+		}
+
+		if item.expectedID > 0 && (snapshot == nil || snapshot.ID != item.expectedID) {
+			t.Errorf("[%d] snapshot mismatch. Want id %d, received %+v", caseNum, item.expectedID, snapshot)
+		}
+	}
+}
+
+func TestMemory_ListAndDelete(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+
+	for _, name := range []string{"v1", "v2", "v3"} {
+		if err := s.Create(&snapshots.Snapshot{Name: name}); err != nil {
+			t.Fatalf("Can not create test snapshot: %s", err.Error())
+		}
+	}
+
+	if err := s.Delete(&snapshots.Snapshot{ID: 2}); err != nil {
+		t.Fatalf("Can not delete test snapshot: %s", err.Error())
+	}
+
+	list, total, err := s.List(0, 10)
+
+	if err != nil {
+		t.Fatalf("error while fetching list: %s", err.Error())
+	}
+
+	if total != 2 || len(list) != 2 {
+		t.Fatalf("total mismatch. Want 2, received %d (%d items)", total, len(list))
+	}
+
+	if list[0].Name != "v3" || list[1].Name != "v1" {
+		t.Errorf("list order mismatch. Want [v3 v1], received [%s %s]", list[0].Name, list[1].Name)
+	}
+}
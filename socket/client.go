@@ -0,0 +1,163 @@
+package socket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	// Time allowed to write a message to the peer.
+	writeWait = 10 * time.Second
+
+	// Time allowed to establish the connection.
+	handshakeTimeout = 10 * time.Second
+)
+
+// Conn is a client connection to the real-time API. Engine.IO pings and the
+// handshake are handled by the connection, Read returns events and acks only.
+type Conn struct {
+	ws    *websocket.Conn
+	codec Codec
+	mu    *sync.Mutex
+	done  chan struct{}
+	once  *sync.Once
+}
+
+type openPayload struct {
+	PingInterval int64 `json:"pingInterval"`
+}
+
+// TargetURL converts the address of the API to the websocket URL expected by the codec.
+// Socket.io addresses without a path get the default /socket.io/ path and the Engine.IO query.
+func TargetURL(target string, codec Codec) (string, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return "", err
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return "", fmt.Errorf("unsupported target scheme: %s", u.Scheme)
+	}
+	if len(u.Host) < 1 {
+		return "", errors.New("target host is empty")
+	}
+	if codec.Name() == CodecSocketIO {
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/socket.io/"
+		}
+		q := u.Query()
+		if len(q.Get("EIO")) < 1 {
+			q.Set("EIO", "3")
+		}
+		if len(q.Get("transport")) < 1 {
+			q.Set("transport", "websocket")
+		}
+		u.RawQuery = q.Encode()
+	}
+	return u.String(), nil
+}
+
+func Dial(target string, codec Codec, header http.Header) (*Conn, error) {
+	u, err := TargetURL(target, codec)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &websocket.Dialer{HandshakeTimeout: handshakeTimeout}
+	ws, _, err := dialer.Dial(u, header)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{ws: ws, codec: codec, mu: &sync.Mutex{}, done: make(chan struct{}), once: &sync.Once{}}, nil
+}
+
+func (c *Conn) Codec() Codec {
+	return c.codec
+}
+
+// Send encodes the frame and writes it to the connection. It is safe for concurrent use.
+func (c *Conn) Send(f *Frame) error {
+	msg, err := c.codec.Encode(f)
+	if err != nil {
+		return err
+	}
+	return c.write(msg)
+}
+
+// Read returns the next event or ack frame along with the raw message.
+// io.EOF is returned when the peer closes the connection.
+func (c *Conn) Read() (*Frame, []byte, error) {
+	for {
+		_, msg, err := c.ws.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil, nil, io.EOF
+			}
+			return nil, nil, err
+		}
+		f, err := c.codec.Decode(msg)
+		if err != nil {
+			return nil, msg, err
+		}
+		switch f.Kind {
+		case FrameEvent, FrameAck:
+			return f, msg, nil
+		case FrameClose:
+			return nil, msg, io.EOF
+		case FrameOpen:
+			p := &openPayload{}
+			if err := json.Unmarshal(f.Data, p); err == nil && p.PingInterval > 0 {
+				go c.pingPump(time.Duration(p.PingInterval) * time.Millisecond)
+			}
+		case FramePing:
+			if err := c.Send(&Frame{Kind: FramePong, AckId: -1}); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+}
+
+func (c *Conn) Close() error {
+	c.once.Do(func() {
+		close(c.done)
+	})
+	c.mu.Lock()
+	c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	c.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	c.mu.Unlock()
+	return c.ws.Close()
+}
+
+func (c *Conn) write(msg []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.ws.WriteMessage(websocket.TextMessage, msg)
+}
+
+// pingPump keeps the Engine.IO session alive, Socket.io v2 servers expect the client to ping.
+func (c *Conn) pingPump(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.Send(&Frame{Kind: FramePing, AckId: -1}); err != nil {
+				return
+			}
+		}
+	}
+}
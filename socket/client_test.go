@@ -0,0 +1,30 @@
+package socket
+
+import "testing"
+
+func TestTargetURL(t *testing.T) {
+	testCases := []struct {
+		target   string
+		codec    Codec
+		expected string
+	}{
+		{"http://localhost:3000", &SocketIOCodec{}, "ws://localhost:3000/socket.io/?EIO=3&transport=websocket"},
+		{"https://api.example.com/rt/?token=1", &SocketIOCodec{}, "wss://api.example.com/rt/?EIO=3&token=1&transport=websocket"},
+		{"ws://localhost:3000/ws", &JSONCodec{}, "ws://localhost:3000/ws"},
+	}
+	for _, tc := range testCases {
+		u, err := TargetURL(tc.target, tc.codec)
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %s", tc.target, err.Error())
+			continue
+		}
+		if u != tc.expected {
+			t.Errorf("Unexpected URL. Expected: %s, actual: %s", tc.expected, u)
+		}
+	}
+	for _, target := range []string{"ftp://localhost", "ws://"} {
+		if _, err := TargetURL(target, &JSONCodec{}); err == nil {
+			t.Errorf("Expected error for %s", target)
+		}
+	}
+}
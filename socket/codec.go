@@ -0,0 +1,249 @@
+package socket
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Codec names
+const (
+	CodecSocketIO = "socketio"
+	CodecJSON     = "json"
+)
+
+// Kinds of frames
+const (
+	FrameEvent   = "event"
+	FrameAck     = "ack"
+	FrameOpen    = "open"
+	FrameConnect = "connect"
+	FramePing    = "ping"
+	FramePong    = "pong"
+	FrameClose   = "close"
+	FrameOther   = "other"
+)
+
+// Frame is a decoded message of the real-time API.
+type Frame struct {
+	Kind  string          `json:"kind"`
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	// AckId is the id of the acknowledgement requested by an event or answered by an ack, -1 if none.
+	AckId int64 `json:"ackId"`
+}
+
+func NewEventFrame(event string, data json.RawMessage) *Frame {
+	return &Frame{Kind: FrameEvent, Event: event, Data: data, AckId: -1}
+}
+
+func NewAckFrame(ackId int64, data json.RawMessage) *Frame {
+	return &Frame{Kind: FrameAck, Data: data, AckId: ackId}
+}
+
+func (f *Frame) HasAck() bool {
+	return f.AckId >= 0
+}
+
+// Codec converts frames to the wire format and back.
+type Codec interface {
+	Name() string
+	Encode(*Frame) ([]byte, error)
+	Decode([]byte) (*Frame, error)
+}
+
+func NewCodec(name string) (Codec, error) {
+	switch name {
+	case CodecSocketIO, "":
+		return &SocketIOCodec{}, nil
+	case CodecJSON:
+		return &JSONCodec{}, nil
+	}
+	return nil, fmt.Errorf("unknown codec: %s", name)
+}
+
+// SocketIOCodec speaks the Socket.io v2 protocol over the Engine.IO v3 websocket transport,
+// e.g. 42["event",{"key":"value"}] or 431[{"ok":true}].
+type SocketIOCodec struct{}
+
+func (c *SocketIOCodec) Name() string {
+	return CodecSocketIO
+}
+
+func (c *SocketIOCodec) Encode(f *Frame) ([]byte, error) {
+	switch f.Kind {
+	case FrameOpen:
+		return append([]byte{'0'}, f.Data...), nil
+	case FrameConnect:
+		return []byte("40"), nil
+	case FrameClose:
+		return []byte("41"), nil
+	case FramePing:
+		return []byte("2"), nil
+	case FramePong:
+		return []byte("3"), nil
+	case FrameEvent, FrameAck:
+		b := &bytes.Buffer{}
+		if f.Kind == FrameEvent {
+			b.WriteString("42")
+		} else {
+			b.WriteString("43")
+		}
+		if f.HasAck() {
+			b.WriteString(strconv.FormatInt(f.AckId, 10))
+		}
+		args := make([]json.RawMessage, 0, 2)
+		if f.Kind == FrameEvent {
+			name, err := json.Marshal(f.Event)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, name)
+		}
+		if len(f.Data) > 0 {
+			args = append(args, f.Data)
+		}
+		data, err := json.Marshal(args)
+		if err != nil {
+			return nil, err
+		}
+		b.Write(data)
+		return b.Bytes(), nil
+	}
+	return nil, fmt.Errorf("socketio: can not encode %s frame", f.Kind)
+}
+
+func (c *SocketIOCodec) Decode(msg []byte) (*Frame, error) {
+	if len(msg) < 1 {
+		return nil, errors.New("socketio: empty message")
+	}
+	f := &Frame{Kind: FrameOther, AckId: -1}
+	switch msg[0] {
+	case '0':
+		f.Kind = FrameOpen
+		f.Data = json.RawMessage(msg[1:])
+		return f, nil
+	case '1':
+		f.Kind = FrameClose
+		return f, nil
+	case '2':
+		f.Kind = FramePing
+		return f, nil
+	case '3':
+		f.Kind = FramePong
+		return f, nil
+	case '4':
+	default:
+		return f, nil
+	}
+	if len(msg) < 2 {
+		return nil, errors.New("socketio: message packet without type")
+	}
+	rest := msg[2:]
+	// Skip the namespace, e.g. 42/chat,["event"]
+	if len(rest) > 0 && rest[0] == '/' {
+		if idx := bytes.IndexByte(rest, ','); idx >= 0 {
+			rest = rest[idx+1:]
+		} else {
+			rest = rest[len(rest):]
+		}
+	}
+	digits := 0
+	for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 {
+		id, err := strconv.ParseInt(string(rest[:digits]), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		f.AckId = id
+		rest = rest[digits:]
+	}
+	switch msg[1] {
+	case '0':
+		f.Kind = FrameConnect
+		return f, nil
+	case '1':
+		f.Kind = FrameClose
+		return f, nil
+	case '2', '3':
+	default:
+		return f, nil
+	}
+	var args []json.RawMessage
+	if err := json.Unmarshal(rest, &args); err != nil {
+		return nil, fmt.Errorf("socketio: invalid packet arguments: %s", err.Error())
+	}
+	if msg[1] == '3' {
+		f.Kind = FrameAck
+		if len(args) > 0 {
+			f.Data = args[0]
+		}
+		return f, nil
+	}
+	f.Kind = FrameEvent
+	if len(args) < 1 || json.Unmarshal(args[0], &f.Event) != nil {
+		return nil, errors.New("socketio: event packet without name")
+	}
+	if len(args) > 1 {
+		f.Data = args[1]
+	}
+	return f, nil
+}
+
+// JSONCodec is used for plain WebSocket APIs exchanging {"event":"name","data":{},"ackId":1} objects.
+type JSONCodec struct{}
+
+type jsonFrame struct {
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data,omitempty"`
+	AckId *int64          `json:"ackId,omitempty"`
+	Ack   bool            `json:"ack,omitempty"`
+}
+
+func (c *JSONCodec) Name() string {
+	return CodecJSON
+}
+
+func (c *JSONCodec) Encode(f *Frame) ([]byte, error) {
+	jf := &jsonFrame{Event: f.Event, Data: f.Data}
+	switch f.Kind {
+	case FrameEvent:
+	case FrameAck:
+		jf.Ack = true
+	case FramePing, FramePong:
+		jf.Event = f.Kind
+	default:
+		return nil, fmt.Errorf("json: can not encode %s frame", f.Kind)
+	}
+	if f.HasAck() {
+		id := f.AckId
+		jf.AckId = &id
+	}
+	return json.Marshal(jf)
+}
+
+func (c *JSONCodec) Decode(msg []byte) (*Frame, error) {
+	jf := &jsonFrame{}
+	if err := json.Unmarshal(msg, jf); err != nil {
+		return nil, fmt.Errorf("json: invalid message: %s", err.Error())
+	}
+	f := &Frame{Kind: FrameEvent, Event: jf.Event, Data: jf.Data, AckId: -1}
+	if jf.AckId != nil {
+		f.AckId = *jf.AckId
+	}
+	switch {
+	case jf.Ack:
+		f.Kind = FrameAck
+		f.Event = ""
+	case jf.Event == FramePing || jf.Event == FramePong:
+		f.Kind = jf.Event
+		f.Event = ""
+	case len(jf.Event) < 1:
+		return nil, errors.New("json: message without event name")
+	}
+	return f, nil
+}
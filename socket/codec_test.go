@@ -0,0 +1,83 @@
+package socket
+
+import (
+	"testing"
+)
+
+func TestSocketIOCodec_Decode(t *testing.T) {
+	testCases := []struct {
+		in    string
+		kind  string
+		event string
+		data  string
+		ackId int64
+	}{
+		{`2`, FramePing, ``, ``, -1},
+		{`40`, FrameConnect, ``, ``, -1},
+		{`42["login",{"name":"John"}]`, FrameEvent, `login`, `{"name":"John"}`, -1},
+		{`4212["login",{"name":"John"}]`, FrameEvent, `login`, `{"name":"John"}`, 12},
+		{`42/chat,3["message","hi"]`, FrameEvent, `message`, `"hi"`, 3},
+		{`42["logout"]`, FrameEvent, `logout`, ``, -1},
+		{`437[{"ok":true}]`, FrameAck, ``, `{"ok":true}`, 7},
+	}
+	c := &SocketIOCodec{}
+	for _, tc := range testCases {
+		f, err := c.Decode([]byte(tc.in))
+		if err != nil {
+			t.Errorf("Unexpected error while decoding %s: %s", tc.in, err.Error())
+			continue
+		}
+		if f.Kind != tc.kind || f.Event != tc.event || string(f.Data) != tc.data || f.AckId != tc.ackId {
+			t.Errorf("Unexpected frame decoded from %s: %+v", tc.in, f)
+		}
+	}
+	for _, in := range []string{``, `42`, `42{}`, `42[1]`} {
+		if _, err := c.Decode([]byte(in)); err == nil {
+			t.Errorf("Expected error while decoding %s", in)
+		}
+	}
+}
+
+func TestSocketIOCodec_Encode(t *testing.T) {
+	testCases := []struct {
+		frame    *Frame
+		expected string
+	}{
+		{NewEventFrame("login", []byte(`{"name":"John"}`)), `42["login",{"name":"John"}]`},
+		{&Frame{Kind: FrameEvent, Event: "login", AckId: 4}, `424["login"]`},
+		{NewAckFrame(7, []byte(`{"ok":true}`)), `437[{"ok":true}]`},
+		{&Frame{Kind: FramePong, AckId: -1}, `3`},
+	}
+	c := &SocketIOCodec{}
+	for _, tc := range testCases {
+		msg, err := c.Encode(tc.frame)
+		if err != nil {
+			t.Errorf("Unexpected error while encoding %+v: %s", tc.frame, err.Error())
+			continue
+		}
+		if string(msg) != tc.expected {
+			t.Errorf("Unexpected message. Expected: %s, actual: %s", tc.expected, msg)
+		}
+	}
+}
+
+func TestJSONCodec(t *testing.T) {
+	c := &JSONCodec{}
+	f, err := c.Decode([]byte(`{"event":"login","data":{"name":"John"},"ackId":2}`))
+	if err != nil {
+		t.Fatalf("Unexpected error while decoding: %s", err.Error())
+	}
+	if f.Kind != FrameEvent || f.Event != "login" || string(f.Data) != `{"name":"John"}` || f.AckId != 2 {
+		t.Errorf("Unexpected frame: %+v", f)
+	}
+	msg, err := c.Encode(NewAckFrame(2, []byte(`{"ok":true}`)))
+	if err != nil {
+		t.Fatalf("Unexpected error while encoding: %s", err.Error())
+	}
+	if expected := `{"data":{"ok":true},"ackId":2,"ack":true}`; string(msg) != expected {
+		t.Errorf("Unexpected message. Expected: %s, actual: %s", expected, msg)
+	}
+	if _, err := c.Decode([]byte(`{"data":{}}`)); err == nil {
+		t.Error("Expected error while decoding message without event")
+	}
+}
@@ -0,0 +1,34 @@
+package socket
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AnyHost allows every host when listed in AllowedHosts.
+const AnyHost = "*"
+
+// AllowedHosts restricts the targets dialed on behalf of the users. Hosts are matched with or
+// without the port. Empty list denies every host, AnyHost allows every host.
+type AllowedHosts []string
+
+// Check rejects the target when its host is not allowed.
+func (hosts AllowedHosts) Check(target string) error {
+	if len(target) < 1 {
+		return fmt.Errorf("target is not set")
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	if len(u.Host) < 1 {
+		return fmt.Errorf("target has no host: %s", target)
+	}
+	for _, host := range hosts {
+		if host == AnyHost || strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return nil
+		}
+	}
+	return fmt.Errorf("target host is not allowed: %s", u.Host)
+}
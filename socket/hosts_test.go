@@ -0,0 +1,28 @@
+package socket
+
+import (
+	"testing"
+)
+
+func TestAllowedHosts_Check(t *testing.T) {
+	testCases := []struct {
+		hosts   AllowedHosts
+		target  string
+		allowed bool
+	}{
+		{AllowedHosts{"staging.example.com", "localhost:3000"}, "", false},
+		{AllowedHosts{"staging.example.com", "localhost:3000"}, "wss://staging.example.com/socket.io/", true},
+		{AllowedHosts{"staging.example.com", "localhost:3000"}, "ws://STAGING.example.com:8080", true},
+		{AllowedHosts{"staging.example.com", "localhost:3000"}, "ws://localhost:3000", true},
+		{AllowedHosts{"staging.example.com", "localhost:3000"}, "ws://localhost:3001", false},
+		{AllowedHosts{"staging.example.com", "localhost:3000"}, "ws://internal.example.com", false},
+		{AllowedHosts{"staging.example.com"}, "staging.example.com", false},
+		{nil, "ws://staging.example.com", false},
+		{AllowedHosts{AnyHost}, "ws://internal.example.com", true},
+	}
+	for _, tc := range testCases {
+		if err := tc.hosts.Check(tc.target); (err == nil) != tc.allowed {
+			t.Errorf("Unexpected check result of %s with %v: %v", tc.target, tc.hosts, err)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/util"
+	"strings"
+	"time"
+)
+
+// APITokenPrefix starts every API token, it tells them apart from the access tokens of sessions
+const APITokenPrefix = "apt_"
+
+// Kinds of API tokens
+const (
+	// APITokenPersonal acts on behalf of its owner, limited by the role of the owner
+	APITokenPersonal = "personal"
+	// APITokenService is not tied to a user and is managed by admins
+	APITokenService = "service"
+)
+
+// Scopes of API tokens
+const (
+	ScopeCatalogRead = "catalog.read"
+	ScopeEventsWrite = "events.write"
+	ScopeTestsRun    = "tests.run"
+)
+
+var scopePermissions = map[string][]Permission{
+	ScopeCatalogRead: {PermPagesRead, PermEventsRead, PermTestsRead, PermProjectsRead},
+	ScopeEventsWrite: {PermEventsWrite},
+	ScopeTestsRun:    {PermTestsRun},
+}
+
+// lastUsedPrecision limits the writes of the last use of a token to one a minute
+const lastUsedPrecision = time.Minute
+
+// ValidScope reports whether the scope is known.
+func ValidScope(scope string) bool {
+	_, ok := scopePermissions[scope]
+	return ok
+}
+
+// APIToken authenticates scripts and CI jobs. The token is shown once on creation, only the SHA-256 hash
+// of it is stored along with the prefix used to recognize the token in lists.
+type APIToken struct {
+	ID   uint64 `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	Name string `json:"name" gorm:"size:255;column:name"`
+	Kind string `json:"kind" gorm:"size:16;column:kind"`
+	// UserId is the owner of a personal token, zero for service tokens
+	UserId uint64 `json:"userId" gorm:"index;column:userId"`
+	// CreatedBy is the user who created the token
+	CreatedBy  uint64          `json:"createdBy" gorm:"column:createdBy"`
+	Scopes     util.StringList `json:"scopes" gorm:"type:text;column:scopes"`
+	Prefix     string          `json:"prefix" gorm:"size:12;column:prefix"`
+	TokenHash  string          `json:"-" gorm:"size:64;unique_index;column:tokenHash"`
+	ExpiresAt  *time.Time      `json:"expiresAt" gorm:"column:expiresAt"`
+	LastUsedAt *time.Time      `json:"lastUsedAt" gorm:"column:lastUsedAt"`
+	RevokedAt  *time.Time      `json:"revokedAt" gorm:"column:revokedAt"`
+	CreatedAt  time.Time       `json:"createdAt" gorm:"column:createdAt"`
+}
+
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+// NewAPIToken returns the token along with the record keeping its hash.
+func NewAPIToken(name, kind string, scopes []string) (string, *APIToken, error) {
+	for _, scope := range scopes {
+		if !ValidScope(scope) {
+			return "", nil, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	secret, err := randomToken()
+	if err != nil {
+		return "", nil, err
+	}
+	token := APITokenPrefix + secret
+	return token, &APIToken{
+		Name:      name,
+		Kind:      kind,
+		Scopes:    scopes,
+		Prefix:    token[:12],
+		TokenHash: HashToken(token),
+	}, nil
+}
+
+// Active reports whether the token can be used at the moment.
+func (t *APIToken) Active(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// Permissions returns the permissions granted by the scopes of the token.
+func (t *APIToken) Permissions() []Permission {
+	perms := make([]Permission, 0)
+	for _, scope := range t.Scopes {
+		perms = append(perms, scopePermissions[scope]...)
+	}
+	return perms
+}
+
+// Grants returns the grants of the token. Personal tokens get the permissions of the owner limited
+// by the scopes, service tokens the permissions of the scopes.
+func (t *APIToken) Grants(owner *User, overrides []*ProjectRole) *Grants {
+	var g *Grants
+	if owner != nil {
+		g = NewGrants(owner, overrides)
+	} else {
+		g = &Grants{Role: RoleAdmin}
+	}
+	g.Scopes = t.Permissions()
+	return g
+}
+
+// AuthenticateAPIToken returns the API token and the owner of a personal token. The last use of the token is
+// recorded.
+func (i *Issuer) AuthenticateAPIToken(token string) (*APIToken, *User, error) {
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return nil, nil, ErrInvalidToken
+	}
+	t, err := i.store.GetAPITokenByHash(HashToken(token))
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	if t == nil || !t.Active(now) {
+		return nil, nil, ErrInvalidToken
+	}
+	var owner *User
+	if t.Kind == APITokenPersonal {
+		if owner, err = i.store.GetById(t.UserId); err != nil {
+			return nil, nil, err
+		}
+		if owner == nil {
+			return nil, nil, ErrInvalidToken
+		}
+	}
+	if t.LastUsedAt == nil || now.Sub(*t.LastUsedAt) >= lastUsedPrecision {
+		if err := i.store.TouchAPIToken(t.ID, now); err != nil {
+			return nil, nil, err
+		}
+		t.LastUsedAt = &now
+	}
+	return t, owner, nil
+}
+
+type apiTokenContextKey struct{}
+
+// WithAPIToken returns the copy of the context carrying the API token of the request.
+func WithAPIToken(ctx context.Context, t *APIToken) context.Context {
+	return context.WithValue(ctx, apiTokenContextKey{}, t)
+}
+
+// APITokenFromContext returns the API token carried by the context, nil for sessions and anonymous requests.
+func APITokenFromContext(ctx context.Context) *APIToken {
+	if ctx == nil {
+		return nil
+	}
+	t, _ := ctx.Value(apiTokenContextKey{}).(*APIToken)
+	return t
+}
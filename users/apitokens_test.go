@@ -0,0 +1,75 @@
+package users_test
+
+import (
+	"github.com/nskondratev/api-page-go-back/users"
+	"github.com/nskondratev/api-page-go-back/users/store"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIssuer_AuthenticateAPIToken(t *testing.T) {
+	s := store.NewMemory(&store.MemoryConfig{})
+	u := &users.User{Email: "john@example.com", Name: "John", Role: users.RoleEditor}
+	if err := s.Create(u); err != nil {
+		t.Fatalf("Can not create test user: %s", err.Error())
+	}
+	issuer, err := users.NewIssuer(&users.IssuerConfig{Store: s, Secret: "test"})
+	if err != nil {
+		t.Fatalf("Can not create issuer: %s", err.Error())
+	}
+
+	if _, _, err := users.NewAPIToken("ci", users.APITokenPersonal, []string{"pages.delete"}); err == nil {
+		t.Errorf("Unknown scope is accepted")
+	}
+	plain, token, err := users.NewAPIToken("ci", users.APITokenPersonal, []string{users.ScopeCatalogRead, users.ScopeEventsWrite})
+	if err != nil {
+		t.Fatalf("Can not create API token: %s", err.Error())
+	}
+	if !strings.HasPrefix(plain, users.APITokenPrefix) || !strings.HasPrefix(plain, token.Prefix) || token.TokenHash == plain {
+		t.Errorf("Unexpected API token %s: %+v", plain, token)
+	}
+	token.UserId = u.ID
+	if err := s.CreateAPIToken(token); err != nil {
+		t.Fatalf("Can not store API token: %s", err.Error())
+	}
+
+	authenticated, owner, err := issuer.AuthenticateAPIToken(plain)
+	if err != nil || authenticated.ID != token.ID || owner == nil || owner.ID != u.ID {
+		t.Fatalf("Unexpected API token authentication: %+v, %+v, %v", authenticated, owner, err)
+	}
+	if stored, _ := s.GetAPIToken(token.ID); stored.LastUsedAt == nil {
+		t.Errorf("Last use of API token is not recorded")
+	}
+
+	g := authenticated.Grants(owner, nil)
+	if err := g.Check(0, users.PermEventsWrite); err != nil {
+		t.Errorf("Scope of API token is rejected: %s", err.Error())
+	}
+	if err := g.Check(0, users.PermPagesWrite); err == nil {
+		t.Errorf("Permission of the owner beyond the scopes is allowed")
+	}
+
+	service := &users.APIToken{Kind: users.APITokenService, Scopes: []string{users.ScopeTestsRun}}
+	if err := service.Grants(nil, nil).Check(0, users.PermTestsRun); err != nil {
+		t.Errorf("Scope of service token is rejected: %s", err.Error())
+	}
+	if err := service.Grants(nil, nil).Check(0, users.PermUsersManage); err == nil {
+		t.Errorf("Service token is allowed beyond its scopes")
+	}
+
+	if _, _, err := issuer.AuthenticateAPIToken(users.APITokenPrefix + "unknown"); err != users.ErrInvalidToken {
+		t.Errorf("Unknown API token is accepted: %v", err)
+	}
+	if err := s.RevokeAPIToken(token.ID, time.Now()); err != nil {
+		t.Fatalf("Can not revoke API token: %s", err.Error())
+	}
+	if _, _, err := issuer.AuthenticateAPIToken(plain); err != users.ErrInvalidToken {
+		t.Errorf("Revoked API token is accepted: %v", err)
+	}
+
+	expiresAt := time.Now().Add(-time.Second)
+	if !(&users.APIToken{}).Active(time.Now()) || (&users.APIToken{ExpiresAt: &expiresAt}).Active(time.Now()) {
+		t.Errorf("Unexpected expiry of API tokens")
+	}
+}
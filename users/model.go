@@ -0,0 +1,73 @@
+package users
+
+import (
+	"context"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+// User is an account allowed to change the catalog. Only the bcrypt hash of the password is stored.
+type User struct {
+	ID    uint64 `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	Email string `json:"email" gorm:"size:255;unique_index;column:email"`
+	Name  string `json:"name" gorm:"size:255;column:name"`
+	// Role applies to all projects unless the project overrides it
+	Role         string    `json:"role" gorm:"size:16;default:'reader';column:role"`
+	PasswordHash string    `json:"-" gorm:"size:60;column:passwordHash"`
+	CreatedAt    time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
+
+// SetPassword replaces the password hash of the user.
+func (u *User) SetPassword(password string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+// CheckPassword reports whether the password matches the stored hash.
+func (u *User) CheckPassword(password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// RefreshToken is issued on login and exchanged for new tokens until it expires or the user logs out.
+// Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID        uint64    `json:"id" gorm:"AUTO_INCREMENT;primary_key"`
+	UserId    uint64    `json:"userId" gorm:"index;column:userId"`
+	TokenHash string    `json:"-" gorm:"size:64;unique_index;column:tokenHash"`
+	ExpiresAt time.Time `json:"expiresAt" gorm:"column:expiresAt"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// Expired reports whether the token can not be used at the moment.
+func (t *RefreshToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+type contextKey struct{}
+
+// NewContext returns the copy of the context carrying the authenticated user.
+func NewContext(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, u)
+}
+
+// FromContext returns the user carried by the context, nil for anonymous requests.
+func FromContext(ctx context.Context) *User {
+	if ctx == nil {
+		return nil
+	}
+	u, _ := ctx.Value(contextKey{}).(*User)
+	return u
+}
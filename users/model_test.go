@@ -0,0 +1,79 @@
+package users_test
+
+import (
+	"github.com/nskondratev/api-page-go-back/users"
+	"github.com/nskondratev/api-page-go-back/users/store"
+	"testing"
+	"time"
+)
+
+func TestUser_CheckPassword(t *testing.T) {
+	u := &users.User{}
+	if err := u.SetPassword("secret-password"); err != nil {
+		t.Fatalf("Can not set password: %s", err.Error())
+	}
+	if u.PasswordHash == "secret-password" {
+		t.Errorf("Password is stored in plain text")
+	}
+	if !u.CheckPassword("secret-password") {
+		t.Errorf("Valid password is rejected")
+	}
+	if u.CheckPassword("wrong-password") {
+		t.Errorf("Invalid password is accepted")
+	}
+}
+
+func TestIssuer(t *testing.T) {
+	s := store.NewMemory(&store.MemoryConfig{})
+	u := &users.User{Email: "john@example.com", Name: "John"}
+	if err := s.Create(u); err != nil {
+		t.Fatalf("Can not create test user: %s", err.Error())
+	}
+	issuer, err := users.NewIssuer(&users.IssuerConfig{Store: s, Secret: "test"})
+	if err != nil {
+		t.Fatalf("Can not create issuer: %s", err.Error())
+	}
+
+	tokens, err := issuer.Issue(u)
+	if err != nil {
+		t.Fatalf("Can not issue tokens: %s", err.Error())
+	}
+	if authenticated, err := issuer.Authenticate(tokens.AccessToken); err != nil || authenticated.ID != u.ID {
+		t.Errorf("Unexpected user of the access token: %+v, %v", authenticated, err)
+	}
+
+	refreshed, err := issuer.Refresh(tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Can not refresh tokens: %s", err.Error())
+	}
+	if _, err := issuer.Refresh(tokens.RefreshToken); err != users.ErrInvalidToken {
+		t.Errorf("Used refresh token is accepted: %v", err)
+	}
+	if err := issuer.Revoke(refreshed.RefreshToken); err != nil {
+		t.Fatalf("Can not revoke refresh token: %s", err.Error())
+	}
+	if _, err := issuer.Refresh(refreshed.RefreshToken); err != users.ErrInvalidToken {
+		t.Errorf("Revoked refresh token is accepted: %v", err)
+	}
+
+	other, _ := users.NewIssuer(&users.IssuerConfig{Store: s, Secret: "other"})
+	if _, err := other.Authenticate(tokens.AccessToken); err != users.ErrInvalidToken {
+		t.Errorf("Token signed with another secret is accepted: %v", err)
+	}
+	expiring, _ := users.NewIssuer(&users.IssuerConfig{Store: s, Secret: "test", AccessTTL: time.Nanosecond})
+	expired, err := expiring.Issue(u)
+	if err != nil {
+		t.Fatalf("Can not issue tokens: %s", err.Error())
+	}
+	time.Sleep(1100 * time.Millisecond)
+	if _, err := issuer.Authenticate(expired.AccessToken); err != users.ErrInvalidToken {
+		t.Errorf("Expired token is accepted: %v", err)
+	}
+
+	if err := s.Delete(u); err != nil {
+		t.Fatalf("Can not delete test user: %s", err.Error())
+	}
+	if _, err := issuer.Authenticate(tokens.AccessToken); err != users.ErrInvalidToken {
+		t.Errorf("Token of deleted user is accepted: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Permission allows a kind of requests
+type Permission string
+
+// Permissions checked by the routes, the GraphQL resolvers and the ws commands
+const (
+	PermPagesRead      Permission = "pages.read"
+	PermPagesWrite     Permission = "pages.write"
+	PermEventsRead     Permission = "events.read"
+	PermEventsWrite    Permission = "events.write"
+	PermTestsRead      Permission = "tests.read"
+	PermTestsWrite     Permission = "tests.write"
+	PermTestsRun       Permission = "tests.run"
+	PermProjectsRead   Permission = "projects.read"
+	PermProjectsManage Permission = "projects.manage"
+	PermUsersManage    Permission = "users.manage"
+	PermAuditRead      Permission = "audit.read"
+)
+
+// Roles of users
+const (
+	// RoleReader reads the catalog and the tests
+	RoleReader = "reader"
+	// RoleEditor changes the catalog and runs the tests
+	RoleEditor = "editor"
+	// RoleAdmin manages projects and users and reads the audit log
+	RoleAdmin = "admin"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleReader: {PermPagesRead, PermEventsRead, PermTestsRead, PermProjectsRead},
+	RoleEditor: {PermPagesRead, PermEventsRead, PermTestsRead, PermProjectsRead,
+		PermPagesWrite, PermEventsWrite, PermTestsWrite, PermTestsRun},
+	RoleAdmin: {PermPagesRead, PermEventsRead, PermTestsRead, PermProjectsRead,
+		PermPagesWrite, PermEventsWrite, PermTestsWrite, PermTestsRun,
+		PermProjectsManage, PermUsersManage, PermAuditRead},
+}
+
+// ValidRole reports whether the role is known.
+func ValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleAllows reports whether the role has the permission.
+func RoleAllows(role string, perm Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectRole overrides the role of the user in the project.
+type ProjectRole struct {
+	ID        uint64    `json:"-" gorm:"AUTO_INCREMENT;primary_key"`
+	UserId    uint64    `json:"userId" gorm:"unique_index:idx_project_roles_user_project;column:userId"`
+	ProjectId uint64    `json:"projectId" gorm:"unique_index:idx_project_roles_user_project;column:projectId"`
+	Role      string    `json:"role" gorm:"size:16;column:role"`
+	CreatedAt time.Time `json:"createdAt" gorm:"column:createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"column:updatedAt"`
+}
+
+func (ProjectRole) TableName() string {
+	return "project_roles"
+}
+
+// Grants are the roles of the user of the request. Requests without a user get the permissions of readers.
+type Grants struct {
+	Role     string
+	Projects map[uint64]string
+	// Scopes limit the permissions of API tokens, nil for sessions
+	Scopes []Permission
+}
+
+// NewGrants returns the grants of the user with the overrides of projects.
+func NewGrants(u *User, overrides []*ProjectRole) *Grants {
+	g := &Grants{Role: u.Role, Projects: make(map[uint64]string, len(overrides))}
+	for _, o := range overrides {
+		g.Projects[o.ProjectId] = o.Role
+	}
+	return g
+}
+
+// RoleIn returns the role in the project, the global role when the project does not override it.
+// Zero projectId returns the global role.
+func (g *Grants) RoleIn(projectId uint64) string {
+	if role, ok := g.Projects[projectId]; ok && projectId > 0 {
+		return role
+	}
+	return g.Role
+}
+
+// Check returns *PermissionError when the role in the project or the scopes lack the permission.
+func (g *Grants) Check(projectId uint64, perm Permission) error {
+	if RoleAllows(g.RoleIn(projectId), perm) && g.scopeAllows(perm) {
+		return nil
+	}
+	return &PermissionError{Permission: perm}
+}
+
+func (g *Grants) scopeAllows(perm Permission) bool {
+	if g.Scopes == nil {
+		return true
+	}
+	for _, p := range g.Scopes {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// PermissionError names the missing permission
+type PermissionError struct {
+	Permission Permission `json:"permission"`
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("missing permission %q", e.Permission)
+}
+
+type grantsContextKey struct{}
+
+// WithGrants returns the copy of the context carrying the grants of the user.
+func WithGrants(ctx context.Context, g *Grants) context.Context {
+	return context.WithValue(ctx, grantsContextKey{}, g)
+}
+
+// GrantsFromContext returns the grants carried by the context, the grants of readers when there are none.
+func GrantsFromContext(ctx context.Context) *Grants {
+	if ctx != nil {
+		if g, ok := ctx.Value(grantsContextKey{}).(*Grants); ok && g != nil {
+			return g
+		}
+	}
+	return &Grants{Role: RoleReader}
+}
+
+// Check verifies the permission of the request context in the project.
+func Check(ctx context.Context, projectId uint64, perm Permission) error {
+	return GrantsFromContext(ctx).Check(projectId, perm)
+}
@@ -0,0 +1,42 @@
+package users
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGrants_Check(t *testing.T) {
+	g := NewGrants(&User{ID: 1, Role: RoleReader}, []*ProjectRole{{UserId: 1, ProjectId: 2, Role: RoleEditor}})
+
+	cases := []struct {
+		projectId uint64
+		perm      Permission
+		allowed   bool
+	}{
+		{0, PermPagesRead, true},
+		{0, PermPagesWrite, false},
+		{1, PermPagesWrite, false},
+		{2, PermPagesWrite, true},
+		{2, PermUsersManage, false},
+	}
+
+	for caseNum, item := range cases {
+		err := g.Check(item.projectId, item.perm)
+		if (err == nil) != item.allowed {
+			t.Errorf("[%d] Unexpected check of %s in project %d: %v", caseNum, item.perm, item.projectId, err)
+		}
+		if err != nil && err.Error() != `missing permission "`+string(item.perm)+`"` {
+			t.Errorf("[%d] Unexpected error: %s", caseNum, err.Error())
+		}
+	}
+
+	if err := Check(context.Background(), 2, PermEventsRead); err != nil {
+		t.Errorf("Anonymous read is rejected: %s", err.Error())
+	}
+	if err := Check(context.Background(), 2, PermEventsWrite); err == nil {
+		t.Errorf("Anonymous write is allowed")
+	}
+	if err := Check(WithGrants(context.Background(), g), 2, PermEventsWrite); err != nil {
+		t.Errorf("Write of the project editor is rejected: %s", err.Error())
+	}
+}
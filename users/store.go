@@ -0,0 +1,31 @@
+package users
+
+import "time"
+
+type Store interface {
+	GetById(uint64) (*User, error)
+	GetByEmail(string) (*User, error)
+	List(offset, limit int) ([]*User, int, error)
+	Create(*User) error
+	Update(*User) error
+	// Delete removes the user along with its refresh tokens, personal API tokens and roles in projects
+	Delete(*User) error
+	CreateRefreshToken(*RefreshToken) error
+	// GetRefreshToken returns the refresh token by the hash, nil when there is none
+	GetRefreshToken(hash string) (*RefreshToken, error)
+	DeleteRefreshToken(hash string) error
+	ListProjectRoles(userId uint64) ([]*ProjectRole, error)
+	// SetProjectRole creates or replaces the role of the user in the project
+	SetProjectRole(*ProjectRole) error
+	DeleteProjectRole(userId, projectId uint64) error
+	CreateAPIToken(*APIToken) error
+	GetAPIToken(id uint64) (*APIToken, error)
+	// GetAPITokenByHash returns the API token by the hash, nil when there is none
+	GetAPITokenByHash(hash string) (*APIToken, error)
+	// ListAPITokens returns the personal tokens of the user, service tokens when userId is zero
+	ListAPITokens(userId uint64) ([]*APIToken, error)
+	// TouchAPIToken records the last use of the API token
+	TouchAPIToken(id uint64, at time.Time) error
+	// RevokeAPIToken marks the API token as revoked, it is kept for the history
+	RevokeAPIToken(id uint64, at time.Time) error
+}
@@ -0,0 +1,205 @@
+package store
+
+import (
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"github.com/nskondratev/api-page-go-back/db"
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/users"
+	"time"
+)
+
+type Gorm struct {
+	db     *gorm.DB
+	logger logger.Logger
+}
+
+type GormConfig struct {
+	DB     *gorm.DB
+	Logger logger.Logger
+}
+
+func NewGorm(c *GormConfig) users.Store {
+	return &Gorm{
+		db:     c.DB,
+		logger: c.Logger,
+	}
+}
+
+func (s *Gorm) GetById(id uint64) (*users.User, error) {
+	var user users.User
+	if err := s.db.First(&user, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *Gorm) GetByEmail(email string) (*users.User, error) {
+	var user users.User
+	if err := s.db.Where("`email` = ?", email).First(&user).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (s *Gorm) List(offset, limit int) ([]*users.User, int, error) {
+	usersList, total := make([]*users.User, 0), 0
+	qb := s.db.Model(&usersList)
+	if err := qb.Count(&total).Error; err != nil {
+		return usersList, total, err
+	}
+	err := qb.Offset(offset).Limit(limit).Order("email asc").Find(&usersList).Error
+	return usersList, total, err
+}
+
+func (s *Gorm) Create(user *users.User) error {
+	return s.db.Create(user).Error
+}
+
+func (s *Gorm) Update(user *users.User) error {
+	existing := &users.User{}
+	if err := s.db.First(existing, user.ID).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return fmt.Errorf("[users.store.gorm] user with id = %d does not exist", user.ID)
+		}
+		return err
+	}
+	user.CreatedAt = existing.CreatedAt
+	res := s.db.Save(user)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		return fmt.Errorf("[users.store.gorm] user with id = %d was not updated", user.ID)
+	}
+	return nil
+}
+
+func (s *Gorm) Delete(user *users.User) error {
+	return db.Transaction(s.db, func(tx *gorm.DB) error {
+		if err := tx.Where("`userId` = ?", user.ID).Delete(&users.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("`userId` = ?", user.ID).Delete(&users.ProjectRole{}).Error; err != nil {
+			return err
+		}
+		err := tx.Where("`kind` = ? AND `userId` = ?", users.APITokenPersonal, user.ID).Delete(&users.APIToken{}).Error
+		if err != nil {
+			return err
+		}
+		res := tx.Delete(user)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected < 1 {
+			return fmt.Errorf("[users.store.gorm] user with id = %d was not deleted", user.ID)
+		}
+		return nil
+	})
+}
+
+func (s *Gorm) CreateRefreshToken(token *users.RefreshToken) error {
+	return s.db.Create(token).Error
+}
+
+func (s *Gorm) GetRefreshToken(hash string) (*users.RefreshToken, error) {
+	var token users.RefreshToken
+	if err := s.db.Where("`tokenHash` = ?", hash).First(&token).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *Gorm) DeleteRefreshToken(hash string) error {
+	return s.db.Where("`tokenHash` = ?", hash).Delete(&users.RefreshToken{}).Error
+}
+
+func (s *Gorm) ListProjectRoles(userId uint64) ([]*users.ProjectRole, error) {
+	roles := make([]*users.ProjectRole, 0)
+	err := s.db.Where("`userId` = ?", userId).Order("`projectId` asc").Find(&roles).Error
+	return roles, err
+}
+
+func (s *Gorm) SetProjectRole(role *users.ProjectRole) error {
+	existing := &users.ProjectRole{}
+	err := s.db.Where("`userId` = ? AND `projectId` = ?", role.UserId, role.ProjectId).First(existing).Error
+	if gorm.IsRecordNotFoundError(err) {
+		return s.db.Create(role).Error
+	}
+	if err != nil {
+		return err
+	}
+	role.ID = existing.ID
+	role.CreatedAt = existing.CreatedAt
+	return s.db.Save(role).Error
+}
+
+func (s *Gorm) DeleteProjectRole(userId, projectId uint64) error {
+	return s.db.Where("`userId` = ? AND `projectId` = ?", userId, projectId).Delete(&users.ProjectRole{}).Error
+}
+
+func (s *Gorm) CreateAPIToken(token *users.APIToken) error {
+	return s.db.Create(token).Error
+}
+
+func (s *Gorm) GetAPIToken(id uint64) (*users.APIToken, error) {
+	var token users.APIToken
+	if err := s.db.First(&token, id).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *Gorm) GetAPITokenByHash(hash string) (*users.APIToken, error) {
+	var token users.APIToken
+	if err := s.db.Where("`tokenHash` = ?", hash).First(&token).Error; err != nil {
+		if gorm.IsRecordNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (s *Gorm) ListAPITokens(userId uint64) ([]*users.APIToken, error) {
+	kind := users.APITokenPersonal
+	if userId == 0 {
+		kind = users.APITokenService
+	}
+	tokens := make([]*users.APIToken, 0)
+	err := s.db.Where("`kind` = ? AND `userId` = ?", kind, userId).Order("`id` desc").Find(&tokens).Error
+	return tokens, err
+}
+
+func (s *Gorm) TouchAPIToken(id uint64, at time.Time) error {
+	return s.db.Model(&users.APIToken{ID: id}).UpdateColumn("lastUsedAt", at).Error
+}
+
+func (s *Gorm) RevokeAPIToken(id uint64, at time.Time) error {
+	res := s.db.Model(&users.APIToken{}).Where("`id` = ? AND `revokedAt` IS NULL", id).UpdateColumn("revokedAt", at)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected < 1 {
+		token, err := s.GetAPIToken(id)
+		if err != nil {
+			return err
+		}
+		if token == nil {
+			return fmt.Errorf("[users.store.gorm] api token with id = %d does not exist", id)
+		}
+	}
+	return nil
+}
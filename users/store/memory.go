@@ -0,0 +1,305 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/logger"
+	"github.com/nskondratev/api-page-go-back/users"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Memory struct {
+	logger        logger.Logger
+	records       []*users.User
+	refreshTokens []*users.RefreshToken
+	projectRoles  []*users.ProjectRole
+	apiTokens     []*users.APIToken
+	lastId        uint64
+	lastTokenId   uint64
+	lastRoleId    uint64
+	lastApiId     uint64
+	mu            *sync.Mutex
+}
+
+type MemoryConfig struct {
+	Logger logger.Logger
+}
+
+func NewMemory(c *MemoryConfig) *Memory {
+	return &Memory{
+		logger:        c.Logger,
+		records:       make([]*users.User, 0),
+		refreshTokens: make([]*users.RefreshToken, 0),
+		projectRoles:  make([]*users.ProjectRole, 0),
+		apiTokens:     make([]*users.APIToken, 0),
+		mu:            &sync.Mutex{},
+	}
+}
+
+func (s *Memory) GetById(id uint64) (*users.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.ID == id {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) GetByEmail(email string) (*users.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.records {
+		if el.Email == email {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) List(offset, limit int) ([]*users.User, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	usersList := make([]*users.User, len(s.records))
+	copy(usersList, s.records)
+	// Ordered by email, like the gorm store
+	sort.SliceStable(usersList, func(i, j int) bool {
+		return usersList[i].Email < usersList[j].Email
+	})
+	total := len(usersList)
+	if offset > total {
+		offset = total
+	}
+	l := limit
+	if l > total-offset || l < 0 {
+		l = total - offset
+	}
+	return usersList[offset : offset+l], total, nil
+}
+
+func (s *Memory) Create(user *users.User) error {
+	s.mu.Lock()
+	s.lastId++
+	user.ID = s.lastId
+	// Like the default of the role column
+	if len(user.Role) < 1 {
+		user.Role = users.RoleReader
+	}
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+	s.records = append(s.records, user)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Update(user *users.User) error {
+	user.UpdatedAt = time.Now()
+	s.mu.Lock()
+	for i, el := range s.records {
+		if el.ID == user.ID {
+			user.CreatedAt = el.CreatedAt
+			s.records[i] = user
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) Delete(user *users.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, el := range s.records {
+		if el.ID == user.ID {
+			copy(s.records[i:], s.records[i+1:])
+			s.records[len(s.records)-1] = nil
+			s.records = s.records[:len(s.records)-1]
+			break
+		}
+	}
+	tokens := s.refreshTokens[:0]
+	for _, el := range s.refreshTokens {
+		if el.UserId != user.ID {
+			tokens = append(tokens, el)
+		}
+	}
+	s.refreshTokens = tokens
+	roles := s.projectRoles[:0]
+	for _, el := range s.projectRoles {
+		if el.UserId != user.ID {
+			roles = append(roles, el)
+		}
+	}
+	s.projectRoles = roles
+	apiTokens := s.apiTokens[:0]
+	for _, el := range s.apiTokens {
+		if el.Kind != users.APITokenPersonal || el.UserId != user.ID {
+			apiTokens = append(apiTokens, el)
+		}
+	}
+	s.apiTokens = apiTokens
+	return nil
+}
+
+func (s *Memory) CreateRefreshToken(token *users.RefreshToken) error {
+	s.mu.Lock()
+	s.lastTokenId++
+	token.ID = s.lastTokenId
+	token.CreatedAt = time.Now()
+	s.refreshTokens = append(s.refreshTokens, token)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) GetRefreshToken(hash string) (*users.RefreshToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.refreshTokens {
+		if el.TokenHash == hash {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) DeleteRefreshToken(hash string) error {
+	s.mu.Lock()
+	for i, el := range s.refreshTokens {
+		if el.TokenHash == hash {
+			copy(s.refreshTokens[i:], s.refreshTokens[i+1:])
+			s.refreshTokens[len(s.refreshTokens)-1] = nil
+			s.refreshTokens = s.refreshTokens[:len(s.refreshTokens)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) ListProjectRoles(userId uint64) ([]*users.ProjectRole, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	roles := make([]*users.ProjectRole, 0)
+	for _, el := range s.projectRoles {
+		if el.UserId == userId {
+			roles = append(roles, el)
+		}
+	}
+	// Ordered by project, like the gorm store
+	sort.SliceStable(roles, func(i, j int) bool {
+		return roles[i].ProjectId < roles[j].ProjectId
+	})
+	return roles, nil
+}
+
+func (s *Memory) SetProjectRole(role *users.ProjectRole) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	role.UpdatedAt = time.Now()
+	for i, el := range s.projectRoles {
+		if el.UserId == role.UserId && el.ProjectId == role.ProjectId {
+			role.ID = el.ID
+			role.CreatedAt = el.CreatedAt
+			s.projectRoles[i] = role
+			return nil
+		}
+	}
+	s.lastRoleId++
+	role.ID = s.lastRoleId
+	role.CreatedAt = role.UpdatedAt
+	s.projectRoles = append(s.projectRoles, role)
+	return nil
+}
+
+func (s *Memory) DeleteProjectRole(userId, projectId uint64) error {
+	s.mu.Lock()
+	for i, el := range s.projectRoles {
+		if el.UserId == userId && el.ProjectId == projectId {
+			copy(s.projectRoles[i:], s.projectRoles[i+1:])
+			s.projectRoles[len(s.projectRoles)-1] = nil
+			s.projectRoles = s.projectRoles[:len(s.projectRoles)-1]
+			break
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) CreateAPIToken(token *users.APIToken) error {
+	s.mu.Lock()
+	s.lastApiId++
+	token.ID = s.lastApiId
+	token.CreatedAt = time.Now()
+	s.apiTokens = append(s.apiTokens, token)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Memory) GetAPIToken(id uint64) (*users.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.apiTokens {
+		if el.ID == id {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) GetAPITokenByHash(hash string) (*users.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.apiTokens {
+		if el.TokenHash == hash {
+			return el, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Memory) ListAPITokens(userId uint64) ([]*users.APIToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kind := users.APITokenPersonal
+	if userId == 0 {
+		kind = users.APITokenService
+	}
+	tokens := make([]*users.APIToken, 0)
+	for _, el := range s.apiTokens {
+		if el.Kind == kind && el.UserId == userId {
+			tokens = append(tokens, el)
+		}
+	}
+	// Newest first, like the gorm store
+	sort.SliceStable(tokens, func(i, j int) bool {
+		return tokens[i].ID > tokens[j].ID
+	})
+	return tokens, nil
+}
+
+func (s *Memory) TouchAPIToken(id uint64, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.apiTokens {
+		if el.ID == id {
+			el.LastUsedAt = &at
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *Memory) RevokeAPIToken(id uint64, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, el := range s.apiTokens {
+		if el.ID == id {
+			if el.RevokedAt == nil {
+				el.RevokedAt = &at
+			}
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"github.com/nskondratev/api-page-go-back/users"
+	"testing"
+	"time"
+)
+
+func TestMemory_Delete(t *testing.T) {
+	s := NewMemory(&MemoryConfig{})
+	john := &users.User{Email: "john@example.com", Name: "John"}
+	jane := &users.User{Email: "jane@example.com", Name: "Jane"}
+	for _, u := range []*users.User{john, jane} {
+		if err := s.Create(u); err != nil {
+			t.Fatalf("Can not create test user: %s", err.Error())
+		}
+		if err := s.CreateRefreshToken(&users.RefreshToken{UserId: u.ID, TokenHash: u.Email, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatalf("Can not create test refresh token: %s", err.Error())
+		}
+	}
+
+	if err := s.Delete(john); err != nil {
+		t.Fatalf("Can not delete user: %s", err.Error())
+	}
+
+	list, total, err := s.List(0, -1)
+	if err != nil {
+		t.Fatalf("Can not list users: %s", err.Error())
+	}
+	if total != 1 || list[0].ID != jane.ID {
+		t.Errorf("Unexpected users list: total %d, %+v", total, list)
+	}
+	if token, _ := s.GetRefreshToken(john.Email); token != nil {
+		t.Errorf("Refresh token of deleted user is kept: %+v", token)
+	}
+	if token, _ := s.GetRefreshToken(jane.Email); token == nil || token.UserId != jane.ID {
+		t.Errorf("Unexpected refresh token of another user: %+v", token)
+	}
+	if found, _ := s.GetByEmail("jane@example.com"); found == nil || found.ID != jane.ID {
+		t.Errorf("Unexpected user by email: %+v", found)
+	}
+}
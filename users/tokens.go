@@ -0,0 +1,163 @@
+package users
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/dgrijalva/jwt-go"
+	"strconv"
+	"time"
+)
+
+// Lifetimes of the tokens when the config does not set them
+const (
+	DefaultAccessTTL  = 15 * time.Minute
+	DefaultRefreshTTL = 30 * 24 * time.Hour
+)
+
+// ErrInvalidToken is returned for malformed, expired and foreign tokens
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Tokens are issued on login and refresh. The access token is a JWT signed with HS256, the refresh
+// token is an opaque random string.
+type Tokens struct {
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	User         *User     `json:"user"`
+}
+
+type IssuerConfig struct {
+	Store  Store
+	Secret string
+	// Lifetimes of the tokens, defaults are used when zero
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+}
+
+// Issuer signs the access tokens and keeps the refresh tokens of users.
+type Issuer struct {
+	store      Store
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+func NewIssuer(c *IssuerConfig) (*Issuer, error) {
+	if len(c.Secret) < 1 {
+		return nil, errors.New("secret of the tokens is empty")
+	}
+	i := &Issuer{
+		store:      c.Store,
+		secret:     []byte(c.Secret),
+		accessTTL:  c.AccessTTL,
+		refreshTTL: c.RefreshTTL,
+	}
+	if i.accessTTL <= 0 {
+		i.accessTTL = DefaultAccessTTL
+	}
+	if i.refreshTTL <= 0 {
+		i.refreshTTL = DefaultRefreshTTL
+	}
+	return i, nil
+}
+
+// Issue returns a new pair of tokens of the user and stores the refresh token.
+func (i *Issuer) Issue(u *User) (*Tokens, error) {
+	now := time.Now()
+	t := &Tokens{
+		ExpiresAt: now.Add(i.accessTTL),
+		User:      u,
+	}
+	claims := &jwt.StandardClaims{
+		Subject:   strconv.FormatUint(u.ID, 10),
+		IssuedAt:  now.Unix(),
+		ExpiresAt: t.ExpiresAt.Unix(),
+	}
+	var err error
+	if t.AccessToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(i.secret); err != nil {
+		return nil, err
+	}
+	if t.RefreshToken, err = randomToken(); err != nil {
+		return nil, err
+	}
+	rt := &RefreshToken{
+		UserId:    u.ID,
+		TokenHash: HashToken(t.RefreshToken),
+		ExpiresAt: now.Add(i.refreshTTL),
+	}
+	if err := i.store.CreateRefreshToken(rt); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Refresh exchanges the refresh token for a new pair of tokens. The used refresh token is revoked.
+func (i *Issuer) Refresh(refreshToken string) (*Tokens, error) {
+	hash := HashToken(refreshToken)
+	rt, err := i.store.GetRefreshToken(hash)
+	if err != nil {
+		return nil, err
+	}
+	if rt == nil || rt.Expired(time.Now()) {
+		return nil, ErrInvalidToken
+	}
+	if err := i.store.DeleteRefreshToken(hash); err != nil {
+		return nil, err
+	}
+	u, err := i.store.GetById(rt.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrInvalidToken
+	}
+	return i.Issue(u)
+}
+
+// Revoke deletes the refresh token, the access tokens stay valid until they expire.
+func (i *Issuer) Revoke(refreshToken string) error {
+	return i.store.DeleteRefreshToken(HashToken(refreshToken))
+}
+
+// Authenticate returns the user of the access token.
+func (i *Issuer) Authenticate(accessToken string) (*User, error) {
+	claims := &jwt.StandardClaims{}
+	_, err := jwt.ParseWithClaims(accessToken, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return i.secret, nil
+	})
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	id, err := strconv.ParseUint(claims.Subject, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	u, err := i.store.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrInvalidToken
+	}
+	return u, nil
+}
+
+// HashToken returns the hex encoded SHA-256 hash of the token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
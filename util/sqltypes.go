@@ -2,7 +2,9 @@ package util
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 )
 
 type NullString struct {
@@ -29,3 +31,121 @@ func NewNullStringFromString(s string) (NullString, error) {
 	}
 	return res, nil
 }
+
+type NullInt64 struct {
+	sql.NullInt64
+}
+
+func (ni *NullInt64) MarshalJSON() ([]byte, error) {
+	if !ni.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(ni.Int64)
+}
+
+func (ni *NullInt64) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		ni.Int64, ni.Valid = 0, false
+		return nil
+	}
+	err := json.Unmarshal(b, &ni.Int64)
+	ni.Valid = err == nil
+	return err
+}
+
+func NewNullInt64FromInt64(i int64) NullInt64 {
+	return NullInt64{sql.NullInt64{Int64: i, Valid: true}}
+}
+
+type NullFloat64 struct {
+	sql.NullFloat64
+}
+
+func (nf *NullFloat64) MarshalJSON() ([]byte, error) {
+	if !nf.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(nf.Float64)
+}
+
+func (nf *NullFloat64) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		nf.Float64, nf.Valid = 0, false
+		return nil
+	}
+	err := json.Unmarshal(b, &nf.Float64)
+	nf.Valid = err == nil
+	return err
+}
+
+func NewNullFloat64FromFloat64(f float64) NullFloat64 {
+	return NullFloat64{sql.NullFloat64{Float64: f, Valid: true}}
+}
+
+// StringList is a list of strings stored as a JSON array in a text column.
+type StringList []string
+
+func (sl StringList) Value() (driver.Value, error) {
+	if sl == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal([]string(sl))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (sl *StringList) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*sl = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(sl))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(sl))
+	default:
+		return fmt.Errorf("util: can not scan %T into StringList", src)
+	}
+}
+
+// RawJSON is an arbitrary JSON value stored as text. An empty RawJSON is NULL.
+type RawJSON []byte
+
+func (rj RawJSON) MarshalJSON() ([]byte, error) {
+	if len(rj) < 1 {
+		return []byte("null"), nil
+	}
+	return rj, nil
+}
+
+func (rj *RawJSON) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		*rj = nil
+		return nil
+	}
+	*rj = append((*rj)[0:0], b...)
+	return nil
+}
+
+func (rj RawJSON) Value() (driver.Value, error) {
+	if len(rj) < 1 {
+		return nil, nil
+	}
+	return string(rj), nil
+}
+
+func (rj *RawJSON) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*rj = nil
+	case []byte:
+		*rj = append((*rj)[0:0], v...)
+	case string:
+		*rj = RawJSON(v)
+	default:
+		return fmt.Errorf("util: can not scan %T into RawJSON", src)
+	}
+	return nil
+}
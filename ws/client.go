@@ -1,8 +1,15 @@
 package ws
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"github.com/gorilla/websocket"
+	"io"
+	"io/ioutil"
+	"log"
 	"net/http"
+	"sort"
 	"time"
 )
 
@@ -16,8 +23,11 @@ const (
 	// Send pings to peer with this period. Must be less than pongWait.
 	pingPeriod = (pongWait * 9) / 10
 
-	// Maximum message size allowed from peer.
-	maxMessageSize = 512
+	// Maximum command size allowed from peer, larger commands are answered with errors.
+	maxMessageSize = 8192
+
+	// Maximum message size allowed from peer, the connection is closed on larger messages.
+	maxFrameSize = 1 << 20
 )
 
 var (
@@ -42,6 +52,96 @@ type Client struct {
 
 	// Buffered channel of outbound messages.
 	send chan []byte
+
+	// Project of the received messages.
+	projectId uint64
+
+	// Subscribed topics, owned by the hub goroutine.
+	topics map[string]bool
+
+	// implicit is set until the first subscription of the client, the client receives all the messages.
+	implicit bool
+
+	// ctx carries the user and the project of the upgrade request to the commands.
+	ctx context.Context
+
+	// registeredSeq is the sequence number of the last broadcast before the registration,
+	// the later broadcasts are sent to the client as they come.
+	registeredSeq uint64
+}
+
+// matches reports whether the broadcast is sent to the client.
+func (c *Client) matches(message *outbound) bool {
+	if message.projectId > 0 && message.projectId != c.projectId {
+		return false
+	}
+	if c.topics[TopicAll] {
+		return true
+	}
+	for _, topic := range message.topics {
+		if c.topics[topic] {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) subscribedTopics() []string {
+	topics := make([]string, 0, len(c.topics))
+	for topic := range c.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// readPump pumps commands from the websocket connection to the client.
+//
+// The client is unregistered when the connection is closed or the peer stops answering the pings.
+// Commands are handled one by one, the replies are sent through the hub.
+func (c *Client) readPump() {
+	defer func() {
+		c.hub.unregister <- c
+		c.conn.Close()
+	}()
+	c.conn.SetReadLimit(maxFrameSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		return nil
+	})
+	for {
+		_, r, err := c.conn.NextReader()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Println(err)
+			}
+			return
+		}
+		// The rest of the oversized command is discarded by the next read
+		message, err := ioutil.ReadAll(io.LimitReader(r, maxMessageSize+1))
+		if err != nil {
+			return
+		}
+		if len(message) > maxMessageSize {
+			c.reply(CommandError, "", &commandErrorEnvelope{
+				Code:  ErrCodeTooLarge,
+				Error: fmt.Sprintf("command exceeds %d bytes", maxMessageSize),
+			})
+			continue
+		}
+		c.handle(message)
+	}
+}
+
+// reply sends the message to the client only.
+func (c *Client) reply(eventConst, id string, data interface{}) {
+	m, err := json.Marshal(&replyMessage{EventConst: eventConst, Id: id, Data: data})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	c.hub.replies <- &reply{client: c, data: m}
 }
 
 // writePump pumps messages from the hub to the websocket connection.
@@ -0,0 +1,208 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/users"
+	"time"
+)
+
+// Commands of the client
+const (
+	CommandPing        = "ping"
+	CommandSubscribe   = "subscribe"
+	CommandUnsubscribe = "unsubscribe"
+	CommandFetchPage   = "fetch_page"
+	CommandFetchEvent  = "fetch_event"
+	CommandQuery       = "query"
+	CommandResume      = "resume"
+)
+
+// Codes of the errors replied to the commands
+const (
+	ErrCodeInvalid     = "invalid_command"
+	ErrCodeUnknown     = "unknown_command"
+	ErrCodeTooLarge    = "command_too_large"
+	ErrCodeForbidden   = "forbidden"
+	ErrCodeNotFound    = "not_found"
+	ErrCodeUnavailable = "unavailable"
+	ErrCodeFailed      = "failed"
+)
+
+// command is sent by the client, the reply carries the ID of the command.
+type command struct {
+	Id      string `json:"id"`
+	Command string `json:"command"`
+	// Topics of subscribe and unsubscribe
+	Topics []string `json:"topics"`
+	// PageId of fetch_page
+	PageId uint64 `json:"pageId"`
+	// EventId of fetch_event
+	EventId uint64 `json:"eventId"`
+	// Query of query
+	Query string `json:"query"`
+	// Since of resume is the sequence number of the last broadcast received by the client
+	Since uint64 `json:"since"`
+	// Epoch of resume is the epoch of the last broadcast received by the client
+	Epoch string `json:"epoch"`
+}
+
+type fetchedPageEnvelope struct {
+	Page interface{} `json:"page"`
+}
+
+type fetchedEventEnvelope struct {
+	Event interface{} `json:"event"`
+}
+
+// commandError is replied with ap_error.
+type commandError struct {
+	code       string
+	message    string
+	permission users.Permission
+}
+
+func (e *commandError) Error() string {
+	return e.message
+}
+
+func newCommandError(code, format string, args ...interface{}) *commandError {
+	return &commandError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+func (c *Client) handle(message []byte) {
+	cmd := &command{}
+	if err := json.Unmarshal(message, cmd); err != nil {
+		c.reply(CommandError, "", &commandErrorEnvelope{
+			Code:  ErrCodeInvalid,
+			Error: fmt.Sprintf("invalid command: %s", err.Error()),
+		})
+		return
+	}
+	if err := c.execute(cmd); err != nil {
+		c.reply(CommandError, cmd.Id, &commandErrorEnvelope{
+			Code:       err.code,
+			Error:      err.message,
+			Permission: err.permission,
+		})
+	}
+}
+
+func (c *Client) execute(cmd *command) *commandError {
+	switch cmd.Command {
+	case CommandPing:
+		c.reply(Pong, cmd.Id, nil)
+		return nil
+	case CommandSubscribe, CommandUnsubscribe:
+		return c.subscribe(cmd)
+	case CommandFetchPage:
+		return c.fetchPage(cmd)
+	case CommandFetchEvent:
+		return c.fetchEvent(cmd)
+	case CommandQuery:
+		return c.query(cmd)
+	case CommandResume:
+		c.hub.resumes <- &resume{client: c, id: cmd.Id, epoch: cmd.Epoch, since: cmd.Since}
+		return nil
+	}
+	return newCommandError(ErrCodeUnknown, "unknown command %q", cmd.Command)
+}
+
+func (c *Client) subscribe(cmd *command) *commandError {
+	if len(cmd.Topics) == 0 {
+		return newCommandError(ErrCodeInvalid, "topics are required")
+	}
+	for _, topic := range cmd.Topics {
+		if err := ValidateTopic(topic); err != nil {
+			return newCommandError(ErrCodeInvalid, "%s", err.Error())
+		}
+	}
+	c.hub.subscriptions <- &subscription{
+		client:    c,
+		id:        cmd.Id,
+		topics:    cmd.Topics,
+		subscribe: cmd.Command == CommandSubscribe,
+	}
+	return nil
+}
+
+func (c *Client) fetchPage(cmd *command) *commandError {
+	if cmd.PageId < 1 {
+		return newCommandError(ErrCodeInvalid, "pageId is required")
+	}
+	if c.hub.pageStore == nil {
+		return newCommandError(ErrCodeUnavailable, "pages can not be fetched")
+	}
+	if err := c.check(users.PermPagesRead); err != nil {
+		return err
+	}
+	page, err := c.hub.pageStore.ForProject(c.projectId).GetById(cmd.PageId)
+	if err != nil {
+		return newCommandError(ErrCodeFailed, "%s", err.Error())
+	}
+	if page == nil {
+		return newCommandError(ErrCodeNotFound, "Not found")
+	}
+	c.reply(Fetched, cmd.Id, &fetchedPageEnvelope{Page: page})
+	return nil
+}
+
+func (c *Client) fetchEvent(cmd *command) *commandError {
+	if cmd.EventId < 1 {
+		return newCommandError(ErrCodeInvalid, "eventId is required")
+	}
+	if c.hub.eventStore == nil {
+		return newCommandError(ErrCodeUnavailable, "events can not be fetched")
+	}
+	if err := c.check(users.PermEventsRead); err != nil {
+		return err
+	}
+	event, err := c.hub.eventStore.ForProject(c.projectId).GetById(cmd.EventId)
+	if err != nil {
+		return newCommandError(ErrCodeFailed, "%s", err.Error())
+	}
+	if event == nil {
+		return newCommandError(ErrCodeNotFound, "Not found")
+	}
+	c.reply(Fetched, cmd.Id, &fetchedEventEnvelope{Event: event})
+	return nil
+}
+
+// query runs the GraphQL query, the resolvers check the permissions.
+func (c *Client) query(cmd *command) *commandError {
+	if len(cmd.Query) == 0 {
+		return newCommandError(ErrCodeInvalid, "query is required")
+	}
+	if c.hub.gqlHub == nil {
+		return newCommandError(ErrCodeUnavailable, "queries can not be run")
+	}
+	result, err := c.hub.gqlHub.Execute(c.ctx, cmd.Query)
+	if err != nil {
+		return newCommandError(ErrCodeFailed, "%s", err.Error())
+	}
+	c.reply(QueryResult, cmd.Id, result)
+	return nil
+}
+
+func (c *Client) check(perm users.Permission) *commandError {
+	if err := users.Check(c.ctx, c.projectId, perm); err != nil {
+		return &commandError{code: ErrCodeForbidden, message: err.Error(), permission: perm}
+	}
+	return nil
+}
+
+// detachedContext keeps the values of the upgrade request after the request is done.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (ctx detachedContext) Deadline() (time.Time, bool)       { return time.Time{}, false }
+func (ctx detachedContext) Done() <-chan struct{}             { return nil }
+func (ctx detachedContext) Err() error                        { return nil }
+func (ctx detachedContext) Value(key interface{}) interface{} { return ctx.parent.Value(key) }
+
+func commandContext(r context.Context) (context.Context, uint64) {
+	return detachedContext{parent: r}, projects.IdFromContext(r)
+}
@@ -9,4 +9,31 @@ const (
 	PageCreated = "ap_page_created"
 	PageUpdated = "ap_page_updated"
 	PageDeleted = "ap_page_deleted"
+	// Shared types
+	TypeCreated = "ap_type_created"
+	TypeUpdated = "ap_type_updated"
+	TypeDeleted = "ap_type_deleted"
+	// Saved requests
+	RequestCreated   = "ap_request_created"
+	RequestUpdated   = "ap_request_updated"
+	RequestDeleted   = "ap_request_deleted"
+	RequestsImported = "ap_requests_imported"
+	FolderCreated    = "ap_folder_created"
+	FolderUpdated    = "ap_folder_updated"
+	FolderDeleted    = "ap_folder_deleted"
+	// Environments
+	EnvironmentCreated = "ap_environment_created"
+	EnvironmentUpdated = "ap_environment_updated"
+	EnvironmentDeleted = "ap_environment_deleted"
+	// Monitors
+	MonitorStatusChanged = "ap_monitor_status_changed"
+	// Replies to the commands of the client
+	Subscribed  = "ap_subscribed"
+	Pong        = "ap_pong"
+	Fetched     = "ap_fetched"
+	QueryResult = "ap_query_result"
+	// Resumed follows the replayed broadcasts, ResyncRequired is sent when they are not buffered anymore
+	Resumed        = "ap_resumed"
+	ResyncRequired = "ap_resync_required"
+	CommandError   = "ap_error"
 )
@@ -1,6 +1,10 @@
 package ws
 
 import (
+	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/gql"
+	"github.com/nskondratev/api-page-go-back/pages"
 	"log"
 	"net/http"
 )
@@ -10,30 +14,116 @@ import (
 
 type IHub interface {
 	Run()
+	// Broadcast sends the message to the clients of all projects
 	Broadcast(message ApMessage) error
+	// BroadcastTo sends the message to the clients of the project
+	BroadcastTo(projectId uint64, message ApMessage) error
+	// ServeWs subscribes the client to the messages of the project of the request context
 	ServeWs(w http.ResponseWriter, r *http.Request)
 }
 
+// outbound is the built message with the project of its receivers, zero for all projects.
+type outbound struct {
+	// seq is the sequence number of the broadcast, assigned by the hub
+	seq       uint64
+	projectId uint64
+	topics    []string
+	data      []byte
+}
+
+// subscription changes the topics of the client, id is the ID of the command.
+type subscription struct {
+	client    *Client
+	id        string
+	topics    []string
+	subscribe bool
+}
+
+// resume replays the broadcasts missed by the client since the sequence number.
+type resume struct {
+	client *Client
+	id     string
+	epoch  string
+	since  uint64
+}
+
+// reply is sent to the client only.
+type reply struct {
+	client *Client
+	data   []byte
+}
+
 type Hub struct {
 	// Registered clients.
 	clients map[*Client]bool
 
-	// Inbound messages from the clients.
-	broadcast chan []byte
+	// Subscribed clients by topic and project, a message reaches only the clients of its topics.
+	topics map[string]map[uint64]map[*Client]bool
+
+	// Messages to send to the clients.
+	broadcast chan *outbound
 
 	// Register requests from the clients.
 	register chan *Client
 
 	// Unregister requests from clients.
 	unregister chan *Client
+
+	// Subscribe and unsubscribe requests from the clients.
+	subscriptions chan *subscription
+
+	// Replies to the commands of the clients.
+	replies chan *reply
+
+	// Resume requests from the clients.
+	resumes chan *resume
+
+	// seq is the sequence number of the last broadcast.
+	seq uint64
+
+	// epoch tells apart the sequence numbers of different runs of the hub.
+	epoch string
+
+	// Latest broadcasts for the resuming clients.
+	replay *replayBuffer
+
+	pageStore  pages.Store
+	eventStore events.Store
+	gqlHub     *gql.GraphQLHub
 }
 
-func NewHub() IHub {
+// HubConfig holds the stores answering the commands of the clients, the commands fail without them.
+type HubConfig struct {
+	PageStore  pages.Store
+	EventStore events.Store
+	GraphQLHub *gql.GraphQLHub
+	// ReplaySize is the number of the broadcasts kept for the resuming clients, DefaultReplaySize when zero.
+	// Clients can not resume when it is negative.
+	ReplaySize int
+}
+
+func NewHub(hc *HubConfig) IHub {
+	replaySize := hc.ReplaySize
+	if replaySize == 0 {
+		replaySize = DefaultReplaySize
+	}
+	if replaySize < 0 {
+		replaySize = 0
+	}
 	return &Hub{
-		broadcast:  make(chan []byte),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		clients:    make(map[*Client]bool),
+		replay:        newReplayBuffer(replaySize),
+		epoch:         newEpoch(),
+		pageStore:     hc.PageStore,
+		eventStore:    hc.EventStore,
+		gqlHub:        hc.GraphQLHub,
+		broadcast:     make(chan *outbound),
+		register:      make(chan *Client),
+		unregister:    make(chan *Client),
+		subscriptions: make(chan *subscription),
+		replies:       make(chan *reply),
+		resumes:       make(chan *resume),
+		clients:       make(map[*Client]bool),
+		topics:        make(map[string]map[uint64]map[*Client]bool),
 	}
 }
 
@@ -42,30 +132,160 @@ func (h *Hub) Run() {
 		select {
 		case client := <-h.register:
 			h.clients[client] = true
+			client.registeredSeq = h.seq
+			client.implicit = true
+			h.subscribe(client, TopicAll)
 		case client := <-h.unregister:
 			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
+				h.remove(client)
+			}
+		case s := <-h.subscriptions:
+			if _, ok := h.clients[s.client]; ok {
+				h.applySubscription(s)
+			}
+		case r := <-h.replies:
+			if _, ok := h.clients[r.client]; ok {
+				h.send(r.client, r.data)
+			}
+		case r := <-h.resumes:
+			if _, ok := h.clients[r.client]; ok {
+				h.resume(r)
 			}
 		case message := <-h.broadcast:
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
+			h.seq++
+			message.seq = h.seq
+			message.data = withSeq(h.epoch, message.seq, message.data)
+			h.replay.add(message)
+			for client := range h.recipients(message) {
+				h.send(client, message.data)
 			}
 		}
 	}
 }
 
+// recipients collects the clients subscribed to any topic of the message, each client once.
+func (h *Hub) recipients(message *outbound) map[*Client]bool {
+	recipients := make(map[*Client]bool)
+	add := func(clients map[*Client]bool) {
+		for client := range clients {
+			recipients[client] = true
+		}
+	}
+	for _, topic := range append(message.topics, TopicAll) {
+		if message.projectId > 0 {
+			add(h.topics[topic][message.projectId])
+			continue
+		}
+		for _, clients := range h.topics[topic] {
+			add(clients)
+		}
+	}
+	return recipients
+}
+
+// resume sends the matching broadcasts after r.since up to the registration of the client. The later ones
+// are already sent to the client, so they may arrive before the replayed ones. The client resyncs when some
+// of the missed broadcasts are not buffered anymore or r.since belongs to another run of the hub.
+func (h *Hub) resume(r *resume) {
+	client := r.client
+	missed, ok := h.replay.since(r.since, client.registeredSeq)
+	if !ok || r.epoch != h.epoch || r.since > h.seq {
+		h.reply(client, ResyncRequired, r.id, &resumeEnvelope{Since: r.since, Seq: h.seq, Epoch: h.epoch})
+		return
+	}
+	replayed := 0
+	for _, message := range missed {
+		if client.matches(message) {
+			h.send(client, message.data)
+			replayed++
+		}
+	}
+	h.reply(client, Resumed, r.id, &resumeEnvelope{Since: r.since, Seq: client.registeredSeq, Replayed: replayed, Epoch: h.epoch})
+}
+
+func (h *Hub) reply(client *Client, eventConst, id string, data interface{}) {
+	m, err := json.Marshal(&replyMessage{EventConst: eventConst, Id: id, Data: data})
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	h.send(client, m)
+}
+
+// send drops the client which does not keep up with its messages.
+func (h *Hub) send(client *Client, data []byte) {
+	select {
+	case client.send <- data:
+	default:
+		h.remove(client)
+	}
+}
+
+func (h *Hub) remove(client *Client) {
+	for topic := range client.topics {
+		h.unsubscribe(client, topic)
+	}
+	delete(h.clients, client)
+	close(client.send)
+}
+
+func (h *Hub) applySubscription(s *subscription) {
+	client := s.client
+	if client.implicit {
+		// The first command replaces the implicit subscription to all topics
+		h.unsubscribe(client, TopicAll)
+		client.implicit = false
+	}
+	for _, topic := range s.topics {
+		if s.subscribe {
+			h.subscribe(client, topic)
+		} else {
+			h.unsubscribe(client, topic)
+		}
+	}
+	h.reply(client, Subscribed, s.id, &subscribedEnvelope{Topics: client.subscribedTopics()})
+}
+
+func (h *Hub) subscribe(client *Client, topic string) {
+	byProject, ok := h.topics[topic]
+	if !ok {
+		byProject = make(map[uint64]map[*Client]bool)
+		h.topics[topic] = byProject
+	}
+	clients, ok := byProject[client.projectId]
+	if !ok {
+		clients = make(map[*Client]bool)
+		byProject[client.projectId] = clients
+	}
+	clients[client] = true
+	client.topics[topic] = true
+}
+
+func (h *Hub) unsubscribe(client *Client, topic string) {
+	delete(client.topics, topic)
+	clients := h.topics[topic][client.projectId]
+	if clients == nil {
+		return
+	}
+	delete(clients, client)
+	if len(clients) == 0 {
+		delete(h.topics[topic], client.projectId)
+	}
+	if len(h.topics[topic]) == 0 {
+		delete(h.topics, topic)
+	}
+}
+
 func (h *Hub) Broadcast(message ApMessage) error {
+	return h.BroadcastTo(0, message)
+}
+
+func (h *Hub) BroadcastTo(projectId uint64, message ApMessage) error {
 	m, err := message.BuildWsMessage()
 	if err != nil {
 		return err
 	}
-	h.broadcast <- m
+	h.broadcast <- &outbound{projectId: projectId, topics: message.Topics(), data: m}
 	return nil
 }
 
@@ -75,10 +295,19 @@ func (h *Hub) ServeWs(w http.ResponseWriter, r *http.Request) {
 		log.Println(err)
 		return
 	}
-	client := &Client{hub: h, conn: conn, send: make(chan []byte, 256)}
+	ctx, projectId := commandContext(r.Context())
+	client := &Client{
+		hub:       h,
+		conn:      conn,
+		send:      make(chan []byte, 256),
+		projectId: projectId,
+		topics:    make(map[string]bool),
+		ctx:       ctx,
+	}
 	client.hub.register <- client
 
 	// Allow collection of memory referenced by the caller by doing all work in
 	// new goroutines.
 	go client.writePump()
+	go client.readPump()
 }
@@ -0,0 +1,302 @@
+package ws
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gorilla/websocket"
+	"github.com/nskondratev/api-page-go-back/events"
+	eventStore "github.com/nskondratev/api-page-go-back/events/store"
+	"github.com/nskondratev/api-page-go-back/gql"
+	"github.com/nskondratev/api-page-go-back/pages"
+	pageStore "github.com/nskondratev/api-page-go-back/pages/store"
+	"github.com/nskondratev/api-page-go-back/projects"
+	"github.com/nskondratev/api-page-go-back/users"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testClient struct {
+	t    *testing.T
+	conn *websocket.Conn
+	// pending messages of the last read, the writer joins queued messages with newlines
+	pending [][]byte
+}
+
+// serveTestHub serves the ws of the project of the project query param,
+// the scope query param limits the permissions of the client to the scope.
+func serveTestHub(hub IHub) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		projectId, _ := strconv.ParseUint(r.URL.Query().Get("project"), 10, 64)
+		ctx := projects.NewContext(r.Context(), projectId)
+		if scope := r.URL.Query().Get("scope"); len(scope) > 0 {
+			ctx = users.WithGrants(ctx, &users.Grants{Role: users.RoleAdmin, Scopes: []users.Permission{users.Permission(scope)}})
+		}
+		hub.ServeWs(w, r.WithContext(ctx))
+	}))
+}
+
+// dialTestHub connects to the hub and waits for the registration of the client.
+func dialTestHub(t *testing.T, server *httptest.Server, query string) *testClient {
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?" + query
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Can not connect to ws: %s", err.Error())
+	}
+	c := &testClient{t: t, conn: conn}
+	// Commands are read only after the registration
+	c.command(`{"id":"hello","command":"ping"}`)
+	c.expect(Pong, `"id":"hello"`)
+	return c
+}
+
+func (c *testClient) command(cmd string) {
+	if err := c.conn.WriteMessage(websocket.TextMessage, []byte(cmd)); err != nil {
+		c.t.Fatalf("Can not send command: %s", err.Error())
+	}
+}
+
+// next returns the next message, nil when nothing is received in a second.
+func (c *testClient) next() []byte {
+	if len(c.pending) == 0 {
+		c.conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return nil
+		}
+		c.pending = bytes.Split(data, newline)
+	}
+	message := c.pending[0]
+	c.pending = c.pending[1:]
+	return message
+}
+
+// expect fails unless the next message is the event containing the text.
+func (c *testClient) expect(eventConst, shouldContain string) {
+	message := c.next()
+	m := &struct {
+		EventConst string `json:"event"`
+	}{}
+	if err := json.Unmarshal(message, m); err != nil {
+		c.t.Fatalf("Can not decode ws message %s: %v", message, err)
+	}
+	if m.EventConst != eventConst || !strings.Contains(string(message), shouldContain) {
+		c.t.Fatalf("Unexpected ws message. Wanted: %s with %s, received: %s", eventConst, shouldContain, message)
+	}
+}
+
+func TestHub_Subscriptions(t *testing.T) {
+	hub := NewHub(&HubConfig{})
+	go hub.Run()
+	server := serveTestHub(hub)
+	defer server.Close()
+
+	all := dialTestHub(t, server, "project=1")
+	defer all.conn.Close()
+	page := dialTestHub(t, server, "project=1")
+	defer page.conn.Close()
+	clientEvents := dialTestHub(t, server, "project=1")
+	defer clientEvents.conn.Close()
+	otherProject := dialTestHub(t, server, "project=2")
+	defer otherProject.conn.Close()
+
+	page.command(`{"id":"1","command":"subscribe","topics":["page:2"]}`)
+	page.expect(Subscribed, `"id":"1","data":{"topics":["page:2"]}`)
+	clientEvents.command(`{"command":"subscribe","topics":["events:client","page:2"]}`)
+	clientEvents.expect(Subscribed, `"topics":["events:client","page:2"]`)
+	clientEvents.command(`{"command":"unsubscribe","topics":["page:2"]}`)
+	clientEvents.expect(Subscribed, `"topics":["events:client"]`)
+	otherProject.command(`{"command":"subscribe","topics":["pages","events"]}`)
+	otherProject.expect(Subscribed, `"topics":["events","pages"]`)
+	otherProject.command(`{"command":"subscribe","topics":["page:x"]}`)
+	otherProject.expect(CommandError, `invalid id in topic \"page:x\"`)
+	otherProject.command(`{"command":"subscribe","topics":["users"]}`)
+	otherProject.expect(CommandError, `unknown topic \"users\"`)
+
+	broadcasts := []struct {
+		projectId uint64
+		message   ApMessage
+	}{
+		{1, &ApPageMessage{EventConst: PageUpdated, Data: &ApMessagePageEnvelope{Page: &pages.Page{ID: 1, Title: "First"}}}},
+		{1, &ApPageMessage{EventConst: PageUpdated, Data: &ApMessagePageEnvelope{Page: &pages.Page{ID: 2, Title: "Second"}}}},
+		{1, &ApEventMessage{EventConst: EventCreated, Data: &ApMessageEventEnvelope{Event: &events.Event{ID: 3, Type: events.TypeFrontend}}}},
+		{1, &ApIdMessage{EventConst: EventDeleted, Data: &ApMessageOnlyIdEnvelope{ID: 4}, EventType: events.TypeClient}},
+		{0, &ApPageMessage{EventConst: PageCreated, Data: &ApMessagePageEnvelope{Page: &pages.Page{ID: 5, Title: "Common"}}}},
+	}
+	for _, b := range broadcasts {
+		if err := hub.BroadcastTo(b.projectId, b.message); err != nil {
+			t.Fatalf("Can not broadcast: %s", err.Error())
+		}
+	}
+
+	all.expect(PageUpdated, `"title":"First"`)
+	all.expect(PageUpdated, `"title":"Second"`)
+	all.expect(EventCreated, `"id":3`)
+	all.expect(EventDeleted, `"id":4`)
+	all.expect(PageCreated, `"title":"Common"`)
+	page.expect(PageUpdated, `"title":"Second"`)
+	clientEvents.expect(EventDeleted, `"id":4`)
+	otherProject.expect(PageCreated, `"title":"Common"`)
+
+	// Nothing else is delivered to the subscribers
+	for _, c := range []*testClient{page, clientEvents, otherProject} {
+		if data := c.next(); data != nil {
+			t.Errorf("Unexpected ws message: %s", data)
+		}
+	}
+}
+
+func TestHub_Commands(t *testing.T) {
+	ps := pageStore.NewMemory(&pageStore.MemoryConfig{})
+	es := eventStore.NewMemory(&eventStore.MemoryConfig{})
+	if err := ps.Create(&pages.Page{Title: "Intro", Text: "Intro"}); err != nil {
+		t.Fatalf("Can not create test page: %s", err.Error())
+	}
+	if err := es.Create(&events.Event{Constant: "LOGIN", Value: "login", Type: events.TypeClient}); err != nil {
+		t.Fatalf("Can not create test event: %s", err.Error())
+	}
+	gqlHub := gql.NewGraphQLHub()
+	gqlHub.AddType(pages.GraphQLType)
+	if err := pages.RegisterGraphQLQueries(ps, gqlHub); err != nil {
+		t.Fatalf("Can not register graphql queries: %s", err.Error())
+	}
+	if err := gqlHub.Compile(); err != nil {
+		t.Fatalf("Can not compile graphql schema: %s", err.Error())
+	}
+	hub := NewHub(&HubConfig{PageStore: ps, EventStore: es, GraphQLHub: gqlHub})
+	go hub.Run()
+	server := serveTestHub(hub)
+	defer server.Close()
+
+	reader := dialTestHub(t, server, "project=1")
+	defer reader.conn.Close()
+	scoped := dialTestHub(t, server, "project=1&scope=events.read")
+	defer scoped.conn.Close()
+
+	cases := []struct {
+		client        *testClient
+		command       string
+		event         string
+		shouldContain string
+	}{
+		{reader, `{"id":"1","command":"ping"}`, Pong, `"id":"1"`},
+		{reader, `{"id":"2","command":"fetch_page","pageId":1}`, Fetched, `"id":"2","data":{"page":{"id":1,"projectId":1,"title":"Intro"`},
+		{reader, `{"id":"3","command":"fetch_page","pageId":99}`, CommandError, `"id":"3","data":{"code":"not_found","error":"Not found"}`},
+		{reader, `{"id":"4","command":"fetch_event","eventId":1}`, Fetched, `"id":"4","data":{"event":{"id":1`},
+		{reader, `{"id":"5","command":"fetch_event"}`, CommandError, `"code":"invalid_command","error":"eventId is required"`},
+		{reader, `{"id":"6","command":"query","query":"{ page(id: 1) { title } }"}`, QueryResult, `"id":"6","data":{"data":{"page":{"title":"Intro"}}}`},
+		{reader, `{"id":"7","command":"query","query":"{ page"}`, CommandError, `"id":"7","data":{"code":"failed"`},
+		{reader, `{"id":"8","command":"drop"}`, CommandError, `"code":"unknown_command","error":"unknown command \"drop\""`},
+		{reader, `{"id":`, CommandError, `"code":"invalid_command"`},
+		{reader, `{"id":"9","query":"` + strings.Repeat("x", maxMessageSize) + `"}`, CommandError, `"code":"command_too_large"`},
+		{reader, `{"id":"10","command":"ping"}`, Pong, `"id":"10"`},
+		{scoped, `{"id":"11","command":"fetch_page","pageId":1}`, CommandError, `"code":"forbidden","error":"missing permission \"pages.read\"","permission":"pages.read"`},
+		{scoped, `{"id":"12","command":"fetch_event","eventId":1}`, Fetched, `"constant":"LOGIN"`},
+		{scoped, `{"id":"13","command":"query","query":"{ page(id: 1) { title } }"}`, CommandError, `"code":"failed","error":"graphql: fail to execute query: [missing permission \"pages.read\"]"`},
+	}
+	for _, item := range cases {
+		item.client.command(item.command)
+		item.client.expect(item.event, item.shouldContain)
+	}
+}
+
+func TestHub_Resume(t *testing.T) {
+	hub := NewHub(&HubConfig{ReplaySize: 3})
+	go hub.Run()
+	server := serveTestHub(hub)
+	defer server.Close()
+
+	broadcast := func(ids ...uint64) {
+		for _, id := range ids {
+			message := &ApPageMessage{EventConst: PageUpdated, Data: &ApMessagePageEnvelope{Page: &pages.Page{ID: id, Title: "Page " + strconv.FormatUint(id, 10)}}}
+			if err := hub.BroadcastTo(1, message); err != nil {
+				t.Fatalf("Can not broadcast: %s", err.Error())
+			}
+		}
+	}
+
+	epoch := hub.(*Hub).epoch
+	first := dialTestHub(t, server, "project=1")
+	broadcast(1, 2)
+	first.expect(PageUpdated, `{"seq":1,"epoch":"`+epoch+`","event":"ap_page_updated","data":{"page":{"id":1`)
+	first.expect(PageUpdated, `{"seq":2,"epoch":"`+epoch+`","event":"ap_page_updated","data":{"page":{"id":2`)
+	first.conn.Close()
+
+	broadcast(3, 4)
+	resumed := dialTestHub(t, server, "project=1")
+	defer resumed.conn.Close()
+	resumed.command(`{"id":"1","command":"resume","since":2,"epoch":"` + epoch + `"}`)
+	resumed.expect(PageUpdated, `{"seq":3,`)
+	resumed.expect(PageUpdated, `{"seq":4,`)
+	resumed.expect(Resumed, `"id":"1","data":{"since":2,"seq":4,"replayed":2,"epoch":"`+epoch+`"}`)
+
+	broadcast(5, 6)
+	resumed.expect(PageUpdated, `{"seq":5,`)
+	resumed.expect(PageUpdated, `{"seq":6,`)
+
+	late := dialTestHub(t, server, "project=1")
+	defer late.conn.Close()
+	late.command(`{"id":"2","command":"resume","since":2,"epoch":"` + epoch + `"}`)
+	late.expect(ResyncRequired, `"id":"2","data":{"since":2,"seq":6,"replayed":0,"epoch":"`+epoch+`"}`)
+	late.command(`{"id":"3","command":"resume","since":99,"epoch":"` + epoch + `"}`)
+	late.expect(ResyncRequired, `"id":"3","data":{"since":99,"seq":6,"replayed":0,"epoch":"`+epoch+`"}`)
+
+	subscribed := dialTestHub(t, server, "project=1")
+	defer subscribed.conn.Close()
+	subscribed.command(`{"command":"subscribe","topics":["page:5"]}`)
+	subscribed.expect(Subscribed, `"topics":["page:5"]`)
+	subscribed.command(`{"id":"4","command":"resume","since":3,"epoch":"` + epoch + `"}`)
+	subscribed.expect(PageUpdated, `{"seq":5,`)
+	subscribed.expect(Resumed, `"id":"4","data":{"since":3,"seq":6,"replayed":1,"epoch":"`+epoch+`"}`)
+}
+
+func TestHub_ResumeAfterRestart(t *testing.T) {
+	broadcast := func(hub IHub, ids ...uint64) {
+		for _, id := range ids {
+			message := &ApPageMessage{EventConst: PageUpdated, Data: &ApMessagePageEnvelope{Page: &pages.Page{ID: id}}}
+			if err := hub.BroadcastTo(1, message); err != nil {
+				t.Fatalf("Can not broadcast: %s", err.Error())
+			}
+		}
+	}
+
+	before := NewHub(&HubConfig{})
+	go before.Run()
+	server := serveTestHub(before)
+	client := dialTestHub(t, server, "project=1")
+	broadcast(before, 1, 2)
+	client.next()
+	received := &struct {
+		Seq   uint64 `json:"seq"`
+		Epoch string `json:"epoch"`
+	}{}
+	if err := json.Unmarshal(client.next(), received); err != nil || received.Seq != 2 || len(received.Epoch) < 1 {
+		t.Fatalf("Unexpected sequence of the broadcast: %+v, error: %v", received, err)
+	}
+	client.conn.Close()
+	server.Close()
+
+	// The restarted hub numbers its broadcasts from the start, the same seq means another broadcast
+	after := NewHub(&HubConfig{})
+	go after.Run()
+	server = serveTestHub(after)
+	defer server.Close()
+	broadcast(after, 3, 4, 5)
+	epoch := after.(*Hub).epoch
+	if epoch == received.Epoch {
+		t.Fatalf("Restarted hub kept the epoch %s", epoch)
+	}
+
+	resumed := dialTestHub(t, server, "project=1")
+	defer resumed.conn.Close()
+	resumed.command(`{"id":"1","command":"resume","since":2,"epoch":"` + received.Epoch + `"}`)
+	resumed.expect(ResyncRequired, `"id":"1","data":{"since":2,"seq":3,"replayed":0,"epoch":"`+epoch+`"}`)
+	resumed.command(`{"id":"2","command":"resume","since":2}`)
+	resumed.expect(ResyncRequired, `"id":"2","data":{"since":2,"seq":3,"replayed":0,"epoch":"`+epoch+`"}`)
+	resumed.command(`{"id":"3","command":"resume","since":2,"epoch":"` + epoch + `"}`)
+	resumed.expect(PageUpdated, `{"seq":3,"epoch":"`+epoch+`","event":"ap_page_updated","data":{"page":{"id":5`)
+	resumed.expect(Resumed, `"id":"3","data":{"since":2,"seq":3,"replayed":1,"epoch":"`+epoch+`"}`)
+}
@@ -12,4 +12,6 @@ func (h *HubMock) Run() {}
 
 func (h *HubMock) Broadcast(message ApMessage) error { return nil }
 
+func (h *HubMock) BroadcastTo(projectId uint64, message ApMessage) error { return nil }
+
 func (h *HubMock) ServeWs(w http.ResponseWriter, r *http.Request) {}
@@ -2,12 +2,19 @@ package ws
 
 import (
 	"encoding/json"
+	"github.com/nskondratev/api-page-go-back/collections"
+	"github.com/nskondratev/api-page-go-back/environments"
 	"github.com/nskondratev/api-page-go-back/events"
+	"github.com/nskondratev/api-page-go-back/monitors"
 	"github.com/nskondratev/api-page-go-back/pages"
+	"github.com/nskondratev/api-page-go-back/registry"
+	"github.com/nskondratev/api-page-go-back/users"
 )
 
 type ApMessage interface {
 	BuildWsMessage() ([]byte, error)
+	// Topics are matched against the subscriptions of the clients
+	Topics() []string
 }
 
 type ApMessageEventEnvelope struct {
@@ -18,6 +25,33 @@ type ApMessagePageEnvelope struct {
 	Page *pages.Page `json:"page"`
 }
 
+type ApMessageTypeEnvelope struct {
+	Type *registry.Type `json:"type"`
+}
+
+type ApMessageRequestEnvelope struct {
+	Request *collections.Request `json:"request"`
+}
+
+type ApMessageFolderEnvelope struct {
+	Folder *collections.Folder `json:"folder"`
+}
+
+type ApMessageCollectionEnvelope struct {
+	EventId    uint64                  `json:"eventId"`
+	Collection *collections.Collection `json:"collection"`
+}
+
+type ApMessageEnvironmentEnvelope struct {
+	Environment *environments.Environment `json:"environment"`
+}
+
+type ApMessageMonitorEnvelope struct {
+	Monitor *monitors.Monitor `json:"monitor"`
+	// Check changed the status of the monitor
+	Check *monitors.Check `json:"check"`
+}
+
 type ApMessageOnlyIdEnvelope struct {
 	ID uint64 `json:"id"`
 }
@@ -32,9 +66,43 @@ type ApPageMessage struct {
 	Data       *ApMessagePageEnvelope `json:"data"`
 }
 
+type ApTypeMessage struct {
+	EventConst string                 `json:"event"`
+	Data       *ApMessageTypeEnvelope `json:"data"`
+}
+
+type ApRequestMessage struct {
+	EventConst string                    `json:"event"`
+	Data       *ApMessageRequestEnvelope `json:"data"`
+}
+
+type ApFolderMessage struct {
+	EventConst string                   `json:"event"`
+	Data       *ApMessageFolderEnvelope `json:"data"`
+}
+
+type ApCollectionMessage struct {
+	EventConst string                       `json:"event"`
+	Data       *ApMessageCollectionEnvelope `json:"data"`
+}
+
+type ApEnvironmentMessage struct {
+	EventConst string                        `json:"event"`
+	Data       *ApMessageEnvironmentEnvelope `json:"data"`
+}
+
+type ApMonitorMessage struct {
+	EventConst string                    `json:"event"`
+	Data       *ApMessageMonitorEnvelope `json:"data"`
+}
+
 type ApIdMessage struct {
 	EventConst string                   `json:"event"`
 	Data       *ApMessageOnlyIdEnvelope `json:"data"`
+	// EventType is the type of the deleted event, its subscribers of the type are notified
+	EventType string `json:"-"`
+	// EventId is the event of the deleted saved request or folder
+	EventId uint64 `json:"-"`
 }
 
 func (aep *ApEventMessage) BuildWsMessage() ([]byte, error) {
@@ -48,3 +116,144 @@ func (aip *ApIdMessage) BuildWsMessage() ([]byte, error) {
 func (app *ApPageMessage) BuildWsMessage() ([]byte, error) {
 	return json.Marshal(app)
 }
+
+func (atp *ApTypeMessage) BuildWsMessage() ([]byte, error) {
+	return json.Marshal(atp)
+}
+
+func (arp *ApRequestMessage) BuildWsMessage() ([]byte, error) {
+	return json.Marshal(arp)
+}
+
+func (afp *ApFolderMessage) BuildWsMessage() ([]byte, error) {
+	return json.Marshal(afp)
+}
+
+func (acp *ApCollectionMessage) BuildWsMessage() ([]byte, error) {
+	return json.Marshal(acp)
+}
+
+func (amp *ApMonitorMessage) BuildWsMessage() ([]byte, error) {
+	return json.Marshal(amp)
+}
+
+// BuildWsMessage masks values of secrets of the environment.
+func (aenp *ApEnvironmentMessage) BuildWsMessage() ([]byte, error) {
+	masked := *aenp
+	if aenp.Data != nil && aenp.Data.Environment != nil {
+		masked.Data = &ApMessageEnvironmentEnvelope{Environment: aenp.Data.Environment.Masked()}
+	}
+	return json.Marshal(&masked)
+}
+
+func (aep *ApEventMessage) Topics() []string {
+	if aep.Data == nil || aep.Data.Event == nil {
+		return []string{TopicEvents}
+	}
+	e := aep.Data.Event
+	return []string{TopicEvents, EventTopic(e.ID), EventTypeTopic(e.Type)}
+}
+
+func (aip *ApIdMessage) Topics() []string {
+	var id uint64
+	if aip.Data != nil {
+		id = aip.Data.ID
+	}
+	switch aip.EventConst {
+	case EventDeleted:
+		topics := []string{TopicEvents, EventTopic(id)}
+		if len(aip.EventType) > 0 {
+			topics = append(topics, EventTypeTopic(aip.EventType))
+		}
+		return topics
+	case PageDeleted:
+		return []string{TopicPages, PageTopic(id)}
+	case TypeDeleted:
+		return []string{TopicTypes, TypeTopic(id)}
+	case RequestDeleted, FolderDeleted:
+		if aip.EventId > 0 {
+			return []string{TopicRequests, RequestsTopic(aip.EventId)}
+		}
+		return []string{TopicRequests}
+	case EnvironmentDeleted:
+		return []string{TopicEnvironments, EnvironmentTopic(id)}
+	}
+	return nil
+}
+
+func (app *ApPageMessage) Topics() []string {
+	if app.Data == nil || app.Data.Page == nil {
+		return []string{TopicPages}
+	}
+	return []string{TopicPages, PageTopic(app.Data.Page.ID)}
+}
+
+func (atp *ApTypeMessage) Topics() []string {
+	if atp.Data == nil || atp.Data.Type == nil {
+		return []string{TopicTypes}
+	}
+	return []string{TopicTypes, TypeTopic(atp.Data.Type.ID)}
+}
+
+func (arp *ApRequestMessage) Topics() []string {
+	if arp.Data == nil || arp.Data.Request == nil {
+		return []string{TopicRequests}
+	}
+	return []string{TopicRequests, RequestsTopic(arp.Data.Request.EventId)}
+}
+
+func (afp *ApFolderMessage) Topics() []string {
+	if afp.Data == nil || afp.Data.Folder == nil {
+		return []string{TopicRequests}
+	}
+	return []string{TopicRequests, RequestsTopic(afp.Data.Folder.EventId)}
+}
+
+func (acp *ApCollectionMessage) Topics() []string {
+	if acp.Data == nil {
+		return []string{TopicRequests}
+	}
+	return []string{TopicRequests, RequestsTopic(acp.Data.EventId)}
+}
+
+func (aenp *ApEnvironmentMessage) Topics() []string {
+	if aenp.Data == nil || aenp.Data.Environment == nil {
+		return []string{TopicEnvironments}
+	}
+	return []string{TopicEnvironments, EnvironmentTopic(aenp.Data.Environment.ID)}
+}
+
+func (amp *ApMonitorMessage) Topics() []string {
+	if amp.Data == nil || amp.Data.Monitor == nil {
+		return []string{TopicMonitors}
+	}
+	return []string{TopicMonitors, MonitorTopic(amp.Data.Monitor.ID)}
+}
+
+type subscribedEnvelope struct {
+	Topics []string `json:"topics"`
+}
+
+type resumeEnvelope struct {
+	// Since is the sequence number sent by the client
+	Since uint64 `json:"since"`
+	// Seq is the sequence number of the last replayed broadcast, or of the last broadcast when the client resyncs
+	Seq      uint64 `json:"seq"`
+	Replayed int    `json:"replayed"`
+	// Epoch is the current epoch of the hub, the sequence numbers of other epochs can not be resumed
+	Epoch string `json:"epoch"`
+}
+
+type commandErrorEnvelope struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+	// Permission is the missing permission of the forbidden command
+	Permission users.Permission `json:"permission,omitempty"`
+}
+
+// replyMessage is sent only to the client which sent the command, with the ID of the command.
+type replyMessage struct {
+	EventConst string      `json:"event"`
+	Id         string      `json:"id,omitempty"`
+	Data       interface{} `json:"data"`
+}
@@ -0,0 +1,76 @@
+package ws
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// DefaultReplaySize is the number of the broadcasts kept for the resuming clients.
+const DefaultReplaySize = 1000
+
+// replayBuffer keeps the latest broadcasts in the ring, oldest first.
+type replayBuffer struct {
+	messages []*outbound
+	// start is the index of the oldest message
+	start int
+}
+
+func newReplayBuffer(size int) *replayBuffer {
+	return &replayBuffer{messages: make([]*outbound, 0, size)}
+}
+
+func (b *replayBuffer) add(message *outbound) {
+	if cap(b.messages) == 0 {
+		return
+	}
+	if len(b.messages) < cap(b.messages) {
+		b.messages = append(b.messages, message)
+		return
+	}
+	b.messages[b.start] = message
+	b.start = (b.start + 1) % len(b.messages)
+}
+
+// since returns the buffered messages after seq up to last, false when some of them are not buffered anymore.
+func (b *replayBuffer) since(seq, last uint64) ([]*outbound, bool) {
+	if seq >= last {
+		return nil, true
+	}
+	if len(b.messages) == 0 || b.messages[b.start].seq > seq+1 {
+		return nil, false
+	}
+	var messages []*outbound
+	for i := 0; i < len(b.messages); i++ {
+		m := b.messages[(b.start+i)%len(b.messages)]
+		if m.seq > seq && m.seq <= last {
+			messages = append(messages, m)
+		}
+	}
+	return messages, true
+}
+
+// newEpoch returns the random ID of the run of the hub. Sequence numbers start over with every run,
+// so the clients resume only within the epoch of the numbers they received.
+func newEpoch() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// withSeq adds the sequence number and the epoch to the built message.
+func withSeq(epoch string, seq uint64, data []byte) []byte {
+	if len(data) < 2 || data[0] != '{' {
+		return data
+	}
+	prefix := `{"seq":` + strconv.FormatUint(seq, 10) + `,"epoch":` + strconv.Quote(epoch)
+	m := make([]byte, 0, len(prefix)+len(data))
+	m = append(m, prefix...)
+	if data[1] != '}' {
+		m = append(m, ',')
+	}
+	return append(m, data[1:]...)
+}
@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"fmt"
+	"github.com/nskondratev/api-page-go-back/events"
+	"strconv"
+	"strings"
+)
+
+// TopicAll matches every message. New clients are subscribed to it until they subscribe to other topics.
+const TopicAll = "*"
+
+// Topics of all entities of a kind. Single entities have the topics like "event:12", events of a type
+// have the topics like "events:client", saved requests of an event have the topics like "requests:12".
+const (
+	TopicPages        = "pages"
+	TopicEvents       = "events"
+	TopicTypes        = "types"
+	TopicRequests     = "requests"
+	TopicEnvironments = "environments"
+	TopicMonitors     = "monitors"
+)
+
+// entityKinds are the prefixes of the topics of single entities
+var entityKinds = map[string]bool{
+	"page":        true,
+	"event":       true,
+	"type":        true,
+	"environment": true,
+	"monitor":     true,
+}
+
+func PageTopic(id uint64) string {
+	return "page:" + strconv.FormatUint(id, 10)
+}
+
+func EventTopic(id uint64) string {
+	return "event:" + strconv.FormatUint(id, 10)
+}
+
+// EventTypeTopic is the topic of the events of the type, like client or frontend.
+func EventTypeTopic(eventType string) string {
+	return TopicEvents + ":" + eventType
+}
+
+func TypeTopic(id uint64) string {
+	return "type:" + strconv.FormatUint(id, 10)
+}
+
+// RequestsTopic is the topic of the saved requests and the folders of the event.
+func RequestsTopic(eventId uint64) string {
+	return TopicRequests + ":" + strconv.FormatUint(eventId, 10)
+}
+
+func EnvironmentTopic(id uint64) string {
+	return "environment:" + strconv.FormatUint(id, 10)
+}
+
+func MonitorTopic(id uint64) string {
+	return "monitor:" + strconv.FormatUint(id, 10)
+}
+
+// ValidateTopic returns the error describing the malformed or unknown topic.
+func ValidateTopic(topic string) error {
+	switch topic {
+	case TopicAll, TopicPages, TopicEvents, TopicTypes, TopicRequests, TopicEnvironments, TopicMonitors:
+		return nil
+	}
+	i := strings.IndexByte(topic, ':')
+	if i < 0 {
+		return fmt.Errorf("unknown topic %q", topic)
+	}
+	kind, param := topic[:i], topic[i+1:]
+	switch {
+	case kind == TopicEvents:
+		if param != events.TypeClient && param != events.TypeFrontend {
+			return fmt.Errorf("unknown event type in topic %q", topic)
+		}
+		return nil
+	case kind == TopicRequests || entityKinds[kind]:
+		if id, err := strconv.ParseUint(param, 10, 64); err != nil || id < 1 {
+			return fmt.Errorf("invalid id in topic %q", topic)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown topic %q", topic)
+}